@@ -2,17 +2,27 @@ import React from 'react';
 import ReactDOM from 'react-dom';
 import { ApolloClient, InMemoryCache, ApolloProvider, HttpLink, from } from "@apollo/client";
 import { onError } from "@apollo/client/link/error";
+import { setContext } from "@apollo/client/link/context";
 import './index.css';
 import App from './App';
 import { AppStateProvider } from './state/AppStateContext';
 import { AppErrorProvider } from './util/errors';
 import { BrowserRouter } from 'react-router-dom';
 import { ERROR_PAGE_SIZE } from './pages/admin/Errors';
+import { getCookie } from './util/cookies';
 
 const httpLink = new HttpLink({
   uri: "/api/internal/graphql"
 });
 
+// Mutations are only accepted with the CSRF token from the cookie the server sets
+const csrfLink = setContext((_, { headers }) => ({
+  headers: {
+    ...headers,
+    "X-CSRF-Token": getCookie("csrf_token") || ""
+  }
+}));
+
 const errorLink = onError(({ graphQLErrors, networkError }) => {
   if (graphQLErrors) {
     graphQLErrors.forEach((e) => {
@@ -29,7 +39,7 @@ const errorLink = onError(({ graphQLErrors, networkError }) => {
 });
 
 const client = new ApolloClient({
-  link: from([errorLink, httpLink]),
+  link: from([errorLink, csrfLink, httpLink]),
   cache: new InMemoryCache({
     typePolicies: {
       Query: {
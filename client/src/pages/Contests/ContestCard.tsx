@@ -1,5 +1,5 @@
 import { Link } from "react-router-dom";
-import { Contest } from ".";
+import { Contest, contestStateLabels } from ".";
 import ActionMenu, { Action } from "../../shared/ActionMenu";
 import useAppState from "../../state/useAppState";
 
@@ -40,7 +40,7 @@ function ContestCard(props: ContestCardProps) {
       <div className="card-header">
         <h3>
           {props.contest.name}
-          {props.contest.isCurrent && <span className="badge">Active</span>}
+          <span className="badge">{contestStateLabels[props.contest.state]}</span>
         </h3>
         <ActionMenu actions={actions}/>
       </div>
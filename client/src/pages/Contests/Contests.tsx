@@ -1,5 +1,5 @@
 import React, { useState } from "react";
-import { Contest } from ".";
+import { Contest, contestStateLabels, contestTransitions } from ".";
 import Button from "../../shared/Button";
 import LoadingSpinner from "../../shared/LoadingSpinner";
 import { ConfirmModal, FormModal } from "../../shared/Modals";
@@ -20,8 +20,7 @@ const GET_ALL_CONTESTS = gql`
       badgeImageUrl
       startDate
       endDate
-      isCurrent
-      isVotingEnabled
+      state
     }
   }
 `;
@@ -43,10 +42,9 @@ const CREATE_CONTEST = gql`
       author
       badgeSlug
       badgeImageUrl
-      isCurrent
       startDate
       endDate
-      isVotingEnabled
+      state
     }
   }
 `;
@@ -60,10 +58,18 @@ const EDIT_CONTEST = gql`
       author
       badgeSlug
       badgeImageUrl
-      isCurrent
       startDate
       endDate
-      isVotingEnabled
+      state
+    }
+  }
+`;
+
+const TRANSITION_CONTEST = gql`
+  mutation TransitionContest($id: ID!, $state: ContestState!) {
+    contest: transitionContest(id: $id, state: $state) {
+      id
+      state
     }
   }
 `;
@@ -77,10 +83,9 @@ const DELETE_CONTEST = gql`
       author
       badgeSlug
       badgeImageUrl
-      isCurrent
       startDate
       endDate
-      isVotingEnabled
+      state
     }
   }
 `;
@@ -95,6 +100,7 @@ function Contests() {
   const { loading: isLoading, data: contestData, refetch: refetchContests } = useQuery<ContestData>(GET_ALL_CONTESTS, { onError: handleGQLError });
   const [createContest, { loading: createContestIsLoading }] = useMutation<ContestMutationResponse>(CREATE_CONTEST, { onError: handleGQLError });
   const [editContest, { loading: editContestIsLoading }] = useMutation<ContestMutationResponse>(EDIT_CONTEST, { onError: handleGQLError });
+  const [transitionContest, { loading: transitionContestIsLoading }] = useMutation<ContestMutationResponse>(TRANSITION_CONTEST, { onError: handleGQLError });
   const [deleteContest, { loading: deleteContestIsLoading }] = useMutation<ContestMutationResponse>(DELETE_CONTEST, { onError: handleGQLError });
 
   const openCreateContestModal = () => {
@@ -112,7 +118,6 @@ function Contests() {
           name: values.name,
           url: values.url,
           author: values.author,
-          isCurrent: values.is_current,
           startDate: values.start_date,
           endDate: values.end_date
         }
@@ -144,16 +149,23 @@ function Contests() {
           name: values.name,
           url: values.url,
           author: values.author,
-          isCurrent: values.is_current,
           startDate: values.start_date,
           endDate: values.end_date,
-          isVotingEnabled: values.voting_enabled,
           badgeSlug: values.badge_name,
           badgeImageUrl: values.badge_image_url
         }
       }
     });
 
+    if (values.state !== contestToEdit.state) {
+      await transitionContest({
+        variables: {
+          id: contestToEdit.id,
+          state: values.state
+        }
+      });
+    }
+
     refetchContests();
     closeEditContestModal();
   }
@@ -264,15 +276,6 @@ function Contests() {
               label: "End Date",
               defaultValue: "",
               required: true
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "is_current",
-              id: "is-current",
-              size: "LARGE",
-              label: "Current Contest",
-              description: "Enables judging for the contest, and shows an Active badge on the contests page.",
-              defaultValue: false
             }
           ]}
         />
@@ -285,7 +288,7 @@ function Contests() {
           handleSubmit={handleEditContest}
           handleCancel={closeEditContestModal}
           cols={4}
-          loading={editContestIsLoading}
+          loading={editContestIsLoading || transitionContestIsLoading}
           fields={[
             {
               fieldType: "INPUT",
@@ -358,22 +361,17 @@ function Contests() {
               defaultValue: contestToEdit.badgeImageUrl || ""
             },
             {
-              fieldType: "CHECKBOX",
-              name: "is_current",
-              id: "is-current",
-              size: "LARGE",
-              label: "Current Contest",
-              description: "Enables judging for the contest, and shows an Active badge on the contests page.",
-              defaultValue: contestToEdit.isCurrent
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "voting_enabled",
-              id: "voting-enabled",
+              fieldType: "SELECT",
+              name: "state",
+              id: "state",
               size: "LARGE",
-              label: "Enable Voting",
-              description: "Enables judges to vote for winning entries.",
-              defaultValue: contestToEdit.isVotingEnabled || false
+              label: "State",
+              description: "Entries are scored while judging, and judges vote for winners while voting. Winners are public once the results are published.",
+              defaultValue: contestToEdit.state,
+              choices: [contestToEdit.state, ...contestTransitions[contestToEdit.state]].map((s) => ({
+                text: contestStateLabels[s],
+                value: s
+              }))
             }
           ]}
         />
@@ -22,8 +22,28 @@ export type Contest = {
   badgeImageUrl: string | null
   startDate: string | null
   endDate: string | null
-  isCurrent: boolean
-  isVotingEnabled: boolean | null
+  state: ContestState
+}
+
+export type ContestState = "DRAFT" | "ACCEPTING_ENTRIES" | "JUDGING" | "VOTING" | "RESULTS_PUBLISHED" | "ARCHIVED"
+
+export const contestStateLabels: { [state in ContestState]: string } = {
+  DRAFT: "Draft",
+  ACCEPTING_ENTRIES: "Accepting Entries",
+  JUDGING: "Judging",
+  VOTING: "Voting",
+  RESULTS_PUBLISHED: "Results Published",
+  ARCHIVED: "Archived"
+}
+
+// The states a contest can move to from each state. The server enforces the same rules.
+export const contestTransitions: { [state in ContestState]: ContestState[] } = {
+  DRAFT: ["ACCEPTING_ENTRIES"],
+  ACCEPTING_ENTRIES: ["DRAFT", "JUDGING"],
+  JUDGING: ["VOTING"],
+  VOTING: ["JUDGING", "RESULTS_PUBLISHED"],
+  RESULTS_PUBLISHED: ["ARCHIVED"],
+  ARCHIVED: []
 }
 
 export default Contests;
\ No newline at end of file
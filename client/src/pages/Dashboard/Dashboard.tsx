@@ -23,9 +23,9 @@ type EvaluatorProgress = {
 type JudgingProgress = {
   user: Progress
   group: Progress
-  entries: Progress | null
-  evaluations: Progress | null
-  evaluators: EvaluatorProgress[] | null
+  entries?: Progress | null
+  evaluations?: Progress | null
+  evaluators?: EvaluatorProgress[] | null
 }
 
 type EntryCounts = {
@@ -36,11 +36,11 @@ type EntryCounts = {
 
 type GetJudgingProgressResponse = {
   judgingProgress: JudgingProgress
-  entryCounts: EntryCounts | null
+  entryCounts?: EntryCounts | null
 }
 
 const GET_JUDGING_PROGRESS = gql`
-  query GetJudgingProgress {
+  query GetJudgingProgress($viewStats: Boolean!) {
     judgingProgress {
       user {
         count
@@ -50,15 +50,15 @@ const GET_JUDGING_PROGRESS = gql`
         count
         total
       }
-      entries {
+      entries @include(if: $viewStats) {
         count
         total
       }
-      evaluations {
+      evaluations @include(if: $viewStats) {
         count
         total
       }
-      evaluators {
+      evaluators @include(if: $viewStats) {
         user {
           nickname
         }
@@ -66,7 +66,7 @@ const GET_JUDGING_PROGRESS = gql`
         total
       }
     }
-    entryCounts {
+    entryCounts @include(if: $viewStats) {
       flagged
       disqualified
       total
@@ -76,7 +76,9 @@ const GET_JUDGING_PROGRESS = gql`
 
 function Dashboard() {
   const { state } = useAppState();
-  const { loading: isLoading, data: progressData } = useQuery<GetJudgingProgressResponse>(GET_JUDGING_PROGRESS);
+  // Stats the user cannot see are left out, since asking for them is forbidden
+  const viewStats = state.isAdmin || !!state.user?.permissions.view_admin_stats;
+  const { loading: isLoading, data: progressData } = useQuery<GetJudgingProgressResponse>(GET_JUDGING_PROGRESS, { variables: { viewStats } });
 
   return (
     <React.Fragment>
@@ -3,7 +3,6 @@ import React, { useState } from "react";
 import ExternalLink from "../../shared/ExternalLink";
 import { Form } from "../../shared/Forms";
 import InfoModal from "../../shared/Modals/InfoModal/InfoModal";
-import { setCookie } from "../../util/cookies";
 import useAppError from "../../util/errors";
 import "./Login.css";
 
@@ -11,7 +10,8 @@ type LoginResponse = {
   data: {
     success: boolean
     isDisabled: boolean
-    token: string | null
+    twoFactorRequired: boolean
+    pendingToken: string | null
   } | null
 }
 
@@ -20,7 +20,19 @@ const LOGIN = gql`
     data: login(username: $username, password: $password) {
       success
       isDisabled
-      token
+      twoFactorRequired
+      pendingToken
+    }
+  }
+`;
+
+const VERIFY_TWO_FACTOR_LOGIN = gql`
+  mutation VerifyTwoFactorLogin($pendingToken: String!, $code: String!) {
+    data: verifyTwoFactorLogin(pendingToken: $pendingToken, code: $code) {
+      success
+      isDisabled
+      twoFactorRequired
+      pendingToken
     }
   }
 `;
@@ -31,7 +43,11 @@ function Login() {
   const [wasFailedLogin, setWasFailedLogin] = useState<boolean>(false);
   const [isAccountLocked, setIsAccountLocked] = useState<boolean>(false);
   const [previousLogin, setPreviousLogin] = useState<{ username: string, password: string }>({ username: "", password: "" });
-  const [login, { loading: loginIsLoading, data: loginResponse }] = useMutation<LoginResponse>(LOGIN, { onError: handleGQLError })
+  // OpenID Connect logins that need a code arrive with the pending token in the URL fragment
+  const [pendingToken, setPendingToken] = useState<string | null>(() => new URLSearchParams(window.location.hash.slice(1)).get("pendingToken"));
+  const [wasFailedCode, setWasFailedCode] = useState<boolean>(false);
+  const [login, { loading: loginIsLoading }] = useMutation<LoginResponse>(LOGIN, { onError: handleGQLError });
+  const [verifyTwoFactorLogin, { loading: verifyIsLoading }] = useMutation<LoginResponse>(VERIFY_TWO_FACTOR_LOGIN, { onError: handleGQLError });
 
   const handleLogin = async (values: { [name: string]: any }) => {
     const response = await login({
@@ -42,14 +58,18 @@ function Login() {
     });
     const data = response?.data?.data;
 
-    if (data?.success && data.token) {
-      setCookie("auth", data.token, 4);
+    // The server sets the session cookie
+    if (data?.success) {
       setWasFailedLogin(false);
       window.location.reload();
     }
     else if (data?.isDisabled) {
       setIsAccountLocked(true);
     }
+    else if (data?.twoFactorRequired && data.pendingToken) {
+      setWasFailedLogin(false);
+      setPendingToken(data.pendingToken);
+    }
     else {
       setPreviousLogin({
         username: values.username,
@@ -59,6 +79,39 @@ function Login() {
     }
   }
 
+  const handleVerifyCode = async (values: { [name: string]: any }) => {
+    if (!pendingToken) {
+      return;
+    }
+
+    const response = await verifyTwoFactorLogin({
+      variables: {
+        pendingToken,
+        code: values.code.trim()
+      }
+    });
+    const data = response?.data?.data;
+
+    if (data?.success) {
+      setWasFailedCode(false);
+      window.location.reload();
+    }
+    else if (data?.isDisabled) {
+      setPendingToken(null);
+      setIsAccountLocked(true);
+    }
+    else {
+      setWasFailedCode(true);
+    }
+  }
+
+  // Goes back to the password step, such as when the pending token has expired
+  const cancelTwoFactorLogin = () => {
+    window.history.replaceState(null, "", window.location.pathname + window.location.search);
+    setPendingToken(null);
+    setWasFailedCode(false);
+  }
+
   const openForgotPasswordModal = () => {
     setShowForgotPwModal(true);
   }
@@ -83,38 +136,70 @@ function Login() {
           </div>
           <div className="col-6 card-section container center">
             <div className="col-8">
-              <Form
-                onSubmit={handleLogin}
-                submitLabel="Login"
-                cols={12}
-                loading={loginIsLoading}
-                fields={[
-                  {
-                    fieldType: "INPUT",
-                    type: "text",
-                    name: "username",
-                    id: "username",
-                    label: "Username",
-                    defaultValue: previousLogin.username,
-                    required: true,
-                    size: "LARGE"
-                  },
-                  {
-                    fieldType: "INPUT",
-                    type: "password",
-                    name: "password",
-                    id: "password",
-                    label: "Password",
-                    defaultValue: previousLogin.password,
-                    required: true,
-                    size: "LARGE",
-                    button: {
-                      action: openForgotPasswordModal,
-                      text: "Forgot password?"
-                    }
+              {pendingToken ?
+                <React.Fragment>
+                  <p>Enter the code from your authenticator app, or one of your recovery codes.</p>
+                  <Form
+                    onSubmit={handleVerifyCode}
+                    submitLabel="Verify"
+                    cols={12}
+                    loading={verifyIsLoading}
+                    fields={[
+                      {
+                        fieldType: "INPUT",
+                        type: "text",
+                        name: "code",
+                        id: "code",
+                        label: "Two-factor code",
+                        defaultValue: "",
+                        required: true,
+                        size: "LARGE",
+                        button: {
+                          action: cancelTwoFactorLogin,
+                          text: "Back to login"
+                        }
+                      }
+                    ]}
+                  />
+
+                  {wasFailedCode &&
+                    <p className="failed-login">That code is incorrect or has expired. Try again, or go back to login.</p>
                   }
-                ]}
-              />
+                </React.Fragment>
+                :
+                <Form
+                  onSubmit={handleLogin}
+                  submitLabel="Login"
+                  cols={12}
+                  loading={loginIsLoading}
+                  fields={[
+                    {
+                      fieldType: "INPUT",
+                      type: "text",
+                      name: "username",
+                      id: "username",
+                      label: "Username",
+                      defaultValue: previousLogin.username,
+                      required: true,
+                      size: "LARGE"
+                    },
+                    {
+                      fieldType: "INPUT",
+                      type: "password",
+                      name: "password",
+                      id: "password",
+                      label: "Password",
+                      defaultValue: previousLogin.password,
+                      required: true,
+                      size: "LARGE",
+                      button: {
+                        action: openForgotPasswordModal,
+                        text: "Forgot password?"
+                      }
+                    }
+                  ]}
+                />
+              }
 
               {wasFailedLogin &&
                 <p className="failed-login">Your username or password is incorrect.</p>
@@ -5,6 +5,7 @@ import Button from "../../shared/Button";
 import LoadingSpinner from "../../shared/LoadingSpinner";
 import { ConfirmModal, FormModal } from "../../shared/Modals";
 import InfoModal from "../../shared/Modals/InfoModal/InfoModal";
+import { ContestState } from "../Contests";
 import ContestsSidebar from "../../shared/Sidebars/ContestsSidebar";
 import useAppState from "../../state/useAppState";
 import useAppError from "../../util/errors";
@@ -41,7 +42,7 @@ type GetContestResponse = {
   contest: {
     name: string
     winners: Entry[]
-    isVotingEnabled: boolean
+    state: ContestState
   }
 }
 
@@ -59,7 +60,7 @@ const GET_CONTEST = gql`
         }
         skillLevel
       }
-      isVotingEnabled
+      state
     }
   }
 `;
@@ -318,7 +319,7 @@ function Results() {
 
               <EntriesByAvgScoreCard
                 entriesByAvgScore={entriesData ? entriesData.entries : []}
-                votingEnabled={contestData?.contest.isVotingEnabled || false}
+                votingEnabled={contestData?.contest.state === "VOTING"}
                 handleShowEntryVotes={showVotesModal}
                 showVoteForm={showVoteForm}
                 handleAddWinner={showConfirmAddWinnerModal}
@@ -3,10 +3,10 @@ import React, { useState } from "react";
 import { User } from ".";
 import Button from "../../../shared/Button";
 import LoadingSpinner from "../../../shared/LoadingSpinner";
-import { ConfirmModal, FormModal } from "../../../shared/Modals";
+import { FormFields } from "../../../shared/Forms";
+import { FormModal } from "../../../shared/Modals";
 import AdminSidebar from "../../../shared/Sidebars/AdminSidebar";
 import useAppState from "../../../state/useAppState";
-import { setCookie } from "../../../util/cookies";
 import useAppError from "../../../util/errors";
 import request from "../../../util/request";
 import UserCard from "./UserCard";
@@ -19,41 +19,16 @@ type GetUsersResponse = {
   users: User[]
 }
 
+type PermissionDefinition = {
+  key: string
+  description: string
+}
+
 type GetUserPermissionsResponse = {
   user: {
-    permissions: {
-      add_entries: boolean
-      add_users: boolean
-      assign_entry_groups: boolean
-      assign_evaluator_groups: boolean
-      assume_user_identities: boolean
-      change_user_passwords: boolean
-      delete_all_evaluations: boolean
-      delete_all_tasks: boolean
-      delete_contests: boolean
-      delete_entries: boolean
-      delete_errors: boolean
-      delete_kb_content: boolean
-      edit_all_evaluations: boolean
-      edit_all_tasks: boolean
-      edit_contests: boolean
-      edit_entries: boolean
-      edit_kb_content: boolean
-      edit_user_profiles: boolean
-      judge_entries: boolean
-      manage_announcements: boolean
-      manage_judging_criteria: boolean
-      manage_judging_groups: boolean
-      manage_winners: boolean
-      publish_kb_content: boolean
-      view_admin_stats: boolean
-      view_all_evaluations: boolean
-      view_all_tasks: boolean
-      view_all_users: boolean
-      view_errors: boolean
-      view_judging_settings: boolean
-    }
+    permissionKeys: string[]
   }
+  permissionDefinitions: PermissionDefinition[]
 }
 
 const GET_ACTIVE_USERS = gql`
@@ -97,38 +72,11 @@ const GET_INACTIVE_USERS = gql`
 const GET_USER_PERMISSIONS = gql`
   query GetUserPermissions($userId: ID!) {
     user(id: $userId) {
-      permissions {
-        add_entries
-        add_users
-        assign_entry_groups
-        assign_evaluator_groups
-        assume_user_identities
-        change_user_passwords
-        delete_all_evaluations
-        delete_all_tasks
-        delete_contests
-        delete_entries
-        delete_errors
-        delete_kb_content
-        edit_all_evaluations
-        edit_all_tasks
-        edit_contests
-        edit_entries
-        edit_kb_content
-        edit_user_profiles
-        judge_entries
-        manage_announcements
-        manage_judging_criteria
-        manage_judging_groups
-        manage_winners
-        publish_kb_content
-        view_admin_stats
-        view_all_evaluations
-        view_all_tasks
-        view_all_users
-        view_errors
-        view_judging_settings
-      }
+      permissionKeys
+    }
+    permissionDefinitions {
+      key
+      description
     }
   }
 `;
@@ -190,89 +138,36 @@ const EDIT_USER_PROFILE = gql`
 `;
 
 type EditUserPermissionsResponse = {
-  permissions: {
-    add_entries: boolean
-    add_users: boolean
-    assign_entry_groups: boolean
-    assign_evaluator_groups: boolean
-    assume_user_identities: boolean
-    change_user_passwords: boolean
-    delete_all_evaluations: boolean
-    delete_all_tasks: boolean
-    delete_contests: boolean
-    delete_entries: boolean
-    delete_errors: boolean
-    delete_kb_content: boolean
-    edit_all_evaluations: boolean
-    edit_all_tasks: boolean
-    edit_contests: boolean
-    edit_entries: boolean
-    edit_kb_content: boolean
-    edit_user_profiles: boolean
-    judge_entries: boolean
-    manage_announcements: boolean
-    manage_judging_criteria: boolean
-    manage_judging_groups: boolean
-    manage_winners: boolean
-    publish_kb_content: boolean
-    view_admin_stats: boolean
-    view_all_evaluations: boolean
-    view_all_tasks: boolean
-    view_all_users: boolean
-    view_errors: boolean
-    view_judging_settings: boolean
+  user: {
+    id: number
+    permissionKeys: string[]
   }
 }
 
 const EDIT_USER_PERMISSIONS = gql`
-  mutation EditUserPermissions($id: ID!, $input: EditUserPermissionsInput!) {
-    editUserPermissions(id: $id, input: $input) {
-      add_entries
-      add_users
-      assign_entry_groups
-      assign_evaluator_groups
-      assume_user_identities
-      change_user_passwords
-      delete_all_evaluations
-      delete_all_tasks
-      delete_contests
-      delete_entries
-      delete_errors
-      delete_kb_content
-      edit_all_evaluations
-      edit_all_tasks
-      edit_contests
-      edit_entries
-      edit_kb_content
-      edit_user_profiles
-      judge_entries
-      manage_announcements
-      manage_judging_criteria
-      manage_judging_groups
-      manage_winners
-      publish_kb_content
-      view_admin_stats
-      view_all_evaluations
-      view_all_tasks
-      view_all_users
-      view_errors
-      view_judging_settings
+  mutation EditUserPermissions($id: ID!, $permissions: [String!]!) {
+    user: editUserPermissions(id: $id, permissions: $permissions) {
+      id
+      permissionKeys
     }
   }
 `;
 
+// Turns a permission key such as EDIT_KB_CONTENT into a label such as "Edit KB Content"
+const permissionLabel = (key: string) => {
+  return key.split("_").map((word) => word === "KB" ? word : word.charAt(0) + word.slice(1).toLowerCase()).join(" ");
+}
+
 type ImpersonateUserResponse = {
   impersonateUser: {
     success: boolean
-    token?: string
   }
 }
 
 const IMPERSONATE_USER = gql`
-  mutation ImpersonateUser($id: ID!) {
-    impersonateUser(id: $id) {
+  mutation ImpersonateUser($id: ID!, $reason: String!, $readOnly: Boolean) {
+    impersonateUser(id: $id, reason: $reason, readOnly: $readOnly) {
       success
-      token
     }
   }
 `;
@@ -400,18 +295,29 @@ function Users(props: UserProps) {
       return;
     }
 
+    // Each checkbox is named by its permission key
     await editUserPermissions({
       variables: {
         id: editUserPermissionsId,
-        input: {
-          ...values
-        }
+        permissions: Object.keys(values).filter((key) => values[key])
       }
     });
 
     closeEditPermissionsModal();
   }
 
+  const createPermissionField = (definition: PermissionDefinition, heldKeys: string[]): FormFields => {
+    return {
+      fieldType: "CHECKBOX",
+      name: definition.key,
+      id: definition.key.toLowerCase().replace(/_/g, "-"),
+      label: permissionLabel(definition.key),
+      description: definition.description,
+      defaultValue: heldKeys.includes(definition.key),
+      size: "LARGE"
+    }
+  }
+
   const openImpersonateUserModal = (userId: number) => {
     setImpersonateUserId(userId);
   }
@@ -420,17 +326,18 @@ function Users(props: UserProps) {
     setImpersonateUserId(null);
   }
 
-  const handleImpersonateUser = async (id: string) => {
+  const handleImpersonateUser = async (values: { [name: string]: any }) => {
     const { data } = await impersonateUser({
       variables: {
-        id: id,
+        id: impersonateUserId,
+        reason: values.reason,
+        readOnly: values.read_only
       },
     });
 
     closeImpersonateUserModal();
 
-    if (data?.impersonateUser.success && data.impersonateUser.token) {
-      setCookie("auth", data.impersonateUser.token, 4);
+    if (data?.impersonateUser.success) {
       window.location.reload();
     }
   }
@@ -670,293 +577,39 @@ function Users(props: UserProps) {
           handleCancel={closeEditPermissionsModal}
           cols={4}
           loading={editUserPermissionsIsLoading}
-          fields={[
-            {
-              fieldType: "CHECKBOX",
-              name: "judge_entries",
-              id: "judge-entries",
-              label: "Judge Entries",
-              description: "Allows the user to score entries. Disable this for view-only users.",
-              defaultValue: permissionsData.user.permissions.judge_entries,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "manage_announcements",
-              id: "manage-announcements",
-              label: "Manage Announcements",
-              description: "Allows the user to create, edit, and delete announcements.",
-              defaultValue: permissionsData.user.permissions.manage_announcements,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "view_admin_stats",
-              id: "view-admin-stats",
-              label: "View Admin Stats",
-              description: "Allows the user to view admin stats on the dashboard page.",
-              defaultValue: permissionsData.user.permissions.view_admin_stats,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "edit_contests",
-              id: "edit-contests",
-              label: "Edit Contests",
-              description: "Allows the user to create and edit contests.",
-              defaultValue: permissionsData.user.permissions.edit_contests,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "delete_contests",
-              id: "delete-contests",
-              label: "Delete Contests",
-              description: "Allows the user to delete contests and all their associated data.",
-              defaultValue: permissionsData.user.permissions.delete_contests,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "add_entries",
-              id: "add-entries",
-              label: "Import Entries",
-              description: "Allows the user to bulk import and individually add entries.",
-              defaultValue: permissionsData.user.permissions.add_entries,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "edit_entries",
-              id: "edit-entries",
-              label: "Edit Entries",
-              description: "Allows the user to edit all entries.",
-              defaultValue: permissionsData.user.permissions.edit_entries,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "delete_entries",
-              id: "delete-entries",
-              label: "Delete Entries",
-              description: "Allows the user to delete entries and their associated data.",
-              defaultValue: permissionsData.user.permissions.delete_entries,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "assign_entry_groups",
-              id: "assign-entry-groups",
-              label: "Assign Entry Groups",
-              description: "Allows the user to assign entries to judging groups.",
-              defaultValue: permissionsData.user.permissions.assign_entry_groups,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "manage_winners",
-              id: "manage-winners",
-              label: "Manage Winners",
-              description: "Allows the user to add and remove winning entries.",
-              defaultValue: permissionsData.user.permissions.manage_winners,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "view_all_evaluations",
-              id: "view-all-evaluations",
-              label: "View All Evaluations",
-              description: "Allows the user to view all evaluations by other users.",
-              defaultValue: permissionsData.user.permissions.view_all_evaluations,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "edit_all_evaluations",
-              id: "edit-all-evaluations",
-              label: "Edit All Evaluations",
-              description: "Allows the user to edit all evaluations by other users.",
-              defaultValue: permissionsData.user.permissions.edit_all_evaluations,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "delete_all_evaluations",
-              id: "delete-all-evaluations",
-              label: "Delete All Evaluations",
-              description: "Allows the user to delete all evaluations by other users",
-              defaultValue: permissionsData.user.permissions.delete_all_evaluations,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "view_all_tasks",
-              id: "view-all-tasks",
-              label: "View All Tasks",
-              description: "Allows the user to view all created tasks.",
-              defaultValue: permissionsData.user.permissions.view_all_tasks,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "edit_all_tasks",
-              id: "edit-all-tasks",
-              label: "Edit All Tasks",
-              description: "Allows the user to edit all created tasks.",
-              defaultValue: permissionsData.user.permissions.edit_all_tasks,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "delete_all_tasks",
-              id: "delete-all-tasks",
-              label: "Delete All Tasks",
-              description: "Allows the user to delete all created tasks.",
-              defaultValue: permissionsData.user.permissions.delete_all_tasks,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "view_judging_settings",
-              id: "view-judging-settings",
-              label: "View Judging Settings",
-              description: "Allows the user to view the judging settings page.",
-              defaultValue: permissionsData.user.permissions.view_judging_settings,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "manage_judging_groups",
-              id: "manage-judging-groups",
-              label: "Edit Judging Groups",
-              description: "Allows the user to create and edit judging groups.",
-              defaultValue: permissionsData.user.permissions.manage_judging_groups,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "assign_evaluator_groups",
-              id: "assign-evaluator-groups",
-              label: "Assign Judging Groups",
-              description: "Allows the user to assign evaluators to judging groups.",
-              defaultValue: permissionsData.user.permissions.assign_evaluator_groups,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "manage_judging_criteria",
-              id: "manage-judging-criteria",
-              label: "Manage Judging Criteria",
-              description: "Allows the user to manage the judging criteria shown on the judging page.",
-              defaultValue: permissionsData.user.permissions.manage_judging_criteria,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "edit_kb_content",
-              id: "edit-kb-content",
-              label: "Edit KB Content",
-              description: "Allows the user to create and edit KB sections and articles.",
-              defaultValue: permissionsData.user.permissions.edit_kb_content,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "publish_kb_content",
-              id: "publish-kb-content",
-              label: "Publish KB Content",
-              description: "Allows the user to publish all draft KB articles.",
-              defaultValue: permissionsData.user.permissions.publish_kb_content,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "delete_kb_content",
-              id: "delete-kb-content",
-              label: "Delete KB Content",
-              description: "Allows the user to delete all KB sections and articles.",
-              defaultValue: permissionsData.user.permissions.delete_kb_content,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "view_all_users",
-              id: "view-all-users",
-              label: "View All Users",
-              description: "Allows the user to view all evaluator accounts and personal information.",
-              defaultValue: permissionsData.user.permissions.view_all_users,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "add_users",
-              id: "add-users",
-              label: "Add Users",
-              description: "Allows the user to create new evaluator accounts.",
-              defaultValue: permissionsData.user.permissions.add_users,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "edit_user_profiles",
-              id: "edit-user-profiles",
-              label: "Edit User Profiles",
-              description: "Allows the user to edit evaluator profile information.",
-              defaultValue: permissionsData.user.permissions.edit_user_profiles,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "change_user_passwords",
-              id: "change-user-passwords",
-              label: "Change User Passwords",
-              description: "Allows the user to change other evaluators' passwords.",
-              defaultValue: permissionsData.user.permissions.change_user_passwords,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "assume_user_identities",
-              id: "assume-user-identities",
-              label: "Assume User Identities",
-              description: "Allows the user to log in as another user.",
-              defaultValue: permissionsData.user.permissions.assume_user_identities,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "view_errors",
-              id: "view-errors",
-              label: "View Errors",
-              description: "Allows the user to view all logged errors.",
-              defaultValue: permissionsData.user.permissions.view_errors,
-              size: "LARGE"
-            },
-            {
-              fieldType: "CHECKBOX",
-              name: "delete_errors",
-              id: "delete-errors",
-              label: "Delete Errors",
-              description: "Allows the user to delete all logged errors.",
-              defaultValue: permissionsData.user.permissions.delete_errors,
-              size: "LARGE"
-            },
-          ]}
+          fields={permissionsData.permissionDefinitions.map((d) => createPermissionField(d, permissionsData.user.permissionKeys))}
         />
       }
 
       {impersonateUserId &&
-        <ConfirmModal
+        <FormModal
           title="Impersonate user?"
-          confirmLabel="Impersonate User"
-          handleConfirm={handleImpersonateUser}
+          submitLabel="Impersonate User"
+          handleSubmit={handleImpersonateUser}
           handleCancel={closeImpersonateUserModal}
           loading={impersonateUserIsLoading}
-          data={impersonateUserId}
-        >
-          <p>Are you sure you want to impersonate this user?</p>
-          <p>Any actions you take while impersonating the user will be as if the user took the actions themselves.</p>
-        </ConfirmModal>
+          fields={[
+            {
+              fieldType: "TEXTAREA",
+              name: "reason",
+              id: "reason",
+              size: "LARGE",
+              label: "Reason",
+              description: "Any actions you take while impersonating the user will be as if the user took the actions themselves. The reason is recorded with the impersonation.",
+              defaultValue: "",
+              required: true
+            },
+            {
+              fieldType: "CHECKBOX",
+              name: "read_only",
+              id: "read-only",
+              size: "LARGE",
+              label: "Read Only",
+              description: "Prevents any changes while impersonating the user.",
+              defaultValue: true
+            }
+          ]}
+        />
       }
     </React.Fragment>
   );
@@ -1,7 +1,6 @@
 import { gql, useMutation } from "@apollo/client";
 import { Link } from "react-router-dom";
 import useAppState from "../../state/useAppState";
-import { setCookie } from "../../util/cookies";
 import useAppError from "../../util/errors";
 import Button from "../Button/Button";
 import "./Header.css";
@@ -9,7 +8,6 @@ import "./Header.css";
 type ReturnFromImpersonationResponse = {
   returnFromImpersonation: {
     success: boolean
-    token?: string
   }
 }
 
@@ -17,7 +15,6 @@ const RETURN_FROM_IMPERSONATION = gql`
   mutation ReturnFromImpersonation {
     returnFromImpersonation {
       success
-      token
     }
   }
 `;
@@ -30,8 +27,7 @@ function Header() {
   async function handleReturnToAccount() {
     const { data } = await returnFromImpersonation();
 
-    if (data?.returnFromImpersonation.success && data.returnFromImpersonation.token) {
-      setCookie('auth', data.returnFromImpersonation.token, 4);
+    if (data?.returnFromImpersonation.success) {
       window.location.reload();
     }
   }
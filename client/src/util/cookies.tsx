@@ -1,6 +1,5 @@
-export function setCookie(cname: string, cvalue: string | number, exhours: number) {
-  const d = new Date();
-  d.setTime(d.getTime() + (exhours * 60 * 60 * 1000));
-  let expires = "expires=" + d.toUTCString();
-  document.cookie = cname + "=" + cvalue + ";" + expires + ";path=/";
+export function getCookie(cname: string): string | undefined {
+  const prefix = cname + "=";
+  const cookie = document.cookie.split(";").map(c => c.trim()).find(c => c.startsWith(prefix));
+  return cookie && decodeURIComponent(cookie.substring(prefix.length));
 }
\ No newline at end of file
@@ -0,0 +1,63 @@
+// Command migrate applies or reverts the database migrations in internal/db/migrations.
+//
+// Usage:
+//
+//	go run ./cmd/migrate up         Applies all pending migrations
+//	go run ./cmd/migrate down [n]   Reverts the n most recent migrations (default 1)
+//	go run ./cmd/migrate version    Prints the current schema version
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/KA-Challenge-Council/Bema/internal/config"
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db.InitDB(cfg.Database)
+
+	switch os.Args[1] {
+	case "up":
+		if err := db.Migrate(db.DB); err != nil {
+			log.Fatal(err)
+		}
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			n, err := strconv.Atoi(os.Args[2])
+			if err != nil || n < 1 {
+				usage()
+			}
+			steps = n
+		}
+		if err := db.MigrateDown(db.DB, steps); err != nil {
+			log.Fatal(err)
+		}
+	case "version":
+		version, err := db.SchemaVersion(db.DB)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(version)
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: migrate up | down [n] | version")
+	os.Exit(2)
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// Serializes a DateTime as an RFC 3339 string in UTC
+func MarshalDateTime(t time.Time) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		io.WriteString(w, strconv.Quote(t.UTC().Format(time.RFC3339)))
+	})
+}
+
+// Parses a DateTime from an RFC 3339 string
+func UnmarshalDateTime(v interface{}) (time.Time, error) {
+	str, ok := v.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("DateTime must be an RFC 3339 string")
+	}
+
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("DateTime must be an RFC 3339 string: %w", err)
+	}
+
+	return t.UTC(), nil
+}
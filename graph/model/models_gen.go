@@ -2,6 +2,13 @@
 
 package model
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
 // An announcement message
 type Announcement struct {
 	// A unique integer ID
@@ -10,6 +17,8 @@ type Announcement struct {
 	Author *User `json:"author"`
 	// The creation date of the announcement
 	Created string `json:"created"`
+	// The time the announcement was created
+	CreatedAt time.Time `json:"createdAt"`
 	// The announcement title
 	Title string `json:"title"`
 	// The announcement body
@@ -28,6 +37,82 @@ type AnnouncementInput struct {
 	IsPublic bool `json:"isPublic"`
 }
 
+// A personal access token for scripting against the API
+type APIToken struct {
+	// A unique integer ID
+	ID int `json:"id"`
+	// A name describing what the token is used for
+	Name string `json:"name"`
+	// The first characters of the token, to tell tokens apart
+	Prefix string `json:"prefix"`
+	// The keys of the permissions the token may use
+	Permissions []string `json:"permissions"`
+	// When the token was created
+	CreatedAt time.Time `json:"createdAt"`
+	// When the token stops working, if it expires
+	ExpiresAt *time.Time `json:"expiresAt"`
+	// When the token was last used
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+}
+
+// A paginated list of audit log entries
+type AuditLogConnection struct {
+	// The entries on this page
+	Edges []*AuditLogEdge `json:"edges"`
+	// Information about the position of this page
+	PageInfo *PageInfo `json:"pageInfo"`
+	// The total number of matching entries across every page
+	TotalCount int `json:"totalCount"`
+}
+
+// A single entry in a paginated list
+type AuditLogEdge struct {
+	// An opaque cursor that can be passed as the after argument to fetch the items after this one
+	Cursor string `json:"cursor"`
+	// The audit log entry at this position
+	Node *AuditLogEntry `json:"node"`
+}
+
+// A record of one use of a privileged mutation. Records cannot be changed or deleted.
+type AuditLogEntry struct {
+	// A unique integer ID
+	ID int `json:"id"`
+	// The user the mutation was made as
+	Actor *User `json:"actor"`
+	// The user who was impersonating the actor, if the mutation was made during impersonation
+	RealActor *User `json:"realActor"`
+	// The name of the mutation, ex. disqualifyEntry
+	Mutation string `json:"mutation"`
+	// The kind of object that was changed
+	TargetType AuditTarget `json:"targetType"`
+	// The IDs of the objects that were changed
+	TargetIds []int `json:"targetIds"`
+	// The target before the change as JSON, or null if it did not exist
+	Before *string `json:"before"`
+	// The target after the change as JSON, or null if it no longer exists
+	After *string `json:"after"`
+	// The IP address the mutation was made from
+	IPAddress *string `json:"ipAddress"`
+	// When the mutation was made
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Narrows down the audit log. Every field that is set must match.
+type AuditLogFilter struct {
+	// Only include mutations made by this user, either as themselves or while impersonating someone else
+	ActorID *int `json:"actorId"`
+	// Only include uses of this mutation, ex. disqualifyEntry
+	Mutation *string `json:"mutation"`
+	// Only include changes to this kind of object
+	TargetType *AuditTarget `json:"targetType"`
+	// Only include changes to the object with this ID. Use with targetType.
+	TargetID *int `json:"targetId"`
+	// Only include mutations made at or after this time
+	Since *time.Time `json:"since"`
+	// Only include mutations made before this time
+	Until *time.Time `json:"until"`
+}
+
 // A contest
 type Contest struct {
 	// A unique integer id of the contest
@@ -42,15 +127,21 @@ type Contest struct {
 	BadgeSlug *string `json:"badgeSlug"`
 	// A url to the badge image
 	BadgeImageURL *string `json:"badgeImageUrl"`
-	// Indicates whether the contest is active (accepting entries or being judged). This must be enabled for users to score entries
+	// Where the contest is in its lifecycle
+	State ContestState `json:"state"`
+	// Indicates whether the contest is accepting entries, being judged or being voted on
 	IsCurrent bool `json:"isCurrent"`
 	// The start date of the contest
 	StartDate *string `json:"startDate"`
+	// The start date of the contest, at midnight UTC
+	StartsAt *time.Time `json:"startsAt"`
 	// The end date (deadline) of the contest
 	EndDate *string `json:"endDate"`
-	// Indicates whether voting for winners is enabled for the contest. Requires authentication.
+	// The end date (deadline) of the contest, at midnight UTC
+	EndsAt *time.Time `json:"endsAt"`
+	// Indicates whether the contest is in the VOTING state. Requires authentication.
 	IsVotingEnabled *bool `json:"isVotingEnabled"`
-	// A list of winning entries
+	// A list of winning entries. Empty until the results are published, except for users with Manage Winners permission.
 	Winners []*Entry `json:"winners"`
 }
 
@@ -68,6 +159,34 @@ type Contestant struct {
 	ContestCount int `json:"contestCount"`
 }
 
+// A paginated list of contestants
+type ContestantConnection struct {
+	// The contestants on this page
+	Edges []*ContestantEdge `json:"edges"`
+	// Information about the position of this page
+	PageInfo *PageInfo `json:"pageInfo"`
+	// The total number of contestants across every page
+	TotalCount int `json:"totalCount"`
+}
+
+// A single contestant in a paginated list
+type ContestantEdge struct {
+	// An opaque cursor that can be passed as the after argument to fetch the items after this one
+	Cursor string `json:"cursor"`
+	// The contestant at this position
+	Node *Contestant `json:"node"`
+}
+
+// Input used for creating an API token
+type CreateAPITokenInput struct {
+	// A name describing what the token is used for
+	Name string `json:"name"`
+	// The keys of the permissions the token may use. Each must be held by the current user.
+	Permissions []string `json:"permissions"`
+	// When the token stops working. Leave empty for a token that does not expire.
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
 // The input required for creating a new contest
 type CreateContestInput struct {
 	// The name of the contest
@@ -76,8 +195,8 @@ type CreateContestInput struct {
 	URL string `json:"url"`
 	// The author of the announcement program code.
 	Author string `json:"author"`
-	// Indicates whether the contest is active (accepting entries or being judged). This must be enabled for users to score entries
-	IsCurrent bool `json:"isCurrent"`
+	// No longer supported, and setting it is an error. New contests start as drafts.
+	IsCurrent *bool `json:"isCurrent"`
 	// The start date of the contest
 	StartDate string `json:"startDate"`
 	// The end date (deadline) of the contest
@@ -112,6 +231,36 @@ type CreateUserInput struct {
 	TermStart string `json:"termStart"`
 }
 
+// A newly created API token
+type CreatedAPIToken struct {
+	// The token to send in the Authorization header. It is only shown once.
+	Token string `json:"token"`
+	// The token's details
+	APIToken *APIToken `json:"apiToken"`
+}
+
+// Statistics about the database connection pool
+type DatabasePoolStats struct {
+	// The maximum number of open connections allowed
+	MaxOpenConnections int `json:"maxOpenConnections"`
+	// The number of open connections, both in use and idle
+	OpenConnections int `json:"openConnections"`
+	// The number of connections currently in use
+	InUse int `json:"inUse"`
+	// The number of idle connections
+	Idle int `json:"idle"`
+	// The total number of times a query waited for a free connection
+	WaitCount int `json:"waitCount"`
+	// The total number of milliseconds spent waiting for a free connection
+	WaitDurationMs int `json:"waitDurationMs"`
+	// The number of connections closed because there were too many idle connections
+	MaxIdleClosed int `json:"maxIdleClosed"`
+	// The number of connections closed because they were idle for too long
+	MaxIdleTimeClosed int `json:"maxIdleTimeClosed"`
+	// The number of connections closed because they reached their maximum lifetime
+	MaxLifetimeClosed int `json:"maxLifetimeClosed"`
+}
+
 // The input required for editing a contest
 type EditContestInput struct {
 	// The name of the contest
@@ -120,8 +269,8 @@ type EditContestInput struct {
 	URL string `json:"url"`
 	// The author of the announcement program code.
 	Author string `json:"author"`
-	// Indicates whether the contest is active (accepting entries or being judged). This must be enabled for users to score entries
-	IsCurrent bool `json:"isCurrent"`
+	// No longer supported, and setting it is an error. The state is changed with transitionContest.
+	IsCurrent *bool `json:"isCurrent"`
 	// The start date of the contest
 	StartDate string `json:"startDate"`
 	// The end date (deadline) of the contest
@@ -130,8 +279,8 @@ type EditContestInput struct {
 	BadgeSlug *string `json:"badgeSlug"`
 	// A url to the badge image
 	BadgeImageURL *string `json:"badgeImageUrl"`
-	// Indicates whether voting for winners is enabled for the contest
-	IsVotingEnabled bool `json:"isVotingEnabled"`
+	// No longer supported, and setting it is an error. The state is changed with transitionContest.
+	IsVotingEnabled *bool `json:"isVotingEnabled"`
 }
 
 // The input required for editing an entry
@@ -184,68 +333,6 @@ type EditTaskInput struct {
 	DueDate string `json:"dueDate"`
 }
 
-type EditUserPermissionsInput struct {
-	// Allows the user to add individual and bulk import entries
-	AddEntries bool `json:"add_entries"`
-	// Allows the user to create new user accounts
-	AddUsers bool `json:"add_users"`
-	// Allows the user to assign entries to judging groups
-	AssignEntryGroups bool `json:"assign_entry_groups"`
-	// Allows the user to assign evaluators to judging groups
-	AssignEvaluatorGroups bool `json:"assign_evaluator_groups"`
-	// Allows the user to impersonate other users
-	AssumeUserIdentities bool `json:"assume_user_identities"`
-	// Allows the user to change the passwords of other users
-	ChangeUserPasswords bool `json:"change_user_passwords"`
-	// Allows the user to delete all evaluations
-	DeleteAllEvaluations bool `json:"delete_all_evaluations"`
-	// Allows the user to delete all tasks
-	DeleteAllTasks bool `json:"delete_all_tasks"`
-	// Allows the user to delete all contests and associated data
-	DeleteContests bool `json:"delete_contests"`
-	// Allows the user to delete all entries
-	DeleteEntries bool `json:"delete_entries"`
-	// Allows the user to delete all errors
-	DeleteErrors bool `json:"delete_errors"`
-	// Allows the user to delete all KB articles and sections
-	DeleteKbContent bool `json:"delete_kb_content"`
-	// Allows the user to edit all evaluations
-	EditAllEvaluations bool `json:"edit_all_evaluations"`
-	// Allows the user to edit all tasks
-	EditAllTasks bool `json:"edit_all_tasks"`
-	// Allows the user to edit all contests
-	EditContests bool `json:"edit_contests"`
-	// Allows the user to edit all entries
-	EditEntries bool `json:"edit_entries"`
-	// Allows the user to edit all KB articles and sections
-	EditKbContent bool `json:"edit_kb_content"`
-	// Allows the user to edit all user profiles
-	EditUserProfiles bool `json:"edit_user_profiles"`
-	JudgeEntries     bool `json:"judge_entries"`
-	// Allows the user to create, edit, and delete announcements
-	ManageAnnouncements bool `json:"manage_announcements"`
-	// Allows the user to create, edit, and delete judging criteria
-	ManageJudgingCriteria bool `json:"manage_judging_criteria"`
-	// Allows the user to create, edit, and delete judging groups. Needs the assign_evaluator_groups permission to also assign users to groups.
-	ManageJudgingGroups bool `json:"manage_judging_groups"`
-	// Allows the user to add and remove winning entries
-	ManageWinners bool `json:"manage_winners"`
-	// Allows the user to publish draft KB articles
-	PublishKbContent bool `json:"publish_kb_content"`
-	// Allows the user to view admin stats on the dashboard
-	ViewAdminStats bool `json:"view_admin_stats"`
-	// Allows the user to view all evaluations
-	ViewAllEvaluations bool `json:"view_all_evaluations"`
-	// Allows the user to view all tasks
-	ViewAllTasks bool `json:"view_all_tasks"`
-	// Allows the user to view all user accounts
-	ViewAllUsers bool `json:"view_all_users"`
-	// Allows the user to view all errors
-	ViewErrors bool `json:"view_errors"`
-	// Allows the user to view all judging settings
-	ViewJudgingSettings bool `json:"view_judging_settings"`
-}
-
 type EditUserProfileInput struct {
 	// The user's real name
 	Name string `json:"name"`
@@ -297,9 +384,12 @@ type Entry struct {
 	Votes int `json:"votes"`
 	// The date the entry program was created
 	Created string `json:"created"`
+	// The time the entry program was created
+	CreatedAt time.Time `json:"createdAt"`
 	// The height of the entry program canvas
 	Height int `json:"height"`
-	// Indicates if the entry is a winner of the contest
+	// Indicates if the entry is a winner of the contest. Always false until the results are published, except for users
+	// with Manage Winners permission.
 	IsWinner bool `json:"isWinner"`
 	// The judging group the entry is assigned to. Requires authentication.
 	Group *JudgingGroup `json:"group"`
@@ -323,6 +413,16 @@ type Entry struct {
 	JudgeVotes []*EntryVote `json:"judgeVotes"`
 }
 
+// A paginated list of entries
+type EntryConnection struct {
+	// The entries on this page
+	Edges []*EntryEdge `json:"edges"`
+	// Information about the position of this page
+	PageInfo *PageInfo `json:"pageInfo"`
+	// The total number of entries across every page
+	TotalCount int `json:"totalCount"`
+}
+
 // The number of entries for a contest
 type EntryCounts struct {
 	// The number of flagged entries
@@ -333,6 +433,14 @@ type EntryCounts struct {
 	Total int `json:"total"`
 }
 
+// A single entry in a paginated list
+type EntryEdge struct {
+	// An opaque cursor that can be passed as the after argument to fetch the items after this one
+	Cursor string `json:"cursor"`
+	// The entry at this position
+	Node *Entry `json:"node"`
+}
+
 // A judge vote submitted for an entry
 type EntryVote struct {
 	// A unique integer ID
@@ -353,6 +461,8 @@ type Error struct {
 	Stack *string `json:"stack"`
 	// The date and time the error occurred
 	Timestamp string `json:"timestamp"`
+	// The time the error occurred
+	OccurredAt time.Time `json:"occurredAt"`
 	// The origin of the network request associated with the error
 	RequestOrigin *string `json:"requestOrigin"`
 	// The referrer of the network request associated with the error
@@ -363,6 +473,24 @@ type Error struct {
 	User *User `json:"user"`
 }
 
+// A paginated list of logged errors
+type ErrorConnection struct {
+	// The errors on this page
+	Edges []*ErrorEdge `json:"edges"`
+	// Information about the position of this page
+	PageInfo *PageInfo `json:"pageInfo"`
+	// The total number of errors across every page
+	TotalCount int `json:"totalCount"`
+}
+
+// A single error in a paginated list
+type ErrorEdge struct {
+	// An opaque cursor that can be passed as the after argument to fetch the items after this one
+	Cursor string `json:"cursor"`
+	// The error at this position
+	Node *Error `json:"node"`
+}
+
 // An evaluation of an entry
 type Evaluation struct {
 	// A unique integer ID
@@ -385,6 +513,8 @@ type Evaluation struct {
 	SkillLevel string `json:"skillLevel"`
 	// The timestamp of when the evaluation was submitted
 	Created string `json:"created"`
+	// The time the evaluation was submitted
+	CreatedAt time.Time `json:"createdAt"`
 	// Indicates whether the current user can edit the evaluation
 	CanEdit bool `json:"canEdit"`
 }
@@ -405,19 +535,119 @@ type FullUserProfile struct {
 	IsAdmin bool `json:"isAdmin"`
 	// Indicates whether the acting user is being impersonated by an admin user
 	IsImpersonated bool `json:"isImpersonated"`
+	// Indicates whether the acting user is being impersonated in read-only mode, which rejects mutations
+	IsReadOnly bool `json:"isReadOnly"`
 	// Indicates whether the actor is logged in
 	LoggedIn bool `json:"loggedIn"`
 	// The ID of the actual user, if the current actor is being impersonated
 	OriginID *int `json:"originId"`
 	// The logged in user
 	User *User `json:"user"`
+	// Indicates the user holds a permission that requires two-factor authentication but has not enabled it. Those permissions are withheld until they do.
+	TwoFactorSetupRequired bool `json:"twoFactorSetupRequired"`
 }
 
 type ImpersonateUserResponse struct {
 	// Indicates if the impersonation was successful
 	Success bool `json:"success"`
-	// The impersonated user's auth token
+	// Always null. The impersonation session token is set in an HttpOnly cookie instead.
 	Token *string `json:"token"`
+	// When the impersonation session ends
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// A time a user logged in as another user with impersonateUser
+type Impersonation struct {
+	// A unique integer ID
+	ID int `json:"id"`
+	// The user who started the impersonation
+	Impersonator *User `json:"impersonator"`
+	// The user being impersonated
+	User *User `json:"user"`
+	// Why the impersonator needed to log in as the user
+	Reason string `json:"reason"`
+	// Indicates whether mutations were rejected during the impersonation
+	IsReadOnly bool `json:"isReadOnly"`
+	// The IP address the impersonation was started from
+	IPAddress *string `json:"ipAddress"`
+	// When the impersonation started
+	StartedAt time.Time `json:"startedAt"`
+	// When the impersonation session expires, or expired
+	ExpiresAt time.Time `json:"expiresAt"`
+	// When the impersonator returned to their own account, if they did
+	EndedAt *time.Time `json:"endedAt"`
+	// Indicates whether the impersonation session can still be used
+	IsActive bool `json:"isActive"`
+}
+
+type ImpersonationConnection struct {
+	// The impersonations on this page
+	Edges []*ImpersonationEdge `json:"edges"`
+	// Information about the position of this page
+	PageInfo *PageInfo `json:"pageInfo"`
+	// The total number of matching impersonations across every page
+	TotalCount int `json:"totalCount"`
+}
+
+// A single entry in a paginated list
+type ImpersonationEdge struct {
+	// An opaque cursor that can be passed as the after argument to fetch the items after this one
+	Cursor string `json:"cursor"`
+	// The impersonation at this position
+	Node *Impersonation `json:"node"`
+}
+
+// An invitation for someone to create an account
+type Invitation struct {
+	// A unique integer ID
+	ID int `json:"id"`
+	// The invitee's real name
+	Name string `json:"name"`
+	// The address the invitation was sent to
+	Email string `json:"email"`
+	// The KAID associated with the invitee's Khan Academy account
+	Kaid string `json:"kaid"`
+	// The start date of the invitee's term, at midnight UTC
+	TermStartsAt time.Time `json:"termStartsAt"`
+	// The end date of the invitee's term, at midnight UTC
+	TermEndsAt *time.Time `json:"termEndsAt"`
+	// The role the invitee is given when they accept
+	Role *Role `json:"role"`
+	// The judging group the invitee is assigned to when they accept
+	Group *JudgingGroup `json:"group"`
+	// The user who sent the invitation
+	InvitedBy *User `json:"invitedBy"`
+	// The account created when the invitation was accepted
+	User *User `json:"user"`
+	// Whether the invitation can still be used
+	Status InvitationStatus `json:"status"`
+	// When the invitation was sent
+	CreatedAt time.Time `json:"createdAt"`
+	// When the link stops working
+	ExpiresAt time.Time `json:"expiresAt"`
+	// When the invitee created their account
+	AcceptedAt *time.Time `json:"acceptedAt"`
+	// When the invitation was revoked
+	RevokedAt *time.Time `json:"revokedAt"`
+	// The invitation link. Only returned by inviteUser, since the link cannot be recovered later.
+	Link *string `json:"link"`
+}
+
+type InviteUserInput struct {
+	// The invitee's real name
+	Name string `json:"name"`
+	// The address to send the invitation to
+	Email string `json:"email"`
+	// The KAID associated with the invitee's Khan Academy account
+	Kaid string `json:"kaid"`
+	// The start date of the invitee's term, ex. 2022-01-31
+	TermStart string `json:"termStart"`
+	// The end date of the invitee's term, ex. 2023-01-31
+	TermEnd *string `json:"termEnd"`
+	// A role to give the invitee
+	RoleID *int `json:"roleId"`
+	// A judging group to assign the invitee to
+	GroupID *int `json:"groupId"`
 }
 
 // Represents a criterium used for scoring entries
@@ -461,11 +691,11 @@ type JudgingProgress struct {
 	User *Progress `json:"user"`
 	// The progress of the current user's group
 	Group *Progress `json:"group"`
-	// The number of entries that have received at least one evaluation
+	// The number of entries that have received at least one evaluation. Requires View Admin Stats permission.
 	Entries *Progress `json:"entries"`
-	// The total and expected number of evaluations
+	// The total and expected number of evaluations. Requires View Admin Stats permission.
 	Evaluations *Progress `json:"evaluations"`
-	// The progress of each evaluator
+	// The progress of each evaluator. Requires View Admin Stats permission.
 	Evaluators []*EvaluatorProgress `json:"evaluators"`
 }
 
@@ -483,6 +713,8 @@ type KBArticle struct {
 	Author *User `json:"author"`
 	// The timestamp of the last update to the article
 	LastUpdated string `json:"lastUpdated"`
+	// The time of the last update to the article
+	UpdatedAt time.Time `json:"updatedAt"`
 	// The visibility of the article. Requires Edit KB Content permission.
 	Visibility *string `json:"visibility"`
 	// Indicates whether the initial version of the article has been published. Requires Edit KB Content permission.
@@ -495,6 +727,16 @@ type KBArticle struct {
 	Drafts []*KBArticleDraft `json:"drafts"`
 }
 
+// A paginated list of KB articles
+type KBArticleConnection struct {
+	// The articles on this page
+	Edges []*KBArticleEdge `json:"edges"`
+	// Information about the position of this page
+	PageInfo *PageInfo `json:"pageInfo"`
+	// The total number of articles across every page
+	TotalCount int `json:"totalCount"`
+}
+
 type KBArticleDraft struct {
 	// A unique integer ID
 	ID int `json:"id"`
@@ -506,6 +748,16 @@ type KBArticleDraft struct {
 	Author *User `json:"author"`
 	// The timestamp of the last update to the article draft
 	LastUpdated string `json:"lastUpdated"`
+	// The time of the last update to the article draft
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// A single article in a paginated list
+type KBArticleEdge struct {
+	// An opaque cursor that can be passed as the after argument to fetch the items after this one
+	Cursor string `json:"cursor"`
+	// The article at this position
+	Node *KBArticle `json:"node"`
 }
 
 type KBArticleInput struct {
@@ -542,16 +794,56 @@ type KBSectionInput struct {
 	Visibility string `json:"visibility"`
 }
 
+// A username or IP address that is refusing logins after too many failed attempts
+type LoginLockout struct {
+	// A unique integer ID
+	ID int `json:"id"`
+	// Whether the lockout applies to a username or an IP address
+	Kind LoginThrottleKind `json:"kind"`
+	// The username, in lower case, or the IP address
+	Value string `json:"value"`
+	// The number of recent failed logins
+	FailedAttempts int `json:"failedAttempts"`
+	// When the most recent failed login happened
+	LastFailedAt time.Time `json:"lastFailedAt"`
+	// When logins will be accepted again
+	LockedUntil time.Time `json:"lockedUntil"`
+}
+
 type LoginResponse struct {
 	// Indicates if the login attempt was successful
 	Success bool `json:"success"`
-	// Indicates the user's account is disabled
+	// Indicates the user's account is disabled. Only set when the password was correct.
 	IsDisabled bool `json:"isDisabled"`
-	// The user's auth token
+	// Always null. The session token is set in an HttpOnly cookie instead.
 	Token *string `json:"token"`
+	// Indicates the password was correct and a two-factor code is needed to finish logging in with verifyTwoFactorLogin
+	TwoFactorRequired bool `json:"twoFactorRequired"`
+	// The token to pass to verifyTwoFactorLogin. It expires after a few minutes.
+	PendingToken *string `json:"pendingToken"`
+}
+
+// Information about a page of a paginated list. Pass first to choose the page size (default 20, max 100) and pass the endCursor of one page as after to fetch the next.
+type PageInfo struct {
+	// Indicates whether there are more items after this page
+	HasNextPage bool `json:"hasNextPage"`
+	// Indicates whether there are items before this page
+	HasPreviousPage bool `json:"hasPreviousPage"`
+	// The cursor of the first item on this page
+	StartCursor *string `json:"startCursor"`
+	// The cursor of the last item on this page
+	EndCursor *string `json:"endCursor"`
 }
 
-// The permissions set, associated with the User type
+// A permission that can be included in roles or granted to users
+type PermissionDefinition struct {
+	// The key of the permission, such as JUDGE_ENTRIES
+	Key string `json:"key"`
+	// A description of what the permission allows
+	Description string `json:"description"`
+}
+
+// The permissions set, associated with the User type. Permissions added later are only listed in User.permissionKeys.
 type Permissions struct {
 	// Allows the user to add individual and bulk import entries
 	AddEntries bool `json:"add_entries"`
@@ -622,6 +914,28 @@ type Progress struct {
 	Total int `json:"total"`
 }
 
+// A named bundle of permissions that can be assigned to users
+type Role struct {
+	// A unique integer ID
+	ID int `json:"id"`
+	// The name of the role
+	Name string `json:"name"`
+	// A description of what the role is for
+	Description string `json:"description"`
+	// The keys of the permissions included in the role, such as JUDGE_ENTRIES
+	Permissions []string `json:"permissions"`
+}
+
+// Input used for creating or editing a role
+type RoleInput struct {
+	// The name of the role
+	Name string `json:"name"`
+	// A description of what the role is for
+	Description string `json:"description"`
+	// The keys of the permissions included in the role
+	Permissions []string `json:"permissions"`
+}
+
 type ScoreEntryInput struct {
 	// The creativity score
 	Creativity float64 `json:"creativity"`
@@ -635,6 +949,42 @@ type ScoreEntryInput struct {
 	SkillLevel string `json:"skillLevel"`
 }
 
+// A login session on one device
+type Session struct {
+	// A unique integer ID
+	ID int `json:"id"`
+	// The browser and operating system the session was last used from, ex. Firefox on Windows
+	Device string `json:"device"`
+	// The full user agent the session was last used from
+	UserAgent *string `json:"userAgent"`
+	// The IP address the session was last used from
+	IPAddress *string `json:"ipAddress"`
+	// When the user logged in
+	CreatedAt time.Time `json:"createdAt"`
+	// When the session was last used
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	// When the session will end unless it is used again
+	ExpiresAt time.Time `json:"expiresAt"`
+	// Indicates whether this is the session making the request
+	IsCurrent bool `json:"isCurrent"`
+}
+
+// The status of the running server and its database
+type SystemStatus struct {
+	// The git version of the running build
+	Version string `json:"version"`
+	// The time the server process started
+	StartedAt time.Time `json:"startedAt"`
+	// The number of seconds the server has been running
+	UptimeSeconds int `json:"uptimeSeconds"`
+	// The version of the most recently applied database migration
+	SchemaVersion int `json:"schemaVersion"`
+	// The newest migration version this build includes. The database is behind if this is greater than schemaVersion.
+	ExpectedSchemaVersion int `json:"expectedSchemaVersion"`
+	// Statistics about the database connection pool
+	DatabasePool *DatabasePoolStats `json:"databasePool"`
+}
+
 // A single task that can be assigned to and completed by a user
 type Task struct {
 	// A uniqune integer ID
@@ -647,6 +997,28 @@ type Task struct {
 	Status string `json:"status"`
 	// The date the task needs to be completed by
 	DueDate string `json:"dueDate"`
+	// The date the task needs to be completed by, at midnight UTC
+	DueAt time.Time `json:"dueAt"`
+}
+
+// A new two-factor secret that has not been confirmed yet
+type TwoFactorEnrollment struct {
+	// The base32 secret, for entering into an authenticator app by hand
+	Secret string `json:"secret"`
+	// The otpauth:// URI to show as a QR code for authenticator apps to scan
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+// The two-factor authentication settings of the current user
+type TwoFactorStatus struct {
+	// Indicates whether two-factor authentication is enabled
+	Enabled bool `json:"enabled"`
+	// When two-factor authentication was enabled
+	EnabledAt *time.Time `json:"enabledAt"`
+	// Indicates the user holds a permission that requires two-factor authentication but has not enabled it
+	SetupRequired bool `json:"setupRequired"`
+	// The number of unused recovery codes
+	RecoveryCodesRemaining int `json:"recoveryCodesRemaining"`
 }
 
 // An evaluator account
@@ -665,16 +1037,26 @@ type User struct {
 	Email *string `json:"email"`
 	// Indicates if the account has been deactivated. Requires View All Users permission.
 	AccountLocked *bool `json:"accountLocked"`
-	// The permission set of the user. Requires View All Users permission.
+	// The permission set of the user, from their roles and direct grants. Requires View All Users permission.
 	Permissions *Permissions `json:"permissions"`
+	// The keys of every permission the user holds through their roles and direct grants, such as JUDGE_ENTRIES. Requires View All Users permission.
+	PermissionKeys []string `json:"permissionKeys"`
+	// The roles held by the user. Requires View All Users permission.
+	Roles []*Role `json:"roles"`
 	// Indicates whether the user is an admin, which allows them to perform all actions and access all data. Requires View All Users permission.
 	IsAdmin *bool `json:"isAdmin"`
 	// The timestamp of the user's last login. Requires View All Users permission.
 	LastLogin *string `json:"lastLogin"`
+	// The time of the user's last login. Requires View All Users permission.
+	LastLoginAt *time.Time `json:"lastLoginAt"`
 	// The start date of the user's term
 	TermStart *string `json:"termStart"`
+	// The start date of the user's term, at midnight UTC
+	TermStartsAt *time.Time `json:"termStartsAt"`
 	// The end date of the user's term
 	TermEnd *string `json:"termEnd"`
+	// The end date of the user's term, at midnight UTC
+	TermEndsAt *time.Time `json:"termEndsAt"`
 	// Indicates whether the user has email notifications enabled for new announcements. Requires View All Users permission.
 	NotificationsEnabled *bool `json:"notificationsEnabled"`
 	// The judging group the user is assigned to. Requires View Judging Settings permission.
@@ -684,3 +1066,227 @@ type User struct {
 	// The total number of contests the user has scored. Requires authentication.
 	TotalContestsJudged *int `json:"totalContestsJudged"`
 }
+
+// A paginated list of users
+type UserConnection struct {
+	// The users on this page
+	Edges []*UserEdge `json:"edges"`
+	// Information about the position of this page
+	PageInfo *PageInfo `json:"pageInfo"`
+	// The total number of users across every page
+	TotalCount int `json:"totalCount"`
+}
+
+// A single user in a paginated list
+type UserEdge struct {
+	// An opaque cursor that can be passed as the after argument to fetch the items after this one
+	Cursor string `json:"cursor"`
+	// The user at this position
+	Node *User `json:"node"`
+}
+
+// The kind of object an audited mutation changed
+type AuditTarget string
+
+const (
+	AuditTargetContest         AuditTarget = "CONTEST"
+	AuditTargetEntry           AuditTarget = "ENTRY"
+	AuditTargetEvaluation      AuditTarget = "EVALUATION"
+	AuditTargetInvitation      AuditTarget = "INVITATION"
+	AuditTargetJudgingCriteria AuditTarget = "JUDGING_CRITERIA"
+	AuditTargetJudgingGroup    AuditTarget = "JUDGING_GROUP"
+	AuditTargetLoginLockout    AuditTarget = "LOGIN_LOCKOUT"
+	AuditTargetRole            AuditTarget = "ROLE"
+	AuditTargetTwoFactorPolicy AuditTarget = "TWO_FACTOR_POLICY"
+	AuditTargetUser            AuditTarget = "USER"
+)
+
+var AllAuditTarget = []AuditTarget{
+	AuditTargetContest,
+	AuditTargetEntry,
+	AuditTargetEvaluation,
+	AuditTargetInvitation,
+	AuditTargetJudgingCriteria,
+	AuditTargetJudgingGroup,
+	AuditTargetLoginLockout,
+	AuditTargetRole,
+	AuditTargetTwoFactorPolicy,
+	AuditTargetUser,
+}
+
+func (e AuditTarget) IsValid() bool {
+	switch e {
+	case AuditTargetContest, AuditTargetEntry, AuditTargetEvaluation, AuditTargetInvitation, AuditTargetJudgingCriteria, AuditTargetJudgingGroup, AuditTargetLoginLockout, AuditTargetRole, AuditTargetTwoFactorPolicy, AuditTargetUser:
+		return true
+	}
+	return false
+}
+
+func (e AuditTarget) String() string {
+	return string(e)
+}
+
+func (e *AuditTarget) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AuditTarget(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AuditTarget", str)
+	}
+	return nil
+}
+
+func (e AuditTarget) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// Where a contest is in its lifecycle
+type ContestState string
+
+const (
+	// Being set up and not yet open. Can move to ACCEPTING_ENTRIES.
+	ContestStateDraft ContestState = "DRAFT"
+	// Open for entries, which can be imported. Can move back to DRAFT or on to JUDGING.
+	ContestStateAcceptingEntries ContestState = "ACCEPTING_ENTRIES"
+	// Entries are being scored, and late entries can still be imported. Can move to VOTING.
+	ContestStateJudging ContestState = "JUDGING"
+	// Judges are voting for winners. Can move back to JUDGING or on to RESULTS_PUBLISHED.
+	ContestStateVoting ContestState = "VOTING"
+	// The winners are public. Can move to ARCHIVED.
+	ContestStateResultsPublished ContestState = "RESULTS_PUBLISHED"
+	// The contest is over and its winners stay public. This is the final state.
+	ContestStateArchived ContestState = "ARCHIVED"
+)
+
+var AllContestState = []ContestState{
+	ContestStateDraft,
+	ContestStateAcceptingEntries,
+	ContestStateJudging,
+	ContestStateVoting,
+	ContestStateResultsPublished,
+	ContestStateArchived,
+}
+
+func (e ContestState) IsValid() bool {
+	switch e {
+	case ContestStateDraft, ContestStateAcceptingEntries, ContestStateJudging, ContestStateVoting, ContestStateResultsPublished, ContestStateArchived:
+		return true
+	}
+	return false
+}
+
+func (e ContestState) String() string {
+	return string(e)
+}
+
+func (e *ContestState) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ContestState(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ContestState", str)
+	}
+	return nil
+}
+
+func (e ContestState) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// Where an invitation is in its life
+type InvitationStatus string
+
+const (
+	// The link can still be used
+	InvitationStatusPending InvitationStatus = "PENDING"
+	// The invitee created their account
+	InvitationStatusAccepted InvitationStatus = "ACCEPTED"
+	// The link expired before it was used
+	InvitationStatusExpired InvitationStatus = "EXPIRED"
+	// The invitation was revoked before it was used
+	InvitationStatusRevoked InvitationStatus = "REVOKED"
+)
+
+var AllInvitationStatus = []InvitationStatus{
+	InvitationStatusPending,
+	InvitationStatusAccepted,
+	InvitationStatusExpired,
+	InvitationStatusRevoked,
+}
+
+func (e InvitationStatus) IsValid() bool {
+	switch e {
+	case InvitationStatusPending, InvitationStatusAccepted, InvitationStatusExpired, InvitationStatusRevoked:
+		return true
+	}
+	return false
+}
+
+func (e InvitationStatus) String() string {
+	return string(e)
+}
+
+func (e *InvitationStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = InvitationStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid InvitationStatus", str)
+	}
+	return nil
+}
+
+func (e InvitationStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// What a login lockout applies to
+type LoginThrottleKind string
+
+const (
+	LoginThrottleKindUsername  LoginThrottleKind = "USERNAME"
+	LoginThrottleKindIPAddress LoginThrottleKind = "IP_ADDRESS"
+)
+
+var AllLoginThrottleKind = []LoginThrottleKind{
+	LoginThrottleKindUsername,
+	LoginThrottleKindIPAddress,
+}
+
+func (e LoginThrottleKind) IsValid() bool {
+	switch e {
+	case LoginThrottleKindUsername, LoginThrottleKindIPAddress:
+		return true
+	}
+	return false
+}
+
+func (e LoginThrottleKind) String() string {
+	return string(e)
+}
+
+func (e *LoginThrottleKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LoginThrottleKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LoginThrottleKind", str)
+	}
+	return nil
+}
+
+func (e LoginThrottleKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
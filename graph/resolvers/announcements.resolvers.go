@@ -9,12 +9,10 @@ import (
 	"github.com/KA-Challenge-Council/Bema/graph/generated"
 	"github.com/KA-Challenge-Council/Bema/graph/model"
 	"github.com/KA-Challenge-Council/Bema/internal/auth"
-	err "github.com/KA-Challenge-Council/Bema/internal/errors"
-	"github.com/KA-Challenge-Council/Bema/internal/models"
 )
 
 func (r *announcementResolver) Author(ctx context.Context, obj *model.Announcement) (*model.User, error) {
-	user, err := models.GetUserById(ctx, obj.Author.ID)
+	user, err := r.Repos.Users.GetUserById(ctx, obj.Author.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -24,11 +22,7 @@ func (r *announcementResolver) Author(ctx context.Context, obj *model.Announceme
 func (r *mutationResolver) CreateAnnouncement(ctx context.Context, input model.AnnouncementInput) (*model.Announcement, error) {
 	user := auth.GetUserFromContext(ctx)
 
-	if !auth.HasPermission(user, auth.ManageAnnouncements) {
-		return nil, err.NewForbiddenError(ctx, "You do not have permission to create announcements.")
-	}
-
-	id, err := models.CreateAnnouncement(ctx, &input, user.ID)
+	id, err := r.Repos.Announcements.CreateAnnouncement(ctx, &input, user.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -37,13 +31,7 @@ func (r *mutationResolver) CreateAnnouncement(ctx context.Context, input model.A
 }
 
 func (r *mutationResolver) EditAnnouncement(ctx context.Context, id int, input model.AnnouncementInput) (*model.Announcement, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ManageAnnouncements) {
-		return nil, err.NewForbiddenError(ctx, "You do not have permission to edit announcements.")
-	}
-
-	err := models.EditAnnouncementById(ctx, id, &input)
+	err := r.Repos.Announcements.EditAnnouncementById(ctx, id, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -52,18 +40,12 @@ func (r *mutationResolver) EditAnnouncement(ctx context.Context, id int, input m
 }
 
 func (r *mutationResolver) DeleteAnnouncement(ctx context.Context, id int) (*model.Announcement, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ManageAnnouncements) {
-		return nil, err.NewForbiddenError(ctx, "You do not have permission to delete announcements.")
-	}
-
 	announcement, err := r.Query().Announcement(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	err = models.DeleteAnnouncementById(ctx, id)
+	err = r.Repos.Announcements.DeleteAnnouncementById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -75,13 +57,13 @@ func (r *queryResolver) Announcements(ctx context.Context) ([]*model.Announcemen
 	// If the user is logged in, return all messages. Otherwise, return only public messages
 	user := auth.GetUserFromContext(ctx)
 	if user != nil {
-		announcements, err := models.GetAllAnnouncements(ctx)
+		announcements, err := r.Repos.Announcements.GetAllAnnouncements(ctx)
 		if err != nil {
 			return []*model.Announcement{}, err
 		}
 		return announcements, nil
 	} else {
-		announcements, err := models.GetPublicAnnouncements(ctx)
+		announcements, err := r.Repos.Announcements.GetPublicAnnouncements(ctx)
 		if err != nil {
 			return []*model.Announcement{}, err
 		}
@@ -92,7 +74,7 @@ func (r *queryResolver) Announcements(ctx context.Context) ([]*model.Announcemen
 func (r *queryResolver) Announcement(ctx context.Context, id int) (*model.Announcement, error) {
 	user := auth.GetUserFromContext(ctx)
 
-	announcement, err := models.GetAnnouncementById(ctx, id)
+	announcement, err := r.Repos.Announcements.GetAnnouncementById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,77 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
+)
+
+func (r *mutationResolver) CreateAPIToken(ctx context.Context, input model.CreateAPITokenInput) (*model.CreatedAPIToken, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if err := requireSession(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// A token would outlast the impersonation
+	if user.IsImpersonated {
+		return nil, errs.NewForbiddenError(ctx, "You cannot create API tokens while impersonating a user.")
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, errs.NewBadRequestError(ctx, "Oops! The expiry date must be in the future.")
+	}
+
+	// Tokens can only be scoped to permissions the user holds
+	if err := checkCanGrant(ctx, user, input.Permissions); err != nil {
+		return nil, err
+	}
+
+	secret, err := auth.NewSecretToken()
+	if err != nil {
+		return nil, errs.NewInternalError(ctx, "An unexpected error occurred while creating an API token", err)
+	}
+	token := auth.APITokenPrefix + secret
+
+	id, err := r.Repos.APITokens.CreateAPIToken(ctx, user.ID, &input, auth.HashSecretToken(token), token[:len(auth.APITokenPrefix)+6])
+	if err != nil {
+		return nil, err
+	}
+
+	apiToken, err := r.Repos.APITokens.GetAPITokenById(ctx, user.ID, *id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CreatedAPIToken{
+		Token:    token,
+		APIToken: apiToken,
+	}, nil
+}
+
+func (r *mutationResolver) RevokeAPIToken(ctx context.Context, id int) (bool, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	err := r.Repos.APITokens.DeleteAPITokenById(ctx, user.ID, id)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *queryResolver) MyAPITokens(ctx context.Context) ([]*model.APIToken, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	tokens, err := r.Repos.APITokens.GetAPITokensByUserId(ctx, user.ID)
+	if err != nil {
+		return []*model.APIToken{}, err
+	}
+	return tokens, nil
+}
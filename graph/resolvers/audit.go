@@ -0,0 +1,263 @@
+package resolvers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+)
+
+// How a user looks in the audit log. Roles and permissions are stored as ids and keys.
+type userSnapshot struct {
+	*model.User
+	GroupID     *int     `json:"groupId"`
+	Roles       []int    `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
+
+// Loads an audited target as it is stored, for the before and after values. Returns nil when the
+// target does not exist. Targets without an id, such as policies, are loaded with a nil id.
+var auditSnapshots = map[model.AuditTarget]func(ctx context.Context, repos *models.Repositories, id *int) interface{}{
+	model.AuditTargetContest: func(ctx context.Context, repos *models.Repositories, id *int) interface{} {
+		if id == nil {
+			return nil
+		}
+		return orNil(repos.Contests.GetContestById(ctx, *id))
+	},
+	model.AuditTargetEntry: func(ctx context.Context, repos *models.Repositories, id *int) interface{} {
+		if id == nil {
+			return nil
+		}
+		return orNil(repos.Entries.GetEntryById(ctx, *id))
+	},
+	model.AuditTargetEvaluation: func(ctx context.Context, repos *models.Repositories, id *int) interface{} {
+		if id == nil {
+			return nil
+		}
+		return orNil(repos.Evaluations.GetEvaluationById(ctx, *id))
+	},
+	model.AuditTargetInvitation: func(ctx context.Context, repos *models.Repositories, id *int) interface{} {
+		if id == nil {
+			return nil
+		}
+		return orNil(repos.Invitations.GetInvitationById(ctx, *id))
+	},
+	model.AuditTargetJudgingCriteria: func(ctx context.Context, repos *models.Repositories, id *int) interface{} {
+		if id == nil {
+			return nil
+		}
+		return orNil(repos.Judging.GetJudgingCriteriaById(ctx, *id))
+	},
+	model.AuditTargetJudgingGroup: func(ctx context.Context, repos *models.Repositories, id *int) interface{} {
+		if id == nil {
+			return nil
+		}
+		return orNil(repos.Judging.GetJudgingGroupById(ctx, *id))
+	},
+	model.AuditTargetLoginLockout: func(ctx context.Context, repos *models.Repositories, id *int) interface{} {
+		if id == nil {
+			return nil
+		}
+		return orNil(repos.LoginThrottles.GetActiveLoginLockoutById(ctx, *id))
+	},
+	model.AuditTargetRole: func(ctx context.Context, repos *models.Repositories, id *int) interface{} {
+		if id == nil {
+			return nil
+		}
+		return orNil(repos.Roles.GetRoleById(ctx, *id))
+	},
+	model.AuditTargetTwoFactorPolicy: func(ctx context.Context, repos *models.Repositories, id *int) interface{} {
+		permissions, err := repos.TwoFactor.GetTwoFactorRequiredPermissions(ctx)
+		if err != nil {
+			return nil
+		}
+		return map[string]interface{}{"requiredPermissions": permissions}
+	},
+	model.AuditTargetUser: func(ctx context.Context, repos *models.Repositories, id *int) interface{} {
+		if id == nil {
+			return nil
+		}
+		user, err := repos.Users.GetUserById(ctx, *id)
+		if err != nil || user == nil {
+			return nil
+		}
+
+		// The group is stored by id, so the placeholder group of the model is left out
+		user.AssignedGroup = nil
+		snapshot := userSnapshot{User: user, Roles: []int{}}
+		if snapshot.GroupID, err = repos.Users.GetUserGroupById(ctx, *id); err != nil {
+			return nil
+		}
+		if snapshot.Permissions, err = repos.Users.GetUserPermissionKeysById(ctx, *id); err != nil {
+			return nil
+		}
+		roles, err := repos.Roles.GetRolesByUserId(ctx, *id)
+		if err != nil {
+			return nil
+		}
+		for _, role := range roles {
+			snapshot.Roles = append(snapshot.Roles, role.ID)
+		}
+		return snapshot
+	},
+}
+
+// Implements @audited. Records the mutation in the audit log once it has succeeded, with the
+// target as it was before and after. Audited changes to the same target run one at a time, so
+// another change cannot land between the before value and the mutation.
+func Audited(repos *models.Repositories) func(ctx context.Context, obj interface{}, next graphql.Resolver, target model.AuditTarget, idArg *string) (interface{}, error) {
+	locks := &auditLocks{locks: map[string]*auditLock{}}
+
+	return func(ctx context.Context, obj interface{}, next graphql.Resolver, target model.AuditTarget, idArg *string) (interface{}, error) {
+		// Every audited mutation needs a user, so anyone else is refused before they can hold up a change
+		if auth.GetUserFromContext(ctx) == nil {
+			return nil, errs.NewForbiddenError(ctx, "You must be logged in to use "+fieldName(ctx)+".")
+		}
+
+		snapshot := auditSnapshots[target]
+
+		var targetId *int
+		if idArg != nil {
+			if fc := graphql.GetFieldContext(ctx); fc != nil {
+				targetId = intArg(fc.Args[*idArg])
+			}
+		}
+
+		// Created objects, and targets without an id, share the lock for their type
+		lockId := 0
+		if targetId != nil {
+			lockId = *targetId
+		}
+		unlock := locks.lock(fmt.Sprintf("%s:%d", target, lockId))
+		defer unlock()
+
+		before := marshalSnapshot(snapshot(ctx, repos, targetId))
+
+		res, err := next(ctx)
+		if err != nil {
+			return res, err
+		}
+
+		// Created objects are identified by the returned object
+		if idArg == nil {
+			targetId = resultId(res)
+		}
+
+		record := &models.AuditRecord{
+			Mutation:   fieldName(ctx),
+			TargetType: target,
+			TargetIDs:  []int{},
+			Before:     before,
+			After:      marshalSnapshot(snapshot(ctx, repos, targetId)),
+			IPAddress:  auth.GetRequestInfoFromContext(ctx).IP,
+		}
+		if targetId != nil {
+			record.TargetIDs = append(record.TargetIDs, *targetId)
+		}
+		if user := auth.GetUserFromContext(ctx); user != nil {
+			record.ActorID = &user.ID
+			if user.IsImpersonated {
+				record.RealActorID = user.OriginID
+			}
+		}
+
+		// The change has already been made, but it must not look like it went unrecorded
+		if err := repos.AuditLog.CreateAuditLogEntry(ctx, record); err != nil {
+			return res, err
+		}
+
+		return res, nil
+	}
+}
+
+// Hands out one lock per audited target. Locks are held in this process, without a database
+// connection, and are dropped once nobody holds or waits for them.
+type auditLocks struct {
+	mu    sync.Mutex
+	locks map[string]*auditLock
+}
+
+type auditLock struct {
+	sync.Mutex
+	// The number of callers holding or waiting for the lock
+	users int
+}
+
+// Waits for the lock on the key, and returns the function that releases it
+func (l *auditLocks) lock(key string) func() {
+	l.mu.Lock()
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &auditLock{}
+		l.locks[key] = lock
+	}
+	lock.users++
+	l.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		l.mu.Lock()
+		lock.users--
+		if lock.users == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// Returns the value, or nil when it could not be loaded. Keeps a missing value from becoming a
+// typed nil inside an interface.
+func orNil[T any](value *T, err error) interface{} {
+	if err != nil || value == nil {
+		return nil
+	}
+	return value
+}
+
+func marshalSnapshot(value interface{}) *string {
+	if value == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	s := string(data)
+	return &s
+}
+
+// Reads an ID argument, which gqlgen passes as an int
+func intArg(value interface{}) *int {
+	switch v := value.(type) {
+	case int:
+		return &v
+	case *int:
+		return v
+	}
+	return nil
+}
+
+// Returns the ID field of a returned object, or nil if it has none
+func resultId(res interface{}) *int {
+	v := reflect.ValueOf(res)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	field := v.Elem().FieldByName("ID")
+	if !field.IsValid() || field.Kind() != reflect.Int {
+		return nil
+	}
+	id := int(field.Int())
+	return &id
+}
@@ -0,0 +1,50 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/KA-Challenge-Council/Bema/graph/generated"
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
+)
+
+func (r *auditLogEntryResolver) Actor(ctx context.Context, obj *model.AuditLogEntry) (*model.User, error) {
+	if obj.Actor == nil {
+		return nil, nil
+	}
+	return r.loaders(ctx).Users.Load(ctx, obj.Actor.ID)
+}
+
+func (r *auditLogEntryResolver) RealActor(ctx context.Context, obj *model.AuditLogEntry) (*model.User, error) {
+	if obj.RealActor == nil {
+		return nil, nil
+	}
+	return r.loaders(ctx).Users.Load(ctx, obj.RealActor.ID)
+}
+
+func (r *queryResolver) AuditLog(ctx context.Context, filter *model.AuditLogFilter, first *int, after *string) (*model.AuditLogConnection, error) {
+	args, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	user := auth.GetUserFromContext(ctx)
+	if user == nil || !user.IsAdmin {
+		return nil, errs.NewForbiddenError(ctx, "You do not have permission to view the audit log.")
+	}
+
+	page, err := r.Repos.AuditLog.GetAuditLogPage(ctx, filter, args)
+	if err != nil {
+		return nil, err
+	}
+	return newAuditLogConnection(page, args), nil
+}
+
+// AuditLogEntry returns generated.AuditLogEntryResolver implementation.
+func (r *Resolver) AuditLogEntry() generated.AuditLogEntryResolver { return &auditLogEntryResolver{r} }
+
+type auditLogEntryResolver struct{ *Resolver }
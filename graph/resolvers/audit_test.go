@@ -0,0 +1,59 @@
+package resolvers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/models/memory"
+)
+
+func TestAuditLocks(t *testing.T) {
+	locks := &auditLocks{locks: map[string]*auditLock{}}
+
+	// A change to another target does not wait for the held lock
+	unlock := locks.lock("CONTEST:1")
+	locks.lock("CONTEST:2")()
+
+	// A change to the same target waits until the lock is released
+	var mu sync.Mutex
+	released := false
+	done := make(chan bool)
+	go func() {
+		unlockSecond := locks.lock("CONTEST:1")
+		mu.Lock()
+		done <- released
+		mu.Unlock()
+		unlockSecond()
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	released = true
+	mu.Unlock()
+	unlock()
+
+	if !<-done {
+		t.Fatal("the second change ran while the first held the lock")
+	}
+
+	locks.mu.Lock()
+	defer locks.mu.Unlock()
+	if len(locks.locks) != 0 {
+		t.Fatalf("%d locks were kept after being released", len(locks.locks))
+	}
+}
+
+func TestAuditedRequiresLogin(t *testing.T) {
+	ran := false
+	_, err := Audited(memory.NewRepositories())(context.Background(), nil, func(ctx context.Context) (interface{}, error) {
+		ran = true
+		return nil, nil
+	}, model.AuditTargetContest, nil)
+
+	if ran || errorStatus(err) != 403 {
+		t.Fatalf("got %v and ran %v, want a forbidden error without running the mutation", err, ran)
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/KA-Challenge-Council/Bema/graph/generated"
 	"github.com/KA-Challenge-Council/Bema/graph/model"
 	"github.com/KA-Challenge-Council/Bema/internal/auth"
-	"github.com/KA-Challenge-Council/Bema/internal/models"
 )
 
 func (r *contestantResolver) Entries(ctx context.Context, obj *model.Contestant) ([]*model.Entry, error) {
@@ -18,7 +17,7 @@ func (r *contestantResolver) Entries(ctx context.Context, obj *model.Contestant)
 		return []*model.Entry{}, nil
 	}
 
-	entries, err := models.GetEntriesByContestantKaid(ctx, obj.Kaid)
+	entries, err := r.Repos.Entries.GetEntriesByContestantKaid(ctx, obj.Kaid)
 	if err != nil {
 		return nil, err
 	}
@@ -26,7 +25,7 @@ func (r *contestantResolver) Entries(ctx context.Context, obj *model.Contestant)
 }
 
 func (r *contestantResolver) EntryCount(ctx context.Context, obj *model.Contestant) (int, error) {
-	entryCount, err := models.GetContestantEntryCount(ctx, obj.Kaid)
+	entryCount, err := r.Repos.Contestants.GetContestantEntryCount(ctx, obj.Kaid)
 	if err != nil {
 		return 0, err
 	}
@@ -34,7 +33,7 @@ func (r *contestantResolver) EntryCount(ctx context.Context, obj *model.Contesta
 }
 
 func (r *contestantResolver) ContestCount(ctx context.Context, obj *model.Contestant) (int, error) {
-	contestCount, err := models.GetContestantContestCount(ctx, obj.Kaid)
+	contestCount, err := r.Repos.Contestants.GetContestantContestCount(ctx, obj.Kaid)
 	if err != nil {
 		return 0, nil
 	}
@@ -42,7 +41,7 @@ func (r *contestantResolver) ContestCount(ctx context.Context, obj *model.Contes
 }
 
 func (r *queryResolver) Contestant(ctx context.Context, kaid string) (*model.Contestant, error) {
-	contestant, err := models.GetContestantByKaid(ctx, kaid)
+	contestant, err := r.Repos.Contestants.GetContestantByKaid(ctx, kaid)
 	if err != nil {
 		return nil, err
 	}
@@ -50,18 +49,26 @@ func (r *queryResolver) Contestant(ctx context.Context, kaid string) (*model.Con
 }
 
 func (r *queryResolver) ContestantSearch(ctx context.Context, query string) ([]*model.Contestant, error) {
-	user := auth.GetUserFromContext(ctx)
-	if user == nil {
-		return []*model.Contestant{}, nil
-	}
-
-	contestants, err := models.GetContestantsBySearchQuery(ctx, query)
+	contestants, err := r.Repos.Contestants.GetContestantsBySearchQuery(ctx, query)
 	if err != nil {
 		return []*model.Contestant{}, err
 	}
 	return contestants, nil
 }
 
+func (r *queryResolver) ContestantSearchConnection(ctx context.Context, query string, first *int, after *string) (*model.ContestantConnection, error) {
+	args, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := r.Repos.Contestants.GetContestantsPageBySearchQuery(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return newContestantConnection(page, args), nil
+}
+
 // Contestant returns generated.ContestantResolver implementation.
 func (r *Resolver) Contestant() generated.ContestantResolver { return &contestantResolver{r} }
 
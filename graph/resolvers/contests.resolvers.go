@@ -5,6 +5,7 @@ package resolvers
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/KA-Challenge-Council/Bema/graph/generated"
 	"github.com/KA-Challenge-Council/Bema/graph/model"
@@ -31,7 +32,11 @@ func (r *contestResolver) IsVotingEnabled(ctx context.Context, obj *model.Contes
 }
 
 func (r *contestResolver) Winners(ctx context.Context, obj *model.Contest) ([]*model.Entry, error) {
-	winners, err := models.GetWinningEntriesByContestId(ctx, obj.ID)
+	if !canSeeWinners(auth.GetUserFromContext(ctx), obj) {
+		return []*model.Entry{}, nil
+	}
+
+	winners, err := r.Repos.Entries.GetWinningEntriesByContestId(ctx, obj.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -39,13 +44,11 @@ func (r *contestResolver) Winners(ctx context.Context, obj *model.Contest) ([]*m
 }
 
 func (r *mutationResolver) CreateContest(ctx context.Context, input model.CreateContestInput) (*model.Contest, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.EditContests) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to create contests.")
+	if err := rejectContestStateField(ctx, "isCurrent", input.IsCurrent); err != nil {
+		return nil, err
 	}
 
-	id, err := models.CreateContest(ctx, &input)
+	id, err := r.Repos.Contests.CreateContest(ctx, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -54,13 +57,14 @@ func (r *mutationResolver) CreateContest(ctx context.Context, input model.Create
 }
 
 func (r *mutationResolver) EditContest(ctx context.Context, id int, input model.EditContestInput) (*model.Contest, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.EditContests) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to edit contests.")
+	if err := rejectContestStateField(ctx, "isCurrent", input.IsCurrent); err != nil {
+		return nil, err
+	}
+	if err := rejectContestStateField(ctx, "isVotingEnabled", input.IsVotingEnabled); err != nil {
+		return nil, err
 	}
 
-	err := models.EditContestById(ctx, id, &input)
+	err := r.Repos.Contests.EditContestById(ctx, id, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -69,27 +73,39 @@ func (r *mutationResolver) EditContest(ctx context.Context, id int, input model.
 }
 
 func (r *mutationResolver) DeleteContest(ctx context.Context, id int) (*model.Contest, error) {
-	user := auth.GetUserFromContext(ctx)
+	contest, err := r.Query().Contest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
-	if !auth.HasPermission(user, auth.DeleteContests) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to delete contests.")
+	err = r.Repos.Contests.DeleteContestById(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
-	contest, err := r.Query().Contest(ctx, id)
+	return contest, nil
+}
+
+func (r *mutationResolver) TransitionContest(ctx context.Context, id int, state model.ContestState) (*model.Contest, error) {
+	contest, err := r.Repos.Contests.GetContestById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	err = models.DeleteContestById(ctx, id)
+	if !models.CanTransitionContest(contest.State, state) {
+		return nil, errs.NewBadRequestError(ctx, fmt.Sprintf("Oops! A contest that is %s cannot move to %s.", describeContestState(contest.State), describeContestState(state)))
+	}
+
+	err = r.Repos.Contests.TransitionContest(ctx, id, contest.State, state)
 	if err != nil {
 		return nil, err
 	}
 
-	return contest, nil
+	return r.Query().Contest(ctx, id)
 }
 
 func (r *queryResolver) Contests(ctx context.Context) ([]*model.Contest, error) {
-	arr, err := models.GetAllContests(ctx)
+	arr, err := r.Repos.Contests.GetAllContests(ctx)
 	if err != nil {
 		return []*model.Contest{}, err
 	}
@@ -97,7 +113,7 @@ func (r *queryResolver) Contests(ctx context.Context) ([]*model.Contest, error)
 }
 
 func (r *queryResolver) Contest(ctx context.Context, id int) (*model.Contest, error) {
-	contest, err := models.GetContestById(ctx, id)
+	contest, err := r.Repos.Contests.GetContestById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -105,7 +121,7 @@ func (r *queryResolver) Contest(ctx context.Context, id int) (*model.Contest, er
 }
 
 func (r *queryResolver) CurrentContest(ctx context.Context) (*model.Contest, error) {
-	contest, err := models.GetCurrentContest(ctx)
+	contest, err := r.Repos.Contests.GetCurrentContest(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -113,12 +129,7 @@ func (r *queryResolver) CurrentContest(ctx context.Context) (*model.Contest, err
 }
 
 func (r *queryResolver) ContestsEvaluatedByUser(ctx context.Context, id int) ([]*model.Contest, error) {
-	user := auth.GetUserFromContext(ctx)
-	if user == nil {
-		return []*model.Contest{}, nil
-	}
-
-	contest, err := models.GetContestsEvaluatedByUser(ctx, id)
+	contest, err := r.Repos.Contests.GetContestsEvaluatedByUser(ctx, id)
 	if err != nil {
 		return []*model.Contest{}, err
 	}
@@ -0,0 +1,108 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/KA-Challenge-Council/Bema/graph/generated"
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Returns the implementations of the schema directives
+func Directives(repos *models.Repositories) generated.DirectiveRoot {
+	return generated.DirectiveRoot{
+		Audited:       Audited(repos),
+		Authenticated: Authenticated,
+		HasPermission: HasPermission,
+	}
+}
+
+// Implements @authenticated. Resolves the field only for logged in users.
+func Authenticated(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
+	if auth.GetUserFromContext(ctx) == nil {
+		return nil, errs.NewForbiddenError(ctx, "You must be logged in to use "+fieldName(ctx)+".")
+	}
+	return next(ctx)
+}
+
+// Implements @hasPermission. Resolves the field only for users holding the permission.
+func HasPermission(ctx context.Context, obj interface{}, next graphql.Resolver, perm string) (interface{}, error) {
+	if !auth.HasPermission(auth.GetUserFromContext(ctx), auth.Permission(perm)) {
+		return nil, errs.NewForbiddenError(ctx, "You do not have permission to use "+fieldName(ctx)+". It requires the "+perm+" permission.")
+	}
+	return next(ctx)
+}
+
+// Returns an error listing every field whose @hasPermission key is not a permission checked in code,
+// so a typo in the schema cannot lock everyone but admins out of a field
+func CheckPermissionDirectives(schema *ast.Schema) error {
+	unknown := []string{}
+	for _, def := range schema.Types {
+		for _, field := range def.Fields {
+			directive := field.Directives.ForName("hasPermission")
+			if directive == nil {
+				continue
+			}
+			if perm := directive.Arguments.ForName("perm"); perm == nil || !auth.IsDefinedPermission(perm.Value.Raw) {
+				unknown = append(unknown, def.Name+"."+field.Name)
+			}
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("@hasPermission names an unknown permission on %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func fieldName(ctx context.Context) string {
+	if fc := graphql.GetFieldContext(ctx); fc != nil {
+		return fc.Field.Name
+	}
+	return "this field"
+}
+
+// Mutations that can still run during a read-only impersonation, so the impersonator can leave it
+var readOnlyMutations = map[string]bool{
+	"logout":                  true,
+	"returnFromImpersonation": true,
+}
+
+// Rejects mutations made through a read-only impersonation session. Runs around every root field.
+func RejectReadOnlyMutations(ctx context.Context, next graphql.RootResolver) graphql.Marshaler {
+	user := auth.GetUserFromContext(ctx)
+	if user == nil || !user.IsReadOnly || graphql.GetOperationContext(ctx).Operation.Operation != ast.Mutation {
+		return next(ctx)
+	}
+
+	field := graphql.GetRootFieldContext(ctx).Field
+	if readOnlyMutations[field.Name] {
+		return next(ctx)
+	}
+
+	err := errs.NewForbiddenError(ctx, "You are impersonating this user in read-only mode, so you cannot use "+field.Name+".")
+	err.Path = ast.Path{ast.PathName(field.Alias)}
+	graphql.AddError(ctx, err)
+	return graphql.Null
+}
+
+// Rejects mutations from requests that did not prove they came from the app, so other sites cannot
+// make a logged in user's browser change anything. Runs around every root field.
+func RejectForgedMutations(ctx context.Context, next graphql.RootResolver) graphql.Marshaler {
+	if auth.GetRequestInfoFromContext(ctx).CSRFVerified || graphql.GetOperationContext(ctx).Operation.Operation != ast.Mutation {
+		return next(ctx)
+	}
+
+	field := graphql.GetRootFieldContext(ctx).Field
+	err := errs.NewForbiddenError(ctx, "Oops! This request is missing its "+auth.CSRFHeader+" header. Please reload the page and try again.")
+	err.Path = ast.Path{ast.PathName(field.Alias)}
+	graphql.AddError(ctx, err)
+	return graphql.Null
+}
@@ -0,0 +1,30 @@
+package resolvers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/KA-Challenge-Council/Bema/graph/generated"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestCheckPermissionDirectives(t *testing.T) {
+	if err := CheckPermissionDirectives(generated.NewExecutableSchema(generated.Config{}).Schema()); err != nil {
+		t.Fatalf("the schema has unknown permissions: %v", err)
+	}
+
+	schema, err := gqlparser.LoadSchema(&ast.Source{Input: `
+		directive @hasPermission(perm: String!) on FIELD_DEFINITION
+		type Query {
+			entries: Int @hasPermission(perm: "EDIT_ENTRIES")
+			typo: Int @hasPermission(perm: "EDIT_ENTRIE")
+		}
+	`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckPermissionDirectives(schema); err == nil || !strings.Contains(err.Error(), "Query.typo") || strings.Contains(err.Error(), "Query.entries") {
+		t.Fatalf("got %v, want an error naming only Query.typo", err)
+	}
+}
@@ -19,7 +19,7 @@ import (
 )
 
 func (r *entryResolver) Contest(ctx context.Context, obj *model.Entry) (*model.Contest, error) {
-	contest, err := r.Query().Contest(ctx, obj.Contest.ID)
+	contest, err := r.loaders(ctx).Contests.Load(ctx, obj.Contest.ID)
 	if err != nil {
 		return nil, nil
 	}
@@ -34,10 +34,13 @@ func (r *entryResolver) Author(ctx context.Context, obj *model.Entry) (*model.Co
 		}, nil
 	}
 
-	author, err := r.Query().Contestant(ctx, obj.Author.Kaid)
+	author, err := r.loaders(ctx).Contestants.Load(ctx, obj.Author.Kaid)
 	if err != nil {
 		return nil, err
 	}
+	if author == nil {
+		return nil, errs.NewNotFoundError(ctx, "The requested contestant does not exist.")
+	}
 	return author, nil
 }
 
@@ -63,10 +66,13 @@ func (r *entryResolver) Group(ctx context.Context, obj *model.Entry) (*model.Jud
 		return nil, nil
 	}
 
-	group, err := r.Query().JudgingGroup(ctx, obj.Group.ID)
+	group, err := r.loaders(ctx).JudgingGroups.Load(ctx, obj.Group.ID)
 	if err != nil {
 		return nil, err
 	}
+	if group == nil {
+		return nil, errs.NewNotFoundError(ctx, "Oops! The requested judging group does not exist.")
+	}
 	return group, nil
 }
 
@@ -112,7 +118,7 @@ func (r *entryResolver) AverageScore(ctx context.Context, obj *model.Entry) (*fl
 		return nil, nil
 	}
 
-	avgScore, err := models.GetEntryAverageScore(ctx, obj.ID)
+	avgScore, err := r.loaders(ctx).EntryAverageScore.Load(ctx, obj.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +131,7 @@ func (r *entryResolver) EvaluationCount(ctx context.Context, obj *model.Entry) (
 		return nil, nil
 	}
 
-	count, err := models.GetEntryEvaluationCount(ctx, obj.ID)
+	count, err := r.loaders(ctx).EntryEvaluations.Load(ctx, obj.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -138,7 +144,7 @@ func (r *entryResolver) VoteCount(ctx context.Context, obj *model.Entry) (*int,
 		return nil, nil
 	}
 
-	count, err := models.GetEntryVoteCount(ctx, obj.ID)
+	count, err := r.loaders(ctx).EntryVotes.Load(ctx, obj.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -151,11 +157,23 @@ func (r *entryResolver) IsVotedByUser(ctx context.Context, obj *model.Entry) (*b
 		return nil, nil
 	}
 
-	wasVoted, err := models.IsEntryVotedByUser(ctx, obj.ID)
+	wasVoted, err := r.loaders(ctx).EntryVotedByUser.Load(ctx, obj.ID)
 	if err != nil {
 		return nil, err
 	}
-	return wasVoted, nil
+	return &wasVoted, nil
+}
+
+func (r *entryResolver) IsWinner(ctx context.Context, obj *model.Entry) (bool, error) {
+	if !obj.IsWinner {
+		return false, nil
+	}
+
+	contest, err := r.loaders(ctx).Contests.Load(ctx, obj.Contest.ID)
+	if err != nil {
+		return false, err
+	}
+	return canSeeWinners(auth.GetUserFromContext(ctx), contest), nil
 }
 
 func (r *entryResolver) JudgeVotes(ctx context.Context, obj *model.Entry) ([]*model.EntryVote, error) {
@@ -164,7 +182,7 @@ func (r *entryResolver) JudgeVotes(ctx context.Context, obj *model.Entry) ([]*mo
 		return []*model.EntryVote{}, nil
 	}
 
-	votes, err := models.GetEntryVotes(ctx, obj.ID)
+	votes, err := r.Repos.Entries.GetEntryVotes(ctx, obj.ID)
 	if err != nil {
 		return []*model.EntryVote{}, err
 	}
@@ -173,23 +191,20 @@ func (r *entryResolver) JudgeVotes(ctx context.Context, obj *model.Entry) ([]*mo
 
 func (r *entryVoteResolver) User(ctx context.Context, obj *model.EntryVote) (*model.User, error) {
 	if obj.User != nil {
-		user, err := models.GetUserById(ctx, obj.User.ID)
+		user, err := r.loaders(ctx).Users.Load(ctx, obj.User.ID)
 		if err != nil {
 			return nil, err
 		}
+		if user == nil {
+			return nil, errs.NewNotFoundError(ctx, "Oops! The requested user does not exist.")
+		}
 		return user, nil
 	}
 	return nil, nil
 }
 
 func (r *mutationResolver) AddWinner(ctx context.Context, id int) (*model.Entry, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ManageWinners) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to add winners.")
-	}
-
-	err := models.AddWinnerByEntryId(ctx, id)
+	err := r.Repos.Entries.AddWinnerByEntryId(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -202,13 +217,7 @@ func (r *mutationResolver) AddWinner(ctx context.Context, id int) (*model.Entry,
 }
 
 func (r *mutationResolver) RemoveWinner(ctx context.Context, id int) (*model.Entry, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ManageWinners) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to remove winners.")
-	}
-
-	err := models.RemoveWinnerByEntryId(ctx, id)
+	err := r.Repos.Entries.RemoveWinnerByEntryId(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -221,13 +230,7 @@ func (r *mutationResolver) RemoveWinner(ctx context.Context, id int) (*model.Ent
 }
 
 func (r *mutationResolver) FlagEntry(ctx context.Context, id int, reason string) (*model.Entry, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.JudgeEntries) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to flag entries.")
-	}
-
-	err := models.FlagEntryById(ctx, id, reason)
+	err := r.Repos.Entries.FlagEntryById(ctx, id, reason)
 	if err != nil {
 		return nil, err
 	}
@@ -236,13 +239,7 @@ func (r *mutationResolver) FlagEntry(ctx context.Context, id int, reason string)
 }
 
 func (r *mutationResolver) ApproveEntry(ctx context.Context, id int) (*model.Entry, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.EditEntries) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to approve entries.")
-	}
-
-	err := models.ApproveEntryById(ctx, id)
+	err := r.Repos.Entries.ApproveEntryById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -251,13 +248,7 @@ func (r *mutationResolver) ApproveEntry(ctx context.Context, id int) (*model.Ent
 }
 
 func (r *mutationResolver) DisqualifyEntry(ctx context.Context, id int) (*model.Entry, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.EditEntries) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to disqualify entries.")
-	}
-
-	err := models.DisqualifyEntryById(ctx, id)
+	err := r.Repos.Entries.DisqualifyEntryById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -268,11 +259,7 @@ func (r *mutationResolver) DisqualifyEntry(ctx context.Context, id int) (*model.
 func (r *mutationResolver) EditEntry(ctx context.Context, id int, input model.EditEntryInput) (*model.Entry, error) {
 	user := auth.GetUserFromContext(ctx)
 
-	if !auth.HasPermission(user, auth.EditEntries) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to edit entries.")
-	}
-
-	entry, err := models.GetEntryById(ctx, id)
+	entry, err := r.Repos.Entries.GetEntryById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -284,7 +271,7 @@ func (r *mutationResolver) EditEntry(ctx context.Context, id int, input model.Ed
 		input.IsSkillLevelLocked = *entry.IsSkillLevelLocked
 	}
 
-	err = models.EditEntryById(ctx, id, &input)
+	err = r.Repos.Entries.EditEntryById(ctx, id, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -293,18 +280,12 @@ func (r *mutationResolver) EditEntry(ctx context.Context, id int, input model.Ed
 }
 
 func (r *mutationResolver) DeleteEntry(ctx context.Context, id int) (*model.Entry, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.DeleteEntries) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to delete entries.")
-	}
-
 	entry, err := r.Query().Entry(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	err = models.DeleteEntryById(ctx, id)
+	err = r.Repos.Entries.DeleteEntryById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -319,7 +300,7 @@ func (r *mutationResolver) SetEntryLevel(ctx context.Context, id int, skillLevel
 		return nil, errs.NewForbiddenError(ctx, "You do not have permission to set entry skill levels.")
 	}
 
-	err := models.SetEntryLevelById(ctx, id, skillLevel)
+	err := r.Repos.Entries.SetEntryLevelById(ctx, id, skillLevel)
 	if err != nil {
 		return nil, err
 	}
@@ -330,25 +311,22 @@ func (r *mutationResolver) SetEntryLevel(ctx context.Context, id int, skillLevel
 func (r *mutationResolver) CreateEntryVote(ctx context.Context, entryID int, reason string) (*model.EntryVote, error) {
 	user := auth.GetUserFromContext(ctx)
 
-	if !auth.HasPermission(user, auth.JudgeEntries) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to vote for winning entries.")
-	}
-
-	entry, err := models.GetEntryById(ctx, entryID)
+	entry, err := r.Repos.Entries.GetEntryById(ctx, entryID)
 	if err != nil {
 		return nil, err
 	}
 
-	contest, err := models.GetContestById(ctx, entry.Contest.ID)
+	contest, err := r.Repos.Contests.GetContestById(ctx, entry.Contest.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	if !*contest.IsVotingEnabled {
-		return nil, errs.NewForbiddenError(ctx, "Voting is not enabled for this contest.")
+	err = requireContestState(ctx, contest, "Voting is not enabled for this contest.", model.ContestStateVoting)
+	if err != nil {
+		return nil, err
 	}
 
-	id, err := models.CreateEntryVote(ctx, entryID, user.ID, reason)
+	id, err := r.Repos.Entries.CreateEntryVote(ctx, entryID, user.ID, reason)
 	if err != nil {
 		return nil, err
 	}
@@ -368,21 +346,22 @@ func (r *mutationResolver) DeleteEntryVote(ctx context.Context, id int) (*model.
 		return nil, errs.NewForbiddenError(ctx, "You do not have permission to vote for winning entries.")
 	}
 
-	contestId, err := models.GetContestIdByVoteId(ctx, id)
+	contestId, err := r.Repos.Entries.GetContestIdByVoteId(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	contest, err := models.GetContestById(ctx, *contestId)
+	contest, err := r.Repos.Contests.GetContestById(ctx, *contestId)
 	if err != nil {
 		return nil, err
 	}
 
-	if !*contest.IsVotingEnabled {
-		return nil, errs.NewForbiddenError(ctx, "Voting is not enabled for this contest.")
+	err = requireContestState(ctx, contest, "Voting is not enabled for this contest.", model.ContestStateVoting)
+	if err != nil {
+		return nil, err
 	}
 
-	err = models.DeleteEntryVoteById(ctx, id)
+	err = r.Repos.Entries.DeleteEntryVoteById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -391,13 +370,12 @@ func (r *mutationResolver) DeleteEntryVote(ctx context.Context, id int) (*model.
 }
 
 func (r *mutationResolver) ImportEntries(ctx context.Context, contestID int) (bool, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.AddEntries) {
-		return false, errs.NewForbiddenError(ctx, "You do not have permission to import entries.")
+	contest, err := r.Repos.Contests.GetContestById(ctx, contestID)
+	if err != nil {
+		return false, err
 	}
 
-	contest, err := models.GetContestById(ctx, contestID)
+	err = requireContestState(ctx, contest, "Oops! Entries can only be imported while the contest is accepting entries or being judged.", model.ContestStateAcceptingEntries, model.ContestStateJudging)
 	if err != nil {
 		return false, err
 	}
@@ -443,7 +421,7 @@ func (r *mutationResolver) ImportEntries(ctx context.Context, contestID int) (bo
 			Created:    data.Scratchpads[i].Created,
 		}
 
-		_, err := models.CreateEntry(ctx, contestID, input)
+		_, err := r.Repos.Entries.CreateEntry(ctx, contestID, input)
 		if err != nil {
 			return false, err
 		}
@@ -453,10 +431,14 @@ func (r *mutationResolver) ImportEntries(ctx context.Context, contestID int) (bo
 }
 
 func (r *mutationResolver) ImportEntry(ctx context.Context, contestID int, kaid string) (*model.Entry, error) {
-	user := auth.GetUserFromContext(ctx)
+	contest, err := r.Repos.Contests.GetContestById(ctx, contestID)
+	if err != nil {
+		return nil, err
+	}
 
-	if !auth.HasPermission(user, auth.AddEntries) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to import entries.")
+	err = requireContestState(ctx, contest, "Oops! Entries can only be imported while the contest is accepting entries or being judged.", model.ContestStateAcceptingEntries, model.ContestStateJudging)
+	if err != nil {
+		return nil, err
 	}
 
 	APIEndpoint := fmt.Sprintf("https://www.khanacademy.org/api/internal/show_scratchpad?scratchpad_id=%s", kaid)
@@ -502,7 +484,7 @@ func (r *mutationResolver) ImportEntry(ctx context.Context, contestID int, kaid
 		Created:    data.Scratchpad.Created,
 	}
 
-	id, err := models.CreateEntry(ctx, contestID, input)
+	id, err := r.Repos.Entries.CreateEntry(ctx, contestID, input)
 	if err != nil {
 		return nil, err
 	}
@@ -511,13 +493,7 @@ func (r *mutationResolver) ImportEntry(ctx context.Context, contestID int, kaid
 }
 
 func (r *mutationResolver) AssignAllEntriesToGroups(ctx context.Context, contestID int) (bool, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.AssignEntryGroups) {
-		return false, errs.NewForbiddenError(ctx, "You do not have permission to assign entries to groups.")
-	}
-
-	err := models.AssignAllEntriesToGroups(ctx, contestID)
+	err := r.Repos.Entries.AssignAllEntriesToGroups(ctx, contestID)
 	if err != nil {
 		return false, err
 	}
@@ -526,13 +502,7 @@ func (r *mutationResolver) AssignAllEntriesToGroups(ctx context.Context, contest
 }
 
 func (r *mutationResolver) AssignNewEntriesToGroups(ctx context.Context, contestID int) (bool, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.AssignEntryGroups) {
-		return false, errs.NewForbiddenError(ctx, "You do not have permission to assign entries to groups.")
-	}
-
-	err := models.AssignNewEntriesToGroups(ctx, contestID)
+	err := r.Repos.Entries.AssignNewEntriesToGroups(ctx, contestID)
 	if err != nil {
 		return false, err
 	}
@@ -541,13 +511,7 @@ func (r *mutationResolver) AssignNewEntriesToGroups(ctx context.Context, contest
 }
 
 func (r *mutationResolver) TransferEntryGroups(ctx context.Context, contest int, prevGroup int, newGroup int) (bool, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.AssignEntryGroups) {
-		return false, errs.NewForbiddenError(ctx, "You do not have permission to transfer entry groups.")
-	}
-
-	err := models.TransferEntryGroups(ctx, contest, prevGroup, newGroup)
+	err := r.Repos.Entries.TransferEntryGroups(ctx, contest, prevGroup, newGroup)
 	if err != nil {
 		return false, err
 	}
@@ -556,15 +520,28 @@ func (r *mutationResolver) TransferEntryGroups(ctx context.Context, contest int,
 }
 
 func (r *queryResolver) Entries(ctx context.Context, contestID int) ([]*model.Entry, error) {
-	entries, err := models.GetEntriesByContestId(ctx, contestID)
+	entries, err := r.Repos.Entries.GetEntriesByContestId(ctx, contestID)
 	if err != nil {
 		return []*model.Entry{}, err
 	}
 	return entries, nil
 }
 
+func (r *queryResolver) EntriesConnection(ctx context.Context, contestID int, first *int, after *string) (*model.EntryConnection, error) {
+	args, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := r.Repos.Entries.GetEntriesPageByContestId(ctx, contestID, args)
+	if err != nil {
+		return nil, err
+	}
+	return newEntryConnection(page, args), nil
+}
+
 func (r *queryResolver) Entry(ctx context.Context, id int) (*model.Entry, error) {
-	entry, err := models.GetEntryById(ctx, id)
+	entry, err := r.Repos.Entries.GetEntryById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -572,29 +549,37 @@ func (r *queryResolver) Entry(ctx context.Context, id int) (*model.Entry, error)
 }
 
 func (r *queryResolver) FlaggedEntries(ctx context.Context) ([]*model.Entry, error) {
-	user := auth.GetUserFromContext(ctx)
-	if !auth.HasPermission(user, auth.ViewJudgingSettings) {
-		return []*model.Entry{}, nil
-	}
-
-	entries, err := models.GetFlaggedEntries(ctx)
+	entries, err := r.Repos.Entries.GetFlaggedEntries(ctx)
 	if err != nil {
 		return []*model.Entry{}, err
 	}
 	return entries, nil
 }
 
+func (r *queryResolver) FlaggedEntriesConnection(ctx context.Context, first *int, after *string) (*model.EntryConnection, error) {
+	args, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := r.Repos.Entries.GetFlaggedEntriesPage(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return newEntryConnection(page, args), nil
+}
+
 func (r *queryResolver) EntriesByAverageScore(ctx context.Context, contestID int) ([]*model.Entry, error) {
 	user := auth.GetUserFromContext(ctx)
 	if user == nil {
-		entries, err := models.GetEntriesByContestId(ctx, contestID)
+		entries, err := r.Repos.Entries.GetEntriesByContestId(ctx, contestID)
 		if err != nil {
 			return []*model.Entry{}, err
 		}
 		return entries, nil
 	}
 
-	entries, err := models.GetEntriesByAverageScore(ctx, contestID)
+	entries, err := r.Repos.Entries.GetEntriesByAverageScore(ctx, contestID)
 	if err != nil {
 		return []*model.Entry{}, err
 	}
@@ -602,7 +587,7 @@ func (r *queryResolver) EntriesByAverageScore(ctx context.Context, contestID int
 }
 
 func (r *queryResolver) EntriesPerLevel(ctx context.Context, contestID int) ([]*model.EntriesPerLevel, error) {
-	entriesPerLevel, err := models.GetEntriesPerLevel(ctx, contestID)
+	entriesPerLevel, err := r.Repos.Entries.GetEntriesPerLevel(ctx, contestID)
 	if err != nil {
 		return []*model.EntriesPerLevel{}, err
 	}
@@ -610,12 +595,7 @@ func (r *queryResolver) EntriesPerLevel(ctx context.Context, contestID int) ([]*
 }
 
 func (r *queryResolver) NextEntryToJudge(ctx context.Context) (*model.Entry, error) {
-	user := auth.GetUserFromContext(ctx)
-	if !auth.HasPermission(user, auth.JudgeEntries) {
-		return nil, nil
-	}
-
-	id, err := models.GetNextEntryToJudge(ctx)
+	id, err := r.Repos.Entries.GetNextEntryToJudge(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -633,10 +613,10 @@ func (r *queryResolver) NextEntryToJudge(ctx context.Context) (*model.Entry, err
 func (r *queryResolver) NextEntryToReviewSkillLevel(ctx context.Context) (*model.Entry, error) {
 	user := auth.GetUserFromContext(ctx)
 	if user == nil || !user.IsAdmin {
-		return nil, nil
+		return nil, errs.NewForbiddenError(ctx, "You do not have permission to review skill levels.")
 	}
 
-	id, err := models.GetNextEntryToReviewSkillLevel(ctx)
+	id, err := r.Repos.Entries.GetNextEntryToReviewSkillLevel(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -658,7 +638,7 @@ func (r *queryResolver) EntryVote(ctx context.Context, id int) (*model.EntryVote
 		return nil, nil
 	}
 
-	vote, err := models.GetEntryVoteById(ctx, id)
+	vote, err := r.Repos.Entries.GetEntryVoteById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
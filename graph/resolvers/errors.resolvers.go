@@ -8,13 +8,10 @@ import (
 
 	"github.com/KA-Challenge-Council/Bema/graph/generated"
 	"github.com/KA-Challenge-Council/Bema/graph/model"
-	"github.com/KA-Challenge-Council/Bema/internal/auth"
-	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
-	"github.com/KA-Challenge-Council/Bema/internal/models"
 )
 
 func (r *errorResolver) User(ctx context.Context, obj *model.Error) (*model.User, error) {
-	user, err := models.GetUserById(ctx, obj.User.ID)
+	user, err := r.Repos.Users.GetUserById(ctx, obj.User.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -23,18 +20,12 @@ func (r *errorResolver) User(ctx context.Context, obj *model.Error) (*model.User
 }
 
 func (r *mutationResolver) DeleteError(ctx context.Context, id int) (*model.Error, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.DeleteErrors) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to delete errors.")
-	}
-
 	e, err := r.Query().Error(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	err = models.DeleteErrorById(ctx, id)
+	err = r.Repos.Errors.DeleteErrorById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -43,12 +34,7 @@ func (r *mutationResolver) DeleteError(ctx context.Context, id int) (*model.Erro
 }
 
 func (r *queryResolver) Errors(ctx context.Context, page int) ([]*model.Error, error) {
-	user := auth.GetUserFromContext(ctx)
-	if !auth.HasPermission(user, auth.ViewErrors) {
-		return []*model.Error{}, nil
-	}
-
-	errors, err := models.GetErrorsByPage(ctx, page)
+	errors, err := r.Repos.Errors.GetErrorsByPage(ctx, page)
 	if err != nil {
 		return []*model.Error{}, err
 	}
@@ -56,13 +42,21 @@ func (r *queryResolver) Errors(ctx context.Context, page int) ([]*model.Error, e
 	return errors, nil
 }
 
-func (r *queryResolver) Error(ctx context.Context, id int) (*model.Error, error) {
-	user := auth.GetUserFromContext(ctx)
-	if !auth.HasPermission(user, auth.ViewErrors) {
-		return nil, nil
+func (r *queryResolver) ErrorsConnection(ctx context.Context, first *int, after *string) (*model.ErrorConnection, error) {
+	args, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
 	}
 
-	e, err := models.GetErrorById(ctx, id)
+	page, err := r.Repos.Errors.GetErrorsPage(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return newErrorConnection(page, args), nil
+}
+
+func (r *queryResolver) Error(ctx context.Context, id int) (*model.Error, error) {
+	e, err := r.Repos.Errors.GetErrorById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -10,23 +10,32 @@ import (
 	"github.com/KA-Challenge-Council/Bema/graph/model"
 	"github.com/KA-Challenge-Council/Bema/internal/auth"
 	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
-	"github.com/KA-Challenge-Council/Bema/internal/models"
 	"github.com/KA-Challenge-Council/Bema/internal/util"
 )
 
 func (r *evaluationResolver) Entry(ctx context.Context, obj *model.Evaluation) (*model.Entry, error) {
-	entry, err := r.Query().Entry(ctx, obj.Entry.ID)
+	entry, err := r.loaders(ctx).Entries.Load(ctx, obj.Entry.ID)
 	if err != nil {
 		return nil, err
 	}
+	if entry == nil {
+		return nil, errs.NewNotFoundError(ctx, "The requested entry does not exist.")
+	}
 	return entry, nil
 }
 
 func (r *evaluationResolver) User(ctx context.Context, obj *model.Evaluation) (*model.User, error) {
-	user, err := r.Query().User(ctx, obj.User.ID)
+	if auth.GetUserFromContext(ctx) == nil {
+		return nil, nil
+	}
+
+	user, err := r.loaders(ctx).Users.Load(ctx, obj.User.ID)
 	if err != nil {
 		return nil, err
 	}
+	if user == nil {
+		return nil, errs.NewNotFoundError(ctx, "Oops! The requested user does not exist.")
+	}
 	return user, nil
 }
 
@@ -35,17 +44,25 @@ func (r *mutationResolver) EditEvaluation(ctx context.Context, id int, input mod
 	if err != nil {
 		return nil, err
 	}
+	if evaluation == nil {
+		return nil, errs.NewForbiddenError(ctx, "You must be logged in to edit evaluations.")
+	}
+
+	err = r.requireEvaluationJudging(ctx, evaluation, "Oops! Evaluations can only be changed while the contest is being judged.")
+	if err != nil {
+		return nil, err
+	}
 
 	if !evaluation.CanEdit {
-		return evaluation, nil
+		return nil, errs.NewForbiddenError(ctx, "You do not have permission to edit this evaluation.")
 	}
 
 	inputIsValid := util.ScoreIsValid(input.Creativity) && util.ScoreIsValid(input.Complexity) && util.ScoreIsValid(input.Interpretation) && util.ScoreIsValid(input.Execution)
 	if !inputIsValid {
-		return nil, nil
+		return nil, errs.NewBadRequestError(ctx, "Oops! Each score must be between 0 and 5, in steps of 0.5.")
 	}
 
-	err = models.EditEvaluationById(ctx, id, &input)
+	err = r.Repos.Evaluations.EditEvaluationById(ctx, id, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -54,18 +71,20 @@ func (r *mutationResolver) EditEvaluation(ctx context.Context, id int, input mod
 }
 
 func (r *mutationResolver) DeleteEvaluation(ctx context.Context, id int) (*model.Evaluation, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.DeleteAllEvaluations) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to delete evaluations.")
+	evaluation, err := r.Query().Evaluation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if evaluation == nil {
+		return nil, errs.NewForbiddenError(ctx, "You must be logged in to delete evaluations.")
 	}
 
-	evaluation, err := r.Query().Evaluation(ctx, id)
+	err = r.requireEvaluationJudging(ctx, evaluation, "Oops! Evaluations can only be deleted while the contest is being judged.")
 	if err != nil {
 		return nil, err
 	}
 
-	err = models.DeleteEvaluationById(ctx, id)
+	err = r.Repos.Evaluations.DeleteEvaluationById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +98,7 @@ func (r *queryResolver) Evaluation(ctx context.Context, id int) (*model.Evaluati
 		return nil, nil
 	}
 
-	evaluation, err := models.GetEvaluationById(ctx, id)
+	evaluation, err := r.Repos.Evaluations.GetEvaluationById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -93,15 +112,12 @@ func (r *queryResolver) Evaluation(ctx context.Context, id int) (*model.Evaluati
 
 func (r *queryResolver) Evaluations(ctx context.Context, userID int, contestID int) ([]*model.Evaluation, error) {
 	user := auth.GetUserFromContext(ctx)
-	if user == nil {
-		return []*model.Evaluation{}, nil
-	}
 
-	if user.ID != userID && !user.Permissions.ViewAllEvaluations && !user.IsAdmin {
+	if user.ID != userID && !auth.HasPermission(user, auth.ViewAllEvaluations) {
 		return []*model.Evaluation{}, nil
 	}
 
-	evaluations, err := models.GetEvaluationsForUserAndContest(ctx, userID, contestID)
+	evaluations, err := r.Repos.Evaluations.GetEvaluationsForUserAndContest(ctx, userID, contestID)
 	if err != nil {
 		return []*model.Evaluation{}, err
 	}
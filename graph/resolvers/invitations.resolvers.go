@@ -0,0 +1,143 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/generated"
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
+)
+
+func (r *invitationResolver) Role(ctx context.Context, obj *model.Invitation) (*model.Role, error) {
+	if obj.Role == nil {
+		return nil, nil
+	}
+	return r.Repos.Roles.GetRoleById(ctx, obj.Role.ID)
+}
+
+func (r *invitationResolver) Group(ctx context.Context, obj *model.Invitation) (*model.JudgingGroup, error) {
+	if obj.Group == nil {
+		return nil, nil
+	}
+	return r.loaders(ctx).JudgingGroups.Load(ctx, obj.Group.ID)
+}
+
+func (r *invitationResolver) InvitedBy(ctx context.Context, obj *model.Invitation) (*model.User, error) {
+	if obj.InvitedBy == nil {
+		return nil, nil
+	}
+	return r.loaders(ctx).Users.Load(ctx, obj.InvitedBy.ID)
+}
+
+func (r *invitationResolver) User(ctx context.Context, obj *model.Invitation) (*model.User, error) {
+	if obj.User == nil {
+		return nil, nil
+	}
+	return r.loaders(ctx).Users.Load(ctx, obj.User.ID)
+}
+
+func (r *mutationResolver) InviteUser(ctx context.Context, input model.InviteUserInput) (*model.Invitation, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	input.Name = strings.TrimSpace(input.Name)
+	input.Email = strings.TrimSpace(input.Email)
+	if input.Name == "" || input.Email == "" || strings.TrimSpace(input.Kaid) == "" {
+		return nil, errs.NewBadRequestError(ctx, "Oops! Please enter the invitee's name, email address and KAID.")
+	}
+
+	if _, err := time.Parse("2006-01-02", input.TermStart); err != nil {
+		return nil, errs.NewBadRequestError(ctx, "Oops! Term dates must be written like 2022-01-31.")
+	}
+	if input.TermEnd != nil {
+		if _, err := time.Parse("2006-01-02", *input.TermEnd); err != nil {
+			return nil, errs.NewBadRequestError(ctx, "Oops! Term dates must be written like 2022-01-31.")
+		}
+	}
+
+	// Users may only hand out access they have themselves
+	if input.RoleID != nil {
+		role, err := r.Repos.Roles.GetRoleById(ctx, *input.RoleID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkCanGrant(ctx, user, role.Permissions); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.GroupID != nil {
+		if _, err := r.Repos.Judging.GetJudgingGroupById(ctx, *input.GroupID); err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := auth.NewSecretToken()
+	if err != nil {
+		return nil, errs.NewInternalError(ctx, "An unexpected error occurred while creating an invitation", err)
+	}
+
+	id, err := r.Repos.Invitations.CreateInvitation(ctx, &input, user.ID, auth.HashSecretToken(token), time.Now().Add(auth.InvitationLifetime))
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := r.Repos.Invitations.GetInvitationById(ctx, *id)
+	if err != nil {
+		return nil, err
+	}
+
+	link := r.AppURL + "/accept-invitation?token=" + url.QueryEscape(token)
+	inv.Link = &link
+	r.sendInvitation(inv, link)
+
+	return inv, nil
+}
+
+func (r *mutationResolver) RevokeInvitation(ctx context.Context, id int) (bool, error) {
+	// Make sure the invitation exists
+	if _, err := r.Repos.Invitations.GetInvitationById(ctx, id); err != nil {
+		return false, err
+	}
+
+	if err := r.Repos.Invitations.RevokeInvitation(ctx, id); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) AcceptInvitation(ctx context.Context, token string, username string, password string) (bool, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return false, errs.NewBadRequestError(ctx, "Oops! Your username cannot be empty.")
+	}
+	if password == "" {
+		return false, errs.NewBadRequestError(ctx, "Oops! Your password cannot be empty.")
+	}
+
+	if _, err := r.Repos.Invitations.AcceptInvitation(ctx, auth.HashSecretToken(token), username, password); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *queryResolver) Invitations(ctx context.Context, includeInactive *bool) ([]*model.Invitation, error) {
+	invitations, err := r.Repos.Invitations.GetInvitations(ctx, includeInactive != nil && *includeInactive)
+	if err != nil {
+		return []*model.Invitation{}, err
+	}
+	return invitations, nil
+}
+
+// Invitation returns generated.InvitationResolver implementation.
+func (r *Resolver) Invitation() generated.InvitationResolver { return &invitationResolver{r} }
+
+type invitationResolver struct{ *Resolver }
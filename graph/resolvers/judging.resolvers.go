@@ -13,18 +13,12 @@ import (
 )
 
 func (r *mutationResolver) CreateCriteria(ctx context.Context, input model.JudgingCriteriaInput) (*model.JudgingCriteria, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ManageJudgingCriteria) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to create judging criteria.")
-	}
-
-	id, err := models.CreateJudgingCriteria(ctx, &input)
+	id, err := r.Repos.Judging.CreateJudgingCriteria(ctx, &input)
 	if err != nil {
 		return nil, err
 	}
 
-	criteria, err := models.GetJudgingCriteriaById(ctx, *id)
+	criteria, err := r.Repos.Judging.GetJudgingCriteriaById(ctx, *id)
 	if err != nil {
 		return nil, err
 	}
@@ -33,18 +27,12 @@ func (r *mutationResolver) CreateCriteria(ctx context.Context, input model.Judgi
 }
 
 func (r *mutationResolver) EditCriteria(ctx context.Context, id int, input model.JudgingCriteriaInput) (*model.JudgingCriteria, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ManageJudgingCriteria) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to edit judging criteria.")
-	}
-
-	criteria, err := models.GetJudgingCriteriaById(ctx, id)
+	criteria, err := r.Repos.Judging.GetJudgingCriteriaById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	activeCriteria, err := models.GetActiveCriteria(ctx)
+	activeCriteria, err := r.Repos.Judging.GetActiveCriteria(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +41,7 @@ func (r *mutationResolver) EditCriteria(ctx context.Context, id int, input model
 		return nil, errs.NewForbiddenError(ctx, "This criteria cannot be updated. There must be at least four active criteria.")
 	}
 
-	err = models.EditJudgingCriteriaById(ctx, id, &input)
+	err = r.Repos.Judging.EditJudgingCriteriaById(ctx, id, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -62,12 +50,6 @@ func (r *mutationResolver) EditCriteria(ctx context.Context, id int, input model
 }
 
 func (r *mutationResolver) DeleteCriteria(ctx context.Context, id int) (*model.JudgingCriteria, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ManageJudgingCriteria) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to delete judging criteria.")
-	}
-
 	criteria, err := r.Query().Criteria(ctx, id)
 	if err != nil {
 		return nil, err
@@ -82,7 +64,7 @@ func (r *mutationResolver) DeleteCriteria(ctx context.Context, id int) (*model.J
 		return nil, errs.NewForbiddenError(ctx, "This criteria cannot be deleted. There must be at least four active criteria.")
 	}
 
-	err = models.DeleteJudgingCriteriaById(ctx, id)
+	err = r.Repos.Judging.DeleteJudgingCriteriaById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -91,13 +73,7 @@ func (r *mutationResolver) DeleteCriteria(ctx context.Context, id int) (*model.J
 }
 
 func (r *mutationResolver) CreateJudgingGroup(ctx context.Context, input model.CreateJudgingGroupInput) (*model.JudgingGroup, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ManageJudgingGroups) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to create judging groups.")
-	}
-
-	id, err := models.CreateJudgingGroup(ctx, &input)
+	id, err := r.Repos.Judging.CreateJudgingGroup(ctx, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -106,13 +82,7 @@ func (r *mutationResolver) CreateJudgingGroup(ctx context.Context, input model.C
 }
 
 func (r *mutationResolver) EditJudgingGroup(ctx context.Context, id int, input model.EditJudgingGroupInput) (*model.JudgingGroup, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ManageJudgingGroups) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to edit judging groups.")
-	}
-
-	err := models.EditJudgingGroupById(ctx, id, &input)
+	err := r.Repos.Judging.EditJudgingGroupById(ctx, id, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -121,18 +91,12 @@ func (r *mutationResolver) EditJudgingGroup(ctx context.Context, id int, input m
 }
 
 func (r *mutationResolver) DeleteJudgingGroup(ctx context.Context, id int) (*model.JudgingGroup, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ManageJudgingGroups) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to delete judging groups.")
-	}
-
 	group, err := r.Query().JudgingGroup(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	err = models.DeleteJudgingGroupById(ctx, id)
+	err = r.Repos.Judging.DeleteJudgingGroupById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -143,22 +107,28 @@ func (r *mutationResolver) DeleteJudgingGroup(ctx context.Context, id int) (*mod
 func (r *mutationResolver) ScoreEntry(ctx context.Context, id int, input model.ScoreEntryInput) (*model.Evaluation, error) {
 	user := auth.GetUserFromContext(ctx)
 
-	if !auth.HasPermission(user, auth.JudgeEntries) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to score entries.")
+	entry, err := r.Repos.Entries.GetEntryById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	contest, err := r.Repos.Contests.GetContestById(ctx, entry.Contest.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	evalId, err := models.ScoreEntry(ctx, user.ID, id, &input)
+	err = requireContestState(ctx, contest, "Oops! Entries can only be scored while the contest is being judged.", model.ContestStateJudging)
 	if err != nil {
 		return nil, err
 	}
 
-	entry, err := models.GetEntryById(ctx, id)
+	evalId, err := r.Repos.Judging.ScoreEntry(ctx, user.ID, id, &input)
 	if err != nil {
 		return nil, err
 	}
 
 	if !*entry.IsSkillLevelLocked {
-		models.AutoUpdateEntryLevel(ctx, id)
+		r.Repos.Judging.AutoUpdateEntryLevel(ctx, id)
 	}
 
 	return r.Query().Evaluation(ctx, *evalId)
@@ -166,29 +136,21 @@ func (r *mutationResolver) ScoreEntry(ctx context.Context, id int, input model.S
 
 func (r *queryResolver) Criteria(ctx context.Context, id int) (*model.JudgingCriteria, error) {
 	user := auth.GetUserFromContext(ctx)
-	if user == nil {
-		return nil, nil
-	}
 
-	criteria, err := models.GetJudgingCriteriaById(ctx, id)
+	criteria, err := r.Repos.Judging.GetJudgingCriteriaById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	if !criteria.IsActive && !auth.HasPermission(user, auth.ManageJudgingCriteria) {
-		return nil, nil
+		return nil, errs.NewForbiddenError(ctx, "You do not have permission to view inactive judging criteria.")
 	}
 
 	return criteria, nil
 }
 
 func (r *queryResolver) AllCriteria(ctx context.Context) ([]*model.JudgingCriteria, error) {
-	user := auth.GetUserFromContext(ctx)
-	if !auth.HasPermission(user, auth.ViewJudgingSettings) {
-		return []*model.JudgingCriteria{}, nil
-	}
-
-	criteria, err := models.GetAllCriteria(ctx)
+	criteria, err := r.Repos.Judging.GetAllCriteria(ctx)
 	if err != nil {
 		return []*model.JudgingCriteria{}, err
 	}
@@ -205,7 +167,7 @@ func (r *queryResolver) ActiveCriteria(ctx context.Context) ([]*model.JudgingCri
 		}
 		return criteria, nil
 	} else {
-		criteria, err := models.GetActiveCriteria(ctx)
+		criteria, err := r.Repos.Judging.GetActiveCriteria(ctx)
 		if err != nil {
 			return []*model.JudgingCriteria{}, nil
 		}
@@ -214,12 +176,7 @@ func (r *queryResolver) ActiveCriteria(ctx context.Context) ([]*model.JudgingCri
 }
 
 func (r *queryResolver) AllJudgingGroups(ctx context.Context) ([]*model.JudgingGroup, error) {
-	user := auth.GetUserFromContext(ctx)
-	if !auth.HasPermission(user, auth.ViewJudgingSettings) {
-		return []*model.JudgingGroup{}, nil
-	}
-
-	groups, err := models.GetAllJudgingGroups(ctx)
+	groups, err := r.Repos.Judging.GetAllJudgingGroups(ctx)
 	if err != nil {
 		return []*model.JudgingGroup{}, err
 	}
@@ -227,12 +184,7 @@ func (r *queryResolver) AllJudgingGroups(ctx context.Context) ([]*model.JudgingG
 }
 
 func (r *queryResolver) ActiveJudgingGroups(ctx context.Context) ([]*model.JudgingGroup, error) {
-	user := auth.GetUserFromContext(ctx)
-	if user == nil {
-		return []*model.JudgingGroup{}, nil
-	}
-
-	groups, err := models.GetActiveJudgingGroups(ctx)
+	groups, err := r.Repos.Judging.GetActiveJudgingGroups(ctx)
 	if err != nil {
 		return []*model.JudgingGroup{}, err
 	}
@@ -240,12 +192,7 @@ func (r *queryResolver) ActiveJudgingGroups(ctx context.Context) ([]*model.Judgi
 }
 
 func (r *queryResolver) JudgingGroup(ctx context.Context, id int) (*model.JudgingGroup, error) {
-	user := auth.GetUserFromContext(ctx)
-	if user == nil {
-		return nil, nil
-	}
-
-	group, err := models.GetJudgingGroupById(ctx, id)
+	group, err := r.Repos.Judging.GetJudgingGroupById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
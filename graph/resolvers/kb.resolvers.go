@@ -47,7 +47,7 @@ func (r *kBArticleResolver) HasDraft(ctx context.Context, obj *model.KBArticle)
 	user := auth.GetUserFromContext(ctx)
 
 	if auth.HasPermission(user, auth.EditKbContent) {
-		hasDraft, err := models.CheckKBArticleHasDraft(ctx, obj.ID)
+		hasDraft, err := r.Repos.KB.CheckKBArticleHasDraft(ctx, obj.ID)
 		if err != nil {
 			return nil, err
 		}
@@ -63,7 +63,7 @@ func (r *kBArticleResolver) Draft(ctx context.Context, obj *model.KBArticle) (*m
 		return nil, nil
 	}
 
-	draft, err := models.GetKBArticleDraftByArticleId(ctx, obj.ID)
+	draft, err := r.Repos.KB.GetKBArticleDraftByArticleId(ctx, obj.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -78,7 +78,7 @@ func (r *kBArticleResolver) Drafts(ctx context.Context, obj *model.KBArticle) ([
 		return []*model.KBArticleDraft{}, nil
 	}
 
-	drafts, err := models.GetKBArticleRecentDraftsByArticleId(ctx, obj.ID)
+	drafts, err := r.Repos.KB.GetKBArticleRecentDraftsByArticleId(ctx, obj.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -109,11 +109,11 @@ func (r *kBSectionResolver) Articles(ctx context.Context, obj *model.KBSection)
 	var articles []*model.KBArticle
 	var err error
 	if user == nil {
-		articles, err = models.GetPublicKBArticlesBySection(ctx, obj.ID)
+		articles, err = r.Repos.KB.GetPublicKBArticlesBySection(ctx, obj.ID)
 	} else if auth.HasPermission(user, auth.EditKbContent) {
-		articles, err = models.GetAdminKBArticlesBySection(ctx, obj.ID)
+		articles, err = r.Repos.KB.GetAdminKBArticlesBySection(ctx, obj.ID)
 	} else {
-		articles, err = models.GetEvaluatorKBArticlesBySection(ctx, obj.ID)
+		articles, err = r.Repos.KB.GetEvaluatorKBArticlesBySection(ctx, obj.ID)
 	}
 
 	if err != nil {
@@ -123,13 +123,7 @@ func (r *kBSectionResolver) Articles(ctx context.Context, obj *model.KBSection)
 }
 
 func (r *mutationResolver) CreateSection(ctx context.Context, input model.KBSectionInput) (*model.KBSection, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.EditKbContent) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to create KB sections.")
-	}
-
-	id, err := models.CreateKBSection(ctx, &input)
+	id, err := r.Repos.KB.CreateKBSection(ctx, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -138,13 +132,7 @@ func (r *mutationResolver) CreateSection(ctx context.Context, input model.KBSect
 }
 
 func (r *mutationResolver) EditSection(ctx context.Context, id int, input model.KBSectionInput) (*model.KBSection, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.EditKbContent) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to edit KB sections.")
-	}
-
-	err := models.EditKBSectionById(ctx, id, &input)
+	err := r.Repos.KB.EditKBSectionById(ctx, id, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -153,18 +141,12 @@ func (r *mutationResolver) EditSection(ctx context.Context, id int, input model.
 }
 
 func (r *mutationResolver) DeleteSection(ctx context.Context, id int) (*model.KBSection, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.DeleteKbContent) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to delete KB sections.")
-	}
-
 	section, err := r.Query().Section(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	articles, err := models.GetAdminKBArticlesBySection(ctx, section.ID)
+	articles, err := r.Repos.KB.GetAdminKBArticlesBySection(ctx, section.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -172,7 +154,7 @@ func (r *mutationResolver) DeleteSection(ctx context.Context, id int) (*model.KB
 		return nil, errs.NewForbiddenError(ctx, "This section has articles associated with it and cannot be deleted.")
 	}
 
-	err = models.DeleteKBSectionById(ctx, id)
+	err = r.Repos.KB.DeleteKBSectionById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -183,11 +165,7 @@ func (r *mutationResolver) DeleteSection(ctx context.Context, id int) (*model.KB
 func (r *mutationResolver) CreateArticle(ctx context.Context, input model.KBArticleInput) (*model.KBArticle, error) {
 	user := auth.GetUserFromContext(ctx)
 
-	if !auth.HasPermission(user, auth.EditKbContent) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to create KB articles.")
-	}
-
-	id, err := models.CreateKBArticle(ctx, &input, user.ID)
+	id, err := r.Repos.KB.CreateKBArticle(ctx, &input, user.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -198,11 +176,7 @@ func (r *mutationResolver) CreateArticle(ctx context.Context, input model.KBArti
 func (r *mutationResolver) EditArticle(ctx context.Context, id int, input model.KBArticleInput) (*model.KBArticle, error) {
 	user := auth.GetUserFromContext(ctx)
 
-	if !auth.HasPermission(user, auth.EditKbContent) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to edit KB articles.")
-	}
-
-	err := models.EditKBArticle(ctx, id, &input, user.ID)
+	err := r.Repos.KB.EditKBArticle(ctx, id, &input, user.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -211,13 +185,7 @@ func (r *mutationResolver) EditArticle(ctx context.Context, id int, input model.
 }
 
 func (r *mutationResolver) EditArticleProperties(ctx context.Context, id int, visibility string, section int) (*model.KBArticle, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.EditKbContent) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to edit KB articles.")
-	}
-
-	err := models.EditKBArticleProperties(ctx, id, visibility, section)
+	err := r.Repos.KB.EditKBArticleProperties(ctx, id, visibility, section)
 	if err != nil {
 		return nil, err
 	}
@@ -226,18 +194,12 @@ func (r *mutationResolver) EditArticleProperties(ctx context.Context, id int, vi
 }
 
 func (r *mutationResolver) DeleteArticle(ctx context.Context, id int) (*model.KBArticle, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.DeleteKbContent) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to delete KB articles.")
-	}
-
 	article, err := r.Query().Article(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	err = models.DeleteKBArticle(ctx, id)
+	err = r.Repos.KB.DeleteKBArticle(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -246,18 +208,12 @@ func (r *mutationResolver) DeleteArticle(ctx context.Context, id int) (*model.KB
 }
 
 func (r *mutationResolver) DeleteArticleDraft(ctx context.Context, id int) (*model.KBArticle, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.EditKbContent) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to delete KB article drafts.")
-	}
-
 	article, err := r.Query().Article(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	err = models.DeleteKBArticleDraft(ctx, id)
+	err = r.Repos.KB.DeleteKBArticleDraft(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -266,13 +222,7 @@ func (r *mutationResolver) DeleteArticleDraft(ctx context.Context, id int) (*mod
 }
 
 func (r *mutationResolver) PublishArticle(ctx context.Context, id int) (*model.KBArticle, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.PublishKbContent) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to publish KB articles.")
-	}
-
-	err := models.PublishKBArticle(ctx, id)
+	err := r.Repos.KB.PublishKBArticle(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -281,13 +231,7 @@ func (r *mutationResolver) PublishArticle(ctx context.Context, id int) (*model.K
 }
 
 func (r *mutationResolver) UnpublishArticle(ctx context.Context, id int) (*model.KBArticle, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.PublishKbContent) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to unpublish KB articles.")
-	}
-
-	err := models.UnpublishKBArticle(ctx, id)
+	err := r.Repos.KB.UnpublishKBArticle(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -302,11 +246,11 @@ func (r *queryResolver) Sections(ctx context.Context) ([]*model.KBSection, error
 	var err error
 
 	if user == nil {
-		sections, err = models.GetPublicKBSections(ctx)
+		sections, err = r.Repos.KB.GetPublicKBSections(ctx)
 	} else if auth.HasPermission(user, auth.EditKbContent) {
-		sections, err = models.GetAllKBSections(ctx)
+		sections, err = r.Repos.KB.GetAllKBSections(ctx)
 	} else {
-		sections, err = models.GetEvaluatorKBSections(ctx)
+		sections, err = r.Repos.KB.GetEvaluatorKBSections(ctx)
 	}
 
 	if err != nil {
@@ -318,7 +262,7 @@ func (r *queryResolver) Sections(ctx context.Context) ([]*model.KBSection, error
 func (r *queryResolver) Section(ctx context.Context, id int) (*model.KBSection, error) {
 	user := auth.GetUserFromContext(ctx)
 
-	section, err := models.GetKBSectionById(ctx, id)
+	section, err := r.Repos.KB.GetKBSectionById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -345,7 +289,7 @@ func (r *queryResolver) Section(ctx context.Context, id int) (*model.KBSection,
 func (r *queryResolver) Article(ctx context.Context, id int) (*model.KBArticle, error) {
 	user := auth.GetUserFromContext(ctx)
 
-	article, err := models.GetKBArticleById(ctx, id)
+	article, err := r.Repos.KB.GetKBArticleById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -362,21 +306,15 @@ func (r *queryResolver) Article(ctx context.Context, id int) (*model.KBArticle,
 }
 
 func (r *queryResolver) Articles(ctx context.Context, filter *string) ([]*model.KBArticle, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.EditKbContent) {
-		return []*model.KBArticle{}, errs.NewForbiddenError(ctx, "You do not have permission to view the full list of KB articles.")
-	}
-
 	var articles []*model.KBArticle
 	var err error
 
 	if filter == nil {
-		articles, err = models.GetAllKBArticles(ctx)
+		articles, err = r.Repos.KB.GetAllKBArticles(ctx)
 	} else if *filter == "DRAFTS" {
-		articles, err = models.GetAllKBArticlesWithDrafts(ctx)
+		articles, err = r.Repos.KB.GetAllKBArticlesWithDrafts(ctx)
 	} else {
-		articles, err = models.GetAllKBArticles(ctx)
+		articles, err = r.Repos.KB.GetAllKBArticles(ctx)
 	}
 
 	if err != nil {
@@ -386,6 +324,26 @@ func (r *queryResolver) Articles(ctx context.Context, filter *string) ([]*model.
 	return articles, nil
 }
 
+func (r *queryResolver) ArticlesConnection(ctx context.Context, filter *string, first *int, after *string) (*model.KBArticleConnection, error) {
+	args, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	var page *models.Page[*model.KBArticle]
+	if filter != nil && *filter == "DRAFTS" {
+		page, err = r.Repos.KB.GetKBArticlesWithDraftsPage(ctx, args)
+	} else {
+		page, err = r.Repos.KB.GetKBArticlesPage(ctx, args)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newKBArticleConnection(page, args), nil
+}
+
 // KBArticle returns generated.KBArticleResolver implementation.
 func (r *Resolver) KBArticle() generated.KBArticleResolver { return &kBArticleResolver{r} }
 
@@ -0,0 +1,35 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/oidc"
+)
+
+func (r *mutationResolver) UnlinkOidcIdentities(ctx context.Context, userID int) (bool, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if user == nil || !user.IsAdmin {
+		return false, errs.NewForbiddenError(ctx, "You do not have permission to unlink single sign-on accounts.")
+	}
+
+	if err := r.Repos.OIDC.DeleteOIDCIdentitiesByUserId(ctx, userID); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *queryResolver) OidcLoginURL(ctx context.Context) (*string, error) {
+	if !r.OIDCEnabled {
+		return nil, nil
+	}
+
+	loginURL := r.AppURL + oidc.LoginPath
+	return &loginURL, nil
+}
@@ -0,0 +1,122 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+)
+
+// Validates the first and after arguments of a paginated query, applying the default page size
+func pageArgs(ctx context.Context, first *int, after *string) (models.PageArgs, error) {
+	args := models.PageArgs{First: models.DefaultPageSize}
+
+	if first != nil {
+		if *first < 0 || *first > models.MaxPageSize {
+			return args, errs.NewBadRequestError(ctx, "Oops! You can request between 0 and 100 items at a time.")
+		}
+		args.First = *first
+	}
+
+	if after != nil {
+		args.After = *after
+	}
+
+	return args, nil
+}
+
+// Builds the page info for a page of results. Pages can only be fetched going forwards,
+// so any page requested with a cursor has a previous page.
+func newPageInfo[T any](page *models.Page[T], args models.PageArgs) *model.PageInfo {
+	info := &model.PageInfo{
+		HasNextPage:     page.HasNextPage,
+		HasPreviousPage: args.After != "",
+	}
+
+	if len(page.Cursors) > 0 {
+		info.StartCursor = &page.Cursors[0]
+		info.EndCursor = &page.Cursors[len(page.Cursors)-1]
+	}
+
+	return info
+}
+
+// Pairs each item on a page with its cursor
+func newEdges[T any, E any](page *models.Page[T], edge func(cursor string, node T) E) []E {
+	edges := make([]E, len(page.Items))
+	for i, item := range page.Items {
+		edges[i] = edge(page.Cursors[i], item)
+	}
+	return edges
+}
+
+func newEntryConnection(page *models.Page[*model.Entry], args models.PageArgs) *model.EntryConnection {
+	return &model.EntryConnection{
+		Edges: newEdges(page, func(cursor string, node *model.Entry) *model.EntryEdge {
+			return &model.EntryEdge{Cursor: cursor, Node: node}
+		}),
+		PageInfo:   newPageInfo(page, args),
+		TotalCount: page.TotalCount,
+	}
+}
+
+func newUserConnection(page *models.Page[*model.User], args models.PageArgs) *model.UserConnection {
+	return &model.UserConnection{
+		Edges: newEdges(page, func(cursor string, node *model.User) *model.UserEdge {
+			return &model.UserEdge{Cursor: cursor, Node: node}
+		}),
+		PageInfo:   newPageInfo(page, args),
+		TotalCount: page.TotalCount,
+	}
+}
+
+func newKBArticleConnection(page *models.Page[*model.KBArticle], args models.PageArgs) *model.KBArticleConnection {
+	return &model.KBArticleConnection{
+		Edges: newEdges(page, func(cursor string, node *model.KBArticle) *model.KBArticleEdge {
+			return &model.KBArticleEdge{Cursor: cursor, Node: node}
+		}),
+		PageInfo:   newPageInfo(page, args),
+		TotalCount: page.TotalCount,
+	}
+}
+
+func newContestantConnection(page *models.Page[*model.Contestant], args models.PageArgs) *model.ContestantConnection {
+	return &model.ContestantConnection{
+		Edges: newEdges(page, func(cursor string, node *model.Contestant) *model.ContestantEdge {
+			return &model.ContestantEdge{Cursor: cursor, Node: node}
+		}),
+		PageInfo:   newPageInfo(page, args),
+		TotalCount: page.TotalCount,
+	}
+}
+
+func newErrorConnection(page *models.Page[*model.Error], args models.PageArgs) *model.ErrorConnection {
+	return &model.ErrorConnection{
+		Edges: newEdges(page, func(cursor string, node *model.Error) *model.ErrorEdge {
+			return &model.ErrorEdge{Cursor: cursor, Node: node}
+		}),
+		PageInfo:   newPageInfo(page, args),
+		TotalCount: page.TotalCount,
+	}
+}
+
+func newAuditLogConnection(page *models.Page[*model.AuditLogEntry], args models.PageArgs) *model.AuditLogConnection {
+	return &model.AuditLogConnection{
+		Edges: newEdges(page, func(cursor string, node *model.AuditLogEntry) *model.AuditLogEdge {
+			return &model.AuditLogEdge{Cursor: cursor, Node: node}
+		}),
+		PageInfo:   newPageInfo(page, args),
+		TotalCount: page.TotalCount,
+	}
+}
+
+func newImpersonationConnection(page *models.Page[*model.Impersonation], args models.PageArgs) *model.ImpersonationConnection {
+	return &model.ImpersonationConnection{
+		Edges: newEdges(page, func(cursor string, node *model.Impersonation) *model.ImpersonationEdge {
+			return &model.ImpersonationEdge{Cursor: cursor, Node: node}
+		}),
+		PageInfo:   newPageInfo(page, args),
+		TotalCount: page.TotalCount,
+	}
+}
@@ -9,22 +9,15 @@ import (
 	"github.com/KA-Challenge-Council/Bema/graph/generated"
 	"github.com/KA-Challenge-Council/Bema/graph/model"
 	"github.com/KA-Challenge-Council/Bema/internal/auth"
-	"github.com/KA-Challenge-Council/Bema/internal/models"
 )
 
 func (r *entryCountsResolver) Flagged(ctx context.Context, obj *model.EntryCounts) (int, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ViewAdminStats) {
-		return 0, nil
-	}
-
-	contest, err := models.GetCurrentContest(ctx)
+	contest, err := r.Repos.Contests.GetCurrentContest(ctx)
 	if err != nil {
 		return 0, err
 	}
 
-	count, err := models.GetFlaggedEntryCountByContestId(ctx, contest.ID)
+	count, err := r.Repos.Reports.GetFlaggedEntryCountByContestId(ctx, contest.ID)
 	if err != nil {
 		return 0, err
 	}
@@ -33,18 +26,12 @@ func (r *entryCountsResolver) Flagged(ctx context.Context, obj *model.EntryCount
 }
 
 func (r *entryCountsResolver) Disqualified(ctx context.Context, obj *model.EntryCounts) (int, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ViewAdminStats) {
-		return 0, nil
-	}
-
-	contest, err := models.GetCurrentContest(ctx)
+	contest, err := r.Repos.Contests.GetCurrentContest(ctx)
 	if err != nil {
 		return 0, err
 	}
 
-	count, err := models.GetDisqualifiedEntryCountByContestId(ctx, contest.ID)
+	count, err := r.Repos.Reports.GetDisqualifiedEntryCountByContestId(ctx, contest.ID)
 	if err != nil {
 		return 0, err
 	}
@@ -53,18 +40,12 @@ func (r *entryCountsResolver) Disqualified(ctx context.Context, obj *model.Entry
 }
 
 func (r *entryCountsResolver) Total(ctx context.Context, obj *model.EntryCounts) (int, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ViewAdminStats) {
-		return 0, nil
-	}
-
-	contest, err := models.GetCurrentContest(ctx)
+	contest, err := r.Repos.Contests.GetCurrentContest(ctx)
 	if err != nil {
 		return 0, err
 	}
 
-	count, err := models.GetTotalEntryCountByContestId(ctx, contest.ID)
+	count, err := r.Repos.Reports.GetTotalEntryCountByContestId(ctx, contest.ID)
 	if err != nil {
 		return 0, err
 	}
@@ -90,12 +71,12 @@ func (r *judgingProgressResolver) User(ctx context.Context, obj *model.JudgingPr
 		}, nil
 	}
 
-	contest, err := models.GetCurrentContest(ctx)
+	contest, err := r.Repos.Contests.GetCurrentContest(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	progress, err := models.GetUserProgressByContestId(ctx, user.ID, contest.ID)
+	progress, err := r.Repos.Reports.GetUserProgressByContestId(ctx, user.ID, contest.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +93,7 @@ func (r *judgingProgressResolver) Group(ctx context.Context, obj *model.JudgingP
 		}, nil
 	}
 
-	groupId, err := models.GetUserGroupById(ctx, user.ID)
+	groupId, err := r.Repos.Users.GetUserGroupById(ctx, user.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -123,12 +104,12 @@ func (r *judgingProgressResolver) Group(ctx context.Context, obj *model.JudgingP
 		}, nil
 	}
 
-	contest, err := models.GetCurrentContest(ctx)
+	contest, err := r.Repos.Contests.GetCurrentContest(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	progress, err := models.GetGroupProgressByContestId(ctx, *groupId, contest.ID)
+	progress, err := r.Repos.Reports.GetGroupProgressByContestId(ctx, *groupId, contest.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -137,18 +118,12 @@ func (r *judgingProgressResolver) Group(ctx context.Context, obj *model.JudgingP
 }
 
 func (r *judgingProgressResolver) Entries(ctx context.Context, obj *model.JudgingProgress) (*model.Progress, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ViewAdminStats) {
-		return nil, nil
-	}
-
-	contest, err := models.GetCurrentContest(ctx)
+	contest, err := r.Repos.Contests.GetCurrentContest(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	progress, err := models.GetEntryProgressByContestId(ctx, contest.ID)
+	progress, err := r.Repos.Reports.GetEntryProgressByContestId(ctx, contest.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -157,18 +132,12 @@ func (r *judgingProgressResolver) Entries(ctx context.Context, obj *model.Judgin
 }
 
 func (r *judgingProgressResolver) Evaluations(ctx context.Context, obj *model.JudgingProgress) (*model.Progress, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ViewAdminStats) {
-		return nil, nil
-	}
-
-	contest, err := models.GetCurrentContest(ctx)
+	contest, err := r.Repos.Contests.GetCurrentContest(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	progress, err := models.GetEvaluationProgressByContestId(ctx, contest.ID)
+	progress, err := r.Repos.Reports.GetEvaluationProgressByContestId(ctx, contest.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -177,18 +146,12 @@ func (r *judgingProgressResolver) Evaluations(ctx context.Context, obj *model.Ju
 }
 
 func (r *judgingProgressResolver) Evaluators(ctx context.Context, obj *model.JudgingProgress) ([]*model.EvaluatorProgress, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ViewAdminStats) {
-		return nil, nil
-	}
-
-	contest, err := models.GetCurrentContest(ctx)
+	contest, err := r.Repos.Contests.GetCurrentContest(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	progress, err := models.GetEvaluatorProgressByContestId(ctx, contest.ID)
+	progress, err := r.Repos.Reports.GetEvaluatorProgressByContestId(ctx, contest.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -201,12 +164,6 @@ func (r *queryResolver) JudgingProgress(ctx context.Context) (*model.JudgingProg
 }
 
 func (r *queryResolver) EntryCounts(ctx context.Context) (*model.EntryCounts, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.ViewAdminStats) {
-		return nil, nil
-	}
-
 	return &model.EntryCounts{}, nil
 }
 
@@ -1,7 +1,290 @@
 package resolvers
 
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	"github.com/KA-Challenge-Council/Bema/internal/dataloaders"
+	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/mail"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+)
+
 // This file will not be regenerated automatically.
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 
-type Resolver struct{}
+type Resolver struct {
+	Repos  *models.Repositories
+	Mailer mail.Mailer
+	// The address users reach the app at, used to build links in emails
+	AppURL string
+	// Whether users can log in through the OpenID Connect provider
+	OIDCEnabled bool
+}
+
+// Returns the request's dataloaders, or an unshared set if the dataloader middleware did not run
+func (r *Resolver) loaders(ctx context.Context) *dataloaders.Loaders {
+	if loaders := dataloaders.For(ctx); loaders != nil {
+		return loaders
+	}
+	return dataloaders.NewLoaders(r.Repos)
+}
+
+// Returns a forbidden error with the message unless the user holds every permission the requested
+// user holds, and is an admin if the requested user is one
+func (r *Resolver) checkPermissionSuperset(ctx context.Context, user *auth.User, id int, message string) error {
+	requestedUser, err := r.Repos.Users.GetUserById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	keys, err := r.Repos.Users.GetUserPermissionKeysById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !auth.HasPermissionSuperset(user, auth.ToPermissions(keys), *requestedUser.IsAdmin) {
+		return errs.NewForbiddenError(ctx, message)
+	}
+	return nil
+}
+
+// Returns a forbidden error naming the first permission in the list that the user does not hold
+func checkCanGrant(ctx context.Context, user *auth.User, keys []string) error {
+	if missing := auth.MissingPermissions(user, auth.ToPermissions(keys)); len(missing) > 0 {
+		return errs.NewForbiddenError(ctx, "You cannot grant the "+string(missing[0])+" permission because you do not have it.")
+	}
+	return nil
+}
+
+// Returns a forbidden error with the message unless the contest is in one of the states
+func requireContestState(ctx context.Context, contest *model.Contest, message string, states ...model.ContestState) error {
+	for _, state := range states {
+		if contest.State == state {
+			return nil
+		}
+	}
+	return errs.NewForbiddenError(ctx, message)
+}
+
+// Returns a forbidden error with the message unless the contest of the evaluated entry is being judged
+func (r *Resolver) requireEvaluationJudging(ctx context.Context, evaluation *model.Evaluation, message string) error {
+	entry, err := r.Repos.Entries.GetEntryById(ctx, evaluation.Entry.ID)
+	if err != nil {
+		return err
+	}
+
+	contest, err := r.Repos.Contests.GetContestById(ctx, entry.Contest.ID)
+	if err != nil {
+		return err
+	}
+	return requireContestState(ctx, contest, message, model.ContestStateJudging)
+}
+
+// Returns a bad request error if a contest input sets a field that is now worked out from the
+// contest's state, so callers do not think the change was made
+func rejectContestStateField(ctx context.Context, field string, value *bool) error {
+	if value == nil {
+		return nil
+	}
+	return errs.NewBadRequestError(ctx, "Oops! "+field+" can no longer be set. Use transitionContest to change the contest's state.")
+}
+
+// Describes a contest state the way it reads in a sentence, e.g. "accepting entries"
+func describeContestState(state model.ContestState) string {
+	return strings.ToLower(strings.ReplaceAll(string(state), "_", " "))
+}
+
+// Reports whether the user can see which entries won the contest. Winners stay hidden until the
+// results are published, except from those who pick them.
+func canSeeWinners(user *auth.User, contest *model.Contest) bool {
+	return models.ContestResultsPublished(contest.State) || auth.HasPermission(user, auth.ManageWinners)
+}
+
+// Returns a forbidden error if the request was authenticated with an API token. Credentials and
+// identities can only be managed from a login session, so a leaked token cannot be used to gain more access.
+func requireSession(ctx context.Context, user *auth.User) error {
+	if user != nil && user.APITokenID != nil {
+		return errs.NewForbiddenError(ctx, "This can only be done from a login session, not with an API token.")
+	}
+	return nil
+}
+
+// Counts a failed login against the username and the IP address, locking out either one that has
+// failed too often, and returns the same response whether or not the account exists
+func (r *Resolver) failLogin(ctx context.Context, username string, ip string) (*model.LoginResponse, error) {
+	policy := auth.LoginThrottle
+	throttles := []struct {
+		kind        model.LoginThrottleKind
+		value       string
+		maxAttempts int
+	}{
+		{model.LoginThrottleKindUsername, username, policy.MaxAttempts},
+		{model.LoginThrottleKindIPAddress, ip, policy.MaxAttemptsPerIP},
+	}
+
+	for _, t := range throttles {
+		failures, err := r.Repos.LoginThrottles.RecordFailedLogin(ctx, t.kind, t.value, policy.Window)
+		if err != nil {
+			return nil, err
+		}
+
+		if lockout := policy.LockoutAfter(failures, t.maxAttempts); lockout > 0 {
+			if err := r.Repos.LoginThrottles.LockLogin(ctx, t.kind, t.value, time.Now().Add(lockout)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &model.LoginResponse{
+		Success:    false,
+		IsDisabled: false,
+	}, nil
+}
+
+// Starts a login session for the user and sets its cookie on the response
+func startSession(ctx context.Context, userId int) error {
+	token := auth.CreateAuthToken(ctx, userId, nil, true)
+	if token == nil {
+		return errs.NewInternalError(ctx, "An unexpected error occurred while logging in", errors.New("could not create a session"))
+	}
+	auth.SetSessionCookie(ctx, token, auth.SessionMaxLifetime)
+	return nil
+}
+
+// Describes how long until t in whole minutes, rounding up
+func minutesUntil(t time.Time) string {
+	minutes := int(math.Ceil(time.Until(t).Minutes()))
+	if minutes <= 1 {
+		return "1 minute"
+	}
+	return strconv.Itoa(minutes) + " minutes"
+}
+
+// Emails the user a link to reset their password, unless they were sent one very recently. The
+// email is sent in the background so the response does not depend on how long the mail server takes.
+func (r *Resolver) sendPasswordReset(ctx context.Context, user *model.User) error {
+	last, err := r.Repos.PasswordResets.GetLastPasswordResetTime(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if last != nil && time.Since(*last) < auth.PasswordResetCooldown {
+		return nil
+	}
+
+	token, err := auth.NewSecretToken()
+	if err != nil {
+		return errs.NewInternalError(ctx, "An unexpected error occurred while requesting a password reset", err)
+	}
+
+	expires := time.Now().Add(auth.PasswordResetLifetime)
+	if err := r.Repos.PasswordResets.CreatePasswordReset(ctx, user.ID, auth.HashSecretToken(token), expires); err != nil {
+		return err
+	}
+
+	greeting := "Hi"
+	if user.Nickname != nil && *user.Nickname != "" {
+		greeting += " " + *user.Nickname
+	}
+
+	msg := mail.Message{
+		To:      *user.Email,
+		Subject: "Reset your KA Challenge Council password",
+		Body: greeting + ",\n\n" +
+			"Someone asked to reset the password for your KA Challenge Council account, " + *user.Username + ". " +
+			"To choose a new password, open this link within the next " + minutesUntil(expires) + ":\n\n" +
+			r.AppURL + "/reset-password?token=" + url.QueryEscape(token) + "\n\n" +
+			"If you did not ask for this, you can ignore this email. Your password has not been changed.\n",
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		if err := r.Mailer.Send(ctx, msg); err != nil {
+			log.Printf("Sending a password reset email to user %d failed: %v", user.ID, err)
+		}
+	}()
+
+	return nil
+}
+
+// Emails an invitation link to the invitee in the background
+func (r *Resolver) sendInvitation(inv *model.Invitation, link string) {
+	msg := mail.Message{
+		To:      inv.Email,
+		Subject: "You're invited to join the KA Challenge Council",
+		Body: "Hi " + inv.Name + ",\n\n" +
+			"You have been invited to create an account on the KA Challenge Council website. " +
+			"To choose your username and password, open this link before " + inv.ExpiresAt.UTC().Format("January 2, 2006 at 15:04 UTC") + ":\n\n" +
+			link + "\n\n" +
+			"The link can only be used once. If you were not expecting this, you can ignore this email.\n",
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		if err := r.Mailer.Send(ctx, msg); err != nil {
+			log.Printf("Sending invitation %d failed: %v", inv.ID, err)
+		}
+	}()
+}
+
+// Checks a code from the user's authenticator app, or one of their recovery codes. Each code can
+// only be used once.
+func (r *Resolver) checkTwoFactorCode(ctx context.Context, userId int, secret string, code string) (bool, error) {
+	if step, ok := auth.ValidateTOTP(secret, code, time.Now()); ok {
+		return r.Repos.TwoFactor.UseTOTPStep(ctx, userId, step)
+	}
+	return r.Repos.TwoFactor.UseRecoveryCode(ctx, userId, auth.HashRecoveryCode(code))
+}
+
+// Returns a bad request error unless the code is a valid two-factor code for the logged in user.
+// Wrong codes count as failed logins, so they cannot be guessed with a stolen session.
+func (r *Resolver) requireTwoFactorCode(ctx context.Context, user *auth.User, secret string, code string) error {
+	throttledName := strings.ToLower(user.Username)
+	ip := auth.GetRequestInfoFromContext(ctx).IP
+	lockedUntil, err := r.Repos.LoginThrottles.GetLoginLockedUntil(ctx, throttledName, ip)
+	if err != nil {
+		return err
+	}
+	if lockedUntil != nil {
+		return errs.NewTooManyRequestsError(ctx, "Too many failed attempts. Please try again in "+minutesUntil(*lockedUntil)+".")
+	}
+
+	ok, err := r.checkTwoFactorCode(ctx, user.ID, secret, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if _, err := r.failLogin(ctx, throttledName, ip); err != nil {
+			return err
+		}
+		return errs.NewBadRequestError(ctx, "Oops! That code is not correct.")
+	}
+	return nil
+}
+
+// Returns a new set of recovery codes and the hashes to store
+func newRecoveryCodes(ctx context.Context) ([]string, []string, error) {
+	codes, err := auth.NewRecoveryCodes()
+	if err != nil {
+		return nil, nil, errs.NewInternalError(ctx, "An unexpected error occurred while creating recovery codes", err)
+	}
+
+	hashes := []string{}
+	for _, code := range codes {
+		hashes = append(hashes, auth.HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
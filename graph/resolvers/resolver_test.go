@@ -0,0 +1,224 @@
+package resolvers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	"github.com/KA-Challenge-Council/Bema/internal/models/memory"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Returns the HTTP status an error from a resolver carries, or 0 if it has none
+func errorStatus(err error) int {
+	gqlErr, ok := err.(*gqlerror.Error)
+	if !ok {
+		return 0
+	}
+	status, _ := gqlErr.Extensions["status"].(int)
+	return status
+}
+
+// Creates a user in the resolver's store, with a password unless it is empty
+func createUser(t *testing.T, r *Resolver, username string, password string) int {
+	t.Helper()
+	ctx := context.Background()
+
+	id, err := r.Repos.Users.CreateUser(ctx, &model.CreateUserInput{Name: username, Kaid: username, Username: username, TermStart: "2022-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if password != "" {
+		if err := r.Repos.Users.ChangeUserPasswordById(ctx, *id, password); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return *id
+}
+
+func TestLogin(t *testing.T) {
+	r := &Resolver{Repos: memory.NewRepositories()}
+	ctx := context.Background()
+
+	createUser(t, r, "judge", "password")
+	createUser(t, r, "nopassword", "")
+	locked := createUser(t, r, "locked", "password")
+	err := r.Repos.Users.EditUserById(ctx, locked, &model.EditUserProfileInput{Name: "locked", Kaid: "locked", Username: "locked", TermStart: "2022-01-01", AccountLocked: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		username   string
+		password   string
+		isDisabled bool
+	}{
+		{"unknown user", "nobody", "password", false},
+		{"wrong password", "judge", "wrong", false},
+		{"no password", "nopassword", "", false},
+		{"locked account", "locked", "password", true},
+		{"wrong password for a locked account", "locked", "wrong", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := r.Mutation().Login(ctx, tt.username, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Success || res.IsDisabled != tt.isDisabled {
+				t.Fatalf("got success %v and disabled %v, want a failed login with disabled %v", res.Success, res.IsDisabled, tt.isDisabled)
+			}
+		})
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	r := &Resolver{Repos: memory.NewRepositories()}
+	ctx := context.Background()
+	createUser(t, r, "judge", "password")
+
+	for i := 0; i < auth.LoginThrottle.MaxAttempts; i++ {
+		if _, err := r.Mutation().Login(ctx, "judge", "wrong"); err != nil {
+			t.Fatalf("attempt %d: %v", i+1, err)
+		}
+	}
+
+	// The right password is refused too once the username is locked out, in any letter case
+	if _, err := r.Mutation().Login(ctx, "JUDGE", "password"); errorStatus(err) != 429 {
+		t.Fatalf("got %v, want a too many requests error", err)
+	}
+}
+
+func TestCheckPermissionSuperset(t *testing.T) {
+	r := &Resolver{Repos: memory.NewRepositories()}
+	ctx := context.Background()
+
+	target := createUser(t, r, "target", "")
+	err := r.Repos.Users.EditUserPermissionsById(ctx, target, []string{"EDIT_CONTESTS", "VIEW_ALL_USERS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := createUser(t, r, "admin", "")
+	err = r.Repos.Users.EditUserById(ctx, admin, &model.EditUserProfileInput{Name: "admin", Kaid: "admin", Username: "admin", TermStart: "2022-01-01", IsAdmin: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		user    *auth.User
+		id      int
+		allowed bool
+	}{
+		{"same permissions", &auth.User{Permissions: auth.Permissions{auth.EditContests: true, auth.ViewAllUsers: true}}, target, true},
+		{"more permissions", &auth.User{Permissions: auth.Permissions{auth.EditContests: true, auth.ViewAllUsers: true, auth.EditUserProfiles: true}}, target, true},
+		{"missing a permission", &auth.User{Permissions: auth.Permissions{auth.EditContests: true}}, target, false},
+		{"admin acting on a user", &auth.User{IsAdmin: true}, target, true},
+		{"non-admin acting on an admin", &auth.User{Permissions: auth.Permissions{auth.EditContests: true, auth.ViewAllUsers: true}}, admin, false},
+		{"admin acting on an admin", &auth.User{IsAdmin: true}, admin, true},
+		{"no user", nil, target, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.checkPermissionSuperset(ctx, tt.user, tt.id, "Forbidden")
+			if tt.allowed && err != nil {
+				t.Fatalf("got %v, want the user to be allowed", err)
+			}
+			if !tt.allowed && errorStatus(err) != 403 {
+				t.Fatalf("got %v, want a forbidden error", err)
+			}
+		})
+	}
+}
+
+func TestTransitionContest(t *testing.T) {
+	r := &Resolver{Repos: memory.NewRepositories()}
+	ctx := context.Background()
+
+	id, err := r.Repos.Contests.CreateContest(ctx, &model.CreateContestInput{Name: "Contest", StartDate: "2022-01-01", EndDate: "2022-02-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each step runs against the state the previous one left the contest in
+	tests := []struct {
+		to      model.ContestState
+		want    model.ContestState
+		allowed bool
+	}{
+		{model.ContestStateJudging, model.ContestStateDraft, false},
+		{model.ContestStateAcceptingEntries, model.ContestStateAcceptingEntries, true},
+		{model.ContestStateDraft, model.ContestStateDraft, true},
+		{model.ContestStateAcceptingEntries, model.ContestStateAcceptingEntries, true},
+		{model.ContestStateJudging, model.ContestStateJudging, true},
+		{model.ContestStateArchived, model.ContestStateJudging, false},
+		{model.ContestStateVoting, model.ContestStateVoting, true},
+		{model.ContestStateJudging, model.ContestStateJudging, true},
+		{model.ContestStateVoting, model.ContestStateVoting, true},
+		{model.ContestStateResultsPublished, model.ContestStateResultsPublished, true},
+		{model.ContestStateVoting, model.ContestStateResultsPublished, false},
+		{model.ContestStateArchived, model.ContestStateArchived, true},
+		{model.ContestStateDraft, model.ContestStateArchived, false},
+	}
+
+	for i, tt := range tests {
+		contest, err := r.Mutation().TransitionContest(ctx, *id, tt.to)
+		if tt.allowed && (err != nil || contest.State != tt.to) {
+			t.Fatalf("step %d: moving to %s got %v, %v", i+1, tt.to, contest, err)
+		}
+		if !tt.allowed && errorStatus(err) != 400 {
+			t.Fatalf("step %d: moving to %s got %v, want a bad request error", i+1, tt.to, err)
+		}
+
+		current, err := r.Query().Contest(ctx, *id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if current.State != tt.want {
+			t.Fatalf("step %d: the contest is %s, want %s", i+1, current.State, tt.want)
+		}
+	}
+}
+
+func TestContestInputsRejectStateFields(t *testing.T) {
+	r := &Resolver{Repos: memory.NewRepositories()}
+	ctx := context.Background()
+	enabled := true
+
+	if _, err := r.Mutation().CreateContest(ctx, model.CreateContestInput{Name: "Contest", StartDate: "2022-01-01", EndDate: "2022-02-01", IsCurrent: &enabled}); errorStatus(err) != 400 {
+		t.Fatalf("creating a contest with isCurrent got %v, want a bad request error", err)
+	}
+
+	contest, err := r.Mutation().CreateContest(ctx, model.CreateContestInput{Name: "Contest", StartDate: "2022-01-01", EndDate: "2022-02-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input model.EditContestInput
+	}{
+		{"isCurrent", model.EditContestInput{Name: "Edited", StartDate: "2022-01-01", EndDate: "2022-02-01", IsCurrent: &enabled}},
+		{"isVotingEnabled", model.EditContestInput{Name: "Edited", StartDate: "2022-01-01", EndDate: "2022-02-01", IsVotingEnabled: &enabled}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := r.Mutation().EditContest(ctx, contest.ID, tt.input); errorStatus(err) != 400 {
+				t.Fatalf("got %v, want a bad request error", err)
+			}
+
+			current, err := r.Query().Contest(ctx, contest.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if current.Name != "Contest" || current.State != model.ContestStateDraft {
+				t.Fatalf("the contest was changed to %s in the %s state", current.Name, current.State)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
+)
+
+func (r *mutationResolver) CreateRole(ctx context.Context, input model.RoleInput) (*model.Role, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if user == nil || !user.IsAdmin {
+		return nil, errs.NewForbiddenError(ctx, "You do not have permission to create roles.")
+	}
+
+	id, err := r.Repos.Roles.CreateRole(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Query().Role(ctx, *id)
+}
+
+func (r *mutationResolver) EditRole(ctx context.Context, id int, input model.RoleInput) (*model.Role, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if user == nil || !user.IsAdmin {
+		return nil, errs.NewForbiddenError(ctx, "You do not have permission to edit roles.")
+	}
+
+	// Make sure the role exists
+	_, err := r.Repos.Roles.GetRoleById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Repos.Roles.EditRoleById(ctx, id, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Query().Role(ctx, id)
+}
+
+func (r *mutationResolver) DeleteRole(ctx context.Context, id int) (*model.Role, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if user == nil || !user.IsAdmin {
+		return nil, errs.NewForbiddenError(ctx, "You do not have permission to delete roles.")
+	}
+
+	role, err := r.Repos.Roles.GetRoleById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Repos.Roles.DeleteRoleById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (r *mutationResolver) SetUserRoles(ctx context.Context, userID int, roleIds []int) (*model.User, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if user == nil || !user.IsAdmin {
+		return nil, errs.NewForbiddenError(ctx, "You do not have permission to assign roles.")
+	}
+
+	// Make sure the requested user exists
+	_, err := r.Repos.Users.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Repos.Roles.SetUserRolesById(ctx, userID, roleIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Query().User(ctx, userID)
+}
+
+func (r *queryResolver) Roles(ctx context.Context) ([]*model.Role, error) {
+	roles, err := r.Repos.Roles.GetAllRoles(ctx)
+	if err != nil {
+		return []*model.Role{}, err
+	}
+	return roles, nil
+}
+
+func (r *queryResolver) Role(ctx context.Context, id int) (*model.Role, error) {
+	role, err := r.Repos.Roles.GetRoleById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (r *queryResolver) PermissionDefinitions(ctx context.Context) ([]*model.PermissionDefinition, error) {
+	definitions, err := r.Repos.Roles.GetAllPermissionDefinitions(ctx)
+	if err != nil {
+		return []*model.PermissionDefinition{}, err
+	}
+	return definitions, nil
+}
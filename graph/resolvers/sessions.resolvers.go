@@ -0,0 +1,92 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/KA-Challenge-Council/Bema/graph/generated"
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
+)
+
+func (r *impersonationResolver) Impersonator(ctx context.Context, obj *model.Impersonation) (*model.User, error) {
+	return r.loaders(ctx).Users.Load(ctx, obj.Impersonator.ID)
+}
+
+func (r *impersonationResolver) User(ctx context.Context, obj *model.Impersonation) (*model.User, error) {
+	return r.loaders(ctx).Users.Load(ctx, obj.User.ID)
+}
+
+func (r *mutationResolver) RevokeSession(ctx context.Context, id int) (bool, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if err := requireSession(ctx, user); err != nil {
+		return false, err
+	}
+
+	if user.IsImpersonated {
+		return false, errs.NewForbiddenError(ctx, "You cannot end the sessions of a user you are impersonating.")
+	}
+
+	err := r.Repos.Sessions.DeleteSessionById(ctx, user.ID, id)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) RevokeAllSessions(ctx context.Context, userID int) (bool, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if user == nil || !user.IsAdmin {
+		return false, errs.NewForbiddenError(ctx, "You do not have permission to end other users' sessions.")
+	}
+
+	err := r.Repos.Sessions.DeleteSessionsByUserId(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *queryResolver) MySessions(ctx context.Context) ([]*model.Session, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	sessions, err := r.Repos.Sessions.GetActiveSessionsByUserId(ctx, user.ID)
+	if err != nil {
+		return []*model.Session{}, err
+	}
+
+	for _, session := range sessions {
+		session.IsCurrent = session.ID == user.SessionID
+	}
+	return sessions, nil
+}
+
+func (r *queryResolver) Impersonations(ctx context.Context, activeOnly *bool, first *int, after *string) (*model.ImpersonationConnection, error) {
+	args, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	user := auth.GetUserFromContext(ctx)
+	if user == nil || !user.IsAdmin {
+		return nil, errs.NewForbiddenError(ctx, "You do not have permission to view impersonations.")
+	}
+
+	page, err := r.Repos.Sessions.GetImpersonationsPage(ctx, activeOnly != nil && *activeOnly, args)
+	if err != nil {
+		return nil, err
+	}
+	return newImpersonationConnection(page, args), nil
+}
+
+// Impersonation returns generated.ImpersonationResolver implementation.
+func (r *Resolver) Impersonation() generated.ImpersonationResolver { return &impersonationResolver{r} }
+
+type impersonationResolver struct{ *Resolver }
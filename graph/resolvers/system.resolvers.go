@@ -0,0 +1,52 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	"github.com/KA-Challenge-Council/Bema/internal/buildinfo"
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
+)
+
+func (r *queryResolver) SystemStatus(ctx context.Context) (*model.SystemStatus, error) {
+	user := auth.GetUserFromContext(ctx)
+	if user == nil || !user.IsAdmin {
+		return nil, errs.NewForbiddenError(ctx, "You do not have permission to view the system status.")
+	}
+
+	schemaVersion, err := r.Repos.System.GetSchemaVersion(ctx)
+	if err != nil {
+		return nil, errs.NewInternalError(ctx, "An unexpected error occurred while reading the schema version.", err)
+	}
+
+	expectedSchemaVersion, err := db.LatestMigrationVersion()
+	if err != nil {
+		return nil, errs.NewInternalError(ctx, "An unexpected error occurred while reading the embedded migrations.", err)
+	}
+
+	stats := r.Repos.System.GetDatabaseStats(ctx)
+
+	return &model.SystemStatus{
+		Version:               buildinfo.GitVersion(),
+		StartedAt:             buildinfo.StartedAt,
+		UptimeSeconds:         int(buildinfo.Uptime().Seconds()),
+		SchemaVersion:         schemaVersion,
+		ExpectedSchemaVersion: expectedSchemaVersion,
+		DatabasePool: &model.DatabasePoolStats{
+			MaxOpenConnections: stats.MaxOpenConnections,
+			OpenConnections:    stats.OpenConnections,
+			InUse:              stats.InUse,
+			Idle:               stats.Idle,
+			WaitCount:          int(stats.WaitCount),
+			WaitDurationMs:     int(stats.WaitDuration.Milliseconds()),
+			MaxIdleClosed:      int(stats.MaxIdleClosed),
+			MaxIdleTimeClosed:  int(stats.MaxIdleTimeClosed),
+			MaxLifetimeClosed:  int(stats.MaxLifetimeClosed),
+		},
+	}, nil
+}
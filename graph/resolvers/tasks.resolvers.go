@@ -10,17 +10,10 @@ import (
 	"github.com/KA-Challenge-Council/Bema/graph/model"
 	"github.com/KA-Challenge-Council/Bema/internal/auth"
 	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
-	"github.com/KA-Challenge-Council/Bema/internal/models"
 )
 
 func (r *mutationResolver) CreateTask(ctx context.Context, input model.CreateTaskInput) (*model.Task, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.EditAllTasks) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to create tasks.")
-	}
-
-	id, err := models.CreateTask(ctx, &input)
+	id, err := r.Repos.Tasks.CreateTask(ctx, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +42,7 @@ func (r *mutationResolver) EditTask(ctx context.Context, id int, input model.Edi
 		input.Title = task.Title
 	}
 
-	err = models.EditTaskById(ctx, id, &input)
+	err = r.Repos.Tasks.EditTaskById(ctx, id, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -58,18 +51,12 @@ func (r *mutationResolver) EditTask(ctx context.Context, id int, input model.Edi
 }
 
 func (r *mutationResolver) DeleteTask(ctx context.Context, id int) (*model.Task, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.DeleteAllTasks) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to delete tasks.")
-	}
-
 	task, err := r.Query().Task(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	err = models.DeleteTaskById(ctx, id)
+	err = r.Repos.Tasks.DeleteTaskById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +67,7 @@ func (r *mutationResolver) DeleteTask(ctx context.Context, id int) (*model.Task,
 func (r *queryResolver) Task(ctx context.Context, id int) (*model.Task, error) {
 	user := auth.GetUserFromContext(ctx)
 
-	task, err := models.GetTaskById(ctx, id)
+	task, err := r.Repos.Tasks.GetTaskById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -93,12 +80,7 @@ func (r *queryResolver) Task(ctx context.Context, id int) (*model.Task, error) {
 }
 
 func (r *queryResolver) Tasks(ctx context.Context) ([]*model.Task, error) {
-	user := auth.GetUserFromContext(ctx)
-	if !auth.HasPermission(user, auth.ViewAllTasks) {
-		return []*model.Task{}, nil
-	}
-
-	tasks, err := models.GetIncompleteTasks(ctx)
+	tasks, err := r.Repos.Tasks.GetIncompleteTasks(ctx)
 	if err != nil {
 		return []*model.Task{}, err
 	}
@@ -106,12 +88,7 @@ func (r *queryResolver) Tasks(ctx context.Context) ([]*model.Task, error) {
 }
 
 func (r *queryResolver) CompletedTasks(ctx context.Context) ([]*model.Task, error) {
-	user := auth.GetUserFromContext(ctx)
-	if !auth.HasPermission(user, auth.ViewAllTasks) {
-		return []*model.Task{}, nil
-	}
-
-	tasks, err := models.GetCompletedTasks(ctx)
+	tasks, err := r.Repos.Tasks.GetCompletedTasks(ctx)
 	if err != nil {
 		return []*model.Task{}, err
 	}
@@ -119,12 +96,7 @@ func (r *queryResolver) CompletedTasks(ctx context.Context) ([]*model.Task, erro
 }
 
 func (r *queryResolver) AvailableTasks(ctx context.Context) ([]*model.Task, error) {
-	user := auth.GetUserFromContext(ctx)
-	if user == nil {
-		return []*model.Task{}, nil
-	}
-
-	tasks, err := models.GetAvailableTasks(ctx)
+	tasks, err := r.Repos.Tasks.GetAvailableTasks(ctx)
 	if err != nil {
 		return []*model.Task{}, err
 	}
@@ -133,11 +105,8 @@ func (r *queryResolver) AvailableTasks(ctx context.Context) ([]*model.Task, erro
 
 func (r *queryResolver) CurrentUserTasks(ctx context.Context) ([]*model.Task, error) {
 	user := auth.GetUserFromContext(ctx)
-	if user == nil {
-		return []*model.Task{}, nil
-	}
 
-	tasks, err := models.GetTasksForUser(ctx, user.ID)
+	tasks, err := r.Repos.Tasks.GetTasksForUser(ctx, user.ID)
 	if err != nil {
 		return []*model.Task{}, err
 	}
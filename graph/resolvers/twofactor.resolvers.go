@@ -0,0 +1,293 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
+)
+
+func (r *mutationResolver) VerifyTwoFactorLogin(ctx context.Context, pendingToken string, code string) (*model.LoginResponse, error) {
+	if auth.GetUserFromContext(ctx) != nil {
+		return nil, errs.NewForbiddenError(ctx, "You're already logged in!")
+	}
+
+	tokenHash := auth.HashSecretToken(pendingToken)
+	userId, err := r.Repos.TwoFactor.GetLoginChallengeUserId(ctx, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if userId == nil {
+		return nil, errs.NewBadRequestError(ctx, "Oops! Your login has expired. Please log in again.")
+	}
+
+	user, err := r.Repos.Users.GetUserById(ctx, *userId)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Username == nil {
+		return nil, errs.NewBadRequestError(ctx, "Oops! Your login has expired. Please log in again.")
+	}
+
+	// The account may have been locked since the password was checked
+	if *user.AccountLocked {
+		if err := r.Repos.TwoFactor.DeleteLoginChallenge(ctx, tokenHash); err != nil {
+			return nil, err
+		}
+		return &model.LoginResponse{
+			Success:    false,
+			IsDisabled: true,
+		}, nil
+	}
+
+	throttledName := strings.ToLower(*user.Username)
+	ip := auth.GetRequestInfoFromContext(ctx).IP
+	lockedUntil, err := r.Repos.LoginThrottles.GetLoginLockedUntil(ctx, throttledName, ip)
+	if err != nil {
+		return nil, err
+	}
+	if lockedUntil != nil {
+		return nil, errs.NewTooManyRequestsError(ctx, "Too many failed login attempts. Please try again in "+minutesUntil(*lockedUntil)+".")
+	}
+
+	tf, err := r.Repos.TwoFactor.GetTwoFactorByUserId(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if tf.Secret == nil {
+		return nil, errs.NewBadRequestError(ctx, "Oops! Your login has expired. Please log in again.")
+	}
+
+	ok, err := r.checkTwoFactorCode(ctx, user.ID, *tf.Secret, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := r.Repos.TwoFactor.FailLoginChallenge(ctx, tokenHash, auth.LoginChallengeMaxFailures); err != nil {
+			return nil, err
+		}
+		return r.failLogin(ctx, throttledName, ip)
+	}
+
+	if err := r.Repos.TwoFactor.DeleteLoginChallenge(ctx, tokenHash); err != nil {
+		return nil, err
+	}
+	if err := r.Repos.LoginThrottles.ClearLoginThrottle(ctx, model.LoginThrottleKindUsername, throttledName); err != nil {
+		return nil, err
+	}
+
+	if err := startSession(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	return &model.LoginResponse{
+		Success:    true,
+		IsDisabled: false,
+	}, nil
+}
+
+func (r *mutationResolver) BeginTwoFactorEnrollment(ctx context.Context) (*model.TwoFactorEnrollment, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if err := requireSession(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if user.IsImpersonated {
+		return nil, errs.NewForbiddenError(ctx, "You cannot change the two-factor authentication of a user you are impersonating.")
+	}
+
+	tf, err := r.Repos.TwoFactor.GetTwoFactorByUserId(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if tf.Secret != nil {
+		return nil, errs.NewBadRequestError(ctx, "Oops! Two-factor authentication is already enabled.")
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		return nil, errs.NewInternalError(ctx, "An unexpected error occurred while setting up two-factor authentication", err)
+	}
+
+	if err := r.Repos.TwoFactor.SetPendingTOTPSecret(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(secret, user.Username),
+	}, nil
+}
+
+func (r *mutationResolver) ConfirmTwoFactorEnrollment(ctx context.Context, code string) ([]string, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if err := requireSession(ctx, user); err != nil {
+		return []string{}, err
+	}
+
+	if user.IsImpersonated {
+		return []string{}, errs.NewForbiddenError(ctx, "You cannot change the two-factor authentication of a user you are impersonating.")
+	}
+
+	tf, err := r.Repos.TwoFactor.GetTwoFactorByUserId(ctx, user.ID)
+	if err != nil {
+		return []string{}, err
+	}
+	if tf.Secret != nil {
+		return []string{}, errs.NewBadRequestError(ctx, "Oops! Two-factor authentication is already enabled.")
+	}
+	if tf.PendingSecret == nil {
+		return []string{}, errs.NewBadRequestError(ctx, "Oops! Start setting up two-factor authentication before confirming it.")
+	}
+
+	step, ok := auth.ValidateTOTP(*tf.PendingSecret, code, time.Now())
+	if !ok {
+		return []string{}, errs.NewBadRequestError(ctx, "Oops! That code is not correct.")
+	}
+
+	codes, hashes, err := newRecoveryCodes(ctx)
+	if err != nil {
+		return []string{}, err
+	}
+
+	if err := r.Repos.TwoFactor.EnableTwoFactor(ctx, user.ID, step, hashes); err != nil {
+		return []string{}, err
+	}
+
+	return codes, nil
+}
+
+func (r *mutationResolver) RegenerateRecoveryCodes(ctx context.Context, code string) ([]string, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if err := requireSession(ctx, user); err != nil {
+		return []string{}, err
+	}
+
+	if user.IsImpersonated {
+		return []string{}, errs.NewForbiddenError(ctx, "You cannot change the two-factor authentication of a user you are impersonating.")
+	}
+
+	tf, err := r.Repos.TwoFactor.GetTwoFactorByUserId(ctx, user.ID)
+	if err != nil {
+		return []string{}, err
+	}
+	if tf.Secret == nil {
+		return []string{}, errs.NewBadRequestError(ctx, "Oops! Two-factor authentication is not enabled.")
+	}
+
+	if err := r.requireTwoFactorCode(ctx, user, *tf.Secret, code); err != nil {
+		return []string{}, err
+	}
+
+	codes, hashes, err := newRecoveryCodes(ctx)
+	if err != nil {
+		return []string{}, err
+	}
+
+	if err := r.Repos.TwoFactor.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		return []string{}, err
+	}
+
+	return codes, nil
+}
+
+func (r *mutationResolver) DisableTwoFactor(ctx context.Context, code string) (bool, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if err := requireSession(ctx, user); err != nil {
+		return false, err
+	}
+
+	if user.IsImpersonated {
+		return false, errs.NewForbiddenError(ctx, "You cannot change the two-factor authentication of a user you are impersonating.")
+	}
+
+	tf, err := r.Repos.TwoFactor.GetTwoFactorByUserId(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	if tf.Secret == nil {
+		return false, errs.NewBadRequestError(ctx, "Oops! Two-factor authentication is not enabled.")
+	}
+
+	required, err := r.Repos.TwoFactor.GetTwoFactorRequiredPermissions(ctx)
+	if err != nil {
+		return false, err
+	}
+	if auth.TwoFactorRequired(user, auth.ToPermissions(required)) {
+		return false, errs.NewBadRequestError(ctx, "Oops! Two-factor authentication is required for your account and cannot be turned off.")
+	}
+
+	if err := r.requireTwoFactorCode(ctx, user, *tf.Secret, code); err != nil {
+		return false, err
+	}
+
+	if err := r.Repos.TwoFactor.DisableTwoFactor(ctx, user.ID); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) ResetTwoFactor(ctx context.Context, userID int) (bool, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if user == nil || !user.IsAdmin {
+		return false, errs.NewForbiddenError(ctx, "You do not have permission to reset two-factor authentication.")
+	}
+
+	if err := r.Repos.TwoFactor.DisableTwoFactor(ctx, userID); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *mutationResolver) SetTwoFactorRequiredPermissions(ctx context.Context, permissions []string) ([]string, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if user == nil || !user.IsAdmin {
+		return []string{}, errs.NewForbiddenError(ctx, "You do not have permission to change the two-factor authentication policy.")
+	}
+
+	if err := r.Repos.TwoFactor.SetTwoFactorRequiredPermissions(ctx, permissions); err != nil {
+		return []string{}, err
+	}
+
+	return r.Repos.TwoFactor.GetTwoFactorRequiredPermissions(ctx)
+}
+
+func (r *queryResolver) TwoFactorStatus(ctx context.Context) (*model.TwoFactorStatus, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	tf, err := r.Repos.TwoFactor.GetTwoFactorByUserId(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorStatus{
+		Enabled:                tf.Secret != nil,
+		EnabledAt:              tf.EnabledAt,
+		SetupRequired:          user.TwoFactorSetupRequired,
+		RecoveryCodesRemaining: tf.RecoveryCodesRemaining,
+	}, nil
+}
+
+func (r *queryResolver) TwoFactorRequiredPermissions(ctx context.Context) ([]string, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if user == nil || !user.IsAdmin {
+		return []string{}, errs.NewForbiddenError(ctx, "You do not have permission to view the two-factor authentication policy.")
+	}
+
+	return r.Repos.TwoFactor.GetTwoFactorRequiredPermissions(ctx)
+}
@@ -5,12 +5,14 @@ package resolvers
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/KA-Challenge-Council/Bema/graph/generated"
 	"github.com/KA-Challenge-Council/Bema/graph/model"
 	"github.com/KA-Challenge-Council/Bema/internal/auth"
 	errs "github.com/KA-Challenge-Council/Bema/internal/errors"
-	"github.com/KA-Challenge-Council/Bema/internal/models"
 )
 
 func (r *mutationResolver) Login(ctx context.Context, username string, password string) (*model.LoginResponse, error) {
@@ -19,21 +21,48 @@ func (r *mutationResolver) Login(ctx context.Context, username string, password
 		return nil, errs.NewForbiddenError(ctx, "You're already logged in!")
 	}
 
+	// Refuse attempts while the username or IP address is locked out, whether or not the account exists
+	throttledName := strings.ToLower(username)
+	ip := auth.GetRequestInfoFromContext(ctx).IP
+	lockedUntil, err := r.Repos.LoginThrottles.GetLoginLockedUntil(ctx, throttledName, ip)
+	if err != nil {
+		return nil, err
+	}
+	if lockedUntil != nil {
+		return nil, errs.NewTooManyRequestsError(ctx, "Too many failed login attempts. Please try again in "+minutesUntil(*lockedUntil)+".")
+	}
+
 	// Look up the user by username
-	user, err := models.GetUserByUsername(ctx, username)
+	user, err := r.Repos.Users.GetUserByUsername(ctx, username)
 	if err != nil {
 		return nil, err
 	}
 
-	// If the user does not exist, failed login
+	// If the user does not exist, failed login. The password is still checked so that the response
+	// takes as long as it would for a wrong password.
 	if user == nil {
-		return &model.LoginResponse{
-			Success:    false,
-			IsDisabled: false,
-		}, nil
+		auth.RejectLogin(password)
+		return r.failLogin(ctx, throttledName, ip)
+	}
+
+	// Retrieve the user's stored password hash
+	hash, err := r.Repos.Users.GetUserPasswordHashByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accounts without a password fail like a wrong password would
+	if hash == nil {
+		auth.RejectLogin(password)
+		return r.failLogin(ctx, throttledName, ip)
 	}
 
-	// If the user's account is disabled, failed login
+	// Validate the user's provided password
+	if !auth.ValidateUserLogin(password, *hash) {
+		return r.failLogin(ctx, throttledName, ip)
+	}
+
+	// Only tell someone who knows the password that the account is disabled
 	if *user.AccountLocked {
 		return &model.LoginResponse{
 			Success:    false,
@@ -41,29 +70,44 @@ func (r *mutationResolver) Login(ctx context.Context, username string, password
 		}, nil
 	}
 
-	// Retrieve the user's stored password hash
-	hash, err := models.GetUserPasswordHashByUsername(ctx, username)
+	// Users with two-factor authentication get a pending token to exchange for a session once they
+	// enter a code. Failed logins are only cleared after that, so codes cannot be guessed for free.
+	tf, err := r.Repos.TwoFactor.GetTwoFactorByUserId(ctx, user.ID)
 	if err != nil {
 		return nil, err
 	}
+	if tf.Secret != nil {
+		pendingToken, err := auth.NewSecretToken()
+		if err != nil {
+			return nil, errs.NewInternalError(ctx, "An unexpected error occurred while logging in", err)
+		}
 
-	// Validate the user's provided password
-	isValid := auth.ValidateUserLogin(password, *hash)
-
-	// The user provided a correct username / password, so log them in
-	if isValid {
-		// Generate an auth token
-		token := auth.CreateAuthToken(ctx, user.ID, nil, true)
+		err = r.Repos.TwoFactor.CreateLoginChallenge(ctx, user.ID, auth.HashSecretToken(pendingToken), time.Now().Add(auth.LoginChallengeLifetime))
+		if err != nil {
+			return nil, err
+		}
 
 		return &model.LoginResponse{
-			Success:    true,
-			IsDisabled: false,
-			Token:      token,
+			Success:           false,
+			IsDisabled:        false,
+			TwoFactorRequired: true,
+			PendingToken:      &pendingToken,
 		}, nil
 	}
 
+	// The user provided a correct username / password, so log them in
+	err = r.Repos.LoginThrottles.ClearLoginThrottle(ctx, model.LoginThrottleKindUsername, throttledName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate an auth token and hand it to the browser in the session cookie
+	if err := startSession(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
 	return &model.LoginResponse{
-		Success:    false,
+		Success:    true,
 		IsDisabled: false,
 	}, nil
 }
@@ -71,17 +115,24 @@ func (r *mutationResolver) Login(ctx context.Context, username string, password
 func (r *mutationResolver) Logout(ctx context.Context) (bool, error) {
 	user := auth.GetUserFromContext(ctx)
 	if user == nil {
+		auth.ClearSessionCookie(ctx)
 		return false, nil
 	}
 
+	if err := requireSession(ctx, user); err != nil {
+		return false, err
+	}
+
 	if user.IsImpersonated {
 		return false, errs.NewForbiddenError(ctx, "You cannot log out an impersonated user. Use the \"Return to your account\" feature instead.")
 	}
 
-	err := auth.RemoveAuthTokensForUser(ctx, user.ID)
+	// End only this session, so the user stays logged in on their other devices
+	err := r.Repos.Sessions.DeleteSessionById(ctx, user.ID, user.SessionID)
 	if err != nil {
-		return false, errs.NewInternalError(ctx, "An unexpected error occurred while logging out a user", err)
+		return false, err
 	}
+	auth.ClearSessionCookie(ctx)
 
 	return true, nil
 }
@@ -89,78 +140,76 @@ func (r *mutationResolver) Logout(ctx context.Context) (bool, error) {
 func (r *mutationResolver) ChangePassword(ctx context.Context, id int, password string) (bool, error) {
 	user := auth.GetUserFromContext(ctx)
 
-	if user.ID != id && !auth.HasPermission(user, auth.ChangeUserPasswords) {
+	if err := requireSession(ctx, user); err != nil {
+		return false, err
+	}
+
+	if user == nil || (user.ID != id && !auth.HasPermission(user, auth.ChangeUserPasswords)) {
 		return false, errs.NewForbiddenError(ctx, "You do not have permission to change user passwords.")
 	}
 
-	requestedUser, err := models.GetUserById(ctx, id)
-	if err != nil {
-		return false, nil
+	// A new password would outlast the impersonation
+	if user.IsImpersonated {
+		return false, errs.NewForbiddenError(ctx, "You cannot change passwords while impersonating a user.")
+	}
+
+	// Don't allow users to change passwords of users with higher permissions than them
+	if user.ID != id {
+		if err := r.checkPermissionSuperset(ctx, user, id, "You do not have permission to change this user's password."); err != nil {
+			return false, err
+		}
 	}
 
-	permissions, err := models.GetUserPermissionsById(ctx, id)
+	err := r.Repos.Users.ChangeUserPasswordById(ctx, id, password)
 	if err != nil {
-		return false, nil
+		return false, err
 	}
 
-	// Don't allow users to change passwords of users with higher permissions than them
-	var hasPermission bool
-	if user.IsAdmin || user.ID == id {
-		hasPermission = true
-	} else if (permissions.AddEntries && !user.Permissions.AddEntries) ||
-		(permissions.AddUsers && !user.Permissions.AddUsers) ||
-		(permissions.AssignEntryGroups && !user.Permissions.AssignEntryGroups) ||
-		(permissions.AssignEvaluatorGroups && !user.Permissions.AssignEvaluatorGroups) ||
-		(permissions.AssumeUserIdentities && !user.Permissions.AssumeUserIdentities) ||
-		(permissions.ChangeUserPasswords && !user.Permissions.ChangeUserPasswords) ||
-		(permissions.DeleteAllEvaluations && !user.Permissions.DeleteAllEvaluations) ||
-		(permissions.DeleteAllTasks && !user.Permissions.DeleteAllTasks) ||
-		(permissions.DeleteContests && !user.Permissions.DeleteContests) ||
-		(permissions.DeleteEntries && !user.Permissions.DeleteEntries) ||
-		(permissions.DeleteErrors && !user.Permissions.DeleteErrors) ||
-		(permissions.DeleteKbContent && !user.Permissions.DeleteKbContent) ||
-		(permissions.EditAllEvaluations && !user.Permissions.EditAllEvaluations) ||
-		(permissions.EditAllTasks && !user.Permissions.EditAllTasks) ||
-		(permissions.EditContests && !user.Permissions.EditContests) ||
-		(permissions.EditEntries && !user.Permissions.EditEntries) ||
-		(permissions.EditKbContent && !user.Permissions.EditKbContent) ||
-		(permissions.EditUserProfiles && !user.Permissions.EditUserProfiles) ||
-		(permissions.JudgeEntries && !user.Permissions.JudgeEntries) ||
-		(permissions.ManageAnnouncements && !user.Permissions.ManageAnnouncements) ||
-		(permissions.ManageJudgingCriteria && !user.Permissions.ManageJudgingCriteria) ||
-		(permissions.ManageJudgingGroups && !user.Permissions.ManageJudgingGroups) ||
-		(permissions.ManageWinners && !user.Permissions.ManageWinners) ||
-		(permissions.PublishKbContent && !user.Permissions.PublishKbContent) ||
-		(permissions.ViewAdminStats && !user.Permissions.ViewAdminStats) ||
-		(permissions.ViewAllEvaluations && !user.Permissions.ViewAllEvaluations) ||
-		(permissions.ViewAllTasks && !user.Permissions.ViewAllTasks) ||
-		(permissions.ViewAllUsers && !user.Permissions.ViewAllUsers) ||
-		(permissions.ViewErrors && !user.Permissions.ViewErrors) ||
-		(permissions.ViewJudgingSettings && !user.Permissions.ViewJudgingSettings) ||
-		(*requestedUser.IsAdmin && !user.IsAdmin) {
-		hasPermission = false
-	}
-
-	if !hasPermission {
-		return false, errs.NewForbiddenError(ctx, "You do not have permission to change this user's password.")
-	}
-
-	err = models.ChangeUserPasswordById(ctx, id, password)
+	return true, nil
+}
+
+func (r *mutationResolver) RequestPasswordReset(ctx context.Context, email string) (bool, error) {
+	users, err := r.Repos.Users.GetActiveUsersByEmail(ctx, strings.TrimSpace(email))
 	if err != nil {
 		return false, err
 	}
 
+	for _, user := range users {
+		if err := r.sendPasswordReset(ctx, user); err != nil {
+			return false, err
+		}
+	}
+
 	return true, nil
 }
 
-func (r *mutationResolver) CreateUser(ctx context.Context, input model.CreateUserInput) (*model.User, error) {
-	user := auth.GetUserFromContext(ctx)
+func (r *mutationResolver) ResetPassword(ctx context.Context, token string, newPassword string) (bool, error) {
+	if newPassword == "" {
+		return false, errs.NewBadRequestError(ctx, "Oops! Your new password cannot be empty.")
+	}
+
+	id, err := r.Repos.PasswordResets.UsePasswordReset(ctx, auth.HashSecretToken(token), newPassword)
+	if err != nil {
+		return false, err
+	}
 
-	if !auth.HasPermission(user, auth.AddUsers) {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to create users.")
+	// The user has proven they own the account, so let them log in straight away
+	user, err := r.Repos.Users.GetUserById(ctx, *id)
+	if err != nil {
+		return false, err
+	}
+	if user.Username != nil {
+		err = r.Repos.LoginThrottles.ClearLoginThrottle(ctx, model.LoginThrottleKindUsername, strings.ToLower(*user.Username))
+		if err != nil {
+			return false, err
+		}
 	}
 
-	id, err := models.CreateUser(ctx, &input)
+	return true, nil
+}
+
+func (r *mutationResolver) CreateUser(ctx context.Context, input model.CreateUserInput) (*model.User, error) {
+	id, err := r.Repos.Users.CreateUser(ctx, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -171,17 +220,32 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input model.CreateUse
 func (r *mutationResolver) EditUserProfile(ctx context.Context, id int, input model.EditUserProfileInput) (*model.User, error) {
 	user := auth.GetUserFromContext(ctx)
 
+	// The email address receives password resets, so a token changing it could take over the account
+	if err := requireSession(ctx, user); err != nil {
+		return nil, err
+	}
+
 	if user == nil || (!auth.HasPermission(user, auth.EditUserProfiles) && id != user.ID) {
 		return nil, errs.NewForbiddenError(ctx, "You do not have permission to edit user profiles")
 	}
 
-	u, err := r.Query().User(ctx, id)
+	// Don't allow users to edit the profiles of users with higher permissions than them
+	if id != user.ID {
+		if err := r.checkPermissionSuperset(ctx, user, id, "You do not have permission to edit this user's profile."); err != nil {
+			return nil, err
+		}
+	}
+
+	u, err := r.Repos.Users.GetUserById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	if input.IsAdmin != *u.IsAdmin && !user.IsAdmin {
+		return nil, errs.NewForbiddenError(ctx, "You do not have permission to change whether a user is an admin.")
+	}
+
 	if !user.IsAdmin {
-		input.IsAdmin = *u.IsAdmin
 		input.AccountLocked = *u.AccountLocked
 	}
 
@@ -192,7 +256,7 @@ func (r *mutationResolver) EditUserProfile(ctx context.Context, id int, input mo
 		input.TermEnd = u.TermEnd
 	}
 
-	err = models.EditUserById(ctx, id, &input)
+	err = r.Repos.Users.EditUserById(ctx, id, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -200,34 +264,27 @@ func (r *mutationResolver) EditUserProfile(ctx context.Context, id int, input mo
 	return r.Query().User(ctx, id)
 }
 
-func (r *mutationResolver) EditUserPermissions(ctx context.Context, id int, input model.EditUserPermissionsInput) (*model.Permissions, error) {
+func (r *mutationResolver) EditUserPermissions(ctx context.Context, id int, permissions []string) (*model.User, error) {
 	user := auth.GetUserFromContext(ctx)
 
-	if user == nil || !user.IsAdmin {
-		return nil, errs.NewForbiddenError(ctx, "You do not have permission to edit user permissions.")
+	// Users can only edit the permissions of users with no more access than them, and only grant permissions they have
+	if err := r.checkPermissionSuperset(ctx, user, id, "You do not have permission to edit this user's permissions."); err != nil {
+		return nil, err
 	}
-
-	err := models.EditUserPermissionsById(ctx, id, &input)
-	if err != nil {
+	if err := checkCanGrant(ctx, user, permissions); err != nil {
 		return nil, err
 	}
 
-	permissions, err := models.GetUserPermissionsById(ctx, id)
+	err := r.Repos.Users.EditUserPermissionsById(ctx, id, permissions)
 	if err != nil {
 		return nil, err
 	}
 
-	return permissions, nil
+	return r.Query().User(ctx, id)
 }
 
 func (r *mutationResolver) AssignUserToJudgingGroup(ctx context.Context, userID int, groupID *int) (bool, error) {
-	user := auth.GetUserFromContext(ctx)
-
-	if !auth.HasPermission(user, auth.AssignEvaluatorGroups) {
-		return false, errs.NewForbiddenError(ctx, "You do not have permission to assign evaluators to groups.")
-	}
-
-	err := models.AssignUserToJudgingGroup(ctx, userID, groupID)
+	err := r.Repos.Users.AssignUserToJudgingGroup(ctx, userID, groupID)
 	if err != nil {
 		return false, err
 	}
@@ -235,7 +292,7 @@ func (r *mutationResolver) AssignUserToJudgingGroup(ctx context.Context, userID
 	return true, nil
 }
 
-func (r *mutationResolver) ImpersonateUser(ctx context.Context, id int) (*model.ImpersonateUserResponse, error) {
+func (r *mutationResolver) ImpersonateUser(ctx context.Context, id int, reason string, readOnly *bool) (*model.ImpersonateUserResponse, error) {
 	user := auth.GetUserFromContext(ctx)
 
 	if !auth.HasPermission(user, auth.AssumeUserIdentities) {
@@ -245,6 +302,13 @@ func (r *mutationResolver) ImpersonateUser(ctx context.Context, id int) (*model.
 		}, errs.NewForbiddenError(ctx, "You do not have permission to impersonate users.")
 	}
 
+	if err := requireSession(ctx, user); err != nil {
+		return &model.ImpersonateUserResponse{
+			Success: false,
+			Token:   nil,
+		}, err
+	}
+
 	// Don't allow impersonation chains
 	if user.IsImpersonated {
 		return &model.ImpersonateUserResponse{
@@ -253,73 +317,35 @@ func (r *mutationResolver) ImpersonateUser(ctx context.Context, id int) (*model.
 		}, errs.NewForbiddenError(ctx, "You are already impersonating a user.")
 	}
 
-	// Make sure the requested user exists
-	requestedUser, err := models.GetUserById(ctx, id)
-	if err != nil {
+	// If the requested user has any permissions that the user does not have, don't let them impersonate
+	if err := r.checkPermissionSuperset(ctx, user, id, "You do not have permission to impersonate this user."); err != nil {
 		return &model.ImpersonateUserResponse{
 			Success: false,
 			Token:   nil,
 		}, err
 	}
 
-	permissions, err := models.GetUserPermissionsById(ctx, id)
-	if err != nil {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
 		return &model.ImpersonateUserResponse{
 			Success: false,
 			Token:   nil,
-		}, err
+		}, errs.NewBadRequestError(ctx, "Oops! Please give a reason for impersonating this user.")
 	}
 
-	// If the requested user has any permissions that the user does not have, don't let them impersonate
-	var hasPermission bool
-	if user.IsAdmin {
-		hasPermission = true
-	} else if (permissions.AddEntries && !user.Permissions.AddEntries) ||
-		(permissions.AddUsers && !user.Permissions.AddUsers) ||
-		(permissions.AssignEntryGroups && !user.Permissions.AssignEntryGroups) ||
-		(permissions.AssignEvaluatorGroups && !user.Permissions.AssignEvaluatorGroups) ||
-		(permissions.AssumeUserIdentities && !user.Permissions.AssumeUserIdentities) ||
-		(permissions.ChangeUserPasswords && !user.Permissions.ChangeUserPasswords) ||
-		(permissions.DeleteAllEvaluations && !user.Permissions.DeleteAllEvaluations) ||
-		(permissions.DeleteAllTasks && !user.Permissions.DeleteAllTasks) ||
-		(permissions.DeleteContests && !user.Permissions.DeleteContests) ||
-		(permissions.DeleteEntries && !user.Permissions.DeleteEntries) ||
-		(permissions.DeleteErrors && !user.Permissions.DeleteErrors) ||
-		(permissions.DeleteKbContent && !user.Permissions.DeleteKbContent) ||
-		(permissions.EditAllEvaluations && !user.Permissions.EditAllEvaluations) ||
-		(permissions.EditAllTasks && !user.Permissions.EditAllTasks) ||
-		(permissions.EditContests && !user.Permissions.EditContests) ||
-		(permissions.EditEntries && !user.Permissions.EditEntries) ||
-		(permissions.EditKbContent && !user.Permissions.EditKbContent) ||
-		(permissions.EditUserProfiles && !user.Permissions.EditUserProfiles) ||
-		(permissions.JudgeEntries && !user.Permissions.JudgeEntries) ||
-		(permissions.ManageAnnouncements && !user.Permissions.ManageAnnouncements) ||
-		(permissions.ManageJudgingCriteria && !user.Permissions.ManageJudgingCriteria) ||
-		(permissions.ManageJudgingGroups && !user.Permissions.ManageJudgingGroups) ||
-		(permissions.ManageWinners && !user.Permissions.ManageWinners) ||
-		(permissions.PublishKbContent && !user.Permissions.PublishKbContent) ||
-		(permissions.ViewAdminStats && !user.Permissions.ViewAdminStats) ||
-		(permissions.ViewAllEvaluations && !user.Permissions.ViewAllEvaluations) ||
-		(permissions.ViewAllTasks && !user.Permissions.ViewAllTasks) ||
-		(permissions.ViewAllUsers && !user.Permissions.ViewAllUsers) ||
-		(permissions.ViewErrors && !user.Permissions.ViewErrors) ||
-		(permissions.ViewJudgingSettings && !user.Permissions.ViewJudgingSettings) ||
-		(*requestedUser.IsAdmin && !user.IsAdmin) {
-		hasPermission = false
-	}
-
-	if !hasPermission {
+	// Create a short-lived auth token for the requested user
+	expiresAt := time.Now().Add(auth.ImpersonationLifetime)
+	token := auth.CreateImpersonationToken(ctx, id, user.ID, reason, readOnly != nil && *readOnly)
+	if token == nil {
 		return &model.ImpersonateUserResponse{
 			Success: false,
 			Token:   nil,
-		}, errs.NewForbiddenError(ctx, "You do not have permission to impersonate this user.")
+		}, errs.NewInternalError(ctx, "An unexpected error occurred while impersonating the user", errors.New("could not create an impersonation session"))
 	}
-
-	// Create an auth token for the requested user
-	token := auth.CreateAuthToken(ctx, id, &user.ID, false)
+	auth.SetSessionCookie(ctx, token, auth.ImpersonationLifetime)
 	return &model.ImpersonateUserResponse{
-		Success: true,
-		Token:   token,
+		Success:   true,
+		ExpiresAt: &expiresAt,
 	}, nil
 }
 
@@ -333,8 +359,14 @@ func (r *mutationResolver) ReturnFromImpersonation(ctx context.Context) (*model.
 		}, errs.NewForbiddenError(ctx, "You are not currently impersonating anyone.")
 	}
 
-	// Remove the token of the impersonated user
-	err := auth.RemoveAuthTokensForUser(ctx, user.ID)
+	// Remove the impersonation session, leaving the impersonated user's own sessions alone
+	if err := r.Repos.Sessions.EndImpersonation(ctx, user.SessionID); err != nil {
+		return &model.ImpersonateUserResponse{
+			Success: false,
+			Token:   nil,
+		}, err
+	}
+	err := r.Repos.Sessions.DeleteSessionById(ctx, user.ID, user.SessionID)
 	if err != nil {
 		return &model.ImpersonateUserResponse{
 			Success: false,
@@ -343,13 +375,32 @@ func (r *mutationResolver) ReturnFromImpersonation(ctx context.Context) (*model.
 	}
 
 	// Create and return a new token for the original user
-	token := auth.CreateAuthToken(ctx, *user.OriginID, nil, true)
+	if err := startSession(ctx, *user.OriginID); err != nil {
+		return &model.ImpersonateUserResponse{
+			Success: false,
+			Token:   nil,
+		}, err
+	}
 	return &model.ImpersonateUserResponse{
 		Success: true,
-		Token:   token,
 	}, nil
 }
 
+func (r *mutationResolver) ClearLoginLockout(ctx context.Context, id int) (bool, error) {
+	user := auth.GetUserFromContext(ctx)
+
+	if user == nil || !user.IsAdmin {
+		return false, errs.NewForbiddenError(ctx, "You do not have permission to clear login lockouts.")
+	}
+
+	err := r.Repos.LoginThrottles.DeleteLoginLockoutById(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 func (r *queryResolver) CurrentUser(ctx context.Context) (*model.FullUserProfile, error) {
 	user := auth.GetUserFromContext(ctx)
 
@@ -368,35 +419,41 @@ func (r *queryResolver) CurrentUser(ctx context.Context) (*model.FullUserProfile
 		}
 
 		return &model.FullUserProfile{
-			IsAdmin:        *userData.IsAdmin,
+			IsAdmin:        user.IsAdmin,
 			IsImpersonated: user.IsImpersonated,
+			IsReadOnly:     user.IsReadOnly,
 			LoggedIn:       true,
 			OriginID:       user.OriginID,
 			User:           userData,
+
+			TwoFactorSetupRequired: user.TwoFactorSetupRequired,
 		}, nil
 	}
 }
 
 func (r *queryResolver) Users(ctx context.Context) ([]*model.User, error) {
-	user := auth.GetUserFromContext(ctx)
-	if user == nil {
-		return []*model.User{}, nil
-	}
-
-	users, err := models.GetAllActiveUsers(ctx)
+	users, err := r.Repos.Users.GetAllActiveUsers(ctx)
 	if err != nil {
 		return []*model.User{}, err
 	}
 	return users, nil
 }
 
-func (r *queryResolver) InactiveUsers(ctx context.Context) ([]*model.User, error) {
-	user := auth.GetUserFromContext(ctx)
-	if !auth.HasPermission(user, auth.ViewAllUsers) {
-		return []*model.User{}, nil
+func (r *queryResolver) UsersConnection(ctx context.Context, first *int, after *string) (*model.UserConnection, error) {
+	args, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
 	}
 
-	users, err := models.GetAllInactiveUsers(ctx)
+	page, err := r.Repos.Users.GetActiveUsersPage(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return newUserConnection(page, args), nil
+}
+
+func (r *queryResolver) InactiveUsers(ctx context.Context) ([]*model.User, error) {
+	users, err := r.Repos.Users.GetAllInactiveUsers(ctx)
 	if err != nil {
 		return []*model.User{}, err
 	}
@@ -409,13 +466,26 @@ func (r *queryResolver) User(ctx context.Context, id int) (*model.User, error) {
 		return nil, nil
 	}
 
-	u, err := models.GetUserById(ctx, id)
+	u, err := r.Repos.Users.GetUserById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	return u, nil
 }
 
+func (r *queryResolver) LoginLockouts(ctx context.Context) ([]*model.LoginLockout, error) {
+	user := auth.GetUserFromContext(ctx)
+	if user == nil || !user.IsAdmin {
+		return []*model.LoginLockout{}, errs.NewForbiddenError(ctx, "You do not have permission to view login lockouts.")
+	}
+
+	lockouts, err := r.Repos.LoginThrottles.GetActiveLoginLockouts(ctx)
+	if err != nil {
+		return []*model.LoginLockout{}, err
+	}
+	return lockouts, nil
+}
+
 func (r *userResolver) Name(ctx context.Context, obj *model.User) (*string, error) {
 	user := auth.GetUserFromContext(ctx)
 	if auth.HasPermission(user, auth.ViewAllUsers) || obj.ID == user.ID {
@@ -446,13 +516,33 @@ func (r *userResolver) AccountLocked(ctx context.Context, obj *model.User) (*boo
 func (r *userResolver) Permissions(ctx context.Context, obj *model.User) (*model.Permissions, error) {
 	user := auth.GetUserFromContext(ctx)
 	if auth.HasPermission(user, auth.ViewAllUsers) || obj.ID == user.ID {
-		permissions, err := models.GetUserPermissionsById(ctx, obj.ID)
+		permissions, err := r.Repos.Users.GetUserPermissionsById(ctx, obj.ID)
 		return permissions, err
 	}
 
 	return nil, nil
 }
 
+func (r *userResolver) PermissionKeys(ctx context.Context, obj *model.User) ([]string, error) {
+	user := auth.GetUserFromContext(ctx)
+	if auth.HasPermission(user, auth.ViewAllUsers) || (user != nil && obj.ID == user.ID) {
+		keys, err := r.Repos.Users.GetUserPermissionKeysById(ctx, obj.ID)
+		return keys, err
+	}
+
+	return nil, nil
+}
+
+func (r *userResolver) Roles(ctx context.Context, obj *model.User) ([]*model.Role, error) {
+	user := auth.GetUserFromContext(ctx)
+	if auth.HasPermission(user, auth.ViewAllUsers) || (user != nil && obj.ID == user.ID) {
+		roles, err := r.Repos.Roles.GetRolesByUserId(ctx, obj.ID)
+		return roles, err
+	}
+
+	return nil, nil
+}
+
 func (r *userResolver) IsAdmin(ctx context.Context, obj *model.User) (*bool, error) {
 	user := auth.GetUserFromContext(ctx)
 	if auth.HasPermission(user, auth.ViewAllUsers) || obj.ID == user.ID {
@@ -471,6 +561,15 @@ func (r *userResolver) LastLogin(ctx context.Context, obj *model.User) (*string,
 	return nil, nil
 }
 
+func (r *userResolver) LastLoginAt(ctx context.Context, obj *model.User) (*time.Time, error) {
+	user := auth.GetUserFromContext(ctx)
+	if auth.HasPermission(user, auth.ViewAllUsers) || obj.ID == user.ID {
+		return obj.LastLoginAt, nil
+	}
+
+	return nil, nil
+}
+
 func (r *userResolver) NotificationsEnabled(ctx context.Context, obj *model.User) (*bool, error) {
 	user := auth.GetUserFromContext(ctx)
 	if auth.HasPermission(user, auth.ViewAllUsers) || obj.ID == user.ID {
@@ -483,7 +582,7 @@ func (r *userResolver) NotificationsEnabled(ctx context.Context, obj *model.User
 func (r *userResolver) AssignedGroup(ctx context.Context, obj *model.User) (*model.JudgingGroup, error) {
 	user := auth.GetUserFromContext(ctx)
 	if auth.HasPermission(user, auth.ViewJudgingSettings) || obj.ID == user.ID {
-		groupId, err := models.GetUserGroupById(ctx, obj.ID)
+		groupId, err := r.Repos.Users.GetUserGroupById(ctx, obj.ID)
 		if err != nil {
 			return nil, err
 		}
@@ -492,7 +591,7 @@ func (r *userResolver) AssignedGroup(ctx context.Context, obj *model.User) (*mod
 			return nil, err
 		}
 
-		group, err := models.GetJudgingGroupById(ctx, *groupId)
+		group, err := r.Repos.Judging.GetJudgingGroupById(ctx, *groupId)
 		if err != nil {
 			return nil, err
 		}
@@ -510,7 +609,7 @@ func (r *userResolver) TotalEvaluations(ctx context.Context, obj *model.User) (*
 		return nil, nil
 	}
 
-	count, err := models.GetUserTotalEvaluations(ctx, obj.ID)
+	count, err := r.Repos.Evaluations.GetUserTotalEvaluations(ctx, obj.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -524,7 +623,7 @@ func (r *userResolver) TotalContestsJudged(ctx context.Context, obj *model.User)
 		return nil, nil
 	}
 
-	count, err := models.GetUserTotalContestsJudged(ctx, obj.ID)
+	count, err := r.Repos.Evaluations.GetUserTotalContestsJudged(ctx, obj.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/lib/pq"
+)
+
+// Starts every API token, so leaked tokens are easy to recognise
+const APITokenPrefix = "bema_"
+
+// Returns the token from an "Authorization: Bearer" header, or an empty string if there is none
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(header[7:])
+}
+
+// Looks up the user an API token belongs to, returning nil if the token is not valid. The user
+// only gets the permissions the token is scoped to that they still hold, and never admin rights.
+func userFromAPIToken(ctx context.Context, token string) *User {
+	user := newUserModel()
+	var tokenId int
+	var lastUsed *time.Time
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var permissionKeys, scopeKeys, requiredKeys pq.StringArray
+	var twoFactorEnabled bool
+	row := db.DB.QueryRowContext(ctx, "SELECT e.evaluator_id, e.evaluator_kaid, e.evaluator_name, e.nickname, e.username, e.is_admin, ARRAY(SELECT evaluator_permission_keys(e.evaluator_id)), ARRAY(SELECT permission_key FROM api_token_permission p WHERE p.api_token_id = t.api_token_id), e.totp_enabled_tstz IS NOT NULL, ARRAY(SELECT permission_key FROM two_factor_required_permission), t.api_token_id, t.last_used_tstz FROM api_token t INNER JOIN evaluator e ON t.evaluator_id = e.evaluator_id WHERE t.token_hash = $1 AND (t.expires_tstz IS NULL OR t.expires_tstz > $2) AND e.account_locked = false;", HashSecretToken(token), time.Now().UTC())
+	if err := row.Scan(&user.ID, &user.Kaid, &user.Name, &user.Nickname, &user.Username, &user.IsAdmin, &permissionKeys, &scopeKeys, &twoFactorEnabled, &requiredKeys, &tokenId, &lastUsed); err != nil {
+		return nil
+	}
+
+	for _, key := range permissionKeys {
+		user.Permissions[Permission(key)] = true
+	}
+	applyTwoFactorPolicy(user, twoFactorEnabled, ToPermissions(requiredKeys))
+	applyTokenScope(user, ToPermissions(scopeKeys))
+	user.APITokenID = &tokenId
+
+	if lastUsed == nil || time.Since(*lastUsed) > sessionTouchInterval {
+		db.DB.ExecContext(ctx, "UPDATE api_token SET last_used_tstz = $1 WHERE api_token_id = $2;", time.Now().UTC(), tokenId)
+	}
+	return user
+}
+
+// Narrows the user's permissions to the ones the token is scoped to
+func applyTokenScope(user *User, scope []Permission) {
+	scoped := Permissions{}
+	for _, permission := range scope {
+		if HasPermission(user, permission) {
+			scoped[permission] = true
+		}
+	}
+	user.IsAdmin = false
+	user.Permissions = scoped
+}
@@ -2,15 +2,35 @@ package auth
 
 import (
 	"context"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/KA-Challenge-Council/Bema/internal/db"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 )
 
 var userCtxKey = &contextKey{"user"}
+var requestCtxKey = &contextKey{"request"}
+
+// How long a session stays valid after it was last used
+var SessionLifetime = 4 * time.Hour
+
+// How long a session can be kept alive by using it, counted from when it was created
+var SessionMaxLifetime = 30 * 24 * time.Hour
+
+// How long an impersonation session lasts. Using it does not extend it.
+var ImpersonationLifetime = 30 * time.Minute
+
+// The proxies whose X-Forwarded-For header is believed. None are trusted by default.
+var TrustedProxies []*net.IPNet
+
+// Sessions are only written back to the database when they have not been seen for this long,
+// so a burst of requests only extends the session once
+const sessionTouchInterval = time.Minute
 
 type contextKey struct {
 	name string
@@ -26,76 +46,77 @@ type User struct {
 	Permissions    Permissions
 	IsImpersonated bool
 	OriginID       *int
+	// Set when the session impersonates the user in read-only mode, which rejects every mutation
+	IsReadOnly bool
+	SessionID  int
+	// Set when the request was authenticated with an API token instead of a session
+	APITokenID *int
+	// Set when the user holds a permission that requires two-factor authentication without having
+	// enabled it. Those permissions are withheld until they do.
+	TwoFactorSetupRequired bool
 }
 
-type Permissions struct {
-	AddEntries            bool
-	AddUsers              bool
-	AssignEntryGroups     bool
-	AssignEvaluatorGroups bool
-	AssumeUserIdentities  bool
-	ChangeUserPasswords   bool
-	DeleteAllEvaluations  bool
-	DeleteAllTasks        bool
-	DeleteContests        bool
-	DeleteEntries         bool
-	DeleteErrors          bool
-	DeleteKbContent       bool
-	EditAllEvaluations    bool
-	EditAllTasks          bool
-	EditContests          bool
-	EditEntries           bool
-	EditKbContent         bool
-	EditUserProfiles      bool
-	JudgeEntries          bool
-	ManageAnnouncements   bool
-	ManageJudgingCriteria bool
-	ManageJudgingGroups   bool
-	ManageWinners         bool
-	PublishKbContent      bool
-	ViewAdminStats        bool
-	ViewAllEvaluations    bool
-	ViewAllTasks          bool
-	ViewAllUsers          bool
-	ViewErrors            bool
-	ViewJudgingSettings   bool
+// Describes where a request came from
+type RequestInfo struct {
+	IP        string
+	UserAgent string
+	// Set when the request came from the app rather than a form or script on another site: it
+	// sent the CSRF token, or authenticated with an API token, which browsers never send on their own
+	CSRFVerified bool
 }
 
+// The permissions a user holds through their roles and direct grants
+type Permissions map[Permission]bool
+
+// The key of a row in the permission table
 type Permission string
 
-const (
-	AddEntries            Permission = "ADD_ENTRIES"
-	AddUsers              Permission = "ADD_USERS"
-	AssignEntryGroups     Permission = "ASSIGN_ENTRY_GROUPS"
-	AssignEvaluatorGroups Permission = "ASSIGN_EVALUATOR_GROUPS"
-	AssumeUserIdentities  Permission = "ASSUME_USER_IDENTITIES"
-	ChangeUserPasswords   Permission = "CHANGE_USER_PASSWORDS"
-	DeleteAllEvaluations  Permission = "DELETE_ALL_EVALUATIONS"
-	DeleteAllTasks        Permission = "DELETE_ALL_TASKS"
-	DeleteContests        Permission = "DELETE_CONTESTS"
-	DeleteEntries         Permission = "DELETE_ENTRIES"
-	DeleteErrors          Permission = "DELETE_ERRORS"
-	DeleteKbContent       Permission = "DELETE_KB_CONTENT"
-	EditAllEvaluations    Permission = "EDIT_ALL_EVALUATIONS"
-	EditAllTasks          Permission = "EDIT_ALL_TASKS"
-	EditContests          Permission = "EDIT_CONTESTS"
-	EditEntries           Permission = "EDIT_ENTRIES"
-	EditKbContent         Permission = "EDIT_KB_CONTENT"
-	EditUserProfiles      Permission = "EDIT_USER_PROFILES"
-	JudgeEntries          Permission = "JUDGE_ENTRIES"
-	ManageAnnouncements   Permission = "MANAGE_ANNOUNCEMENTS"
-	ManageJudgingCriteria Permission = "MANAGE_JUDGING_CRITERIA"
-	ManageJudgingGroups   Permission = "MANAGE_JUDGING_GROUPS"
-	ManageWinners         Permission = "MANAGE_WINNERS"
-	PublishKbContent      Permission = "PUBLISH_KB_CONTENT"
-	ViewAdminStats        Permission = "VIEW_ADMIN_STATS"
-	ViewAllEvaluations    Permission = "VIEW_ALL_EVALUATIONS"
-	ViewAllTasks          Permission = "VIEW_ALL_TASKS"
-	ViewAllUsers          Permission = "VIEW_ALL_USERS"
-	ViewErrors            Permission = "VIEW_ERRORS"
-	ViewJudgingSettings   Permission = "VIEW_JUDGING_SETTINGS"
+// The permissions checked in code. Each one needs a matching row in the permission table.
+var (
+	AddEntries            = definePermission("ADD_ENTRIES")
+	AddUsers              = definePermission("ADD_USERS")
+	AssignEntryGroups     = definePermission("ASSIGN_ENTRY_GROUPS")
+	AssignEvaluatorGroups = definePermission("ASSIGN_EVALUATOR_GROUPS")
+	AssumeUserIdentities  = definePermission("ASSUME_USER_IDENTITIES")
+	ChangeUserPasswords   = definePermission("CHANGE_USER_PASSWORDS")
+	DeleteAllEvaluations  = definePermission("DELETE_ALL_EVALUATIONS")
+	DeleteAllTasks        = definePermission("DELETE_ALL_TASKS")
+	DeleteContests        = definePermission("DELETE_CONTESTS")
+	DeleteEntries         = definePermission("DELETE_ENTRIES")
+	DeleteErrors          = definePermission("DELETE_ERRORS")
+	DeleteKbContent       = definePermission("DELETE_KB_CONTENT")
+	EditAllEvaluations    = definePermission("EDIT_ALL_EVALUATIONS")
+	EditAllTasks          = definePermission("EDIT_ALL_TASKS")
+	EditContests          = definePermission("EDIT_CONTESTS")
+	EditEntries           = definePermission("EDIT_ENTRIES")
+	EditKbContent         = definePermission("EDIT_KB_CONTENT")
+	EditUserProfiles      = definePermission("EDIT_USER_PROFILES")
+	JudgeEntries          = definePermission("JUDGE_ENTRIES")
+	ManageAnnouncements   = definePermission("MANAGE_ANNOUNCEMENTS")
+	ManageJudgingCriteria = definePermission("MANAGE_JUDGING_CRITERIA")
+	ManageJudgingGroups   = definePermission("MANAGE_JUDGING_GROUPS")
+	ManageWinners         = definePermission("MANAGE_WINNERS")
+	PublishKbContent      = definePermission("PUBLISH_KB_CONTENT")
+	ViewAdminStats        = definePermission("VIEW_ADMIN_STATS")
+	ViewAllEvaluations    = definePermission("VIEW_ALL_EVALUATIONS")
+	ViewAllTasks          = definePermission("VIEW_ALL_TASKS")
+	ViewAllUsers          = definePermission("VIEW_ALL_USERS")
+	ViewErrors            = definePermission("VIEW_ERRORS")
+	ViewJudgingSettings   = definePermission("VIEW_JUDGING_SETTINGS")
 )
 
+var definedPermissions = map[Permission]bool{}
+
+func definePermission(key string) Permission {
+	definedPermissions[Permission(key)] = true
+	return Permission(key)
+}
+
+// Reports whether the key is one of the permissions checked in code
+func IsDefinedPermission(key string) bool {
+	return definedPermissions[Permission(key)]
+}
+
 func newUserModel() *User {
 	user := &User{}
 	user.Permissions = Permissions{}
@@ -105,40 +126,148 @@ func newUserModel() *User {
 func Middleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			token, err := r.Cookie("auth")
-			user := newUserModel()
+			info := RequestInfo{IP: ClientIP(r), UserAgent: r.UserAgent()}
 
-			// Don't set the user for unauthenticated users
-			if err != nil || token == nil {
-				user = nil
+			// Scripts authenticate with an API token, browsers with the session cookie. Don't set the
+			// user for unauthenticated users.
+			var user *User
+			if bearer := bearerToken(r); bearer != "" {
+				user = userFromAPIToken(r.Context(), bearer)
+				info.CSRFVerified = true
 			} else {
-				// Look up the user by their session token
-				var impersonatedBy *int
-				row := db.DB.QueryRow("SELECT e.evaluator_id, e.evaluator_kaid, e.evaluator_name, e.nickname, e.username, e.is_admin, p.view_admin_stats, p.edit_contests, p.delete_contests, p.add_entries, p.edit_entries, p.delete_entries, p.assign_entry_groups, p.view_all_evaluations, p.edit_all_evaluations, p.delete_all_evaluations, p.manage_winners, p.view_all_tasks, p.edit_all_tasks, p.delete_all_tasks, p.view_judging_settings, p.manage_judging_groups, p.assign_evaluator_groups, p.manage_judging_criteria, p.view_all_users, p.edit_user_profiles, p.change_user_passwords, p.assume_user_identities, p.add_users, p.view_errors, p.delete_errors, p.judge_entries, p.edit_kb_content, p.delete_kb_content, p.publish_kb_content, p.manage_announcements, s.impersonated_by_user FROM user_session s INNER JOIN evaluator e ON s.user_id = e.evaluator_id INNER JOIN evaluator_permissions p ON p.evaluator_id = e.evaluator_id WHERE s.token = $1 AND s.expires > $2 AND e.account_locked = false;", token.Value, time.Now().UTC())
-				if err := row.Scan(&user.ID, &user.Kaid, &user.Name, &user.Nickname, &user.Username, &user.IsAdmin, &user.Permissions.ViewAdminStats, &user.Permissions.EditContests, &user.Permissions.DeleteContests, &user.Permissions.AddEntries, &user.Permissions.EditEntries, &user.Permissions.DeleteEntries, &user.Permissions.AssignEntryGroups, &user.Permissions.ViewAllEvaluations, &user.Permissions.EditAllEvaluations, &user.Permissions.DeleteAllEvaluations, &user.Permissions.ManageWinners, &user.Permissions.ViewAllTasks, &user.Permissions.EditAllTasks, &user.Permissions.DeleteAllTasks, &user.Permissions.ViewJudgingSettings, &user.Permissions.ManageJudgingGroups, &user.Permissions.AssignEvaluatorGroups, &user.Permissions.ManageJudgingCriteria, &user.Permissions.ViewAllUsers, &user.Permissions.EditUserProfiles, &user.Permissions.ChangeUserPasswords, &user.Permissions.AssumeUserIdentities, &user.Permissions.AddUsers, &user.Permissions.ViewErrors, &user.Permissions.DeleteErrors, &user.Permissions.JudgeEntries, &user.Permissions.EditKbContent, &user.Permissions.DeleteKbContent, &user.Permissions.PublishKbContent, &user.Permissions.ManageAnnouncements, &impersonatedBy); err != nil {
-					user = nil
-				}
-
-				if user != nil {
-					user.IsImpersonated = impersonatedBy != nil
-					user.OriginID = impersonatedBy
+				info.CSRFVerified = checkCSRFToken(w, r)
+				if token, err := r.Cookie(SessionCookie); err == nil && token != nil {
+					user = userFromSession(r.Context(), token.Value, info)
 				}
 			}
 
-			// Add user to request context
+			// Add user, request info and the response, so resolvers can set cookies, to request context
 			ctx := context.WithValue(r.Context(), userCtxKey, user)
+			ctx = context.WithValue(ctx, requestCtxKey, info)
+			ctx = context.WithValue(ctx, responseCtxKey, w)
 			r = r.WithContext(ctx)
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// Looks up the user by their session token, returning nil if the session is not valid
+func userFromSession(ctx context.Context, token string, info RequestInfo) *User {
+	user := newUserModel()
+	var impersonatedBy *int
+	var lastSeen time.Time
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var permissionKeys, requiredKeys pq.StringArray
+	var twoFactorEnabled bool
+	row := db.DB.QueryRowContext(ctx, "SELECT e.evaluator_id, e.evaluator_kaid, e.evaluator_name, e.nickname, e.username, e.is_admin, ARRAY(SELECT evaluator_permission_keys(e.evaluator_id)), e.totp_enabled_tstz IS NOT NULL, ARRAY(SELECT permission_key FROM two_factor_required_permission), s.impersonated_by_user, COALESCE(i.read_only, false), s.session_id, s.last_seen_tstz FROM user_session s INNER JOIN evaluator e ON s.user_id = e.evaluator_id LEFT JOIN impersonation i ON i.session_id = s.session_id WHERE s.token = $1 AND s.expires > $2 AND e.account_locked = false;", token, time.Now().UTC())
+	if err := row.Scan(&user.ID, &user.Kaid, &user.Name, &user.Nickname, &user.Username, &user.IsAdmin, &permissionKeys, &twoFactorEnabled, &requiredKeys, &impersonatedBy, &user.IsReadOnly, &user.SessionID, &lastSeen); err != nil {
+		return nil
+	}
+
+	for _, key := range permissionKeys {
+		user.Permissions[Permission(key)] = true
+	}
+	applyTwoFactorPolicy(user, twoFactorEnabled, ToPermissions(requiredKeys))
+	user.IsImpersonated = impersonatedBy != nil
+	user.OriginID = impersonatedBy
+
+	if time.Since(lastSeen) > sessionTouchInterval {
+		touchSession(ctx, user.SessionID, info)
+	}
+	return user
+}
+
+// Converts permission keys, such as rows from the permission table, to permissions
+func ToPermissions(keys []string) []Permission {
+	permissions := []Permission{}
+	for _, key := range keys {
+		permissions = append(permissions, Permission(key))
+	}
+	return permissions
+}
+
+// Marks a session as seen and extends it, without going past its maximum lifetime. Impersonation
+// sessions keep the expiry they were created with.
+func touchSession(ctx context.Context, sessionId int, info RequestInfo) {
+	now := time.Now().UTC()
+	db.DB.ExecContext(ctx, "UPDATE user_session SET last_seen_tstz = $1, expires = CASE WHEN impersonated_by_user IS NULL THEN LEAST($2::timestamptz, created_tstz + $3 * interval '1 second') ELSE expires END, ip_address = $4, user_agent = $5 WHERE session_id = $6;", now, now.Add(SessionLifetime), SessionMaxLifetime.Seconds(), info.IP, info.UserAgent, sessionId)
+}
+
+// Returns the address of the client that sent the request. X-Forwarded-For is only read when the
+// request came from a trusted proxy, such as Heroku's router, since anyone else can set it. The
+// client is then the last address in it that was not added by another trusted proxy.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host) {
+		return host
+	}
+
+	addresses := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(addresses) - 1; i >= 0; i-- {
+		address := strings.TrimSpace(addresses[i])
+		if address == "" {
+			break
+		}
+		if !isTrustedProxy(address) {
+			return address
+		}
+		host = address
+	}
+	return host
+}
+
+// Parses the addresses or CIDR ranges of trusted proxies
+func ParseTrustedProxies(values []string) ([]*net.IPNet, error) {
+	networks := []*net.IPNet{}
+	for _, value := range values {
+		if ip := net.ParseIP(value); ip != nil {
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func isTrustedProxy(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range TrustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetUserFromContext finds the user from the context. REQUIRES Middleware to have already run.
 func GetUserFromContext(ctx context.Context) *User {
 	user, _ := ctx.Value(userCtxKey).(*User)
 	return user
 }
 
+// Returns where the request came from. REQUIRES Middleware to have already run.
+func GetRequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestCtxKey).(RequestInfo)
+	return info
+}
+
 func HasPermission(user *User, permission Permission) bool {
 	if user == nil {
 		return false
@@ -148,70 +277,28 @@ func HasPermission(user *User, permission Permission) bool {
 		return true
 	}
 
-	switch permission {
-	case AddEntries:
-		return user.Permissions.AddEntries
-	case AddUsers:
-		return user.Permissions.AddUsers
-	case AssignEntryGroups:
-		return user.Permissions.AssignEntryGroups
-	case AssignEvaluatorGroups:
-		return user.Permissions.AssignEvaluatorGroups
-	case AssumeUserIdentities:
-		return user.Permissions.AssumeUserIdentities
-	case ChangeUserPasswords:
-		return user.Permissions.ChangeUserPasswords
-	case DeleteAllEvaluations:
-		return user.Permissions.DeleteAllEvaluations
-	case DeleteAllTasks:
-		return user.Permissions.DeleteAllTasks
-	case DeleteContests:
-		return user.Permissions.DeleteContests
-	case DeleteEntries:
-		return user.Permissions.DeleteEntries
-	case DeleteErrors:
-		return user.Permissions.DeleteErrors
-	case DeleteKbContent:
-		return user.Permissions.DeleteKbContent
-	case EditAllEvaluations:
-		return user.Permissions.EditAllEvaluations
-	case EditAllTasks:
-		return user.Permissions.EditAllTasks
-	case EditContests:
-		return user.Permissions.EditContests
-	case EditEntries:
-		return user.Permissions.EditEntries
-	case EditKbContent:
-		return user.Permissions.EditKbContent
-	case EditUserProfiles:
-		return user.Permissions.EditUserProfiles
-	case JudgeEntries:
-		return user.Permissions.JudgeEntries
-	case ManageAnnouncements:
-		return user.Permissions.ManageAnnouncements
-	case ManageJudgingCriteria:
-		return user.Permissions.ManageJudgingCriteria
-	case ManageJudgingGroups:
-		return user.Permissions.ManageJudgingGroups
-	case ManageWinners:
-		return user.Permissions.ManageWinners
-	case PublishKbContent:
-		return user.Permissions.PublishKbContent
-	case ViewAdminStats:
-		return user.Permissions.ViewAdminStats
-	case ViewAllEvaluations:
-		return user.Permissions.ViewAllEvaluations
-	case ViewAllTasks:
-		return user.Permissions.ViewAllTasks
-	case ViewAllUsers:
-		return user.Permissions.ViewAllUsers
-	case ViewErrors:
-		return user.Permissions.ViewErrors
-	case ViewJudgingSettings:
-		return user.Permissions.ViewJudgingSettings
-	default:
+	return user.Permissions[permission]
+}
+
+// Returns the permissions in the list that the user does not hold
+func MissingPermissions(user *User, permissions []Permission) []Permission {
+	missing := []Permission{}
+	for _, permission := range permissions {
+		if !HasPermission(user, permission) {
+			missing = append(missing, permission)
+		}
+	}
+	return missing
+}
+
+// Reports whether the user holds every permission in the list, and is an admin if isAdmin is set.
+// Users may only grant access they have themselves, and may only act on accounts that have no more
+// access than they do.
+func HasPermissionSuperset(user *User, permissions []Permission, isAdmin bool) bool {
+	if user == nil || (isAdmin && !user.IsAdmin) {
 		return false
 	}
+	return len(MissingPermissions(user, permissions)) == 0
 }
 
 func ValidateUserLogin(password string, hashedPassword string) bool {
@@ -221,14 +308,19 @@ func ValidateUserLogin(password string, hashedPassword string) bool {
 
 func CreateAuthToken(ctx context.Context, userId int, impersonatedById *int, updateLastLogin bool) *string {
 	token := uuid.NewString()
+	info := GetRequestInfoFromContext(ctx)
+	now := time.Now().UTC()
+
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
 
-	_, err := db.DB.Exec("INSERT INTO user_session (user_id, token, expires, impersonated_by_user) VALUES ($1, $2, $3, $4);", userId, token, time.Now().Add(14400000000000).UTC(), impersonatedById)
+	_, err := db.DB.ExecContext(ctx, "INSERT INTO user_session (user_id, token, expires, impersonated_by_user, created_tstz, last_seen_tstz, ip_address, user_agent) VALUES ($1, $2, $3, $4, $5, $5, $6, $7);", userId, token, now.Add(SessionLifetime), impersonatedById, now, info.IP, info.UserAgent)
 	if err != nil {
 		return nil
 	}
 
 	if updateLastLogin {
-		_, err = db.DB.Exec("UPDATE evaluator SET logged_in_tstz = $1 WHERE evaluator_id = $2;", time.Now().UTC(), userId)
+		_, err = db.DB.ExecContext(ctx, "UPDATE evaluator SET logged_in_tstz = $1 WHERE evaluator_id = $2;", now, userId)
 		if err != nil {
 			return nil
 		}
@@ -237,7 +329,21 @@ func CreateAuthToken(ctx context.Context, userId int, impersonatedById *int, upd
 	return &token
 }
 
-func RemoveAuthTokensForUser(ctx context.Context, userId int) error {
-	_, err := db.DB.Exec("DELETE FROM user_session WHERE user_id = $1", userId)
-	return err
+// Creates a session for impersonating a user that ends after ImpersonationLifetime, and records
+// who started it and why
+func CreateImpersonationToken(ctx context.Context, userId int, impersonatorId int, reason string, readOnly bool) *string {
+	token := uuid.NewString()
+	info := GetRequestInfoFromContext(ctx)
+	now := time.Now().UTC()
+	expires := now.Add(ImpersonationLifetime)
+
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := db.DB.ExecContext(ctx, "WITH s AS (INSERT INTO user_session (user_id, token, expires, impersonated_by_user, created_tstz, last_seen_tstz, ip_address, user_agent) VALUES ($1, $2, $3, $4, $5, $5, $6, $7) RETURNING session_id) INSERT INTO impersonation (impersonator_id, user_id, session_id, reason, read_only, ip_address, started_tstz, expires_tstz) SELECT $4, $1, s.session_id, $8, $9, NULLIF($6, ''), $5, $3 FROM s;", userId, token, expires, impersonatorId, now, info.IP, info.UserAgent, reason, readOnly)
+	if err != nil {
+		return nil
+	}
+
+	return &token
 }
@@ -0,0 +1,320 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHasPermission(t *testing.T) {
+	judge := &User{ID: 2, Permissions: Permissions{JudgeEntries: true}}
+	admin := &User{ID: 1, IsAdmin: true}
+
+	tests := []struct {
+		name       string
+		user       *User
+		permission Permission
+		want       bool
+	}{
+		{"anonymous users have no permissions", nil, JudgeEntries, false},
+		{"granted permission", judge, JudgeEntries, true},
+		{"permission that was not granted", judge, PublishKbContent, false},
+		{"admins have every permission", admin, PublishKbContent, true},
+		{"unknown permission", judge, Permission("NOT_A_PERMISSION"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasPermission(tt.user, tt.permission); got != tt.want {
+				t.Fatalf("HasPermission(%+v, %s) = %v, want %v", tt.user, tt.permission, got, tt.want)
+			}
+		})
+	}
+
+	// Every permission maps to its own field, so granting one must not grant any other
+	permissions := []Permission{AddEntries, AddUsers, AssignEntryGroups, AssignEvaluatorGroups, AssumeUserIdentities, ChangeUserPasswords, DeleteAllEvaluations, DeleteAllTasks, DeleteContests, DeleteEntries, DeleteErrors, DeleteKbContent, EditAllEvaluations, EditAllTasks, EditContests, EditEntries, EditKbContent, EditUserProfiles, JudgeEntries, ManageAnnouncements, ManageJudgingCriteria, ManageJudgingGroups, ManageWinners, PublishKbContent, ViewAdminStats, ViewAllEvaluations, ViewAllTasks, ViewAllUsers, ViewErrors, ViewJudgingSettings}
+	for _, permission := range permissions {
+		if HasPermission(judge, permission) != (permission == JudgeEntries) {
+			t.Errorf("HasPermission(judge, %s) = %v", permission, !(permission == JudgeEntries))
+		}
+		if !HasPermission(admin, permission) {
+			t.Errorf("HasPermission(admin, %s) = false, want true", permission)
+		}
+	}
+}
+
+func TestHasPermissionSuperset(t *testing.T) {
+	organizer := &User{ID: 6, Permissions: Permissions{AssignEntryGroups: true, ViewAllUsers: true}}
+	admin := &User{ID: 1, IsAdmin: true}
+
+	tests := []struct {
+		name        string
+		user        *User
+		permissions []Permission
+		isAdmin     bool
+		want        bool
+	}{
+		{"anonymous users hold nothing", nil, nil, false, false},
+		{"no permissions", organizer, nil, false, true},
+		{"subset of the user's permissions", organizer, []Permission{AssignEntryGroups}, false, true},
+		{"same permissions", organizer, []Permission{AssignEntryGroups, ViewAllUsers}, false, true},
+		{"permission the user does not hold", organizer, []Permission{AssignEntryGroups, JudgeEntries}, false, false},
+		{"admin target", organizer, nil, true, false},
+		{"admins hold every permission", admin, []Permission{JudgeEntries, AddUsers}, false, true},
+		{"admins may act on admins", admin, nil, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasPermissionSuperset(tt.user, tt.permissions, tt.isAdmin); got != tt.want {
+				t.Fatalf("HasPermissionSuperset(%+v, %v, %v) = %v, want %v", tt.user, tt.permissions, tt.isAdmin, got, tt.want)
+			}
+		})
+	}
+
+	missing := MissingPermissions(organizer, []Permission{JudgeEntries, AssignEntryGroups, AddUsers})
+	if len(missing) != 2 || missing[0] != JudgeEntries || missing[1] != AddUsers {
+		t.Fatalf("MissingPermissions() = %v, want [%s %s]", missing, JudgeEntries, AddUsers)
+	}
+}
+
+func TestLockoutAfter(t *testing.T) {
+	policy := LoginThrottlePolicy{Lockout: time.Minute, MaxLockout: 10 * time.Minute}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{4, 0},
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{8, 8 * time.Minute},
+		{9, 10 * time.Minute},
+		{100, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := policy.LockoutAfter(tt.failures, 5); got != tt.want {
+			t.Errorf("LockoutAfter(%d, 5) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestSecretTokens(t *testing.T) {
+	a, err := NewSecretToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewSecretToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a == b {
+		t.Fatal("NewSecretToken() returned the same token twice")
+	}
+	if len(a) != 43 {
+		t.Errorf("len(NewSecretToken()) = %d, want 43", len(a))
+	}
+	if HashSecretToken(a) != HashSecretToken(a) || HashSecretToken(a) == HashSecretToken(b) {
+		t.Error("HashSecretToken() must return the same hash for the same token only")
+	}
+	if HashSecretToken(a) == a {
+		t.Error("HashSecretToken() returned the token itself")
+	}
+}
+
+func TestTOTP(t *testing.T) {
+	// The SHA-1 test vectors from RFC 6238, truncated to six digits
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+
+	now := time.Unix(1234567890, 0)
+	if step, ok := ValidateTOTP(secret, "005924", now.Add(30*time.Second)); !ok || step != 1234567890/30 {
+		t.Errorf("ValidateTOTP() = %d, %v, want the code from the previous period to be accepted", step, ok)
+	}
+	if _, ok := ValidateTOTP(secret, "005924", now.Add(2*time.Minute)); ok {
+		t.Error("ValidateTOTP() accepted a code from four periods ago")
+	}
+	if _, ok := ValidateTOTP(secret, "000000", now); ok {
+		t.Error("ValidateTOTP() accepted a wrong code")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != RecoveryCodeCount || len(codes[0]) != 11 || codes[0][5] != '-' {
+		t.Fatalf("NewRecoveryCodes() = %v, want %d codes like xxxxx-xxxxx", codes, RecoveryCodeCount)
+	}
+	if HashRecoveryCode(codes[0]) != HashRecoveryCode(" "+strings.ToUpper(strings.Replace(codes[0], "-", "", 1))) {
+		t.Error("HashRecoveryCode() must ignore case, spaces and dashes")
+	}
+}
+
+func TestApplyTwoFactorPolicy(t *testing.T) {
+	required := []Permission{AssumeUserIdentities}
+
+	manager := &User{Permissions: Permissions{AssumeUserIdentities: true, JudgeEntries: true}}
+	applyTwoFactorPolicy(manager, false, required)
+	if !manager.TwoFactorSetupRequired || HasPermission(manager, AssumeUserIdentities) || !HasPermission(manager, JudgeEntries) {
+		t.Errorf("got %+v, want only the required permission withheld", manager)
+	}
+
+	admin := &User{IsAdmin: true, Permissions: Permissions{}}
+	applyTwoFactorPolicy(admin, false, required)
+	if admin.IsAdmin || !admin.TwoFactorSetupRequired {
+		t.Errorf("got %+v, want admin rights withheld", admin)
+	}
+
+	enrolled := &User{IsAdmin: true, Permissions: Permissions{}}
+	applyTwoFactorPolicy(enrolled, true, required)
+	if !enrolled.IsAdmin || enrolled.TwoFactorSetupRequired {
+		t.Errorf("got %+v, want nothing withheld once two-factor authentication is enabled", enrolled)
+	}
+
+	judge := &User{Permissions: Permissions{JudgeEntries: true}}
+	applyTwoFactorPolicy(judge, false, required)
+	if judge.TwoFactorSetupRequired {
+		t.Error("two-factor authentication was required of a user without the required permission")
+	}
+}
+
+func TestApplyTokenScope(t *testing.T) {
+	manager := &User{Permissions: Permissions{EditUserProfiles: true, JudgeEntries: true}}
+	applyTokenScope(manager, []Permission{JudgeEntries, AddUsers})
+	if !HasPermission(manager, JudgeEntries) || HasPermission(manager, EditUserProfiles) || HasPermission(manager, AddUsers) {
+		t.Errorf("got %v, want only the scoped permissions the user holds", manager.Permissions)
+	}
+
+	admin := &User{IsAdmin: true, Permissions: Permissions{}}
+	applyTokenScope(admin, []Permission{ViewAllUsers})
+	if admin.IsAdmin || !HasPermission(admin, ViewAllUsers) || HasPermission(admin, AddUsers) {
+		t.Errorf("got %+v, want an admin's token limited to its scope", admin)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"Bearer bema_abc", "bema_abc"},
+		{"bearer  bema_abc ", "bema_abc"},
+		{"Basic dXNlcjpwYXNz", ""},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		if tt.header != "" {
+			r.Header.Set("Authorization", tt.header)
+		}
+		if got := bearerToken(r); got != tt.want {
+			t.Errorf("bearerToken(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCheckCSRFToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		cookie string
+		header string
+		want   bool
+	}{
+		{"no cookie", "", "abc", false},
+		{"no header", "abc", "", false},
+		{"different token", "abc", "abd", false},
+		{"matching token", "abc", "abc", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: CSRFCookie, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				r.Header.Set(CSRFHeader, tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			if got := checkCSRFToken(w, r); got != tt.want {
+				t.Fatalf("checkCSRFToken() = %v, want %v", got, tt.want)
+			}
+
+			// Clients without a token are given one
+			issued := false
+			for _, cookie := range w.Result().Cookies() {
+				issued = issued || (cookie.Name == CSRFCookie && cookie.Value != "" && !cookie.HttpOnly)
+			}
+			if issued != (tt.cookie == "") {
+				t.Fatalf("issued a CSRF cookie = %v, want %v", issued, tt.cookie == "")
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	TrustedProxies = proxies
+	defer func() { TrustedProxies = nil }()
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{"direct", "203.0.113.5:1234", "", "203.0.113.5"},
+		{"forged header on a direct request", "203.0.113.5:1234", "198.51.100.7", "203.0.113.5"},
+		{"through a trusted proxy", "10.1.2.3:1234", "198.51.100.7", "198.51.100.7"},
+		{"client prepends a forged address", "10.1.2.3:1234", "1.2.3.4, 198.51.100.7", "198.51.100.7"},
+		{"through two trusted proxies", "10.1.2.3:1234", "198.51.100.7, 192.0.2.1", "198.51.100.7"},
+		{"trusted proxy without the header", "10.1.2.3:1234", "", "10.1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if got := ClientIP(r); got != tt.want {
+				t.Fatalf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseTrustedProxies([]string{"not an address"}); err == nil {
+		t.Fatal("ParseTrustedProxies accepted an invalid address")
+	}
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"time"
+)
+
+// The cookie holding the session token. The server sets it and scripts cannot read it.
+const SessionCookie = "auth"
+
+// The cookie holding the CSRF token. The app reads it and sends it back in CSRFHeader with every
+// request, which other sites cannot do.
+const CSRFCookie = "csrf_token"
+
+const CSRFHeader = "X-CSRF-Token"
+
+// Set when the app is served over HTTPS, so cookies are only sent over HTTPS
+var SecureCookies = true
+
+var responseCtxKey = &contextKey{"response"}
+
+// Builds the session cookie for a token, expiring after lifetime
+func NewSessionCookie(token string, lifetime time.Duration) *http.Cookie {
+	return &http.Cookie{
+		Name:     SessionCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(lifetime.Seconds()),
+		Secure:   SecureCookies,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// Sets the session cookie on the response to the current request. Does nothing for a nil token.
+// REQUIRES Middleware to have already run.
+func SetSessionCookie(ctx context.Context, token *string, lifetime time.Duration) {
+	w, _ := ctx.Value(responseCtxKey).(http.ResponseWriter)
+	if w == nil || token == nil {
+		return
+	}
+	http.SetCookie(w, NewSessionCookie(*token, lifetime))
+}
+
+// Removes the session cookie from the client. REQUIRES Middleware to have already run.
+func ClearSessionCookie(ctx context.Context) {
+	w, _ := ctx.Value(responseCtxKey).(http.ResponseWriter)
+	if w == nil {
+		return
+	}
+	cookie := NewSessionCookie("", 0)
+	cookie.MaxAge = -1
+	http.SetCookie(w, cookie)
+}
+
+// Reports whether the request carries the CSRF token from its cookie in the CSRF header. Clients
+// without the cookie are given one, which they can use from their next request on.
+func checkCSRFToken(w http.ResponseWriter, r *http.Request) bool {
+	cookie, err := r.Cookie(CSRFCookie)
+	if err != nil || cookie.Value == "" {
+		if token, err := NewSecretToken(); err == nil {
+			http.SetCookie(w, &http.Cookie{
+				Name:     CSRFCookie,
+				Value:    token,
+				Path:     "/",
+				Secure:   SecureCookies,
+				SameSite: http.SameSiteStrictMode,
+			})
+		}
+		return false
+	}
+
+	header := r.Header.Get(CSRFHeader)
+	return header != "" && subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) == 1
+}
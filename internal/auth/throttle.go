@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"math"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Limits failed logins. Set from the config when the server starts.
+var LoginThrottle = LoginThrottlePolicy{
+	MaxAttempts:      5,
+	MaxAttemptsPerIP: 50,
+	Window:           15 * time.Minute,
+	Lockout:          5 * time.Minute,
+	MaxLockout:       time.Hour,
+}
+
+type LoginThrottlePolicy struct {
+	// Failed logins allowed for a username before it is locked out
+	MaxAttempts int
+	// Failed logins allowed from an IP address, across all usernames, before it is locked out
+	MaxAttemptsPerIP int
+	// How long after the last failure, or the end of the last lockout, failures are forgotten
+	Window time.Duration
+	// The first lockout. Each further failure doubles it.
+	Lockout    time.Duration
+	MaxLockout time.Duration
+}
+
+// Returns how long to refuse logins after the given number of recent failures, or 0 if there
+// have not been too many
+func (p LoginThrottlePolicy) LockoutAfter(failures int, maxAttempts int) time.Duration {
+	if failures < maxAttempts {
+		return 0
+	}
+
+	lockout := float64(p.Lockout) * math.Pow(2, float64(failures-maxAttempts))
+	if lockout > float64(p.MaxLockout) {
+		return p.MaxLockout
+	}
+	return time.Duration(lockout)
+}
+
+// Hashed once so that RejectLogin costs as much as checking a real password
+var rejectHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+
+// Compares the password against a hash it never matches, so that logins for unknown usernames
+// take as long as logins with wrong passwords
+func RejectLogin(password string) {
+	bcrypt.CompareHashAndPassword(rejectHash, []byte(password))
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// How long a password reset link can be used for
+var PasswordResetLifetime = time.Hour
+
+// How long an invitation link can be used for
+var InvitationLifetime = 7 * 24 * time.Hour
+
+// How long after a password reset email is sent before another can be sent to the same user
+const PasswordResetCooldown = time.Minute
+
+// Returns a random token that is safe to put in a URL. Only its hash should be stored.
+func NewSecretToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Returns the hash a secret token is stored and looked up by
+func HashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The name authenticator apps show next to the account
+const TOTPIssuer = "KA Challenge Council"
+
+// RFC 6238 parameters. These are the defaults every authenticator app supports.
+const (
+	totpPeriod = 30
+	totpDigits = 6
+	// Codes from this many periods either side of now are accepted, to allow for clock drift
+	totpSkew = 1
+)
+
+// How long a user has to enter their two-factor code after entering their password
+const LoginChallengeLifetime = 5 * time.Minute
+
+// Wrong two-factor codes allowed for one login before the password has to be entered again
+const LoginChallengeMaxFailures = 5
+
+// The number of recovery codes a user is given
+const RecoveryCodeCount = 10
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Returns a new random base32 TOTP secret
+func NewTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// Returns the otpauth:// URI that authenticator apps read from a QR code
+func TOTPProvisioningURI(secret string, username string) string {
+	label := url.PathEscape(TOTPIssuer + ":" + username)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", TOTPIssuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// Returns the code for the given time step
+func totpCode(key []byte, step int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation from RFC 4226
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// Returns the code an authenticator app would show at t
+func TOTPCode(secret string, t time.Time) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+	return totpCode(key, t.Unix()/totpPeriod), nil
+}
+
+// Checks a code against the secret at time t and returns the time step it belongs to. Callers must
+// reject steps that were already used, so that a code cannot be replayed.
+func ValidateTOTP(secret string, code string, t time.Time) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	code = strings.ReplaceAll(code, " ", "")
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	now := t.Unix() / totpPeriod
+	for step := now - totpSkew; step <= now+totpSkew; step++ {
+		if hmac.Equal([]byte(totpCode(key, step)), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// Returns a set of recovery codes formatted as xxxxx-xxxxx
+func NewRecoveryCodes() ([]string, error) {
+	codes := []string{}
+	for i := 0; i < RecoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(totpEncoding.EncodeToString(b))[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+	}
+	return codes, nil
+}
+
+// Returns the hash a recovery code is stored by, ignoring case, spaces and dashes
+func HashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	return HashSecretToken(normalized)
+}
+
+// Reports whether the user holds a permission that requires two-factor authentication. Admins
+// hold every permission, so any requirement applies to them.
+func TwoFactorRequired(user *User, required []Permission) bool {
+	if user == nil || len(required) == 0 {
+		return false
+	}
+	return user.IsAdmin || len(MissingPermissions(user, required)) < len(required)
+}
+
+// Withholds the permissions that require two-factor authentication, and admin rights if any do,
+// from a user who has not enabled it
+func applyTwoFactorPolicy(user *User, enabled bool, required []Permission) {
+	if enabled || !TwoFactorRequired(user, required) {
+		return
+	}
+
+	user.TwoFactorSetupRequired = true
+	user.IsAdmin = false
+	for _, permission := range required {
+		delete(user.Permissions, permission)
+	}
+}
@@ -0,0 +1,50 @@
+// Package buildinfo reports which build of the server is running and for how long.
+package buildinfo
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// Can be set at build time with -ldflags "-X github.com/KA-Challenge-Council/Bema/internal/buildinfo.Version=<version>".
+// When it is not set, the git revision Go embedded in the binary is used instead.
+var Version = ""
+
+// The time the server process started
+var StartedAt = time.Now().UTC()
+
+// Returns the git version of the running build, or "unknown" if it was not recorded
+func GitVersion() string {
+	if Version != "" {
+		return Version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	revision := ""
+	modified := false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+
+	if revision == "" {
+		return "unknown"
+	}
+	if modified {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// Returns how long the server has been running
+func Uptime() time.Duration {
+	return time.Since(StartedAt)
+}
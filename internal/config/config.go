@@ -0,0 +1,378 @@
+// Package config loads and validates the server's settings.
+//
+// Every setting is read from an environment variable. Settings that are not set in
+// the environment are read from the file named by CONFIG_FILE (default .env), which
+// uses the same KEY=VALUE names. Anything still missing falls back to a default.
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type Config struct {
+	// The port the HTTP server listens on
+	Port int
+	// "dev" selects DEV_DB_URL, anything else PROD_DB_URL
+	AppState string
+	// Applies pending migrations when the server starts
+	AutoMigrate bool
+	// The directory holding the built React app
+	StaticDir string
+	// The address users reach the app at, used to build links in emails
+	AppURL string
+
+	Database Database
+	HTTP     HTTP
+	CORS     CORS
+	Session  Session
+	Login    Login
+	Terms    Terms
+	Mail     Mail
+	OIDC     OIDC
+}
+
+type Database struct {
+	URL             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	// The longest a single model operation may run. Zero disables the deadline.
+	QueryTimeout time.Duration
+}
+
+type HTTP struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// How long in-flight requests are given to finish after a shutdown signal
+	ShutdownTimeout time.Duration
+	// Addresses or CIDR ranges of the proxies in front of the server, whose X-Forwarded-For header is believed
+	TrustedProxies []string
+}
+
+type CORS struct {
+	AllowedOrigins []string
+}
+
+type Session struct {
+	// How long a login session lasts after it was last used
+	Lifetime time.Duration
+	// How long a session can be kept alive by using it
+	MaxLifetime time.Duration
+	// How long an admin can impersonate a user before having to start again
+	ImpersonationLifetime time.Duration
+	// How often expired sessions are deleted
+	PurgeInterval time.Duration
+}
+
+type Login struct {
+	// Failed logins allowed for a username before it is locked out
+	MaxAttempts int
+	// Failed logins allowed from an IP address before it is locked out
+	MaxAttemptsPerIP int
+	// How long failed logins are remembered
+	AttemptWindow time.Duration
+	// The first lockout, which doubles with each further failure
+	Lockout    time.Duration
+	MaxLockout time.Duration
+	// How long a password reset link can be used for
+	PasswordResetLifetime time.Duration
+	// How long an invitation link can be used for
+	InvitationLifetime time.Duration
+}
+
+// Locking, reactivating and warning accounts based on their term dates
+type Terms struct {
+	// How often term dates are checked
+	CheckInterval time.Duration
+	// How long before a term ends the user and admins are warned
+	EndWarning time.Duration
+}
+
+type Mail struct {
+	// "smtp" sends mail through the SMTP server, "log" writes it to Dir or the server log
+	Driver string
+	// The address mail is sent from
+	From string
+	// The directory the log driver writes each message to. Empty logs messages instead.
+	Dir          string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// Single sign-on through an OpenID Connect provider, which is off unless Issuer is set
+type OIDC struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// The callback URL registered with the provider
+	RedirectURL string
+	Scopes      []string
+}
+
+var defaultAllowedOrigins = []string{"https://www.kachallengecouncil.org", "https://bema-development.herokuapp.com", "https://studio.apollographql.com", "http://localhost:6001"}
+
+// Loads the configuration from the environment and config file and validates it
+func Load() (*Config, error) {
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		path = ".env"
+	}
+
+	file, err := godotenv.Read(path)
+	if err != nil {
+		// The default file is optional, but one that was asked for by name must exist
+		if os.Getenv("CONFIG_FILE") != "" {
+			return nil, fmt.Errorf("config: reading %s: %w", path, err)
+		}
+		file = map[string]string{}
+	}
+
+	return parse(func(key string) string {
+		if value, ok := os.LookupEnv(key); ok {
+			return value
+		}
+		return file[key]
+	})
+}
+
+func parse(lookup func(key string) string) (*Config, error) {
+	p := &parser{lookup: lookup}
+
+	cfg := &Config{
+		Port:        p.int("PORT", p.int("GRAPHQL_PORT", 8080)),
+		AppState:    p.string("APP_STATE", "prod"),
+		AutoMigrate: p.bool("AUTO_MIGRATE", false),
+		StaticDir:   p.string("STATIC_DIR", "./client/build"),
+		AppURL:      strings.TrimSuffix(p.string("APP_URL", "https://www.kachallengecouncil.org"), "/"),
+		Database: Database{
+			MaxOpenConns:    p.int("DB_MAX_OPEN_CONNS", 10),
+			MaxIdleConns:    p.int("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime: p.duration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+			QueryTimeout:    p.duration("DB_QUERY_TIMEOUT", 10*time.Second),
+		},
+		HTTP: HTTP{
+			ReadTimeout:       p.duration("HTTP_READ_TIMEOUT", 15*time.Second),
+			ReadHeaderTimeout: p.duration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+			WriteTimeout:      p.duration("HTTP_WRITE_TIMEOUT", 30*time.Second),
+			IdleTimeout:       p.duration("HTTP_IDLE_TIMEOUT", 60*time.Second),
+			ShutdownTimeout:   p.duration("HTTP_SHUTDOWN_TIMEOUT", 30*time.Second),
+			TrustedProxies:    p.list("TRUSTED_PROXIES", []string{}),
+		},
+		CORS: CORS{
+			AllowedOrigins: p.list("CORS_ALLOWED_ORIGINS", defaultAllowedOrigins),
+		},
+		Session: Session{
+			Lifetime:      p.duration("SESSION_LIFETIME", 4*time.Hour),
+			MaxLifetime:   p.duration("SESSION_MAX_LIFETIME", 30*24*time.Hour),
+			PurgeInterval: p.duration("SESSION_PURGE_INTERVAL", time.Hour),
+
+			ImpersonationLifetime: p.duration("IMPERSONATION_LIFETIME", 30*time.Minute),
+		},
+		Login: Login{
+			MaxAttempts:      p.int("LOGIN_MAX_ATTEMPTS", 5),
+			MaxAttemptsPerIP: p.int("LOGIN_MAX_ATTEMPTS_PER_IP", 50),
+			AttemptWindow:    p.duration("LOGIN_ATTEMPT_WINDOW", 15*time.Minute),
+			Lockout:          p.duration("LOGIN_LOCKOUT", 5*time.Minute),
+			MaxLockout:       p.duration("LOGIN_MAX_LOCKOUT", time.Hour),
+
+			PasswordResetLifetime: p.duration("PASSWORD_RESET_LIFETIME", time.Hour),
+			InvitationLifetime:    p.duration("INVITATION_LIFETIME", 7*24*time.Hour),
+		},
+		Terms: Terms{
+			CheckInterval: p.duration("TERM_CHECK_INTERVAL", time.Hour),
+			EndWarning:    p.duration("TERM_END_WARNING", 14*24*time.Hour),
+		},
+		Mail: Mail{
+			Driver:       p.string("MAIL_DRIVER", "log"),
+			From:         p.string("MAIL_FROM", "KA Challenge Council <noreply@kachallengecouncil.org>"),
+			Dir:          p.string("MAIL_DIR", ""),
+			SMTPHost:     p.string("SMTP_HOST", ""),
+			SMTPPort:     p.int("SMTP_PORT", 587),
+			SMTPUsername: p.string("SMTP_USERNAME", ""),
+			SMTPPassword: p.string("SMTP_PASSWORD", ""),
+		},
+		OIDC: OIDC{
+			Issuer:       p.string("OIDC_ISSUER", ""),
+			ClientID:     p.string("OIDC_CLIENT_ID", ""),
+			ClientSecret: p.string("OIDC_CLIENT_SECRET", ""),
+			Scopes:       strings.Fields(p.string("OIDC_SCOPES", "openid email profile")),
+		},
+	}
+	cfg.OIDC.RedirectURL = p.string("OIDC_REDIRECT_URL", cfg.AppURL+"/auth/oidc/callback")
+
+	// DATABASE_URL takes priority, otherwise the URL for the app state is used
+	cfg.Database.URL = p.string("DATABASE_URL", "")
+	if cfg.Database.URL == "" {
+		if cfg.AppState == "dev" {
+			cfg.Database.URL = p.string("DEV_DB_URL", "")
+		} else {
+			cfg.Database.URL = p.string("PROD_DB_URL", "")
+		}
+	}
+
+	if len(p.errs) > 0 {
+		return nil, fmt.Errorf("config: %s", strings.Join(p.errs, "; "))
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Returns an error describing every invalid setting
+func (c *Config) Validate() error {
+	problems := []string{}
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.Port > 0 && c.Port <= 65535, "PORT must be between 1 and 65535")
+	check(c.Database.URL != "", "a database URL is required (DATABASE_URL, or DEV_DB_URL/PROD_DB_URL for the app state)")
+	check(c.Database.MaxOpenConns > 0, "DB_MAX_OPEN_CONNS must be positive")
+	check(c.Database.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS must not be negative")
+	check(c.Database.MaxIdleConns <= c.Database.MaxOpenConns, "DB_MAX_IDLE_CONNS must not be greater than DB_MAX_OPEN_CONNS")
+	check(c.Database.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME must not be negative")
+	check(c.Database.QueryTimeout >= 0, "DB_QUERY_TIMEOUT must not be negative")
+	check(c.HTTP.ReadTimeout > 0, "HTTP_READ_TIMEOUT must be positive")
+	check(c.HTTP.ReadHeaderTimeout > 0, "HTTP_READ_HEADER_TIMEOUT must be positive")
+	check(c.HTTP.WriteTimeout > 0, "HTTP_WRITE_TIMEOUT must be positive")
+	check(c.HTTP.IdleTimeout > 0, "HTTP_IDLE_TIMEOUT must be positive")
+	check(c.HTTP.ShutdownTimeout > 0, "HTTP_SHUTDOWN_TIMEOUT must be positive")
+	check(c.Session.Lifetime >= time.Minute, "SESSION_LIFETIME must be at least 1m")
+	check(c.Session.MaxLifetime >= c.Session.Lifetime, "SESSION_MAX_LIFETIME must not be less than SESSION_LIFETIME")
+	check(c.Session.ImpersonationLifetime >= time.Minute, "IMPERSONATION_LIFETIME must be at least 1m")
+	check(c.Session.PurgeInterval >= time.Minute, "SESSION_PURGE_INTERVAL must be at least 1m")
+	check(c.Login.MaxAttempts > 0, "LOGIN_MAX_ATTEMPTS must be positive")
+	check(c.Login.MaxAttemptsPerIP >= c.Login.MaxAttempts, "LOGIN_MAX_ATTEMPTS_PER_IP must not be less than LOGIN_MAX_ATTEMPTS")
+	check(c.Login.AttemptWindow > 0, "LOGIN_ATTEMPT_WINDOW must be positive")
+	check(c.Login.Lockout > 0, "LOGIN_LOCKOUT must be positive")
+	check(c.Login.MaxLockout >= c.Login.Lockout, "LOGIN_MAX_LOCKOUT must not be less than LOGIN_LOCKOUT")
+	check(c.Login.PasswordResetLifetime >= 5*time.Minute, "PASSWORD_RESET_LIFETIME must be at least 5m")
+	check(c.Login.InvitationLifetime >= time.Hour, "INVITATION_LIFETIME must be at least 1h")
+	check(c.Terms.CheckInterval >= time.Minute, "TERM_CHECK_INTERVAL must be at least 1m")
+	check(c.Terms.EndWarning >= 0, "TERM_END_WARNING must not be negative")
+	check(c.Mail.Driver == "smtp" || c.Mail.Driver == "log", "MAIL_DRIVER must be smtp or log, got %q", c.Mail.Driver)
+	check(c.Mail.From != "", "MAIL_FROM is required")
+	check(c.Mail.Driver != "smtp" || c.Mail.SMTPHost != "", "SMTP_HOST is required when MAIL_DRIVER is smtp")
+	check(c.Mail.SMTPPort > 0 && c.Mail.SMTPPort <= 65535, "SMTP_PORT must be between 1 and 65535")
+	check(len(c.CORS.AllowedOrigins) > 0, "CORS_ALLOWED_ORIGINS must list at least one origin")
+
+	appURL, err := url.Parse(c.AppURL)
+	check(err == nil && (appURL.Scheme == "http" || appURL.Scheme == "https") && appURL.Host != "", "APP_URL must be an http or https URL, got %q", c.AppURL)
+
+	if c.OIDC.Issuer != "" {
+		issuer, err := url.Parse(c.OIDC.Issuer)
+		check(err == nil && (issuer.Scheme == "https" || issuer.Scheme == "http") && issuer.Host != "", "OIDC_ISSUER must be an http or https URL, got %q", c.OIDC.Issuer)
+		check(c.OIDC.ClientID != "", "OIDC_CLIENT_ID is required when OIDC_ISSUER is set")
+
+		redirect, err := url.Parse(c.OIDC.RedirectURL)
+		check(err == nil && (redirect.Scheme == "https" || redirect.Scheme == "http") && redirect.Host != "", "OIDC_REDIRECT_URL must be an http or https URL, got %q", c.OIDC.RedirectURL)
+
+		hasOpenID := false
+		for _, scope := range c.OIDC.Scopes {
+			hasOpenID = hasOpenID || scope == "openid"
+		}
+		check(hasOpenID, "OIDC_SCOPES must include openid")
+	}
+
+	for _, proxy := range c.HTTP.TrustedProxies {
+		_, _, err := net.ParseCIDR(proxy)
+		check(err == nil || net.ParseIP(proxy) != nil, "TRUSTED_PROXIES has an invalid address or CIDR range %q", proxy)
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		u, err := url.Parse(origin)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && (u.Path == "" || u.Path == "/"), "CORS_ALLOWED_ORIGINS has an invalid origin %q", origin)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("config: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Reads typed values, collecting an error for each value that cannot be parsed
+type parser struct {
+	lookup func(key string) string
+	errs   []string
+}
+
+func (p *parser) string(key string, fallback string) string {
+	if value := strings.TrimSpace(p.lookup(key)); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func (p *parser) int(key string, fallback int) int {
+	value := p.string(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Sprintf("%s must be an integer, got %q", key, value))
+		return fallback
+	}
+	return n
+}
+
+func (p *parser) bool(key string, fallback bool) bool {
+	value := p.string(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Sprintf("%s must be true or false, got %q", key, value))
+		return fallback
+	}
+	return b
+}
+
+func (p *parser) duration(key string, fallback time.Duration) time.Duration {
+	value := p.string(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Sprintf("%s must be a duration such as 30s or 5m, got %q", key, value))
+		return fallback
+	}
+	return d
+}
+
+func (p *parser) list(key string, fallback []string) []string {
+	value := p.string(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,74 @@
+// Package dataloaders batches the per-row lookups made by field resolvers, so a
+// list of entries is resolved with one query per field instead of one per entry.
+package dataloaders
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+)
+
+// How long a loader waits for more keys before running its query
+const batchWait = 2 * time.Millisecond
+
+// The most keys sent in a single query
+const maxBatchSize = 500
+
+var loadersCtxKey = &contextKey{"dataloaders"}
+
+type contextKey struct {
+	name string
+}
+
+type Loaders struct {
+	Contests          *Loader[int, *model.Contest]
+	Contestants       *Loader[string, *model.Contestant]
+	Entries           *Loader[int, *model.Entry]
+	EntryAverageScore *Loader[int, *float64]
+	EntryEvaluations  *Loader[int, int]
+	EntryVotes        *Loader[int, int]
+	EntryVotedByUser  *Loader[int, bool]
+	JudgingGroups     *Loader[int, *model.JudgingGroup]
+	Users             *Loader[int, *model.User]
+}
+
+// Creates a new set of loaders. Loaders cache their results, so a new set should be created for each request.
+func NewLoaders(repos *models.Repositories) *Loaders {
+	return &Loaders{
+		Contests:          NewLoader(repos.Contests.GetContestsByIds, batchWait, maxBatchSize),
+		Contestants:       NewLoader(repos.Contestants.GetContestantsByKaids, batchWait, maxBatchSize),
+		Entries:           NewLoader(repos.Entries.GetEntriesByIds, batchWait, maxBatchSize),
+		EntryAverageScore: NewLoader(repos.Entries.GetEntryAverageScores, batchWait, maxBatchSize),
+		EntryEvaluations:  NewLoader(repos.Entries.GetEntryEvaluationCounts, batchWait, maxBatchSize),
+		EntryVotes:        NewLoader(repos.Entries.GetEntryVoteCounts, batchWait, maxBatchSize),
+		EntryVotedByUser: NewLoader(func(ctx context.Context, ids []int) (map[int]bool, error) {
+			user := auth.GetUserFromContext(ctx)
+			if user == nil {
+				return map[int]bool{}, nil
+			}
+			return repos.Entries.GetEntriesVotedByUser(ctx, user.ID, ids)
+		}, batchWait, maxBatchSize),
+		JudgingGroups: NewLoader(repos.Judging.GetJudgingGroupsByIds, batchWait, maxBatchSize),
+		Users:         NewLoader(repos.Users.GetUsersByIds, batchWait, maxBatchSize),
+	}
+}
+
+// Attaches a fresh set of loaders to each request
+func Middleware(repos *models.Repositories) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), loadersCtxKey, NewLoaders(repos))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// For finds the loaders from the context. Returns nil if Middleware has not run.
+func For(ctx context.Context) *Loaders {
+	loaders, _ := ctx.Value(loadersCtxKey).(*Loaders)
+	return loaders
+}
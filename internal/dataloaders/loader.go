@@ -0,0 +1,102 @@
+package dataloaders
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Fetches the values for a batch of keys. Keys that have no value can be left out of the map.
+type FetchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Collects the keys requested within a short window and fetches them all at once.
+// Results are cached for the lifetime of the loader, which is a single request.
+type Loader[K comparable, V any] struct {
+	fetch    FetchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	cache   map[K]*result[V]
+	pending *batch[K, V]
+}
+
+type result[V any] struct {
+	value V
+	err   error
+	done  chan struct{}
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	results []*result[V]
+	full    chan struct{}
+}
+
+func NewLoader[K comparable, V any](fetch FetchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		cache:    map[K]*result[V]{},
+	}
+}
+
+// Returns the value for the key, waiting for its batch to be fetched.
+// The zero value is returned if the key does not exist.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+
+	if r, ok := l.cache[key]; ok {
+		l.mu.Unlock()
+		<-r.done
+		return r.value, r.err
+	}
+
+	r := &result[V]{done: make(chan struct{})}
+	l.cache[key] = r
+
+	if l.pending == nil {
+		l.pending = &batch[K, V]{full: make(chan struct{})}
+		go l.dispatch(ctx, l.pending)
+	}
+
+	b := l.pending
+	b.keys = append(b.keys, key)
+	b.results = append(b.results, r)
+
+	// Start a new batch once this one is full, so no single query grows unbounded
+	if len(b.keys) >= l.maxBatch {
+		l.pending = nil
+		close(b.full)
+	}
+
+	l.mu.Unlock()
+
+	<-r.done
+	return r.value, r.err
+}
+
+// Waits for the batch to fill up or for the wait window to pass, then fetches it
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	select {
+	case <-b.full:
+	case <-time.After(l.wait):
+		l.mu.Lock()
+		if l.pending == b {
+			l.pending = nil
+		}
+		l.mu.Unlock()
+	}
+
+	values, err := l.fetch(ctx, b.keys)
+	for i, key := range b.keys {
+		r := b.results[i]
+		if err != nil {
+			r.err = err
+		} else {
+			r.value = values[key]
+		}
+		close(r.done)
+	}
+}
@@ -0,0 +1,136 @@
+package dataloaders
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoader(t *testing.T) {
+	errFetch := errors.New("fetch failed")
+
+	tests := []struct {
+		name     string
+		keys     []int
+		maxBatch int
+		err      error
+		// The number of fetches, and the keys fetched across all of them
+		wantBatches int
+		wantFetched []int
+		want        map[int]string
+	}{
+		{
+			name:        "concurrent loads share one batch",
+			keys:        []int{1, 2, 3},
+			maxBatch:    100,
+			wantBatches: 1,
+			wantFetched: []int{1, 2, 3},
+			want:        map[int]string{1: "one", 2: "two", 3: "three"},
+		},
+		{
+			name:        "duplicate keys are fetched once",
+			keys:        []int{1, 1, 2, 2, 2},
+			maxBatch:    100,
+			wantBatches: 1,
+			wantFetched: []int{1, 2},
+			want:        map[int]string{1: "one", 2: "two"},
+		},
+		{
+			name:        "missing keys get the zero value",
+			keys:        []int{1, 4},
+			maxBatch:    100,
+			wantBatches: 1,
+			wantFetched: []int{1, 4},
+			want:        map[int]string{1: "one", 4: ""},
+		},
+		{
+			name:        "full batches are split",
+			keys:        []int{1, 2, 3},
+			maxBatch:    2,
+			wantBatches: 2,
+			wantFetched: []int{1, 2, 3},
+			want:        map[int]string{1: "one", 2: "two", 3: "three"},
+		},
+		{
+			name:        "a batch error reaches every key",
+			keys:        []int{1, 2},
+			maxBatch:    100,
+			err:         errFetch,
+			wantBatches: 1,
+			wantFetched: []int{1, 2},
+			want:        map[int]string{1: "", 2: ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			batches := 0
+			fetched := []int{}
+
+			loader := NewLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+				mu.Lock()
+				batches++
+				fetched = append(fetched, keys...)
+				mu.Unlock()
+
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				values := map[int]string{}
+				for _, key := range keys {
+					if name, ok := map[int]string{1: "one", 2: "two", 3: "three"}[key]; ok {
+						values[key] = name
+					}
+				}
+				return values, nil
+			}, 10*time.Millisecond, tt.maxBatch)
+
+			values := make([]string, len(tt.keys))
+			errs := make([]error, len(tt.keys))
+			var wg sync.WaitGroup
+			for i, key := range tt.keys {
+				wg.Add(1)
+				go func(i int, key int) {
+					defer wg.Done()
+					values[i], errs[i] = loader.Load(context.Background(), key)
+				}(i, key)
+			}
+			wg.Wait()
+
+			for i, key := range tt.keys {
+				if errs[i] != tt.err {
+					t.Errorf("Load(%d) error = %v, want %v", key, errs[i], tt.err)
+				}
+				if values[i] != tt.want[key] {
+					t.Errorf("Load(%d) = %q, want %q", key, values[i], tt.want[key])
+				}
+			}
+
+			sort.Ints(fetched)
+			if batches != tt.wantBatches || !equalInts(fetched, tt.wantFetched) {
+				t.Fatalf("got %d fetches of keys %v, want %d fetches of keys %v", batches, fetched, tt.wantBatches, tt.wantFetched)
+			}
+
+			// Loaded keys are cached for the rest of the request
+			if _, err := loader.Load(context.Background(), tt.keys[0]); err != tt.err || batches != tt.wantBatches {
+				t.Fatalf("loading %d again fetched it again or changed its error", tt.keys[0])
+			}
+		})
+	}
+}
+
+func equalInts(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,25 +1,24 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"log"
-	"os"
+	"time"
 
+	"github.com/KA-Challenge-Council/Bema/internal/config"
 	_ "github.com/lib/pq"
 )
 
 var DB *sql.DB
 
-func InitDB() {
-	var connStr string
-	if os.Getenv("DATABASE_URL") != "" {
-		connStr = os.Getenv("DATABASE_URL")
-	} else if os.Getenv("APP_STATE") == "dev" {
-		connStr = os.Getenv("DEV_DB_URL")
-	} else {
-		connStr = os.Getenv("PROD_DB_URL")
-	}
-	db, err := sql.Open("postgres", connStr)
+// The longest a single model operation may run before it is cancelled. A value
+// of zero disables the deadline so only the request context applies.
+var QueryTimeout = 10 * time.Second
+
+// Opens the connection pool described by cfg and stores it in DB
+func InitDB(cfg config.Database) {
+	db, err := sql.Open("postgres", cfg.URL)
 	if err != nil {
 		log.Panic(err)
 	}
@@ -28,9 +27,22 @@ func InitDB() {
 		log.Panic(err)
 	}
 
-	db.SetMaxOpenConns(10)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	QueryTimeout = cfg.QueryTimeout
 
 	DB = db
 
 	log.Println("Connection to database established")
 }
+
+// Returns a copy of ctx that is cancelled once QueryTimeout has elapsed. The
+// returned cancel func must always be called to release the timer.
+func WithQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, QueryTimeout)
+}
@@ -0,0 +1,216 @@
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migration files are named <version>_<name>.up.sql and <version>_<name>.down.sql
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Arbitrary key used to serialize migrations run from several processes at once
+const migrationLockKey = 4242
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Loads the embedded migrations, sorted by version
+func LoadMigrations() ([]Migration, error) {
+	files, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, f := range files {
+		name := f.Name()
+
+		var direction string
+		if strings.HasSuffix(name, ".up.sql") {
+			direction = "up"
+		} else if strings.HasSuffix(name, ".down.sql") {
+			direction = "down"
+		} else {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimSuffix(name, "."+direction+".sql"), "_", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid migration file name %q", name)
+		}
+
+		version, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", name, err)
+		}
+
+		contents, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[1]}
+			byVersion[version] = m
+		}
+
+		if direction == "up" {
+			m.Up = string(contents)
+		} else {
+			m.Down = string(contents)
+		}
+	}
+
+	migrations := []Migration{}
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s is missing its up or down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Returns the version of the newest embedded migration, which is the schema version this build expects
+func LatestMigrationVersion() (int, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+	return migrations[len(migrations)-1].Version, nil
+}
+
+// Returns the version of the most recently applied migration, or 0 if none have been applied
+func SchemaVersion(db *sql.DB) (int, error) {
+	if err := createMigrationsTable(db); err != nil {
+		return 0, err
+	}
+
+	var version int
+	row := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations;")
+	if err := row.Scan(&version); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// Applies all pending migrations
+func Migrate(db *sql.DB) error {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return err
+	}
+
+	if err := createMigrationsTable(db); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		applied, err := applyMigration(db, m, true)
+		if err != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+		if applied {
+			log.Printf("Applied migration %d_%s", m.Version, m.Name)
+		}
+	}
+
+	return nil
+}
+
+// Reverts the given number of most recently applied migrations
+func MigrateDown(db *sql.DB, steps int) error {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return err
+	}
+
+	if err := createMigrationsTable(db); err != nil {
+		return err
+	}
+
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		m := migrations[i]
+		reverted, err := applyMigration(db, m, false)
+		if err != nil {
+			return fmt.Errorf("reverting migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+		if reverted {
+			log.Printf("Reverted migration %d_%s", m.Version, m.Name)
+			steps--
+		}
+	}
+
+	return nil
+}
+
+func createMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version INT PRIMARY KEY, name TEXT NOT NULL, applied_tstz TIMESTAMPTZ NOT NULL DEFAULT now());")
+	return err
+}
+
+// Runs a single migration in its own transaction. Returns false if there was nothing to do,
+// which happens when the migration was already applied (up) or was never applied (down).
+func applyMigration(db *sql.DB, m Migration, up bool) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Hold the lock until the transaction ends so concurrent migrators wait their turn
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1);", migrationLockKey); err != nil {
+		return false, err
+	}
+
+	var count int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = $1;", m.Version).Scan(&count); err != nil {
+		return false, err
+	}
+
+	isApplied := count > 0
+	if isApplied == up {
+		return false, nil
+	}
+
+	if up {
+		if _, err := tx.Exec(m.Up); err != nil {
+			return false, err
+		}
+		if _, err := tx.Exec("INSERT INTO schema_migrations (version, name) VALUES ($1, $2);", m.Version, m.Name); err != nil {
+			return false, err
+		}
+	} else {
+		if _, err := tx.Exec(m.Down); err != nil {
+			return false, err
+		}
+		if _, err := tx.Exec("DELETE FROM schema_migrations WHERE version = $1;", m.Version); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS kb_article_draft;
+DROP TABLE IF EXISTS kb_article;
+DROP TABLE IF EXISTS kb_section;
+DROP TABLE IF EXISTS error;
+DROP TABLE IF EXISTS task;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS judging_criteria;
+DROP TABLE IF EXISTS entry_vote;
+DROP TABLE IF EXISTS evaluation;
+DROP TABLE IF EXISTS entry;
+DROP TABLE IF EXISTS user_session;
+DROP TABLE IF EXISTS evaluator_permissions;
+DROP TABLE IF EXISTS evaluator;
+DROP TABLE IF EXISTS evaluator_group;
+DROP TABLE IF EXISTS contest;
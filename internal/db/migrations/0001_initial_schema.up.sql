@@ -0,0 +1,190 @@
+-- The initial Bema schema. Every statement is idempotent so that this migration
+-- can also be applied to databases that were created before migrations existed.
+
+CREATE TABLE IF NOT EXISTS contest (
+    contest_id SERIAL PRIMARY KEY,
+    contest_name TEXT NOT NULL,
+    contest_url TEXT,
+    contest_author TEXT,
+    date_start DATE,
+    date_end DATE,
+    current BOOLEAN NOT NULL DEFAULT false,
+    voting_enabled BOOLEAN NOT NULL DEFAULT false,
+    badge_name TEXT,
+    badge_image_url TEXT
+);
+
+CREATE TABLE IF NOT EXISTS evaluator_group (
+    group_id SERIAL PRIMARY KEY,
+    group_name TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true
+);
+
+CREATE TABLE IF NOT EXISTS evaluator (
+    evaluator_id SERIAL PRIMARY KEY,
+    evaluator_kaid TEXT NOT NULL,
+    evaluator_name TEXT,
+    nickname TEXT NOT NULL DEFAULT '',
+    username TEXT UNIQUE,
+    email TEXT,
+    password TEXT,
+    account_locked BOOLEAN NOT NULL DEFAULT false,
+    is_admin BOOLEAN NOT NULL DEFAULT false,
+    logged_in_tstz TIMESTAMPTZ,
+    dt_term_start DATE,
+    dt_term_end DATE,
+    receive_emails BOOLEAN NOT NULL DEFAULT true,
+    group_id INT REFERENCES evaluator_group (group_id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS evaluator_permissions (
+    evaluator_id INT PRIMARY KEY REFERENCES evaluator (evaluator_id) ON DELETE CASCADE,
+    view_admin_stats BOOLEAN NOT NULL DEFAULT false,
+    edit_contests BOOLEAN NOT NULL DEFAULT false,
+    delete_contests BOOLEAN NOT NULL DEFAULT false,
+    add_entries BOOLEAN NOT NULL DEFAULT false,
+    edit_entries BOOLEAN NOT NULL DEFAULT false,
+    delete_entries BOOLEAN NOT NULL DEFAULT false,
+    assign_entry_groups BOOLEAN NOT NULL DEFAULT false,
+    view_all_evaluations BOOLEAN NOT NULL DEFAULT false,
+    edit_all_evaluations BOOLEAN NOT NULL DEFAULT false,
+    delete_all_evaluations BOOLEAN NOT NULL DEFAULT false,
+    manage_winners BOOLEAN NOT NULL DEFAULT false,
+    view_all_tasks BOOLEAN NOT NULL DEFAULT false,
+    edit_all_tasks BOOLEAN NOT NULL DEFAULT false,
+    delete_all_tasks BOOLEAN NOT NULL DEFAULT false,
+    view_judging_settings BOOLEAN NOT NULL DEFAULT false,
+    manage_judging_groups BOOLEAN NOT NULL DEFAULT false,
+    assign_evaluator_groups BOOLEAN NOT NULL DEFAULT false,
+    manage_judging_criteria BOOLEAN NOT NULL DEFAULT false,
+    view_all_users BOOLEAN NOT NULL DEFAULT false,
+    edit_user_profiles BOOLEAN NOT NULL DEFAULT false,
+    change_user_passwords BOOLEAN NOT NULL DEFAULT false,
+    assume_user_identities BOOLEAN NOT NULL DEFAULT false,
+    add_users BOOLEAN NOT NULL DEFAULT false,
+    view_errors BOOLEAN NOT NULL DEFAULT false,
+    delete_errors BOOLEAN NOT NULL DEFAULT false,
+    judge_entries BOOLEAN NOT NULL DEFAULT false,
+    edit_kb_content BOOLEAN NOT NULL DEFAULT false,
+    delete_kb_content BOOLEAN NOT NULL DEFAULT false,
+    publish_kb_content BOOLEAN NOT NULL DEFAULT false,
+    manage_announcements BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE TABLE IF NOT EXISTS user_session (
+    session_id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES evaluator (evaluator_id) ON DELETE CASCADE,
+    token TEXT NOT NULL UNIQUE,
+    expires TIMESTAMPTZ NOT NULL,
+    impersonated_by_user INT REFERENCES evaluator (evaluator_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS entry (
+    entry_id SERIAL PRIMARY KEY,
+    contest_id INT NOT NULL REFERENCES contest (contest_id) ON DELETE CASCADE,
+    entry_url TEXT NOT NULL,
+    entry_kaid TEXT NOT NULL,
+    entry_title TEXT NOT NULL,
+    entry_author TEXT,
+    entry_author_kaid TEXT,
+    entry_level TEXT NOT NULL DEFAULT 'TBD',
+    entry_level_locked BOOLEAN NOT NULL DEFAULT false,
+    entry_votes INT NOT NULL DEFAULT 0,
+    entry_created TIMESTAMPTZ NOT NULL DEFAULT now(),
+    entry_height INT NOT NULL DEFAULT 400,
+    is_winner BOOLEAN NOT NULL DEFAULT false,
+    assigned_group_id INT REFERENCES evaluator_group (group_id) ON DELETE SET NULL,
+    flagged BOOLEAN NOT NULL DEFAULT false,
+    flag_reason TEXT,
+    disqualified BOOLEAN NOT NULL DEFAULT false,
+    UNIQUE (contest_id, entry_kaid)
+);
+
+CREATE INDEX IF NOT EXISTS entry_author_kaid_idx ON entry (entry_author_kaid);
+
+CREATE TABLE IF NOT EXISTS evaluation (
+    evaluation_id SERIAL PRIMARY KEY,
+    entry_id INT NOT NULL REFERENCES entry (entry_id) ON DELETE CASCADE,
+    evaluator_id INT NOT NULL REFERENCES evaluator (evaluator_id) ON DELETE CASCADE,
+    creativity REAL,
+    complexity REAL,
+    execution REAL,
+    interpretation REAL,
+    evaluation_level TEXT,
+    evaluation_tstz TIMESTAMPTZ NOT NULL DEFAULT now(),
+    evaluation_complete BOOLEAN NOT NULL DEFAULT false,
+    UNIQUE (entry_id, evaluator_id)
+);
+
+CREATE INDEX IF NOT EXISTS evaluation_evaluator_id_idx ON evaluation (evaluator_id);
+
+CREATE TABLE IF NOT EXISTS entry_vote (
+    vote_id SERIAL PRIMARY KEY,
+    entry_id INT NOT NULL REFERENCES entry (entry_id) ON DELETE CASCADE,
+    evaluator_id INT NOT NULL REFERENCES evaluator (evaluator_id) ON DELETE CASCADE,
+    feedback TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS judging_criteria (
+    criteria_id SERIAL PRIMARY KEY,
+    criteria_name TEXT NOT NULL,
+    criteria_description TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    sort_order INT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS messages (
+    message_id SERIAL PRIMARY KEY,
+    author_id INT REFERENCES evaluator (evaluator_id) ON DELETE SET NULL,
+    message_date TIMESTAMPTZ NOT NULL DEFAULT now(),
+    message_title TEXT NOT NULL,
+    message_content TEXT NOT NULL,
+    public BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE TABLE IF NOT EXISTS task (
+    task_id SERIAL PRIMARY KEY,
+    task_title TEXT NOT NULL,
+    assigned_member INT REFERENCES evaluator (evaluator_id) ON DELETE SET NULL,
+    task_status TEXT NOT NULL DEFAULT 'Not Started',
+    due_date DATE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS error (
+    error_id SERIAL PRIMARY KEY,
+    error_message TEXT NOT NULL,
+    error_stack TEXT,
+    error_tstz TIMESTAMPTZ NOT NULL DEFAULT now(),
+    request_origin TEXT,
+    request_referer TEXT,
+    user_agent TEXT,
+    evaluator_id INT REFERENCES evaluator (evaluator_id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS kb_section (
+    section_id SERIAL PRIMARY KEY,
+    section_name TEXT NOT NULL,
+    section_description TEXT NOT NULL,
+    section_visibility TEXT NOT NULL DEFAULT 'Public'
+);
+
+CREATE TABLE IF NOT EXISTS kb_article (
+    article_id SERIAL PRIMARY KEY,
+    section_id INT NOT NULL REFERENCES kb_section (section_id),
+    article_name TEXT NOT NULL,
+    article_content TEXT NOT NULL,
+    article_author INT REFERENCES evaluator (evaluator_id) ON DELETE SET NULL,
+    article_last_updated TIMESTAMPTZ NOT NULL DEFAULT now(),
+    article_visibility TEXT NOT NULL DEFAULT 'Public',
+    is_published BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE TABLE IF NOT EXISTS kb_article_draft (
+    draft_id SERIAL PRIMARY KEY,
+    article_id INT NOT NULL REFERENCES kb_article (article_id) ON DELETE CASCADE,
+    draft_name TEXT NOT NULL,
+    draft_content TEXT NOT NULL,
+    draft_author INT REFERENCES evaluator (evaluator_id) ON DELETE SET NULL,
+    draft_last_updated TIMESTAMPTZ NOT NULL DEFAULT now(),
+    is_published BOOLEAN NOT NULL DEFAULT false
+);
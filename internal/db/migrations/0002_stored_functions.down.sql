@@ -0,0 +1,4 @@
+DROP FUNCTION IF EXISTS update_entry_level(INT);
+DROP FUNCTION IF EXISTS evaluate(INT, INT, REAL, REAL, REAL, REAL, TEXT);
+DROP FUNCTION IF EXISTS get_entry_and_create_placeholder(INT);
+DROP FUNCTION IF EXISTS log_error(TEXT, TEXT, INT, TEXT, TEXT, TEXT);
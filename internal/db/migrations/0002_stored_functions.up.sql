@@ -0,0 +1,112 @@
+-- Stored functions called from the Go code.
+
+-- Records an application error. Called by errors.NewInternalError.
+CREATE OR REPLACE FUNCTION log_error(
+    p_message TEXT,
+    p_stack TEXT,
+    p_evaluator_id INT,
+    p_origin TEXT,
+    p_referer TEXT,
+    p_user_agent TEXT
+) RETURNS VOID AS $$
+BEGIN
+    INSERT INTO error (error_message, error_stack, error_tstz, request_origin, request_referer, user_agent, evaluator_id)
+    VALUES (p_message, p_stack, now(), p_origin, p_referer, p_user_agent, p_evaluator_id);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Returns the next entry an evaluator should score, and reserves it by creating
+-- an incomplete placeholder evaluation. An entry the evaluator already started is
+-- returned first. Returns an entry id of -1 when there is nothing left to judge.
+CREATE OR REPLACE FUNCTION get_entry_and_create_placeholder(p_evaluator_id INT)
+RETURNS TABLE (o_entry_id INT, o_entry_url TEXT, o_entry_title TEXT, o_entry_height INT) AS $$
+DECLARE
+    v_group_id INT;
+    v_contest_id INT;
+    v_entry entry%ROWTYPE;
+BEGIN
+    SELECT group_id INTO v_group_id FROM evaluator WHERE evaluator_id = p_evaluator_id;
+    SELECT contest_id INTO v_contest_id FROM contest WHERE current = true ORDER BY contest_id DESC LIMIT 1;
+
+    SELECT e.* INTO v_entry
+    FROM entry e
+    INNER JOIN evaluation ev ON ev.entry_id = e.entry_id
+    WHERE ev.evaluator_id = p_evaluator_id
+        AND ev.evaluation_complete = false
+        AND e.contest_id = v_contest_id
+        AND e.flagged = false
+        AND e.disqualified = false
+    ORDER BY ev.evaluation_id ASC
+    LIMIT 1;
+
+    IF NOT FOUND THEN
+        SELECT e.* INTO v_entry
+        FROM entry e
+        WHERE e.contest_id = v_contest_id
+            AND e.assigned_group_id = v_group_id
+            AND e.flagged = false
+            AND e.disqualified = false
+            AND NOT EXISTS (SELECT 1 FROM evaluation ev WHERE ev.entry_id = e.entry_id AND ev.evaluator_id = p_evaluator_id)
+        ORDER BY (SELECT COUNT(*) FROM evaluation ev WHERE ev.entry_id = e.entry_id) ASC, random()
+        LIMIT 1;
+
+        IF NOT FOUND THEN
+            RETURN QUERY SELECT -1, NULL::TEXT, NULL::TEXT, NULL::INT;
+            RETURN;
+        END IF;
+
+        INSERT INTO evaluation (entry_id, evaluator_id, evaluation_complete)
+        VALUES (v_entry.entry_id, p_evaluator_id, false);
+    END IF;
+
+    RETURN QUERY SELECT v_entry.entry_id, v_entry.entry_url, v_entry.entry_title, v_entry.entry_height;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Completes an evaluator's evaluation of an entry, filling in the placeholder
+-- created by get_entry_and_create_placeholder if one exists.
+CREATE OR REPLACE FUNCTION evaluate(
+    p_entry_id INT,
+    p_evaluator_id INT,
+    p_creativity REAL,
+    p_complexity REAL,
+    p_execution REAL,
+    p_interpretation REAL,
+    p_level TEXT
+) RETURNS VOID AS $$
+BEGIN
+    UPDATE evaluation
+    SET creativity = p_creativity,
+        complexity = p_complexity,
+        execution = p_execution,
+        interpretation = p_interpretation,
+        evaluation_level = p_level,
+        evaluation_tstz = now(),
+        evaluation_complete = true
+    WHERE entry_id = p_entry_id AND evaluator_id = p_evaluator_id;
+
+    IF NOT FOUND THEN
+        INSERT INTO evaluation (entry_id, evaluator_id, creativity, complexity, execution, interpretation, evaluation_level, evaluation_tstz, evaluation_complete)
+        VALUES (p_entry_id, p_evaluator_id, p_creativity, p_complexity, p_execution, p_interpretation, p_level, now(), true);
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Sets an unlocked entry's skill level to the level suggested most often by its
+-- completed evaluations.
+CREATE OR REPLACE FUNCTION update_entry_level(p_entry_id INT) RETURNS VOID AS $$
+DECLARE
+    v_level TEXT;
+BEGIN
+    SELECT evaluation_level INTO v_level
+    FROM evaluation
+    WHERE entry_id = p_entry_id AND evaluation_complete = true
+    GROUP BY evaluation_level
+    ORDER BY COUNT(*) DESC, MAX(evaluation_tstz) DESC
+    LIMIT 1;
+
+    IF v_level IS NOT NULL THEN
+        UPDATE entry SET entry_level = v_level WHERE entry_id = p_entry_id AND entry_level_locked = false;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
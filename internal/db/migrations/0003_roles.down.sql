@@ -0,0 +1,76 @@
+CREATE TABLE evaluator_permissions (
+    evaluator_id INT PRIMARY KEY REFERENCES evaluator (evaluator_id) ON DELETE CASCADE,
+    view_admin_stats BOOLEAN NOT NULL DEFAULT false,
+    edit_contests BOOLEAN NOT NULL DEFAULT false,
+    delete_contests BOOLEAN NOT NULL DEFAULT false,
+    add_entries BOOLEAN NOT NULL DEFAULT false,
+    edit_entries BOOLEAN NOT NULL DEFAULT false,
+    delete_entries BOOLEAN NOT NULL DEFAULT false,
+    assign_entry_groups BOOLEAN NOT NULL DEFAULT false,
+    view_all_evaluations BOOLEAN NOT NULL DEFAULT false,
+    edit_all_evaluations BOOLEAN NOT NULL DEFAULT false,
+    delete_all_evaluations BOOLEAN NOT NULL DEFAULT false,
+    manage_winners BOOLEAN NOT NULL DEFAULT false,
+    view_all_tasks BOOLEAN NOT NULL DEFAULT false,
+    edit_all_tasks BOOLEAN NOT NULL DEFAULT false,
+    delete_all_tasks BOOLEAN NOT NULL DEFAULT false,
+    view_judging_settings BOOLEAN NOT NULL DEFAULT false,
+    manage_judging_groups BOOLEAN NOT NULL DEFAULT false,
+    assign_evaluator_groups BOOLEAN NOT NULL DEFAULT false,
+    manage_judging_criteria BOOLEAN NOT NULL DEFAULT false,
+    view_all_users BOOLEAN NOT NULL DEFAULT false,
+    edit_user_profiles BOOLEAN NOT NULL DEFAULT false,
+    change_user_passwords BOOLEAN NOT NULL DEFAULT false,
+    assume_user_identities BOOLEAN NOT NULL DEFAULT false,
+    add_users BOOLEAN NOT NULL DEFAULT false,
+    view_errors BOOLEAN NOT NULL DEFAULT false,
+    delete_errors BOOLEAN NOT NULL DEFAULT false,
+    judge_entries BOOLEAN NOT NULL DEFAULT false,
+    edit_kb_content BOOLEAN NOT NULL DEFAULT false,
+    delete_kb_content BOOLEAN NOT NULL DEFAULT false,
+    publish_kb_content BOOLEAN NOT NULL DEFAULT false,
+    manage_announcements BOOLEAN NOT NULL DEFAULT false
+);
+
+-- Flattens each user's roles and direct grants back into columns
+INSERT INTO evaluator_permissions (evaluator_id, view_admin_stats, edit_contests, delete_contests, add_entries, edit_entries, delete_entries, assign_entry_groups, view_all_evaluations, edit_all_evaluations, delete_all_evaluations, manage_winners, view_all_tasks, edit_all_tasks, delete_all_tasks, view_judging_settings, manage_judging_groups, assign_evaluator_groups, manage_judging_criteria, view_all_users, edit_user_profiles, change_user_passwords, assume_user_identities, add_users, view_errors, delete_errors, judge_entries, edit_kb_content, delete_kb_content, publish_kb_content, manage_announcements)
+SELECT e.evaluator_id,
+    'VIEW_ADMIN_STATS' = ANY (k.keys),
+    'EDIT_CONTESTS' = ANY (k.keys),
+    'DELETE_CONTESTS' = ANY (k.keys),
+    'ADD_ENTRIES' = ANY (k.keys),
+    'EDIT_ENTRIES' = ANY (k.keys),
+    'DELETE_ENTRIES' = ANY (k.keys),
+    'ASSIGN_ENTRY_GROUPS' = ANY (k.keys),
+    'VIEW_ALL_EVALUATIONS' = ANY (k.keys),
+    'EDIT_ALL_EVALUATIONS' = ANY (k.keys),
+    'DELETE_ALL_EVALUATIONS' = ANY (k.keys),
+    'MANAGE_WINNERS' = ANY (k.keys),
+    'VIEW_ALL_TASKS' = ANY (k.keys),
+    'EDIT_ALL_TASKS' = ANY (k.keys),
+    'DELETE_ALL_TASKS' = ANY (k.keys),
+    'VIEW_JUDGING_SETTINGS' = ANY (k.keys),
+    'MANAGE_JUDGING_GROUPS' = ANY (k.keys),
+    'ASSIGN_EVALUATOR_GROUPS' = ANY (k.keys),
+    'MANAGE_JUDGING_CRITERIA' = ANY (k.keys),
+    'VIEW_ALL_USERS' = ANY (k.keys),
+    'EDIT_USER_PROFILES' = ANY (k.keys),
+    'CHANGE_USER_PASSWORDS' = ANY (k.keys),
+    'ASSUME_USER_IDENTITIES' = ANY (k.keys),
+    'ADD_USERS' = ANY (k.keys),
+    'VIEW_ERRORS' = ANY (k.keys),
+    'DELETE_ERRORS' = ANY (k.keys),
+    'JUDGE_ENTRIES' = ANY (k.keys),
+    'EDIT_KB_CONTENT' = ANY (k.keys),
+    'DELETE_KB_CONTENT' = ANY (k.keys),
+    'PUBLISH_KB_CONTENT' = ANY (k.keys),
+    'MANAGE_ANNOUNCEMENTS' = ANY (k.keys)
+FROM evaluator e
+CROSS JOIN LATERAL (SELECT ARRAY(SELECT evaluator_permission_keys(e.evaluator_id)) AS keys) k;
+
+DROP FUNCTION IF EXISTS evaluator_permission_keys(INT);
+DROP TABLE IF EXISTS evaluator_permission;
+DROP TABLE IF EXISTS evaluator_role;
+DROP TABLE IF EXISTS role_permission;
+DROP TABLE IF EXISTS role;
+DROP TABLE IF EXISTS permission;
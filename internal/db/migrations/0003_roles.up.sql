@@ -0,0 +1,148 @@
+-- Replaces the boolean columns of evaluator_permissions with permissions stored as rows.
+-- A user holds the permissions of every role assigned to them plus any permissions
+-- granted to them directly. Adding a permission only needs a row in the permission table.
+
+CREATE TABLE permission (
+    permission_key TEXT PRIMARY KEY,
+    permission_description TEXT NOT NULL
+);
+
+INSERT INTO permission (permission_key, permission_description) VALUES
+    ('ADD_ENTRIES', 'Allows the user to add individual and bulk import entries.'),
+    ('ADD_USERS', 'Allows the user to create new user accounts.'),
+    ('ASSIGN_ENTRY_GROUPS', 'Allows the user to assign entries to judging groups.'),
+    ('ASSIGN_EVALUATOR_GROUPS', 'Allows the user to assign evaluators to judging groups.'),
+    ('ASSUME_USER_IDENTITIES', 'Allows the user to impersonate other users.'),
+    ('CHANGE_USER_PASSWORDS', 'Allows the user to change the passwords of other users.'),
+    ('DELETE_ALL_EVALUATIONS', 'Allows the user to delete all evaluations.'),
+    ('DELETE_ALL_TASKS', 'Allows the user to delete all tasks.'),
+    ('DELETE_CONTESTS', 'Allows the user to delete all contests and associated data.'),
+    ('DELETE_ENTRIES', 'Allows the user to delete all entries.'),
+    ('DELETE_ERRORS', 'Allows the user to delete all errors.'),
+    ('DELETE_KB_CONTENT', 'Allows the user to delete all KB articles and sections.'),
+    ('EDIT_ALL_EVALUATIONS', 'Allows the user to edit all evaluations.'),
+    ('EDIT_ALL_TASKS', 'Allows the user to edit all tasks.'),
+    ('EDIT_CONTESTS', 'Allows the user to edit all contests.'),
+    ('EDIT_ENTRIES', 'Allows the user to edit all entries.'),
+    ('EDIT_KB_CONTENT', 'Allows the user to edit all KB articles and sections.'),
+    ('EDIT_USER_PROFILES', 'Allows the user to edit all user profiles.'),
+    ('JUDGE_ENTRIES', 'Allows the user to score entries.'),
+    ('MANAGE_ANNOUNCEMENTS', 'Allows the user to create, edit, and delete announcements.'),
+    ('MANAGE_JUDGING_CRITERIA', 'Allows the user to create, edit, and delete judging criteria.'),
+    ('MANAGE_JUDGING_GROUPS', 'Allows the user to create, edit, and delete judging groups. Needs the assign_evaluator_groups permission to also assign users to groups..'),
+    ('MANAGE_WINNERS', 'Allows the user to add and remove winning entries.'),
+    ('PUBLISH_KB_CONTENT', 'Allows the user to publish draft KB articles.'),
+    ('VIEW_ADMIN_STATS', 'Allows the user to view admin stats on the dashboard.'),
+    ('VIEW_ALL_EVALUATIONS', 'Allows the user to view all evaluations.'),
+    ('VIEW_ALL_TASKS', 'Allows the user to view all tasks.'),
+    ('VIEW_ALL_USERS', 'Allows the user to view all user accounts.'),
+    ('VIEW_ERRORS', 'Allows the user to view all errors.'),
+    ('VIEW_JUDGING_SETTINGS', 'Allows the user to view all judging settings.');
+
+CREATE TABLE role (
+    role_id SERIAL PRIMARY KEY,
+    role_name TEXT NOT NULL UNIQUE,
+    role_description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE role_permission (
+    role_id INT NOT NULL REFERENCES role (role_id) ON DELETE CASCADE,
+    permission_key TEXT NOT NULL REFERENCES permission (permission_key) ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY (role_id, permission_key)
+);
+
+CREATE TABLE evaluator_role (
+    evaluator_id INT NOT NULL REFERENCES evaluator (evaluator_id) ON DELETE CASCADE,
+    role_id INT NOT NULL REFERENCES role (role_id) ON DELETE CASCADE,
+    PRIMARY KEY (evaluator_id, role_id)
+);
+
+CREATE INDEX evaluator_role_role_id_idx ON evaluator_role (role_id);
+
+-- Permissions granted to a single user outside of any role
+CREATE TABLE evaluator_permission (
+    evaluator_id INT NOT NULL REFERENCES evaluator (evaluator_id) ON DELETE CASCADE,
+    permission_key TEXT NOT NULL REFERENCES permission (permission_key) ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY (evaluator_id, permission_key)
+);
+
+INSERT INTO role (role_name, role_description) VALUES
+    ('Evaluator', 'Scores entries in their assigned judging group.'),
+    ('Lead', 'Runs judging: manages entries, groups and winners and sees everyone''s progress.'),
+    ('KB Editor', 'Writes, publishes and removes knowledge base content.'),
+    ('Admin', 'Holds every permission.');
+
+INSERT INTO role_permission (role_id, permission_key)
+SELECT r.role_id, k.permission_key
+FROM role r
+INNER JOIN (VALUES
+    ('Evaluator', 'JUDGE_ENTRIES'),
+    ('Lead', 'JUDGE_ENTRIES'),
+    ('Lead', 'VIEW_ADMIN_STATS'),
+    ('Lead', 'ADD_ENTRIES'),
+    ('Lead', 'EDIT_ENTRIES'),
+    ('Lead', 'ASSIGN_ENTRY_GROUPS'),
+    ('Lead', 'ASSIGN_EVALUATOR_GROUPS'),
+    ('Lead', 'MANAGE_JUDGING_GROUPS'),
+    ('Lead', 'VIEW_JUDGING_SETTINGS'),
+    ('Lead', 'VIEW_ALL_EVALUATIONS'),
+    ('Lead', 'VIEW_ALL_TASKS'),
+    ('Lead', 'VIEW_ALL_USERS'),
+    ('Lead', 'MANAGE_WINNERS'),
+    ('KB Editor', 'EDIT_KB_CONTENT'),
+    ('KB Editor', 'DELETE_KB_CONTENT'),
+    ('KB Editor', 'PUBLISH_KB_CONTENT')
+) AS k (role_name, permission_key) ON k.role_name = r.role_name;
+
+INSERT INTO role_permission (role_id, permission_key)
+SELECT r.role_id, p.permission_key FROM role r CROSS JOIN permission p WHERE r.role_name = 'Admin';
+
+-- Existing permissions carry over as direct grants so nobody gains or loses access
+INSERT INTO evaluator_permission (evaluator_id, permission_key)
+SELECT p.evaluator_id, v.permission_key
+FROM evaluator_permissions p
+CROSS JOIN LATERAL (VALUES
+        ('VIEW_ADMIN_STATS', p.view_admin_stats),
+        ('EDIT_CONTESTS', p.edit_contests),
+        ('DELETE_CONTESTS', p.delete_contests),
+        ('ADD_ENTRIES', p.add_entries),
+        ('EDIT_ENTRIES', p.edit_entries),
+        ('DELETE_ENTRIES', p.delete_entries),
+        ('ASSIGN_ENTRY_GROUPS', p.assign_entry_groups),
+        ('VIEW_ALL_EVALUATIONS', p.view_all_evaluations),
+        ('EDIT_ALL_EVALUATIONS', p.edit_all_evaluations),
+        ('DELETE_ALL_EVALUATIONS', p.delete_all_evaluations),
+        ('MANAGE_WINNERS', p.manage_winners),
+        ('VIEW_ALL_TASKS', p.view_all_tasks),
+        ('EDIT_ALL_TASKS', p.edit_all_tasks),
+        ('DELETE_ALL_TASKS', p.delete_all_tasks),
+        ('VIEW_JUDGING_SETTINGS', p.view_judging_settings),
+        ('MANAGE_JUDGING_GROUPS', p.manage_judging_groups),
+        ('ASSIGN_EVALUATOR_GROUPS', p.assign_evaluator_groups),
+        ('MANAGE_JUDGING_CRITERIA', p.manage_judging_criteria),
+        ('VIEW_ALL_USERS', p.view_all_users),
+        ('EDIT_USER_PROFILES', p.edit_user_profiles),
+        ('CHANGE_USER_PASSWORDS', p.change_user_passwords),
+        ('ASSUME_USER_IDENTITIES', p.assume_user_identities),
+        ('ADD_USERS', p.add_users),
+        ('VIEW_ERRORS', p.view_errors),
+        ('DELETE_ERRORS', p.delete_errors),
+        ('JUDGE_ENTRIES', p.judge_entries),
+        ('EDIT_KB_CONTENT', p.edit_kb_content),
+        ('DELETE_KB_CONTENT', p.delete_kb_content),
+        ('PUBLISH_KB_CONTENT', p.publish_kb_content),
+        ('MANAGE_ANNOUNCEMENTS', p.manage_announcements)
+) AS v (permission_key, granted)
+WHERE v.granted;
+
+DROP TABLE evaluator_permissions;
+
+-- Returns the key of every permission a user holds through their roles or direct grants
+CREATE FUNCTION evaluator_permission_keys(p_evaluator_id INT) RETURNS SETOF TEXT AS $$
+    SELECT rp.permission_key
+    FROM evaluator_role er
+    INNER JOIN role_permission rp ON rp.role_id = er.role_id
+    WHERE er.evaluator_id = p_evaluator_id
+    UNION
+    SELECT permission_key FROM evaluator_permission WHERE evaluator_id = p_evaluator_id;
+$$ LANGUAGE sql STABLE;
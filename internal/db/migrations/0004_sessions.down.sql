@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS user_session_expires_idx;
+DROP INDEX IF EXISTS user_session_user_id_idx;
+
+ALTER TABLE user_session
+    DROP COLUMN IF EXISTS user_agent,
+    DROP COLUMN IF EXISTS ip_address,
+    DROP COLUMN IF EXISTS last_seen_tstz,
+    DROP COLUMN IF EXISTS created_tstz;
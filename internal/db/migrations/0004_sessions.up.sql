@@ -0,0 +1,10 @@
+-- Records where each session was created and when it was last used, so users can
+-- review and end their sessions, and lets sessions expire after a period of inactivity.
+ALTER TABLE user_session
+    ADD COLUMN created_tstz TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN last_seen_tstz TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN ip_address TEXT,
+    ADD COLUMN user_agent TEXT;
+
+CREATE INDEX IF NOT EXISTS user_session_user_id_idx ON user_session (user_id);
+CREATE INDEX IF NOT EXISTS user_session_expires_idx ON user_session (expires);
@@ -0,0 +1,11 @@
+-- Counts recent failed logins per username and per IP address, and how long
+-- further attempts are refused once there have been too many.
+CREATE TABLE IF NOT EXISTS login_throttle (
+    login_throttle_id SERIAL PRIMARY KEY,
+    throttle_kind TEXT NOT NULL CHECK (throttle_kind IN ('USERNAME', 'IP_ADDRESS')),
+    throttle_value TEXT NOT NULL,
+    failed_count INT NOT NULL DEFAULT 0,
+    last_failed_tstz TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    UNIQUE (throttle_kind, throttle_value)
+);
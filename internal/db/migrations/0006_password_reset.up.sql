@@ -0,0 +1,12 @@
+-- Links emailed to users who have forgotten their password. Only a SHA-256 hash of
+-- each token is stored, and a token can be used once before it expires.
+CREATE TABLE IF NOT EXISTS password_reset (
+    password_reset_id SERIAL PRIMARY KEY,
+    evaluator_id INT NOT NULL REFERENCES evaluator (evaluator_id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_tstz TIMESTAMPTZ NOT NULL,
+    expires_tstz TIMESTAMPTZ NOT NULL,
+    used_tstz TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS password_reset_evaluator_id_idx ON password_reset (evaluator_id);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS two_factor_required_permission;
+DROP TABLE IF EXISTS login_challenge;
+DROP TABLE IF EXISTS recovery_code;
+
+ALTER TABLE evaluator
+    DROP COLUMN IF EXISTS totp_last_step,
+    DROP COLUMN IF EXISTS totp_enabled_tstz,
+    DROP COLUMN IF EXISTS totp_pending_secret,
+    DROP COLUMN IF EXISTS totp_secret;
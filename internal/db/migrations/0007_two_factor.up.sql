@@ -0,0 +1,31 @@
+-- TOTP two-factor authentication. A secret is pending until the user confirms it with a
+-- code, and totp_last_step stops a code from being used twice.
+ALTER TABLE evaluator
+    ADD COLUMN IF NOT EXISTS totp_secret TEXT,
+    ADD COLUMN IF NOT EXISTS totp_pending_secret TEXT,
+    ADD COLUMN IF NOT EXISTS totp_enabled_tstz TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS totp_last_step BIGINT;
+
+-- Single use codes for logging in without the authenticator. Only hashes are stored.
+CREATE TABLE IF NOT EXISTS recovery_code (
+    recovery_code_id SERIAL PRIMARY KEY,
+    evaluator_id INT NOT NULL REFERENCES evaluator (evaluator_id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_tstz TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS recovery_code_evaluator_id_idx ON recovery_code (evaluator_id);
+
+-- Logins where the password was correct and a two-factor code is still needed
+CREATE TABLE IF NOT EXISTS login_challenge (
+    login_challenge_id SERIAL PRIMARY KEY,
+    evaluator_id INT NOT NULL REFERENCES evaluator (evaluator_id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_tstz TIMESTAMPTZ NOT NULL,
+    failed_count INT NOT NULL DEFAULT 0
+);
+
+-- Users holding any of these permissions must enable two-factor authentication to use them
+CREATE TABLE IF NOT EXISTS two_factor_required_permission (
+    permission_key TEXT PRIMARY KEY REFERENCES permission (permission_key) ON DELETE CASCADE ON UPDATE CASCADE
+);
@@ -0,0 +1,21 @@
+-- Personal access tokens for scripts, sent in an Authorization: Bearer header. Only a SHA-256
+-- hash of each token is stored, with its first characters so users can tell them apart.
+CREATE TABLE IF NOT EXISTS api_token (
+    api_token_id SERIAL PRIMARY KEY,
+    evaluator_id INT NOT NULL REFERENCES evaluator (evaluator_id) ON DELETE CASCADE,
+    token_name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    token_prefix TEXT NOT NULL,
+    created_tstz TIMESTAMPTZ NOT NULL,
+    expires_tstz TIMESTAMPTZ,
+    last_used_tstz TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS api_token_evaluator_id_idx ON api_token (evaluator_id);
+
+-- The permissions a token may use. A token never has more access than its owner.
+CREATE TABLE IF NOT EXISTS api_token_permission (
+    api_token_id INT NOT NULL REFERENCES api_token (api_token_id) ON DELETE CASCADE,
+    permission_key TEXT NOT NULL REFERENCES permission (permission_key) ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY (api_token_id, permission_key)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS oidc_login_state;
+DROP TABLE IF EXISTS evaluator_oidc_identity;
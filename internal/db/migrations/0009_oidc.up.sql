@@ -0,0 +1,25 @@
+-- Links evaluators to their accounts at the OpenID Connect provider. A subject is only unique
+-- within its issuer.
+CREATE TABLE IF NOT EXISTS evaluator_oidc_identity (
+    oidc_identity_id SERIAL PRIMARY KEY,
+    evaluator_id INT NOT NULL REFERENCES evaluator (evaluator_id) ON DELETE CASCADE,
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    email TEXT,
+    linked_tstz TIMESTAMPTZ NOT NULL,
+    last_login_tstz TIMESTAMPTZ,
+    UNIQUE (issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS evaluator_oidc_identity_evaluator_id_idx ON evaluator_oidc_identity (evaluator_id);
+
+-- Logins that were sent to the provider and have not come back yet. The state is stored as a
+-- SHA-256 hash, with the PKCE verifier and nonce it was sent with.
+CREATE TABLE IF NOT EXISTS oidc_login_state (
+    oidc_login_state_id SERIAL PRIMARY KEY,
+    state_hash TEXT NOT NULL UNIQUE,
+    code_verifier TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    created_tstz TIMESTAMPTZ NOT NULL,
+    expires_tstz TIMESTAMPTZ NOT NULL
+);
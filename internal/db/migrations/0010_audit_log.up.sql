@@ -0,0 +1,31 @@
+-- An append-only record of privileged mutations. Actor ids are not foreign keys, so the record
+-- outlives the users it mentions.
+CREATE TABLE IF NOT EXISTS audit_log (
+    audit_log_id SERIAL PRIMARY KEY,
+    actor_id INT,
+    -- The user who was impersonating the actor
+    real_actor_id INT,
+    mutation_name TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_ids INT[] NOT NULL,
+    before_value JSONB,
+    after_value JSONB,
+    ip_address TEXT,
+    created_tstz TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_actor_id_idx ON audit_log (actor_id);
+CREATE INDEX IF NOT EXISTS audit_log_real_actor_id_idx ON audit_log (real_actor_id);
+CREATE INDEX IF NOT EXISTS audit_log_target_idx ON audit_log (target_type);
+CREATE INDEX IF NOT EXISTS audit_log_created_tstz_idx ON audit_log (created_tstz);
+
+-- Rows can be added but never changed or removed
+CREATE OR REPLACE FUNCTION reject_audit_log_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_log_change();
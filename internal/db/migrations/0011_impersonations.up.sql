@@ -0,0 +1,19 @@
+-- A record of each time a user impersonated another. The row outlives its session, so admins can
+-- review past impersonations.
+CREATE TABLE IF NOT EXISTS impersonation (
+    impersonation_id SERIAL PRIMARY KEY,
+    impersonator_id INT NOT NULL REFERENCES evaluator (evaluator_id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES evaluator (evaluator_id) ON DELETE CASCADE,
+    -- The session handed to the impersonator, until it ends
+    session_id INT UNIQUE REFERENCES user_session (session_id) ON DELETE SET NULL,
+    reason TEXT NOT NULL,
+    -- Read-only impersonations cannot run mutations
+    read_only BOOLEAN NOT NULL DEFAULT false,
+    ip_address TEXT,
+    started_tstz TIMESTAMPTZ NOT NULL,
+    expires_tstz TIMESTAMPTZ NOT NULL,
+    -- When the impersonator returned to their own account
+    ended_tstz TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS impersonation_started_tstz_idx ON impersonation (started_tstz);
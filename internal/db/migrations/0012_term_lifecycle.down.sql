@@ -0,0 +1,3 @@
+ALTER TABLE evaluator
+    DROP COLUMN IF EXISTS term_end_warned_date,
+    DROP COLUMN IF EXISTS term_locked_tstz;
@@ -0,0 +1,6 @@
+-- Term lifecycle. term_locked_tstz is set when an account is locked because its term ended, so it
+-- can be reactivated when the term is renewed; accounts locked by an admin are left alone.
+-- term_end_warned_date is the term end the user and admins were last warned about.
+ALTER TABLE evaluator
+    ADD COLUMN IF NOT EXISTS term_locked_tstz TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS term_end_warned_date DATE;
@@ -0,0 +1,19 @@
+-- Invitations to create an account. Only a hash of the link's token is stored.
+CREATE TABLE IF NOT EXISTS invitation (
+    invitation_id SERIAL PRIMARY KEY,
+    token_hash TEXT NOT NULL UNIQUE,
+    invitee_name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    invitee_kaid TEXT NOT NULL,
+    dt_term_start DATE NOT NULL,
+    dt_term_end DATE,
+    role_id INT REFERENCES role (role_id) ON DELETE SET NULL,
+    group_id INT REFERENCES evaluator_group (group_id) ON DELETE SET NULL,
+    invited_by INT REFERENCES evaluator (evaluator_id) ON DELETE SET NULL,
+    -- The account created when the invitation was accepted
+    evaluator_id INT REFERENCES evaluator (evaluator_id) ON DELETE SET NULL,
+    created_tstz TIMESTAMPTZ NOT NULL,
+    expires_tstz TIMESTAMPTZ NOT NULL,
+    accepted_tstz TIMESTAMPTZ,
+    revoked_tstz TIMESTAMPTZ
+);
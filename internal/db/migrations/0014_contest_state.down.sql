@@ -0,0 +1,57 @@
+ALTER TABLE contest
+    ADD COLUMN IF NOT EXISTS current BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS voting_enabled BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE contest SET
+    current = contest_state IN ('ACCEPTING_ENTRIES', 'JUDGING', 'VOTING'),
+    voting_enabled = contest_state = 'VOTING';
+
+ALTER TABLE contest DROP COLUMN IF EXISTS contest_state;
+
+-- Returns the next entry an evaluator should score, and reserves it by creating
+-- an incomplete placeholder evaluation. An entry the evaluator already started is
+-- returned first. Returns an entry id of -1 when there is nothing left to judge.
+CREATE OR REPLACE FUNCTION get_entry_and_create_placeholder(p_evaluator_id INT)
+RETURNS TABLE (o_entry_id INT, o_entry_url TEXT, o_entry_title TEXT, o_entry_height INT) AS $$
+DECLARE
+    v_group_id INT;
+    v_contest_id INT;
+    v_entry entry%ROWTYPE;
+BEGIN
+    SELECT group_id INTO v_group_id FROM evaluator WHERE evaluator_id = p_evaluator_id;
+    SELECT contest_id INTO v_contest_id FROM contest WHERE current = true ORDER BY contest_id DESC LIMIT 1;
+
+    SELECT e.* INTO v_entry
+    FROM entry e
+    INNER JOIN evaluation ev ON ev.entry_id = e.entry_id
+    WHERE ev.evaluator_id = p_evaluator_id
+        AND ev.evaluation_complete = false
+        AND e.contest_id = v_contest_id
+        AND e.flagged = false
+        AND e.disqualified = false
+    ORDER BY ev.evaluation_id ASC
+    LIMIT 1;
+
+    IF NOT FOUND THEN
+        SELECT e.* INTO v_entry
+        FROM entry e
+        WHERE e.contest_id = v_contest_id
+            AND e.assigned_group_id = v_group_id
+            AND e.flagged = false
+            AND e.disqualified = false
+            AND NOT EXISTS (SELECT 1 FROM evaluation ev WHERE ev.entry_id = e.entry_id AND ev.evaluator_id = p_evaluator_id)
+        ORDER BY (SELECT COUNT(*) FROM evaluation ev WHERE ev.entry_id = e.entry_id) ASC, random()
+        LIMIT 1;
+
+        IF NOT FOUND THEN
+            RETURN QUERY SELECT -1, NULL::TEXT, NULL::TEXT, NULL::INT;
+            RETURN;
+        END IF;
+
+        INSERT INTO evaluation (entry_id, evaluator_id, evaluation_complete)
+        VALUES (v_entry.entry_id, p_evaluator_id, false);
+    END IF;
+
+    RETURN QUERY SELECT v_entry.entry_id, v_entry.entry_url, v_entry.entry_title, v_entry.entry_height;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,65 @@
+-- Contest lifecycle. contest_state replaces the current and voting_enabled flags, and only
+-- moves between states through the transitions allowed in internal/models/contests.go.
+ALTER TABLE contest ADD COLUMN IF NOT EXISTS contest_state TEXT NOT NULL DEFAULT 'DRAFT'
+    CHECK (contest_state IN ('DRAFT', 'ACCEPTING_ENTRIES', 'JUDGING', 'VOTING', 'RESULTS_PUBLISHED', 'ARCHIVED'));
+
+-- Current contests still open for entries are accepting them, other current contests are being
+-- judged or voted on, and every other contest is over
+UPDATE contest SET contest_state = CASE
+    WHEN current AND voting_enabled THEN 'VOTING'
+    WHEN current AND date_end >= CURRENT_DATE THEN 'ACCEPTING_ENTRIES'
+    WHEN current THEN 'JUDGING'
+    ELSE 'ARCHIVED'
+END;
+
+ALTER TABLE contest
+    DROP COLUMN IF EXISTS current,
+    DROP COLUMN IF EXISTS voting_enabled;
+
+-- Returns the next entry an evaluator should score, and reserves it by creating
+-- an incomplete placeholder evaluation. An entry the evaluator already started is
+-- returned first. Returns an entry id of -1 when there is nothing left to judge.
+CREATE OR REPLACE FUNCTION get_entry_and_create_placeholder(p_evaluator_id INT)
+RETURNS TABLE (o_entry_id INT, o_entry_url TEXT, o_entry_title TEXT, o_entry_height INT) AS $$
+DECLARE
+    v_group_id INT;
+    v_contest_id INT;
+    v_entry entry%ROWTYPE;
+BEGIN
+    SELECT group_id INTO v_group_id FROM evaluator WHERE evaluator_id = p_evaluator_id;
+    SELECT contest_id INTO v_contest_id FROM contest WHERE contest_state = 'JUDGING' ORDER BY contest_id DESC LIMIT 1;
+
+    SELECT e.* INTO v_entry
+    FROM entry e
+    INNER JOIN evaluation ev ON ev.entry_id = e.entry_id
+    WHERE ev.evaluator_id = p_evaluator_id
+        AND ev.evaluation_complete = false
+        AND e.contest_id = v_contest_id
+        AND e.flagged = false
+        AND e.disqualified = false
+    ORDER BY ev.evaluation_id ASC
+    LIMIT 1;
+
+    IF NOT FOUND THEN
+        SELECT e.* INTO v_entry
+        FROM entry e
+        WHERE e.contest_id = v_contest_id
+            AND e.assigned_group_id = v_group_id
+            AND e.flagged = false
+            AND e.disqualified = false
+            AND NOT EXISTS (SELECT 1 FROM evaluation ev WHERE ev.entry_id = e.entry_id AND ev.evaluator_id = p_evaluator_id)
+        ORDER BY (SELECT COUNT(*) FROM evaluation ev WHERE ev.entry_id = e.entry_id) ASC, random()
+        LIMIT 1;
+
+        IF NOT FOUND THEN
+            RETURN QUERY SELECT -1, NULL::TEXT, NULL::TEXT, NULL::INT;
+            RETURN;
+        END IF;
+
+        INSERT INTO evaluation (entry_id, evaluator_id, evaluation_complete)
+        VALUES (v_entry.entry_id, p_evaluator_id, false);
+    END IF;
+
+    RETURN QUERY SELECT v_entry.entry_id, v_entry.entry_url, v_entry.entry_title, v_entry.entry_height;
+END;
+$$ LANGUAGE plpgsql;
@@ -7,6 +7,7 @@ import (
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/KA-Challenge-Council/Bema/internal/auth"
 	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
@@ -19,8 +20,16 @@ type causer interface {
 	Cause() error
 }
 
-// Creates and logs a new internal error
+// Postgres reports a cancelled statement, including one cancelled by a context, with this code
+const queryCanceledCode = "57014"
+
+// Creates and logs a new internal error. Errors caused by a query timing out or being
+// cancelled are returned as timeout errors instead and are not logged.
 func NewInternalError(ctx context.Context, publicMessage string, err error) *gqlerror.Error {
+	if IsTimeout(err) {
+		return NewTimeoutError(ctx, publicMessage)
+	}
+
 	internalError := errors.WithStack(err) // Wraps the passed in error so a stack trace can be added
 	callStack := fmt.Sprintf("%v\n%v", internalError.(causer).Cause(), internalError.(stackTracer).StackTrace())
 	request := GetRequestFromContext(ctx)
@@ -31,7 +40,12 @@ func NewInternalError(ctx context.Context, publicMessage string, err error) *gql
 		userId = &user.ID
 	}
 
-	logError(publicMessage, callStack, userId, request.RemoteAddr, request.Referer(), request.UserAgent())
+	// Errors raised outside of an http request (ex. in tests) are not tied to a client
+	if request != nil {
+		logError(publicMessage, callStack, userId, request.RemoteAddr, request.Referer(), request.UserAgent())
+	} else {
+		logError(publicMessage, callStack, userId, "", "", "")
+	}
 
 	return &gqlerror.Error{
 		Path:    graphql.GetPath(ctx),
@@ -64,6 +78,57 @@ func NewForbiddenError(ctx context.Context, message string) *gqlerror.Error {
 	}
 }
 
+// Creates a new bad request error
+func NewBadRequestError(ctx context.Context, message string) *gqlerror.Error {
+	return &gqlerror.Error{
+		Path:    graphql.GetPath(ctx),
+		Message: message,
+		Extensions: map[string]interface{}{
+			"status": 400,
+		},
+	}
+}
+
+// Creates a new too many requests error
+func NewTooManyRequestsError(ctx context.Context, message string) *gqlerror.Error {
+	return &gqlerror.Error{
+		Path:    graphql.GetPath(ctx),
+		Message: message,
+		Extensions: map[string]interface{}{
+			"status": 429,
+		},
+	}
+}
+
+// Creates a new timeout error
+func NewTimeoutError(ctx context.Context, message string) *gqlerror.Error {
+	return &gqlerror.Error{
+		Path:    graphql.GetPath(ctx),
+		Message: message,
+		Extensions: map[string]interface{}{
+			"status": 504,
+		},
+	}
+}
+
+// Returns whether err was caused by a deadline passing or the request being cancelled
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == queryCanceledCode
+}
+
 func logError(publicMessage string, callStack string, userId *int, origin string, referer string, userAgent string) {
-	db.DB.Query("SELECT log_error($1, $2, $3, $4, $5, $6);", publicMessage, callStack, userId, origin, referer, userAgent)
+	if db.DB == nil {
+		return
+	}
+
+	// Errors are logged even if the request that raised them has been cancelled
+	ctx, cancel := db.WithQueryTimeout(context.Background())
+	defer cancel()
+
+	db.DB.ExecContext(ctx, "SELECT log_error($1, $2, $3, $4, $5, $6);", publicMessage, callStack, userId, origin, referer, userAgent)
 }
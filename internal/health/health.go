@@ -0,0 +1,78 @@
+// Package health serves the liveness and readiness checks used by the deploy platform.
+package health
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+)
+
+type readiness struct {
+	Status                string `json:"status"`
+	Error                 string `json:"error,omitempty"`
+	SchemaVersion         int    `json:"schemaVersion"`
+	ExpectedSchemaVersion int    `json:"expectedSchemaVersion"`
+}
+
+// Reports that the process is running. It does not touch the database, so a
+// database outage does not get the process restarted.
+func Healthz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	}
+}
+
+// Reports whether the server can handle requests: the database must be reachable
+// and migrated to at least the schema version this build expects.
+func Readyz(system models.SystemRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := readiness{Status: "ready"}
+
+		expected, err := db.LatestMigrationVersion()
+		if err != nil {
+			log.Printf("readyz: loading migrations: %v", err)
+			status.Status = "unavailable"
+			status.Error = "the embedded migrations could not be read"
+			writeJSON(w, http.StatusServiceUnavailable, status)
+			return
+		}
+		status.ExpectedSchemaVersion = expected
+
+		if err := system.Ping(r.Context()); err != nil {
+			log.Printf("readyz: pinging database: %v", err)
+			status.Status = "unavailable"
+			status.Error = "the database is unreachable"
+			writeJSON(w, http.StatusServiceUnavailable, status)
+			return
+		}
+
+		version, err := system.GetSchemaVersion(r.Context())
+		if err != nil {
+			log.Printf("readyz: reading schema version: %v", err)
+			status.Status = "unavailable"
+			status.Error = "the schema version could not be read"
+			writeJSON(w, http.StatusServiceUnavailable, status)
+			return
+		}
+		status.SchemaVersion = version
+
+		if version < expected {
+			status.Status = "unavailable"
+			status.Error = "the database has pending migrations"
+			writeJSON(w, http.StatusServiceUnavailable, status)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, status)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
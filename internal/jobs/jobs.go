@@ -0,0 +1,87 @@
+// Package jobs runs background work on a schedule while the server is up.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+)
+
+// Runs fn every interval until ctx is cancelled. A failed or panicking run is logged and the job
+// carries on with the next one.
+func Every(ctx context.Context, interval time.Duration, name string, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := run(ctx, fn); err != nil {
+				log.Printf("Job %q failed: %v", name, err)
+			}
+		}
+	}
+}
+
+// Runs fn once, turning a panic into an error so it cannot take down the server
+func run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return fn(ctx)
+}
+
+// Returns a job that deletes expired sessions
+func PurgeExpiredSessions(sessions models.SessionRepository) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		n, err := sessions.DeleteExpiredSessions(ctx)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			log.Printf("Deleted %d expired sessions", n)
+		}
+		return nil
+	}
+}
+
+// Returns a job that deletes failed logins that are old enough to be forgotten
+func PurgeLoginThrottles(throttles models.LoginThrottleRepository) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := throttles.DeleteExpiredLoginThrottles(ctx, auth.LoginThrottle.Window)
+		return err
+	}
+}
+
+// Returns a job that deletes expired password reset links
+func PurgeExpiredPasswordResets(resets models.PasswordResetRepository) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := resets.DeleteExpiredPasswordResets(ctx)
+		return err
+	}
+}
+
+// Returns a job that deletes logins that expired while waiting for a two-factor code
+func PurgeExpiredLoginChallenges(twoFactor models.TwoFactorRepository) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := twoFactor.DeleteExpiredLoginChallenges(ctx)
+		return err
+	}
+}
+
+// Returns a job that deletes single sign-on logins that were never finished
+func PurgeExpiredOIDCLoginStates(oidc models.OIDCRepository) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := oidc.DeleteExpiredOIDCLoginStates(ctx)
+		return err
+	}
+}
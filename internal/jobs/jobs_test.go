@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEvery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runs := make(chan struct{}, 10)
+	done := make(chan struct{})
+	go func() {
+		n := 0
+		Every(ctx, time.Millisecond, "test", func(ctx context.Context) error {
+			runs <- struct{}{}
+			// Neither a failed nor a panicking run may stop the job
+			n++
+			if n == 2 {
+				panic("broken")
+			}
+			return errors.New("failed")
+		})
+		close(done)
+	}()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatalf("got %d runs, want 3", i)
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Every did not return after its context was cancelled")
+	}
+}
@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/mail"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+)
+
+// Returns a job that acts on evaluator term dates. It locks accounts whose term has ended, ends
+// their sessions and removes them from their judging group, reactivates accounts it locked once
+// their term is renewed, and emails users and admins when a term ends within warning.
+func ApplyTermDates(lifecycle models.LifecycleRepository, users models.UserRepository, mailer mail.Mailer, appURL string, warning time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		now := time.Now().UTC()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+		locked, err := lifecycle.LockUsersWithEndedTerms(ctx, today)
+		if err != nil {
+			return err
+		}
+		if len(locked) > 0 {
+			log.Printf("Locked %d accounts whose term ended: %v", len(locked), locked)
+		}
+
+		reactivated, err := lifecycle.ReactivateUsersWithRenewedTerms(ctx, today)
+		if err != nil {
+			return err
+		}
+		if len(reactivated) > 0 {
+			log.Printf("Reactivated %d accounts whose term was renewed: %v", len(reactivated), reactivated)
+		}
+
+		ending, err := lifecycle.GetUsersToWarnOfTermEnd(ctx, today, today.Add(warning))
+		if err != nil || len(ending) == 0 {
+			return err
+		}
+
+		active, err := users.GetAllActiveUsers(ctx)
+		if err != nil {
+			return err
+		}
+
+		send := func(msg mail.Message) {
+			if err := mailer.Send(ctx, msg); err != nil {
+				log.Printf("Sending a term end warning to %s failed: %v", msg.To, err)
+			}
+		}
+
+		for _, user := range ending {
+			if user.Email != nil && *user.Email != "" {
+				send(termEndingMessage(user, appURL))
+			}
+			if err := lifecycle.SetTermEndWarned(ctx, user.ID, *user.TermEndsAt); err != nil {
+				return err
+			}
+		}
+
+		for _, admin := range active {
+			if admin.IsAdmin != nil && *admin.IsAdmin && admin.Email != nil && *admin.Email != "" {
+				send(termsEndingAdminMessage(admin, ending, appURL))
+			}
+		}
+		return nil
+	}
+}
+
+// The date a term ends on, as written in emails
+func termEndDate(user *model.User) string {
+	return user.TermEndsAt.Format("January 2, 2006")
+}
+
+func greeting(user *model.User) string {
+	if user.Nickname != nil && *user.Nickname != "" {
+		return "Hi " + *user.Nickname
+	}
+	return "Hi"
+}
+
+// Names the user in emails. Accounts do not need a name, so those are named by id.
+func displayName(user *model.User) string {
+	if user.Name != nil && *user.Name != "" {
+		return *user.Name
+	}
+	return "User #" + strconv.Itoa(user.ID)
+}
+
+// Returns the username between prefix and suffix, or nothing for accounts without a username
+func usernameClause(user *model.User, prefix string, suffix string) string {
+	if user.Username == nil || *user.Username == "" {
+		return ""
+	}
+	return prefix + *user.Username + suffix
+}
+
+func termEndingMessage(user *model.User, appURL string) mail.Message {
+	return mail.Message{
+		To:      *user.Email,
+		Subject: "Your KA Challenge Council term is ending soon",
+		Body: greeting(user) + ",\n\n" +
+			"Your term on the KA Challenge Council ends on " + termEndDate(user) + ". " +
+			"On that day your account" + usernameClause(user, ", ", ",") + " will be locked and removed from its judging group.\n\n" +
+			"If your term is being renewed, ask an admin to update your term end date at " + appURL + " and your account will stay active.\n",
+	}
+}
+
+func termsEndingAdminMessage(admin *model.User, ending []*model.User, appURL string) mail.Message {
+	var list strings.Builder
+	for _, user := range ending {
+		list.WriteString("- " + displayName(user) + usernameClause(user, " (", ")") + ", on " + termEndDate(user) + "\n")
+	}
+
+	return mail.Message{
+		To:      *admin.Email,
+		Subject: "KA Challenge Council terms ending soon",
+		Body: greeting(admin) + ",\n\n" +
+			"These members' terms are ending soon. Their accounts will be locked on that day unless their term end date is changed:\n\n" +
+			list.String() + "\n" +
+			"You can change term dates at " + appURL + ".\n",
+	}
+}
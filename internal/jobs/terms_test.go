@@ -0,0 +1,113 @@
+package jobs
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/mail"
+	"github.com/KA-Challenge-Council/Bema/internal/models/memory"
+)
+
+type recordingMailer struct {
+	sent []mail.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mail.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestApplyTermDates(t *testing.T) {
+	ctx := context.Background()
+	repos := memory.NewRepositories()
+	mailer := &recordingMailer{}
+	job := ApplyTermDates(repos.Lifecycle, repos.Users, mailer, "https://example.com", 14*24*time.Hour)
+
+	day := func(days int) *string {
+		date := time.Now().UTC().AddDate(0, 0, days).Format("2006-01-02")
+		return &date
+	}
+
+	// Creates a user with the given term end, or no term end if it is nil
+	createUser := func(username string, termEnd *string, isAdmin bool, locked bool) int {
+		email := username + "@example.com"
+		id, _ := repos.Users.CreateUser(ctx, &model.CreateUserInput{Name: username, Email: &email, Kaid: "kaid_" + username, Username: username, TermStart: "2022-01-01"})
+		_ = repos.Users.EditUserById(ctx, *id, &model.EditUserProfileInput{Name: username, Email: &email, Kaid: "kaid_" + username, Username: username, Nickname: username, TermStart: "2022-01-01", TermEnd: termEnd, IsAdmin: isAdmin, AccountLocked: locked})
+		return *id
+	}
+
+	isLocked := func(id int) bool {
+		user, _ := repos.Users.GetUserById(ctx, id)
+		return *user.AccountLocked
+	}
+
+	createUser("admin", nil, true, false)
+	ended := createUser("ended", day(-1), false, false)
+	ending := createUser("ending", day(3), false, false)
+	later := createUser("later", day(60), false, false)
+	banned := createUser("banned", day(-1), false, true)
+	_ = repos.Users.AssignUserToJudgingGroup(ctx, ended, intPtr(1))
+
+	if err := job(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if !isLocked(ended) || isLocked(ending) || isLocked(later) {
+		t.Fatalf("got ended %t, ending %t and later %t locked, want only the ended term locked", isLocked(ended), isLocked(ending), isLocked(later))
+	}
+	if group, _ := repos.Users.GetUserGroupById(ctx, ended); group != nil {
+		t.Fatalf("got group %d, want the locked account removed from its group", *group)
+	}
+
+	// The user whose term ends within the warning and every admin are warned once
+	recipients := []string{}
+	for _, msg := range mailer.sent {
+		recipients = append(recipients, msg.To)
+	}
+	if len(mailer.sent) != 2 || mailer.sent[0].To != "ending@example.com" || mailer.sent[1].To != "admin@example.com" {
+		t.Fatalf("got emails to %v, want a warning to the ending user and the admin", recipients)
+	}
+	if !strings.Contains(mailer.sent[1].Body, "ending (ending)") || strings.Contains(mailer.sent[1].Body, "later") {
+		t.Fatalf("got admin email %q, want it to list only the ending user", mailer.sent[1].Body)
+	}
+
+	mailer.sent = nil
+	if err := job(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(mailer.sent) != 0 {
+		t.Fatalf("got %d emails, want no repeated warnings", len(mailer.sent))
+	}
+
+	// Renewing a term reactivates accounts locked by the job, but not accounts an admin locked
+	for _, id := range []int{ended, banned} {
+		user, _ := repos.Users.GetUserById(ctx, id)
+		_ = repos.Users.EditUserById(ctx, id, &model.EditUserProfileInput{Name: *user.Name, Email: user.Email, Kaid: user.Kaid, Username: *user.Username, TermStart: "2022-01-01", TermEnd: day(365), AccountLocked: true})
+	}
+	if err := job(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if isLocked(ended) || !isLocked(banned) {
+		t.Fatalf("got ended %t and banned %t locked, want only the renewed term reactivated", isLocked(ended), isLocked(banned))
+	}
+}
+
+func TestTermEndingMessagesWithoutNames(t *testing.T) {
+	termEnd := time.Date(2030, time.March, 1, 0, 0, 0, 0, time.UTC)
+	email := "nameless@example.com"
+	user := &model.User{ID: 12, Email: &email, TermEndsAt: &termEnd}
+
+	if body := termEndingMessage(user, "https://example.com").Body; !strings.Contains(body, "On that day your account will be locked") {
+		t.Fatalf("got %q, want the warning to leave out the missing username", body)
+	}
+	if body := termsEndingAdminMessage(user, []*model.User{user}, "https://example.com").Body; !strings.Contains(body, "- User #12, on March 1, 2030") {
+		t.Fatalf("got %q, want the user listed by id", body)
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
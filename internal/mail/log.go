@@ -0,0 +1,39 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// Writes each message to a file in Dir, or to the server log if Dir is empty, instead of sending
+// it. Intended for local development.
+type LogMailer struct {
+	Dir  string
+	From string
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9@._-]+`)
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	data, err := msg.Format(m.From, now)
+	if err != nil {
+		return err
+	}
+
+	if m.Dir == "" {
+		log.Printf("Not sending email to %s:\n%s", msg.To, data)
+		return nil
+	}
+
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d-%s.eml", now.UnixNano(), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	return os.WriteFile(filepath.Join(m.Dir, name), data, 0o644)
+}
@@ -0,0 +1,70 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/internal/config"
+)
+
+func TestFormat(t *testing.T) {
+	msg := Message{To: "judge@example.com", Subject: "Reset your password", Body: "Hello,\nClick the link.\n"}
+	data, err := msg.Format("Bema <noreply@example.com>", time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(data)
+	for _, want := range []string{
+		"From: Bema <noreply@example.com>\r\n",
+		"To: judge@example.com\r\n",
+		"Subject: Reset your password\r\n",
+		"Date: Sun, 01 May 2022 12:00:00 +0000\r\n",
+		"\r\n\r\nHello,\r\nClick the link.\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Format() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestFormatRejectsHeaderInjection(t *testing.T) {
+	msg := Message{To: "judge@example.com\r\nBcc: everyone@example.com", Subject: "Hi"}
+	if _, err := msg.Format("noreply@example.com", time.Now()); err == nil {
+		t.Fatal("Format() accepted a recipient containing a line break")
+	}
+}
+
+func TestLogMailerWritesFiles(t *testing.T) {
+	dir := t.TempDir()
+	mailer, err := New(config.Mail{Driver: "log", Dir: dir, From: "noreply@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mailer.Send(context.Background(), Message{To: "judge@example.com", Subject: "Hi", Body: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*-judge@example.com.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("found %v (%v), want one message file", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(data), "\r\n\r\nHello") {
+		t.Errorf("message = %q, want it to end with the body", data)
+	}
+}
+
+func TestNewRejectsUnknownDriver(t *testing.T) {
+	if _, err := New(config.Mail{Driver: "pigeon"}); err == nil {
+		t.Fatal("New() accepted an unknown driver")
+	}
+}
@@ -18,61 +18,92 @@ func NewAnnouncementModel() model.Announcement {
 	return announcement
 }
 
-func GetAnnouncementById(ctx context.Context, id int) (*model.Announcement, error) {
-	row := db.DB.QueryRow("SELECT m.message_id, m.message_date, m.message_title, m.message_content, m.public, m.author_id FROM messages m WHERE m.message_id = $1;", id)
+// Fills in the deprecated pre-formatted date fields from their DateTime fields
+func formatAnnouncementDates(a *model.Announcement) {
+	a.Created = a.CreatedAt.Format(time.RFC3339Nano)
+}
+
+func (p *Postgres) GetAnnouncementById(ctx context.Context, id int) (*model.Announcement, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT m.message_id, m.message_date, m.message_title, m.message_content, m.public, m.author_id FROM messages m WHERE m.message_id = $1;", id)
 
 	a := NewAnnouncementModel()
-	if err := row.Scan(&a.ID, &a.Created, &a.Title, &a.Content, &a.IsPublic, &a.Author.ID); err != nil {
+	if err := row.Scan(&a.ID, &a.CreatedAt, &a.Title, &a.Content, &a.IsPublic, &a.Author.ID); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NewNotFoundError(ctx, "Oops! The requested announcement does not exist.")
 		}
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the requested announcement", err)
 	}
 
+	formatAnnouncementDates(&a)
+
 	return &a, nil
 }
 
-func GetAllAnnouncements(ctx context.Context) ([]*model.Announcement, error) {
+func (p *Postgres) GetAllAnnouncements(ctx context.Context) ([]*model.Announcement, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	announcements := []*model.Announcement{}
 
-	rows, err := db.DB.Query("SELECT m.message_id, m.message_date, m.message_title, m.message_content, m.public, m.author_id FROM messages m;")
+	rows, err := p.db.QueryContext(ctx, "SELECT m.message_id, m.message_date, m.message_title, m.message_content, m.public, m.author_id FROM messages m;")
 	if err != nil {
 		return []*model.Announcement{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of announcements.", err)
 	}
 
 	for rows.Next() {
 		a := NewAnnouncementModel()
-		if err := rows.Scan(&a.ID, &a.Created, &a.Title, &a.Content, &a.IsPublic, &a.Author.ID); err != nil {
+		if err := rows.Scan(&a.ID, &a.CreatedAt, &a.Title, &a.Content, &a.IsPublic, &a.Author.ID); err != nil {
 			return []*model.Announcement{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of announcements.", err)
 		}
+
+		formatAnnouncementDates(&a)
 		announcements = append(announcements, &a)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.Announcement{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of announcements.", err)
+	}
+
 	return announcements, nil
 }
 
-func GetPublicAnnouncements(ctx context.Context) ([]*model.Announcement, error) {
+func (p *Postgres) GetPublicAnnouncements(ctx context.Context) ([]*model.Announcement, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	announcements := []*model.Announcement{}
 
-	rows, err := db.DB.Query("SELECT m.message_id, m.message_date, m.message_title, m.message_content, m.public, m.author_id FROM messages m WHERE m.public = true;")
+	rows, err := p.db.QueryContext(ctx, "SELECT m.message_id, m.message_date, m.message_title, m.message_content, m.public, m.author_id FROM messages m WHERE m.public = true;")
 	if err != nil {
 		return []*model.Announcement{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of announcements.", err)
 	}
 
 	for rows.Next() {
 		a := NewAnnouncementModel()
-		if err := rows.Scan(&a.ID, &a.Created, &a.Title, &a.Content, &a.IsPublic, &a.Author.ID); err != nil {
+		if err := rows.Scan(&a.ID, &a.CreatedAt, &a.Title, &a.Content, &a.IsPublic, &a.Author.ID); err != nil {
 			return []*model.Announcement{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of announcements.", err)
 		}
+
+		formatAnnouncementDates(&a)
 		announcements = append(announcements, &a)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.Announcement{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of announcements.", err)
+	}
+
 	return announcements, nil
 }
 
-func CreateAnnouncement(ctx context.Context, input *model.AnnouncementInput, authorId int) (*int, error) {
+func (p *Postgres) CreateAnnouncement(ctx context.Context, input *model.AnnouncementInput, authorId int) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	var id int
-	row := db.DB.QueryRow("INSERT INTO messages (author_id, message_date, message_title, message_content, public) VALUES ($1, $2, $3, $4, $5) RETURNING message_id;", authorId, time.Now().UTC(), input.Title, input.Content, input.IsPublic)
+	row := p.db.QueryRowContext(ctx, "INSERT INTO messages (author_id, message_date, message_title, message_content, public) VALUES ($1, $2, $3, $4, $5) RETURNING message_id;", authorId, time.Now().UTC(), input.Title, input.Content, input.IsPublic)
 	if err := row.Scan(&id); err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while creating an announcement", err)
 	}
@@ -80,8 +111,11 @@ func CreateAnnouncement(ctx context.Context, input *model.AnnouncementInput, aut
 	return &id, nil
 }
 
-func EditAnnouncementById(ctx context.Context, id int, input *model.AnnouncementInput) error {
-	_, err := db.DB.Exec("UPDATE messages SET message_title = $1, message_content = $2, public = $3 WHERE message_id = $4", input.Title, input.Content, input.IsPublic, id)
+func (p *Postgres) EditAnnouncementById(ctx context.Context, id int, input *model.AnnouncementInput) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE messages SET message_title = $1, message_content = $2, public = $3 WHERE message_id = $4", input.Title, input.Content, input.IsPublic, id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while editing an announcement", err)
 	}
@@ -89,8 +123,11 @@ func EditAnnouncementById(ctx context.Context, id int, input *model.Announcement
 	return nil
 }
 
-func DeleteAnnouncementById(ctx context.Context, id int) error {
-	_, err := db.DB.Exec("DELETE FROM messages WHERE message_id = $1", id)
+func (p *Postgres) DeleteAnnouncementById(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM messages WHERE message_id = $1", id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while delete an announcement", err)
 	}
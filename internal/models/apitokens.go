@@ -0,0 +1,119 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/lib/pq"
+)
+
+const apiTokenColumns = "t.api_token_id, t.token_name, t.token_prefix, ARRAY(SELECT permission_key FROM api_token_permission p WHERE p.api_token_id = t.api_token_id ORDER BY permission_key), t.created_tstz, t.expires_tstz, t.last_used_tstz"
+
+func scanAPIToken(row interface{ Scan(...interface{}) error }) (*model.APIToken, error) {
+	token := &model.APIToken{}
+	if err := row.Scan(&token.ID, &token.Name, &token.Prefix, pq.Array(&token.Permissions), &token.CreatedAt, &token.ExpiresAt, &token.LastUsedAt); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Returns the user's API tokens, including expired ones, newest first
+func (p *Postgres) GetAPITokensByUserId(ctx context.Context, userId int) ([]*model.APIToken, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	tokens := []*model.APIToken{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT "+apiTokenColumns+" FROM api_token t WHERE t.evaluator_id = $1 ORDER BY t.created_tstz DESC, t.api_token_id DESC;", userId)
+	if err != nil {
+		return tokens, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving API tokens", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return []*model.APIToken{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving API tokens", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []*model.APIToken{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving API tokens", err)
+	}
+
+	return tokens, nil
+}
+
+// Returns one of the user's API tokens, or a not found error if they do not have one with the id
+func (p *Postgres) GetAPITokenById(ctx context.Context, userId int, id int) (*model.APIToken, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT "+apiTokenColumns+" FROM api_token t WHERE t.api_token_id = $1 AND t.evaluator_id = $2;", id, userId)
+
+	token, err := scanAPIToken(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError(ctx, "Oops! The requested API token does not exist.")
+		}
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the requested API token", err)
+	}
+	return token, nil
+}
+
+// Stores an API token by its hash, scoped to the given permissions
+func (p *Postgres) CreateAPIToken(ctx context.Context, userId int, input *model.CreateAPITokenInput, tokenHash string, prefix string) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var id int
+	err := p.withTx(ctx, "An unexpected error occurred while creating an API token", func(tx *sql.Tx) error {
+		keys := uniqueKeys(input.Permissions)
+		if err := checkPermissionKeys(ctx, tx, keys); err != nil {
+			return err
+		}
+
+		var expires *time.Time
+		if input.ExpiresAt != nil {
+			utc := input.ExpiresAt.UTC()
+			expires = &utc
+		}
+
+		row := tx.QueryRowContext(ctx, "INSERT INTO api_token (evaluator_id, token_name, token_hash, token_prefix, created_tstz, expires_tstz) VALUES ($1, $2, $3, $4, $5, $6) RETURNING api_token_id;", userId, input.Name, tokenHash, prefix, time.Now().UTC(), expires)
+		if err := row.Scan(&id); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, "INSERT INTO api_token_permission (api_token_id, permission_key) SELECT $1, unnest($2::text[]);", id, pq.Array(keys))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &id, nil
+}
+
+func (p *Postgres) DeleteAPITokenById(ctx context.Context, userId int, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := p.db.ExecContext(ctx, "DELETE FROM api_token WHERE api_token_id = $1 AND evaluator_id = $2;", id, userId)
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while revoking an API token", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while revoking an API token", err)
+	}
+	if n == 0 {
+		return errors.NewNotFoundError(ctx, "Oops! The requested API token does not exist.")
+	}
+	return nil
+}
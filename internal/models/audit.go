@@ -0,0 +1,141 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/lib/pq"
+)
+
+// A use of a privileged mutation to add to the audit log
+type AuditRecord struct {
+	ActorID *int
+	// The user who was impersonating the actor
+	RealActorID *int
+	Mutation    string
+	TargetType  model.AuditTarget
+	TargetIDs   []int
+	// The target before and after the change as JSON, or nil where it did not exist
+	Before    *string
+	After     *string
+	IPAddress string
+}
+
+func NewAuditLogEntryModel() model.AuditLogEntry {
+	return model.AuditLogEntry{Actor: &model.User{}, RealActor: &model.User{}, TargetIds: []int{}}
+}
+
+func (p *Postgres) CreateAuditLogEntry(ctx context.Context, record *AuditRecord) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	targetIds := record.TargetIDs
+	if targetIds == nil {
+		targetIds = []int{}
+	}
+
+	_, err := p.db.ExecContext(ctx, "INSERT INTO audit_log (actor_id, real_actor_id, mutation_name, target_type, target_ids, before_value, after_value, ip_address, created_tstz) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9);", record.ActorID, record.RealActorID, record.Mutation, record.TargetType, pq.Array(targetIds), record.Before, record.After, record.IPAddress, time.Now().UTC())
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while recording the change in the audit log", err)
+	}
+	return nil
+}
+
+// Returns a page of the audit log entries matching the filter, newest first
+func (p *Postgres) GetAuditLogPage(ctx context.Context, filter *model.AuditLogFilter, args PageArgs) (*Page[*model.AuditLogEntry], error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	// Audit log ids are never negative, so this sorts after every entry when there is no cursor
+	afterId := math.MaxInt32
+	if args.After != "" {
+		if err := DecodeCursor(args.After, &afterId); err != nil {
+			return nil, errors.NewBadRequestError(ctx, "Oops! The cursor provided is not valid.")
+		}
+	}
+
+	conditions := []string{"TRUE"}
+	values := []interface{}{}
+	where := func(condition string, value interface{}) {
+		values = append(values, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(values)))
+	}
+	if filter != nil {
+		if filter.ActorID != nil {
+			where("(actor_id = $%[1]d OR real_actor_id = $%[1]d)", *filter.ActorID)
+		}
+		if filter.Mutation != nil {
+			where("mutation_name = $%d", *filter.Mutation)
+		}
+		if filter.TargetType != nil {
+			where("target_type = $%d", *filter.TargetType)
+		}
+		if filter.TargetID != nil {
+			where("$%d = ANY(target_ids)", *filter.TargetID)
+		}
+		if filter.Since != nil {
+			where("created_tstz >= $%d", filter.Since.UTC())
+		}
+		if filter.Until != nil {
+			where("created_tstz < $%d", filter.Until.UTC())
+		}
+	}
+	filtered := strings.Join(conditions, " AND ")
+
+	var totalCount int
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_log WHERE "+filtered+";", values...)
+	if err := row.Scan(&totalCount); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while counting the audit log entries", err)
+	}
+
+	values = append(values, afterId, args.First+1)
+	rows, err := p.db.QueryContext(ctx, fmt.Sprintf("SELECT audit_log_id, actor_id, real_actor_id, mutation_name, target_type, target_ids, before_value, after_value, ip_address, created_tstz FROM audit_log WHERE %s AND audit_log_id < $%d ORDER BY audit_log_id DESC LIMIT $%d;", filtered, len(values)-1, len(values)), values...)
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the audit log", err)
+	}
+	defer rows.Close()
+
+	entries := []*model.AuditLogEntry{}
+	for rows.Next() {
+		e := NewAuditLogEntryModel()
+		var actorId, realActorId *int
+		var targetIds pq.Int64Array
+
+		if err := rows.Scan(&e.ID, &actorId, &realActorId, &e.Mutation, &e.TargetType, &targetIds, &e.Before, &e.After, &e.IPAddress, &e.CreatedAt); err != nil {
+			return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading the audit log", err)
+		}
+
+		if actorId == nil {
+			e.Actor = nil
+		} else {
+			e.Actor.ID = *actorId
+		}
+		if realActorId == nil {
+			e.RealActor = nil
+		} else {
+			e.RealActor.ID = *realActorId
+		}
+		for _, id := range targetIds {
+			e.TargetIds = append(e.TargetIds, int(id))
+		}
+
+		entries = append(entries, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading the audit log", err)
+	}
+
+	return newPage(entries, args.First, totalCount, AuditLogCursor), nil
+}
+
+// Returns the cursor of an entry in a paginated list of audit log entries
+func AuditLogCursor(e *model.AuditLogEntry) string {
+	return EncodeCursor(e.ID)
+}
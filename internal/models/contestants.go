@@ -8,6 +8,7 @@ import (
 	"github.com/KA-Challenge-Council/Bema/graph/model"
 	"github.com/KA-Challenge-Council/Bema/internal/db"
 	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/lib/pq"
 )
 
 func NewContestantModel() model.Contestant {
@@ -17,8 +18,11 @@ func NewContestantModel() model.Contestant {
 	return contestant
 }
 
-func GetContestantByKaid(ctx context.Context, kaid string) (*model.Contestant, error) {
-	row := db.DB.QueryRow("SELECT entry_author FROM entry WHERE entry_author_kaid = $1 ORDER BY entry_id ASC LIMIT 1;", kaid)
+func (p *Postgres) GetContestantByKaid(ctx context.Context, kaid string) (*model.Contestant, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT entry_author FROM entry WHERE entry_author_kaid = $1 ORDER BY entry_id ASC LIMIT 1;", kaid)
 
 	contestant := NewContestantModel()
 	contestant.Kaid = kaid
@@ -32,7 +36,38 @@ func GetContestantByKaid(ctx context.Context, kaid string) (*model.Contestant, e
 	return &contestant, nil
 }
 
-func GetContestantsBySearchQuery(ctx context.Context, searchQuery string) ([]*model.Contestant, error) {
+// Returns the requested contestants keyed by kaid. Kaids without any entries are left out.
+func (p *Postgres) GetContestantsByKaids(ctx context.Context, kaids []string) (map[string]*model.Contestant, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	contestants := map[string]*model.Contestant{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT DISTINCT ON (entry_author_kaid) entry_author_kaid, entry_author FROM entry WHERE entry_author_kaid = ANY($1) ORDER BY entry_author_kaid, entry_id ASC;", pq.Array(kaids))
+	if err != nil {
+		return contestants, errors.NewInternalError(ctx, "An unexpected error occurred while looking up contestants.", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c := NewContestantModel()
+		if err := rows.Scan(&c.Kaid, &c.Name); err != nil {
+			return map[string]*model.Contestant{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading contestants.", err)
+		}
+		contestants[c.Kaid] = &c
+	}
+
+	if err := rows.Err(); err != nil {
+		return map[string]*model.Contestant{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading contestants.", err)
+	}
+
+	return contestants, nil
+}
+
+func (p *Postgres) GetContestantsBySearchQuery(ctx context.Context, searchQuery string) ([]*model.Contestant, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	contestants := []*model.Contestant{}
 
 	var query string
@@ -42,7 +77,7 @@ func GetContestantsBySearchQuery(ctx context.Context, searchQuery string) ([]*mo
 		query = "SELECT STRING_AGG(DISTINCT entry_author, ', ') as contestant_names, entry_author_kaid as contestant_kaid FROM entry WHERE entry_author LIKE $1 GROUP BY entry_author_kaid;"
 	}
 
-	rows, err := db.DB.Query(query, "%"+searchQuery+"%")
+	rows, err := p.db.QueryContext(ctx, query, "%"+searchQuery+"%")
 	if err != nil {
 		return []*model.Contestant{}, errors.NewInternalError(ctx, "An unexpected error occurred while searching for contestants.", err)
 	}
@@ -64,11 +99,78 @@ func GetContestantsBySearchQuery(ctx context.Context, searchQuery string) ([]*mo
 		contestants = append(contestants, &c)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.Contestant{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading contestants matching a search query.", err)
+	}
+
 	return contestants, nil
 }
 
-func GetContestantEntryCount(ctx context.Context, kaid string) (int, error) {
-	row := db.DB.QueryRow("SELECT COUNT(entry_id) FROM entry WHERE entry_author_kaid = $1;", kaid)
+// Returns a page of contestants matching a search query, ordered by kaid
+func (p *Postgres) GetContestantsPageBySearchQuery(ctx context.Context, searchQuery string, args PageArgs) (*Page[*model.Contestant], error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	// A kaid search matches at most one contestant, so it is always a single page
+	if strings.Contains(searchQuery, "kaid_") {
+		contestants, err := p.GetContestantsBySearchQuery(ctx, searchQuery)
+		if err != nil {
+			return nil, err
+		}
+		if args.After != "" {
+			contestants = []*model.Contestant{}
+		}
+		return newPage(contestants, args.First, len(contestants), ContestantCursor), nil
+	}
+
+	afterKaid := ""
+	if args.After != "" {
+		if err := DecodeCursor(args.After, &afterKaid); err != nil {
+			return nil, errors.NewBadRequestError(ctx, "Oops! The cursor provided is not valid.")
+		}
+	}
+
+	var totalCount int
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(DISTINCT COALESCE(entry_author_kaid, '')) FROM entry WHERE entry_author LIKE $1;", "%"+searchQuery+"%")
+	if err := row.Scan(&totalCount); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while counting contestants matching a search query.", err)
+	}
+
+	contestants := []*model.Contestant{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT STRING_AGG(DISTINCT entry_author, ', ') as contestant_names, COALESCE(entry_author_kaid, '') as contestant_kaid FROM entry WHERE entry_author LIKE $1 AND ($2 OR COALESCE(entry_author_kaid, '') > $3) GROUP BY COALESCE(entry_author_kaid, '') ORDER BY contestant_kaid ASC LIMIT $4;", "%"+searchQuery+"%", args.After == "", afterKaid, args.First+1)
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while searching for contestants.", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c := NewContestantModel()
+
+		if err := rows.Scan(&c.Name, &c.Kaid); err != nil {
+			return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading contestants matching a search query.", err)
+		}
+
+		contestants = append(contestants, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading contestants matching a search query.", err)
+	}
+
+	return newPage(contestants, args.First, totalCount, ContestantCursor), nil
+}
+
+// Returns the cursor of a contestant in a paginated list of contestants
+func ContestantCursor(c *model.Contestant) string {
+	return EncodeCursor(c.Kaid)
+}
+
+func (p *Postgres) GetContestantEntryCount(ctx context.Context, kaid string) (int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(entry_id) FROM entry WHERE entry_author_kaid = $1;", kaid)
 
 	var entryCount int
 	if err := row.Scan(&entryCount); err != nil {
@@ -81,8 +183,11 @@ func GetContestantEntryCount(ctx context.Context, kaid string) (int, error) {
 	return entryCount, nil
 }
 
-func GetContestantContestCount(ctx context.Context, kaid string) (int, error) {
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM contest c WHERE EXISTS (SELECT e.entry_id FROM entry e WHERE e.entry_author_kaid = $1 AND e.contest_id = c.contest_id);", kaid)
+func (p *Postgres) GetContestantContestCount(ctx context.Context, kaid string) (int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM contest c WHERE EXISTS (SELECT e.entry_id FROM entry e WHERE e.entry_author_kaid = $1 AND e.contest_id = c.contest_id);", kaid)
 
 	var contestCount int
 	if err := row.Scan(&contestCount); err != nil {
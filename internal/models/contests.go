@@ -8,6 +8,7 @@ import (
 	"github.com/KA-Challenge-Council/Bema/internal/db"
 	"github.com/KA-Challenge-Council/Bema/internal/errors"
 	"github.com/KA-Challenge-Council/Bema/internal/util"
+	"github.com/lib/pq"
 )
 
 func NewContestModel() model.Contest {
@@ -17,75 +18,171 @@ func NewContestModel() model.Contest {
 	return contest
 }
 
-func GetAllContests(ctx context.Context) ([]*model.Contest, error) {
+// The states each contest state can move to
+var contestTransitions = map[model.ContestState][]model.ContestState{
+	model.ContestStateDraft:            {model.ContestStateAcceptingEntries},
+	model.ContestStateAcceptingEntries: {model.ContestStateDraft, model.ContestStateJudging},
+	model.ContestStateJudging:          {model.ContestStateVoting},
+	model.ContestStateVoting:           {model.ContestStateJudging, model.ContestStateResultsPublished},
+	model.ContestStateResultsPublished: {model.ContestStateArchived},
+	model.ContestStateArchived:         {},
+}
+
+// Reports whether a contest in one state can be moved to the other
+func CanTransitionContest(from model.ContestState, to model.ContestState) bool {
+	for _, state := range contestTransitions[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports whether a contest in the state is accepting entries, being judged or being voted on
+func ContestIsActive(state model.ContestState) bool {
+	return state == model.ContestStateAcceptingEntries || state == model.ContestStateJudging || state == model.ContestStateVoting
+}
+
+// Reports whether a contest's winners are public in the state
+func ContestResultsPublished(state model.ContestState) bool {
+	return state == model.ContestStateResultsPublished || state == model.ContestStateArchived
+}
+
+const contestColumns = "contest_id, contest_name, contest_url, contest_author, date_start, date_end, contest_state, badge_name, badge_image_url"
+
+func scanContest(row interface{ Scan(...interface{}) error }) (*model.Contest, error) {
+	c := NewContestModel()
+	if err := row.Scan(&c.ID, &c.Name, &c.URL, &c.Author, &c.StartsAt, &c.EndsAt, &c.State, &c.BadgeSlug, &c.BadgeImageURL); err != nil {
+		return nil, err
+	}
+
+	// Fill in the deprecated fields
+	c.StartDate = util.FormatNullableDate(c.StartsAt, util.DisplayDateFormat)
+	c.EndDate = util.FormatNullableDate(c.EndsAt, util.DisplayDateFormat)
+	c.IsCurrent = ContestIsActive(c.State)
+	votingEnabled := c.State == model.ContestStateVoting
+	c.IsVotingEnabled = &votingEnabled
+
+	return &c, nil
+}
+
+func (p *Postgres) GetAllContests(ctx context.Context) ([]*model.Contest, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	contests := []*model.Contest{}
 
-	rows, err := db.DB.Query("SELECT contest_id, contest_name, contest_url, contest_author, to_char(date_start, $1) as date_start, to_char(date_end, $1) as date_end, current, voting_enabled, badge_name, badge_image_url FROM contest ORDER BY contest_id DESC;", util.DisplayDateFormat)
+	rows, err := p.db.QueryContext(ctx, "SELECT "+contestColumns+" FROM contest ORDER BY contest_id DESC;")
 	if err != nil {
 		return contests, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of contests", err)
 	}
 
 	for rows.Next() {
-		c := NewContestModel()
-		if err := rows.Scan(&c.ID, &c.Name, &c.URL, &c.Author, &c.StartDate, &c.EndDate, &c.IsCurrent, &c.IsVotingEnabled, &c.BadgeSlug, &c.BadgeImageURL); err != nil {
+		c, err := scanContest(rows)
+		if err != nil {
 			return []*model.Contest{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of contests", err)
 		}
-		contests = append(contests, &c)
+		contests = append(contests, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []*model.Contest{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of contests", err)
 	}
 
 	return contests, nil
 }
 
-func GetContestById(ctx context.Context, id int) (*model.Contest, error) {
-	row := db.DB.QueryRow("SELECT contest_id, contest_name, contest_url, contest_author, to_char(date_start, $1) as date_start, to_char(date_end, $1) as date_end, current, voting_enabled, badge_name, badge_image_url FROM contest WHERE contest_id = $2;", util.DisplayDateFormat, id)
+func (p *Postgres) GetContestById(ctx context.Context, id int) (*model.Contest, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
 
-	c := NewContestModel()
-	if err := row.Scan(&c.ID, &c.Name, &c.URL, &c.Author, &c.StartDate, &c.EndDate, &c.IsCurrent, &c.IsVotingEnabled, &c.BadgeSlug, &c.BadgeImageURL); err != nil {
+	c, err := scanContest(p.db.QueryRowContext(ctx, "SELECT "+contestColumns+" FROM contest WHERE contest_id = $1;", id))
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NewNotFoundError(ctx, "Oops! This contest does not exist.")
 		}
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while looking up a contest", err)
 	}
 
-	return &c, nil
+	return c, nil
 }
 
-func GetCurrentContest(ctx context.Context) (*model.Contest, error) {
-	row := db.DB.QueryRow("SELECT contest_id, contest_name, contest_url, contest_author, to_char(date_start, $1) as date_start, to_char(date_end, $1) as date_end, current, voting_enabled, badge_name, badge_image_url FROM contest ORDER BY contest_id DESC LIMIT 1;", util.DisplayDateFormat)
+// Returns the requested contests keyed by id. Ids that do not exist are left out.
+func (p *Postgres) GetContestsByIds(ctx context.Context, ids []int) (map[int]*model.Contest, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
 
-	c := NewContestModel()
-	if err := row.Scan(&c.ID, &c.Name, &c.URL, &c.Author, &c.StartDate, &c.EndDate, &c.IsCurrent, &c.IsVotingEnabled, &c.BadgeSlug, &c.BadgeImageURL); err != nil {
+	contests := map[int]*model.Contest{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT "+contestColumns+" FROM contest WHERE contest_id = ANY($1);", pq.Array(ids))
+	if err != nil {
+		return contests, errors.NewInternalError(ctx, "An unexpected error occurred while looking up contests", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c, err := scanContest(rows)
+		if err != nil {
+			return map[int]*model.Contest{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading contests", err)
+		}
+		contests[c.ID] = c
+	}
+
+	if err := rows.Err(); err != nil {
+		return map[int]*model.Contest{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading contests", err)
+	}
+
+	return contests, nil
+}
+
+// Returns the newest active contest, or else the newest contest that is not a draft
+func (p *Postgres) GetCurrentContest(ctx context.Context) (*model.Contest, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	c, err := scanContest(p.db.QueryRowContext(ctx, "SELECT "+contestColumns+" FROM contest WHERE contest_state != 'DRAFT' ORDER BY contest_state IN ('ACCEPTING_ENTRIES', 'JUDGING', 'VOTING') DESC, contest_id DESC LIMIT 1;"))
+	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.NewNotFoundError(ctx, "Oops! No contests have been created yet.")
+			return nil, errors.NewNotFoundError(ctx, "Oops! No contests have been opened yet.")
 		}
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while looking up the current contest", err)
 	}
 
-	return &c, nil
+	return c, nil
 }
 
-func GetContestsEvaluatedByUser(ctx context.Context, userId int) ([]*model.Contest, error) {
+func (p *Postgres) GetContestsEvaluatedByUser(ctx context.Context, userId int) ([]*model.Contest, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	contests := []*model.Contest{}
 
-	rows, err := db.DB.Query("SELECT c.contest_id, c.contest_name, c.contest_url, c.contest_author, to_char(c.date_start, $1) as date_start, to_char(c.date_end, $1) as date_end, c.current, c.voting_enabled, c.badge_name, c.badge_image_url FROM contest c INNER JOIN entry en ON en.contest_id = c.contest_id INNER JOIN evaluation ev ON ev.entry_id = en.entry_id WHERE ev.evaluator_id = $2 AND ev.evaluation_complete = true GROUP BY c.contest_id ORDER BY c.contest_id DESC;", util.DisplayDateFormat, userId)
+	rows, err := p.db.QueryContext(ctx, "SELECT "+contestColumns+" FROM contest WHERE contest_id IN (SELECT en.contest_id FROM entry en INNER JOIN evaluation ev ON ev.entry_id = en.entry_id WHERE ev.evaluator_id = $1 AND ev.evaluation_complete = true) ORDER BY contest_id DESC;", userId)
 	if err != nil {
 		return []*model.Contest{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of contests evaluated by the user", err)
 	}
 
 	for rows.Next() {
-		c := NewContestModel()
-		if err := rows.Scan(&c.ID, &c.Name, &c.URL, &c.Author, &c.StartDate, &c.EndDate, &c.IsCurrent, &c.IsVotingEnabled, &c.BadgeSlug, &c.BadgeImageURL); err != nil {
+		c, err := scanContest(rows)
+		if err != nil {
 			return []*model.Contest{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of contests evaluated by the user", err)
 		}
-		contests = append(contests, &c)
+		contests = append(contests, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []*model.Contest{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of contests evaluated by the user", err)
 	}
 
 	return contests, nil
 }
 
-func CreateContest(ctx context.Context, input *model.CreateContestInput) (*int, error) {
+func (p *Postgres) CreateContest(ctx context.Context, input *model.CreateContestInput) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	var id int
-	row := db.DB.QueryRow("INSERT INTO contest (contest_name, contest_url, contest_author, date_start, date_end, current) VALUES ($1, $2, $3, $4, $5, $6) RETURNING contest_id;", input.Name, input.URL, input.Author, input.StartDate, input.EndDate, input.IsCurrent)
+	row := p.db.QueryRowContext(ctx, "INSERT INTO contest (contest_name, contest_url, contest_author, date_start, date_end) VALUES ($1, $2, $3, $4, $5) RETURNING contest_id;", input.Name, input.URL, input.Author, input.StartDate, input.EndDate)
 	if err := row.Scan(&id); err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while creating a contest", err)
 	}
@@ -93,16 +190,39 @@ func CreateContest(ctx context.Context, input *model.CreateContestInput) (*int,
 	return &id, nil
 }
 
-func EditContestById(ctx context.Context, id int, input *model.EditContestInput) error {
-	_, err := db.DB.Exec("UPDATE contest SET contest_name = $1, contest_url = $2, contest_author = $3, date_start = $4, date_end = $5, current = $6, voting_enabled = $7, badge_name = $8, badge_image_url = $9 WHERE contest_id = $10", input.Name, input.URL, input.Author, input.StartDate, input.EndDate, input.IsCurrent, input.IsVotingEnabled, input.BadgeSlug, input.BadgeImageURL, id)
+func (p *Postgres) EditContestById(ctx context.Context, id int, input *model.EditContestInput) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE contest SET contest_name = $1, contest_url = $2, contest_author = $3, date_start = $4, date_end = $5, badge_name = $6, badge_image_url = $7 WHERE contest_id = $8", input.Name, input.URL, input.Author, input.StartDate, input.EndDate, input.BadgeSlug, input.BadgeImageURL, id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while editing a contest", err)
 	}
 	return nil
 }
 
-func DeleteContestById(ctx context.Context, id int) error {
-	_, err := db.DB.Exec("DELETE FROM contest WHERE contest_id = $1", id)
+// Moves a contest from one state to another. Fails if the contest is no longer in the from state,
+// so two people moving the same contest cannot both succeed.
+func (p *Postgres) TransitionContest(ctx context.Context, id int, from model.ContestState, to model.ContestState) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := p.db.ExecContext(ctx, "UPDATE contest SET contest_state = $1 WHERE contest_id = $2 AND contest_state = $3;", to, id, from)
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while changing the state of a contest", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.NewBadRequestError(ctx, "Oops! This contest was changed by someone else. Please reload and try again.")
+	}
+	return nil
+}
+
+func (p *Postgres) DeleteContestById(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM contest WHERE contest_id = $1", id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while deleting a contest", err)
 	}
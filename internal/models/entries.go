@@ -10,6 +10,7 @@ import (
 	"github.com/KA-Challenge-Council/Bema/internal/db"
 	"github.com/KA-Challenge-Council/Bema/internal/errors"
 	"github.com/KA-Challenge-Council/Bema/internal/util"
+	"github.com/lib/pq"
 )
 
 type EntryInput struct {
@@ -46,10 +47,18 @@ func NewEntryVoteModel() model.EntryVote {
 	return vote
 }
 
-func GetEntriesByContestId(ctx context.Context, contestId int) ([]*model.Entry, error) {
+// Fills in the deprecated pre-formatted date fields from their DateTime fields
+func formatEntryDates(e *model.Entry) {
+	e.Created = util.FormatDate(e.CreatedAt, util.DisplayFancyDateFormat)
+}
+
+func (p *Postgres) GetEntriesByContestId(ctx context.Context, contestId int) ([]*model.Entry, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	entries := []*model.Entry{}
 
-	rows, err := db.DB.Query("SELECT entry_id, contest_id, entry_url, entry_kaid, entry_title, entry_level, entry_votes, to_char(entry_created, $1) as entry_created, entry_height, is_winner, assigned_group_id, flagged, flag_reason, disqualified, entry_author_kaid, entry_level_locked FROM entry WHERE contest_id = $2 ORDER BY entry_id ASC;", util.DisplayFancyDateFormat, contestId)
+	rows, err := p.db.QueryContext(ctx, "SELECT entry_id, contest_id, entry_url, entry_kaid, entry_title, entry_level, entry_votes, entry_created, entry_height, is_winner, assigned_group_id, flagged, flag_reason, disqualified, entry_author_kaid, entry_level_locked FROM entry WHERE contest_id = $1 ORDER BY entry_id ASC;", contestId)
 	if err != nil {
 		return []*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of entries.", err)
 	}
@@ -59,10 +68,12 @@ func GetEntriesByContestId(ctx context.Context, contestId int) ([]*model.Entry,
 		var groupId *int
 		var authorKaid *string
 
-		if err := rows.Scan(&entry.ID, &entry.Contest.ID, &entry.URL, &entry.Kaid, &entry.Title, &entry.SkillLevel, &entry.Votes, &entry.Created, &entry.Height, &entry.IsWinner, &groupId, &entry.IsFlagged, &entry.FlagReason, &entry.IsDisqualified, &authorKaid, &entry.IsSkillLevelLocked); err != nil {
+		if err := rows.Scan(&entry.ID, &entry.Contest.ID, &entry.URL, &entry.Kaid, &entry.Title, &entry.SkillLevel, &entry.Votes, &entry.CreatedAt, &entry.Height, &entry.IsWinner, &groupId, &entry.IsFlagged, &entry.FlagReason, &entry.IsDisqualified, &authorKaid, &entry.IsSkillLevelLocked); err != nil {
 			return []*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of entries.", err)
 		}
 
+		formatEntryDates(&entry)
+
 		if groupId == nil {
 			entry.Group = nil
 		} else {
@@ -78,13 +89,52 @@ func GetEntriesByContestId(ctx context.Context, contestId int) ([]*model.Entry,
 		entries = append(entries, &entry)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of entries.", err)
+	}
+
 	return entries, nil
 }
 
-func GetEntriesByAverageScore(ctx context.Context, contestId int) ([]*model.Entry, error) {
+// Returns a page of a contest's entries ordered by id
+func (p *Postgres) GetEntriesPageByContestId(ctx context.Context, contestId int, args PageArgs) (*Page[*model.Entry], error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	afterId := 0
+	if args.After != "" {
+		if err := DecodeCursor(args.After, &afterId); err != nil {
+			return nil, errors.NewBadRequestError(ctx, "Oops! The cursor provided is not valid.")
+		}
+	}
+
+	var totalCount int
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entry WHERE contest_id = $1;", contestId)
+	if err := row.Scan(&totalCount); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while counting the entries in a contest.", err)
+	}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT entry_id, contest_id, entry_url, entry_kaid, entry_title, entry_level, entry_votes, entry_created, entry_height, is_winner, assigned_group_id, flagged, flag_reason, disqualified, entry_author_kaid, entry_level_locked FROM entry WHERE contest_id = $1 AND entry_id > $2 ORDER BY entry_id ASC LIMIT $3;", contestId, afterId, args.First+1)
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of entries.", err)
+	}
+	defer rows.Close()
+
+	entries, err := scanEntryRows(ctx, rows, "An unexpected error occurred while reading the list of entries.")
+	if err != nil {
+		return nil, err
+	}
+
+	return newPage(entries, args.First, totalCount, EntryCursor), nil
+}
+
+func (p *Postgres) GetEntriesByAverageScore(ctx context.Context, contestId int) ([]*model.Entry, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	entries := []*model.Entry{}
 
-	rows, err := db.DB.Query("SELECT e.entry_id, e.contest_id, e.entry_url, e.entry_kaid, e.entry_title, e.entry_level, e.entry_votes, to_char(e.entry_created, $1), e.entry_height, e.is_winner, e.assigned_group_id, e.flagged, e.flag_reason, e.disqualified, e.entry_author_kaid, e.entry_level_locked, AVG(ev.creativity + ev.complexity + ev.execution + ev.interpretation) as avg_score FROM entry e INNER JOIN evaluation ev ON e.entry_id = ev.entry_id WHERE e.contest_id = $2 AND ev.evaluation_complete = true AND e.disqualified = false GROUP BY e.entry_id ORDER BY e.entry_level, avg_score DESC, e.entry_id ASC;", util.DisplayFancyDateFormat, contestId)
+	rows, err := p.db.QueryContext(ctx, "SELECT e.entry_id, e.contest_id, e.entry_url, e.entry_kaid, e.entry_title, e.entry_level, e.entry_votes, e.entry_created, e.entry_height, e.is_winner, e.assigned_group_id, e.flagged, e.flag_reason, e.disqualified, e.entry_author_kaid, e.entry_level_locked, AVG(ev.creativity + ev.complexity + ev.execution + ev.interpretation) as avg_score FROM entry e INNER JOIN evaluation ev ON e.entry_id = ev.entry_id WHERE e.contest_id = $1 AND ev.evaluation_complete = true AND e.disqualified = false GROUP BY e.entry_id ORDER BY e.entry_level, avg_score DESC, e.entry_id ASC;", contestId)
 	if err != nil {
 		return []*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of entries.", err)
 	}
@@ -94,10 +144,12 @@ func GetEntriesByAverageScore(ctx context.Context, contestId int) ([]*model.Entr
 		var groupId *int
 		var authorKaid *string
 
-		if err := rows.Scan(&entry.ID, &entry.Contest.ID, &entry.URL, &entry.Kaid, &entry.Title, &entry.SkillLevel, &entry.Votes, &entry.Created, &entry.Height, &entry.IsWinner, &groupId, &entry.IsFlagged, &entry.FlagReason, &entry.IsDisqualified, &authorKaid, &entry.IsSkillLevelLocked, &entry.AverageScore); err != nil {
+		if err := rows.Scan(&entry.ID, &entry.Contest.ID, &entry.URL, &entry.Kaid, &entry.Title, &entry.SkillLevel, &entry.Votes, &entry.CreatedAt, &entry.Height, &entry.IsWinner, &groupId, &entry.IsFlagged, &entry.FlagReason, &entry.IsDisqualified, &authorKaid, &entry.IsSkillLevelLocked, &entry.AverageScore); err != nil {
 			return []*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of entries.", err)
 		}
 
+		formatEntryDates(&entry)
+
 		if groupId == nil {
 			entry.Group = nil
 		} else {
@@ -113,22 +165,31 @@ func GetEntriesByAverageScore(ctx context.Context, contestId int) ([]*model.Entr
 		entries = append(entries, &entry)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of entries.", err)
+	}
+
 	return entries, nil
 }
 
-func GetEntryById(ctx context.Context, id int) (*model.Entry, error) {
-	row := db.DB.QueryRow("SELECT entry_id, contest_id, entry_url, entry_kaid, entry_title, entry_level, entry_votes, to_char(entry_created, $1) as entry_created, entry_height, is_winner, assigned_group_id, flagged, flag_reason, disqualified, entry_author_kaid, entry_level_locked FROM entry WHERE entry_id = $2;", util.DisplayFancyDateFormat, id)
+func (p *Postgres) GetEntryById(ctx context.Context, id int) (*model.Entry, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT entry_id, contest_id, entry_url, entry_kaid, entry_title, entry_level, entry_votes, entry_created, entry_height, is_winner, assigned_group_id, flagged, flag_reason, disqualified, entry_author_kaid, entry_level_locked FROM entry WHERE entry_id = $1;", id)
 
 	entry := NewEntryModel()
 	var groupId *int
 	var authorKaid *string
-	if err := row.Scan(&entry.ID, &entry.Contest.ID, &entry.URL, &entry.Kaid, &entry.Title, &entry.SkillLevel, &entry.Votes, &entry.Created, &entry.Height, &entry.IsWinner, &groupId, &entry.IsFlagged, &entry.FlagReason, &entry.IsDisqualified, &authorKaid, &entry.IsSkillLevelLocked); err != nil {
+	if err := row.Scan(&entry.ID, &entry.Contest.ID, &entry.URL, &entry.Kaid, &entry.Title, &entry.SkillLevel, &entry.Votes, &entry.CreatedAt, &entry.Height, &entry.IsWinner, &groupId, &entry.IsFlagged, &entry.FlagReason, &entry.IsDisqualified, &authorKaid, &entry.IsSkillLevelLocked); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NewNotFoundError(ctx, "The requested entry does not exist.")
 		}
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while looking up an entry", err)
 	}
 
+	formatEntryDates(&entry)
+
 	if groupId == nil {
 		entry.Group = nil
 	} else {
@@ -144,10 +205,59 @@ func GetEntryById(ctx context.Context, id int) (*model.Entry, error) {
 	return &entry, nil
 }
 
-func GetEntriesByContestantKaid(ctx context.Context, contestantKaid string) ([]*model.Entry, error) {
+// Returns the requested entries keyed by id. Ids that do not exist are left out.
+func (p *Postgres) GetEntriesByIds(ctx context.Context, ids []int) (map[int]*model.Entry, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	entries := map[int]*model.Entry{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT entry_id, contest_id, entry_url, entry_kaid, entry_title, entry_level, entry_votes, entry_created, entry_height, is_winner, assigned_group_id, flagged, flag_reason, disqualified, entry_author_kaid, entry_level_locked FROM entry WHERE entry_id = ANY($1);", pq.Array(ids))
+	if err != nil {
+		return entries, errors.NewInternalError(ctx, "An unexpected error occurred while looking up entries", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := NewEntryModel()
+		var groupId *int
+		var authorKaid *string
+
+		if err := rows.Scan(&entry.ID, &entry.Contest.ID, &entry.URL, &entry.Kaid, &entry.Title, &entry.SkillLevel, &entry.Votes, &entry.CreatedAt, &entry.Height, &entry.IsWinner, &groupId, &entry.IsFlagged, &entry.FlagReason, &entry.IsDisqualified, &authorKaid, &entry.IsSkillLevelLocked); err != nil {
+			return map[int]*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading entries", err)
+		}
+
+		formatEntryDates(&entry)
+
+		if groupId == nil {
+			entry.Group = nil
+		} else {
+			entry.Group.ID = *groupId
+		}
+
+		if authorKaid == nil {
+			entry.Author = nil
+		} else {
+			entry.Author.Kaid = *authorKaid
+		}
+
+		entries[entry.ID] = &entry
+	}
+
+	if err := rows.Err(); err != nil {
+		return map[int]*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading entries", err)
+	}
+
+	return entries, nil
+}
+
+func (p *Postgres) GetEntriesByContestantKaid(ctx context.Context, contestantKaid string) ([]*model.Entry, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	entries := []*model.Entry{}
 
-	rows, err := db.DB.Query("SELECT entry_id, contest_id, entry_url, entry_kaid, entry_title, entry_level, entry_votes, to_char(entry_created, $1) as entry_created, entry_height, is_winner, assigned_group_id, flagged, flag_reason, disqualified, entry_author_kaid, entry_level_locked FROM entry WHERE entry_author_kaid = $2 ORDER BY entry_id DESC;", util.DisplayFancyDateFormat, contestantKaid)
+	rows, err := p.db.QueryContext(ctx, "SELECT entry_id, contest_id, entry_url, entry_kaid, entry_title, entry_level, entry_votes, entry_created, entry_height, is_winner, assigned_group_id, flagged, flag_reason, disqualified, entry_author_kaid, entry_level_locked FROM entry WHERE entry_author_kaid = $1 ORDER BY entry_id DESC;", contestantKaid)
 	if err != nil {
 		return []*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of contestant entries.", err)
 	}
@@ -156,10 +266,12 @@ func GetEntriesByContestantKaid(ctx context.Context, contestantKaid string) ([]*
 		entry := NewEntryModel()
 		var groupId *int
 		var authorKaid *string
-		if err := rows.Scan(&entry.ID, &entry.Contest.ID, &entry.URL, &entry.Kaid, &entry.Title, &entry.SkillLevel, &entry.Votes, &entry.Created, &entry.Height, &entry.IsWinner, &groupId, &entry.IsFlagged, &entry.FlagReason, &entry.IsDisqualified, &authorKaid, &entry.IsSkillLevelLocked); err != nil {
+		if err := rows.Scan(&entry.ID, &entry.Contest.ID, &entry.URL, &entry.Kaid, &entry.Title, &entry.SkillLevel, &entry.Votes, &entry.CreatedAt, &entry.Height, &entry.IsWinner, &groupId, &entry.IsFlagged, &entry.FlagReason, &entry.IsDisqualified, &authorKaid, &entry.IsSkillLevelLocked); err != nil {
 			return []*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of contestant entries.", err)
 		}
 
+		formatEntryDates(&entry)
+
 		if groupId == nil {
 			entry.Group = nil
 		} else {
@@ -175,13 +287,20 @@ func GetEntriesByContestantKaid(ctx context.Context, contestantKaid string) ([]*
 		entries = append(entries, &entry)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of contestant entries.", err)
+	}
+
 	return entries, nil
 }
 
-func GetWinningEntriesByContestId(ctx context.Context, contestId int) ([]*model.Entry, error) {
+func (p *Postgres) GetWinningEntriesByContestId(ctx context.Context, contestId int) ([]*model.Entry, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	entries := []*model.Entry{}
 
-	rows, err := db.DB.Query("SELECT entry_id, contest_id, entry_url, entry_kaid, entry_title, entry_level, entry_votes, to_char(entry_created, $1) as entry_created, entry_height, is_winner, assigned_group_id, flagged, flag_reason, disqualified, entry_author_kaid, entry_level_locked FROM entry WHERE contest_id = $2 AND is_winner = true ORDER BY entry_level ASC;", util.DisplayFancyDateFormat, contestId)
+	rows, err := p.db.QueryContext(ctx, "SELECT entry_id, contest_id, entry_url, entry_kaid, entry_title, entry_level, entry_votes, entry_created, entry_height, is_winner, assigned_group_id, flagged, flag_reason, disqualified, entry_author_kaid, entry_level_locked FROM entry WHERE contest_id = $1 AND is_winner = true ORDER BY entry_level ASC;", contestId)
 	if err != nil {
 		return []*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the contest winners.", err)
 	}
@@ -192,10 +311,12 @@ func GetWinningEntriesByContestId(ctx context.Context, contestId int) ([]*model.
 		var groupId *int
 		var authorKaid *string
 
-		if err := rows.Scan(&entry.ID, &entry.Contest.ID, &entry.URL, &entry.Kaid, &entry.Title, &entry.SkillLevel, &entry.Votes, &entry.Created, &entry.Height, &entry.IsWinner, &groupId, &entry.IsFlagged, &entry.FlagReason, &entry.IsDisqualified, &authorKaid, &entry.IsSkillLevelLocked); err != nil {
+		if err := rows.Scan(&entry.ID, &entry.Contest.ID, &entry.URL, &entry.Kaid, &entry.Title, &entry.SkillLevel, &entry.Votes, &entry.CreatedAt, &entry.Height, &entry.IsWinner, &groupId, &entry.IsFlagged, &entry.FlagReason, &entry.IsDisqualified, &authorKaid, &entry.IsSkillLevelLocked); err != nil {
 			return []*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the contest winners.", err)
 		}
 
+		formatEntryDates(&entry)
+
 		if groupId == nil {
 			entry.Group = nil
 		} else {
@@ -211,11 +332,18 @@ func GetWinningEntriesByContestId(ctx context.Context, contestId int) ([]*model.
 		entries = append(entries, &entry)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the contest winners.", err)
+	}
+
 	return entries, nil
 }
 
-func GetEntryAverageScore(ctx context.Context, id int) (*float64, error) {
-	row := db.DB.QueryRow("SELECT AVG(creativity + complexity + interpretation + execution) as avg_score FROM evaluation WHERE entry_id = $1", id)
+func (p *Postgres) GetEntryAverageScore(ctx context.Context, id int) (*float64, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT AVG(creativity + complexity + interpretation + execution) as avg_score FROM evaluation WHERE entry_id = $1", id)
 
 	var avgScore *float64
 	if err := row.Scan(&avgScore); err != nil {
@@ -228,10 +356,42 @@ func GetEntryAverageScore(ctx context.Context, id int) (*float64, error) {
 	return avgScore, nil
 }
 
-func GetFlaggedEntries(ctx context.Context) ([]*model.Entry, error) {
+// Returns the average score of each requested entry. Entries without evaluations are left out.
+func (p *Postgres) GetEntryAverageScores(ctx context.Context, ids []int) (map[int]*float64, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	scores := map[int]*float64{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT entry_id, AVG(creativity + complexity + interpretation + execution) as avg_score FROM evaluation WHERE entry_id = ANY($1) GROUP BY entry_id;", pq.Array(ids))
+	if err != nil {
+		return scores, errors.NewInternalError(ctx, "An unexpected error occurred while determining entry average scores", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var avgScore *float64
+		if err := rows.Scan(&id, &avgScore); err != nil {
+			return map[int]*float64{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading entry average scores", err)
+		}
+		scores[id] = avgScore
+	}
+
+	if err := rows.Err(); err != nil {
+		return map[int]*float64{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading entry average scores", err)
+	}
+
+	return scores, nil
+}
+
+func (p *Postgres) GetFlaggedEntries(ctx context.Context) ([]*model.Entry, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	entries := []*model.Entry{}
 
-	rows, err := db.DB.Query("SELECT entry_id, contest_id, entry_url, entry_kaid, entry_title, entry_level, entry_votes, to_char(entry_created, $1) as entry_created, entry_height, is_winner, assigned_group_id, flagged, flag_reason, disqualified, entry_author_kaid, entry_level_locked FROM entry WHERE flagged = true AND disqualified = false ORDER BY entry_id ASC;", util.DisplayFancyDateFormat)
+	rows, err := p.db.QueryContext(ctx, "SELECT entry_id, contest_id, entry_url, entry_kaid, entry_title, entry_level, entry_votes, entry_created, entry_height, is_winner, assigned_group_id, flagged, flag_reason, disqualified, entry_author_kaid, entry_level_locked FROM entry WHERE flagged = true AND disqualified = false ORDER BY entry_id ASC;")
 	if err != nil {
 		return []*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of flagged entries.", err)
 	}
@@ -241,10 +401,12 @@ func GetFlaggedEntries(ctx context.Context) ([]*model.Entry, error) {
 		var groupId *int
 		var authorKaid *string
 
-		if err := rows.Scan(&entry.ID, &entry.Contest.ID, &entry.URL, &entry.Kaid, &entry.Title, &entry.SkillLevel, &entry.Votes, &entry.Created, &entry.Height, &entry.IsWinner, &groupId, &entry.IsFlagged, &entry.FlagReason, &entry.IsDisqualified, &authorKaid, &entry.IsSkillLevelLocked); err != nil {
+		if err := rows.Scan(&entry.ID, &entry.Contest.ID, &entry.URL, &entry.Kaid, &entry.Title, &entry.SkillLevel, &entry.Votes, &entry.CreatedAt, &entry.Height, &entry.IsWinner, &groupId, &entry.IsFlagged, &entry.FlagReason, &entry.IsDisqualified, &authorKaid, &entry.IsSkillLevelLocked); err != nil {
 			return []*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of flagged entries.", err)
 		}
 
+		formatEntryDates(&entry)
+
 		if groupId == nil {
 			entry.Group = nil
 		} else {
@@ -260,11 +422,92 @@ func GetFlaggedEntries(ctx context.Context) ([]*model.Entry, error) {
 		entries = append(entries, &entry)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.Entry{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of flagged entries.", err)
+	}
+
 	return entries, nil
 }
 
-func GetEntryEvaluationCount(ctx context.Context, id int) (int, error) {
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM evaluation WHERE entry_id = $1 AND evaluation_complete = true;", id)
+// Returns a page of the flagged entries that have not been disqualified, ordered by id
+func (p *Postgres) GetFlaggedEntriesPage(ctx context.Context, args PageArgs) (*Page[*model.Entry], error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	afterId := 0
+	if args.After != "" {
+		if err := DecodeCursor(args.After, &afterId); err != nil {
+			return nil, errors.NewBadRequestError(ctx, "Oops! The cursor provided is not valid.")
+		}
+	}
+
+	var totalCount int
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entry WHERE flagged = true AND disqualified = false;")
+	if err := row.Scan(&totalCount); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while counting the flagged entries.", err)
+	}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT entry_id, contest_id, entry_url, entry_kaid, entry_title, entry_level, entry_votes, entry_created, entry_height, is_winner, assigned_group_id, flagged, flag_reason, disqualified, entry_author_kaid, entry_level_locked FROM entry WHERE flagged = true AND disqualified = false AND entry_id > $1 ORDER BY entry_id ASC LIMIT $2;", afterId, args.First+1)
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of flagged entries.", err)
+	}
+	defer rows.Close()
+
+	entries, err := scanEntryRows(ctx, rows, "An unexpected error occurred while reading the list of flagged entries.")
+	if err != nil {
+		return nil, err
+	}
+
+	return newPage(entries, args.First, totalCount, EntryCursor), nil
+}
+
+// Returns the cursor of an entry in a paginated list of entries
+func EntryCursor(entry *model.Entry) string {
+	return EncodeCursor(entry.ID)
+}
+
+// Reads entries selected with the standard entry columns
+func scanEntryRows(ctx context.Context, rows *sql.Rows, errorMessage string) ([]*model.Entry, error) {
+	entries := []*model.Entry{}
+
+	for rows.Next() {
+		entry := NewEntryModel()
+		var groupId *int
+		var authorKaid *string
+
+		if err := rows.Scan(&entry.ID, &entry.Contest.ID, &entry.URL, &entry.Kaid, &entry.Title, &entry.SkillLevel, &entry.Votes, &entry.CreatedAt, &entry.Height, &entry.IsWinner, &groupId, &entry.IsFlagged, &entry.FlagReason, &entry.IsDisqualified, &authorKaid, &entry.IsSkillLevelLocked); err != nil {
+			return nil, errors.NewInternalError(ctx, errorMessage, err)
+		}
+
+		formatEntryDates(&entry)
+
+		if groupId == nil {
+			entry.Group = nil
+		} else {
+			entry.Group.ID = *groupId
+		}
+
+		if authorKaid == nil {
+			entry.Author = nil
+		} else {
+			entry.Author.Kaid = *authorKaid
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, errorMessage, err)
+	}
+
+	return entries, nil
+}
+
+func (p *Postgres) GetEntryEvaluationCount(ctx context.Context, id int) (int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM evaluation WHERE entry_id = $1 AND evaluation_complete = true;", id)
 
 	var count int
 	if err := row.Scan(&count); err != nil {
@@ -277,8 +520,39 @@ func GetEntryEvaluationCount(ctx context.Context, id int) (int, error) {
 	return count, nil
 }
 
-func GetEntryVoteCount(ctx context.Context, id int) (int, error) {
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM entry_vote WHERE entry_id = $1;", id)
+// Returns the number of completed evaluations for each requested entry. Entries without any are left out.
+func (p *Postgres) GetEntryEvaluationCounts(ctx context.Context, ids []int) (map[int]int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	counts := map[int]int{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT entry_id, COUNT(*) FROM evaluation WHERE entry_id = ANY($1) AND evaluation_complete = true GROUP BY entry_id;", pq.Array(ids))
+	if err != nil {
+		return counts, errors.NewInternalError(ctx, "An unexpected error occurred while determining entry evaluation counts", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, count int
+		if err := rows.Scan(&id, &count); err != nil {
+			return map[int]int{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading entry evaluation counts", err)
+		}
+		counts[id] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return map[int]int{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading entry evaluation counts", err)
+	}
+
+	return counts, nil
+}
+
+func (p *Postgres) GetEntryVoteCount(ctx context.Context, id int) (int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entry_vote WHERE entry_id = $1;", id)
 
 	var count int
 	if err := row.Scan(&count); err != nil {
@@ -291,10 +565,41 @@ func GetEntryVoteCount(ctx context.Context, id int) (int, error) {
 	return count, nil
 }
 
-func GetEntryVotes(ctx context.Context, entryId int) ([]*model.EntryVote, error) {
+// Returns the number of votes for each requested entry. Entries without any are left out.
+func (p *Postgres) GetEntryVoteCounts(ctx context.Context, ids []int) (map[int]int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	counts := map[int]int{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT entry_id, COUNT(*) FROM entry_vote WHERE entry_id = ANY($1) GROUP BY entry_id;", pq.Array(ids))
+	if err != nil {
+		return counts, errors.NewInternalError(ctx, "An unexpected error occurred while determining entry vote counts", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, count int
+		if err := rows.Scan(&id, &count); err != nil {
+			return map[int]int{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading entry vote counts", err)
+		}
+		counts[id] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return map[int]int{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading entry vote counts", err)
+	}
+
+	return counts, nil
+}
+
+func (p *Postgres) GetEntryVotes(ctx context.Context, entryId int) ([]*model.EntryVote, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	entryVotes := []*model.EntryVote{}
 
-	rows, err := db.DB.Query("SELECT vote_id, evaluator_id, feedback FROM entry_vote WHERE entry_id = $1", entryId)
+	rows, err := p.db.QueryContext(ctx, "SELECT vote_id, evaluator_id, feedback FROM entry_vote WHERE entry_id = $1", entryId)
 	if err != nil {
 		return []*model.EntryVote{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of entry votes.", err)
 	}
@@ -310,13 +615,20 @@ func GetEntryVotes(ctx context.Context, entryId int) ([]*model.EntryVote, error)
 		entryVotes = append(entryVotes, &e)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.EntryVote{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of entry votes.", err)
+	}
+
 	return entryVotes, nil
 }
 
-func GetEntriesPerLevel(ctx context.Context, contestId int) ([]*model.EntriesPerLevel, error) {
+func (p *Postgres) GetEntriesPerLevel(ctx context.Context, contestId int) ([]*model.EntriesPerLevel, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	entriesPerLevel := []*model.EntriesPerLevel{}
 
-	rows, err := db.DB.Query("SELECT COUNT(*), entry_level FROM entry WHERE contest_id = $1 AND disqualified = false GROUP BY entry_level;", contestId)
+	rows, err := p.db.QueryContext(ctx, "SELECT COUNT(*), entry_level FROM entry WHERE contest_id = $1 AND disqualified = false GROUP BY entry_level;", contestId)
 	if err != nil {
 		return []*model.EntriesPerLevel{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the number of entries per level.", err)
 	}
@@ -331,17 +643,24 @@ func GetEntriesPerLevel(ctx context.Context, contestId int) ([]*model.EntriesPer
 		entriesPerLevel = append(entriesPerLevel, &count)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.EntriesPerLevel{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the number of entries per level.", err)
+	}
+
 	return entriesPerLevel, nil
 }
 
-func IsEntryVotedByUser(ctx context.Context, entryId int) (*bool, error) {
+func (p *Postgres) IsEntryVotedByUser(ctx context.Context, entryId int) (*bool, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	user := auth.GetUserFromContext(ctx)
 
 	if user == nil {
 		return nil, nil
 	}
 
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM entry_vote WHERE entry_id = $1 AND evaluator_id = $2", entryId, user.ID)
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entry_vote WHERE entry_id = $1 AND evaluator_id = $2", entryId, user.ID)
 
 	var count int
 	var wasVoted bool
@@ -361,13 +680,44 @@ func IsEntryVotedByUser(ctx context.Context, entryId int) (*bool, error) {
 	return &wasVoted, nil
 }
 
-func GetNextEntryToJudge(ctx context.Context) (*int, error) {
+// Returns the subset of the requested entries that the user has voted for
+func (p *Postgres) GetEntriesVotedByUser(ctx context.Context, userId int, ids []int) (map[int]bool, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	voted := map[int]bool{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT DISTINCT entry_id FROM entry_vote WHERE evaluator_id = $1 AND entry_id = ANY($2);", userId, pq.Array(ids))
+	if err != nil {
+		return voted, errors.NewInternalError(ctx, "An unexpected error occurred while determining which entries the user voted for", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return map[int]bool{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading which entries the user voted for", err)
+		}
+		voted[id] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return map[int]bool{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading which entries the user voted for", err)
+	}
+
+	return voted, nil
+}
+
+func (p *Postgres) GetNextEntryToJudge(ctx context.Context) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	user := auth.GetUserFromContext(ctx)
 	if user == nil {
 		return nil, nil
 	}
 
-	row := db.DB.QueryRow("SELECT * FROM get_entry_and_create_placeholder($1)", user.ID)
+	row := p.db.QueryRowContext(ctx, "SELECT * FROM get_entry_and_create_placeholder($1)", user.ID)
 
 	var ID *int
 	var url, title, height *string
@@ -385,8 +735,11 @@ func GetNextEntryToJudge(ctx context.Context) (*int, error) {
 	return ID, nil
 }
 
-func GetNextEntryToReviewSkillLevel(ctx context.Context) (*int, error) {
-	row := db.DB.QueryRow("SELECT entry_id FROM entry WHERE entry_level_locked = false AND disqualified = false ORDER BY entry_id DESC LIMIT 1;")
+func (p *Postgres) GetNextEntryToReviewSkillLevel(ctx context.Context) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT entry_id FROM entry WHERE entry_level_locked = false AND disqualified = false ORDER BY entry_id DESC LIMIT 1;")
 
 	var ID *int
 	if err := row.Scan(&ID); err != nil {
@@ -399,72 +752,99 @@ func GetNextEntryToReviewSkillLevel(ctx context.Context) (*int, error) {
 	return ID, nil
 }
 
-func AddWinnerByEntryId(ctx context.Context, id int) error {
-	_, err := db.DB.Exec("UPDATE entry SET is_winner = true WHERE entry_id = $1;", id)
+func (p *Postgres) AddWinnerByEntryId(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE entry SET is_winner = true WHERE entry_id = $1;", id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while adding a winning entry", err)
 	}
 	return nil
 }
 
-func RemoveWinnerByEntryId(ctx context.Context, id int) error {
-	_, err := db.DB.Exec("UPDATE entry SET is_winner = false WHERE entry_id = $1;", id)
+func (p *Postgres) RemoveWinnerByEntryId(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE entry SET is_winner = false WHERE entry_id = $1;", id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while removing a winning entry", err)
 	}
 	return nil
 }
 
-func FlagEntryById(ctx context.Context, id int, reason string) error {
-	_, err := db.DB.Exec("UPDATE entry SET flagged = true, flag_reason = $1 WHERE entry_id = $2;", reason, id)
+func (p *Postgres) FlagEntryById(ctx context.Context, id int, reason string) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE entry SET flagged = true, flag_reason = $1 WHERE entry_id = $2;", reason, id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while flagging an entry", err)
 	}
 	return nil
 }
 
-func ApproveEntryById(ctx context.Context, id int) error {
-	_, err := db.DB.Exec("UPDATE entry SET flagged = false, disqualified = false WHERE entry_id = $1;", id)
+func (p *Postgres) ApproveEntryById(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE entry SET flagged = false, disqualified = false WHERE entry_id = $1;", id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while approving an entry", err)
 	}
 	return nil
 }
 
-func DisqualifyEntryById(ctx context.Context, id int) error {
-	_, err := db.DB.Exec("UPDATE entry SET disqualified = true WHERE entry_id = $1;", id)
+func (p *Postgres) DisqualifyEntryById(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE entry SET disqualified = true WHERE entry_id = $1;", id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while disqualifying an entry", err)
 	}
 	return nil
 }
 
-func DeleteEntryById(ctx context.Context, id int) error {
-	_, err := db.DB.Exec("DELETE FROM entry WHERE entry_id = $1;", id)
+func (p *Postgres) DeleteEntryById(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM entry WHERE entry_id = $1;", id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while deleting an entry", err)
 	}
 	return nil
 }
 
-func EditEntryById(ctx context.Context, id int, input *model.EditEntryInput) error {
-	_, err := db.DB.Exec("UPDATE entry SET entry_title = $1, entry_level = $2, entry_height = $3, assigned_group_id = $4, flagged = $5, disqualified = $6, entry_level_locked = $7 WHERE entry_id = $8", input.Title, input.SkillLevel, input.Height, input.Group, input.IsFlagged, input.IsDisqualified, input.IsSkillLevelLocked, id)
+func (p *Postgres) EditEntryById(ctx context.Context, id int, input *model.EditEntryInput) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE entry SET entry_title = $1, entry_level = $2, entry_height = $3, assigned_group_id = $4, flagged = $5, disqualified = $6, entry_level_locked = $7 WHERE entry_id = $8", input.Title, input.SkillLevel, input.Height, input.Group, input.IsFlagged, input.IsDisqualified, input.IsSkillLevelLocked, id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while editing an entry", err)
 	}
 	return nil
 }
 
-func SetEntryLevelById(ctx context.Context, id int, skillLevel string) error {
-	_, err := db.DB.Exec("UPDATE entry SET entry_level = $1, entry_level_locked = true WHERE entry_id = $2;", skillLevel, id)
+func (p *Postgres) SetEntryLevelById(ctx context.Context, id int, skillLevel string) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE entry SET entry_level = $1, entry_level_locked = true WHERE entry_id = $2;", skillLevel, id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while setting an entry's skill level", err)
 	}
 	return nil
 }
 
-func GetEntryVoteById(ctx context.Context, id int) (*model.EntryVote, error) {
-	row := db.DB.QueryRow("SELECT vote_id, evaluator_id, feedback FROM entry_vote WHERE vote_id = $1;", id)
+func (p *Postgres) GetEntryVoteById(ctx context.Context, id int) (*model.EntryVote, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT vote_id, evaluator_id, feedback FROM entry_vote WHERE vote_id = $1;", id)
 
 	entryVote := NewEntryVoteModel()
 	if err := row.Scan(&entryVote.ID, &entryVote.User.ID, &entryVote.Reason); err != nil {
@@ -477,8 +857,11 @@ func GetEntryVoteById(ctx context.Context, id int) (*model.EntryVote, error) {
 	return &entryVote, nil
 }
 
-func CreateEntryVote(ctx context.Context, entryId int, userId int, reason string) (*int, error) {
-	row := db.DB.QueryRow("INSERT INTO entry_vote (entry_id, evaluator_id, feedback) VALUES ($1, $2, $3) RETURNING vote_id;", entryId, userId, reason)
+func (p *Postgres) CreateEntryVote(ctx context.Context, entryId int, userId int, reason string) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "INSERT INTO entry_vote (entry_id, evaluator_id, feedback) VALUES ($1, $2, $3) RETURNING vote_id;", entryId, userId, reason)
 
 	var id *int
 	if err := row.Scan(&id); err != nil {
@@ -488,16 +871,22 @@ func CreateEntryVote(ctx context.Context, entryId int, userId int, reason string
 	return id, nil
 }
 
-func DeleteEntryVoteById(ctx context.Context, id int) error {
-	_, err := db.DB.Exec("DELETE FROM entry_vote WHERE vote_id = $1;", id)
+func (p *Postgres) DeleteEntryVoteById(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM entry_vote WHERE vote_id = $1;", id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while deleting an entry vote", err)
 	}
 	return nil
 }
 
-func GetContestIdByVoteId(ctx context.Context, voteId int) (*int, error) {
-	row := db.DB.QueryRow("SELECT e.contest_id FROM entry_vote v INNER JOIN entry e ON e.entry_id = v.entry_id WHERE v.vote_id = $1;", voteId)
+func (p *Postgres) GetContestIdByVoteId(ctx context.Context, voteId int) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT e.contest_id FROM entry_vote v INNER JOIN entry e ON e.entry_id = v.entry_id WHERE v.vote_id = $1;", voteId)
 
 	var contestId *int
 	if err := row.Scan(&contestId); err != nil {
@@ -510,8 +899,11 @@ func GetContestIdByVoteId(ctx context.Context, voteId int) (*int, error) {
 	return contestId, nil
 }
 
-func CreateEntry(ctx context.Context, contestId int, input *EntryInput) (*int, error) {
-	row := db.DB.QueryRow("INSERT INTO entry (contest_id, entry_url, entry_kaid, entry_title, entry_author, entry_votes, entry_created, entry_author_kaid) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT(contest_id, entry_kaid) DO UPDATE SET entry_title = excluded.entry_title, entry_author = excluded.entry_author, entry_votes = excluded.entry_votes RETURNING entry_id;", contestId, input.URL, input.Kaid, input.Title, input.AuthorName, input.Votes, input.Created, input.AuthorKaid)
+func (p *Postgres) CreateEntry(ctx context.Context, contestId int, input *EntryInput) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "INSERT INTO entry (contest_id, entry_url, entry_kaid, entry_title, entry_author, entry_votes, entry_created, entry_author_kaid) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT(contest_id, entry_kaid) DO UPDATE SET entry_title = excluded.entry_title, entry_author = excluded.entry_author, entry_votes = excluded.entry_votes RETURNING entry_id;", contestId, input.URL, input.Kaid, input.Title, input.AuthorName, input.Votes, input.Created, input.AuthorKaid)
 
 	var id int
 	if err := row.Scan(&id); err != nil {
@@ -521,40 +913,48 @@ func CreateEntry(ctx context.Context, contestId int, input *EntryInput) (*int, e
 	return &id, nil
 }
 
-func AssignAllEntriesToGroups(ctx context.Context, contestId int) error {
-	groups, err := GetActiveJudgingGroups(ctx)
+func (p *Postgres) AssignAllEntriesToGroups(ctx context.Context, contestId int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	groups, err := p.GetActiveJudgingGroups(ctx)
 	if err != nil {
 		return err
 	}
 
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM entry WHERE disqualified = false AND contest_id = $1;", contestId)
+	return p.withTx(ctx, "An unexpected error occurred while assigning all entries to groups", func(tx *sql.Tx) error {
+		row := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM entry WHERE disqualified = false AND contest_id = $1;", contestId)
 
-	var entryCount int
-	if err := row.Scan(&entryCount); err != nil {
-		return errors.NewInternalError(ctx, "An unexpected error occurred while assigning all entries to groups", err)
-	}
+		var entryCount int
+		if err := row.Scan(&entryCount); err != nil {
+			return errors.NewInternalError(ctx, "An unexpected error occurred while assigning all entries to groups", err)
+		}
 
-	offset := 0
-	limit := math.Ceil(float64(entryCount) / float64(len(groups)))
+		offset := 0
+		limit := math.Ceil(float64(entryCount) / float64(len(groups)))
 
-	for i := range groups {
-		_, err := db.DB.Exec("UPDATE entry SET assigned_group_id = $1 WHERE entry_id IN (SELECT entry_id FROM entry WHERE contest_id = $2 AND disqualified = false ORDER BY entry_id ASC LIMIT $3 OFFSET $4) AND contest_id = $2;", groups[i].ID, contestId, limit, offset)
-		if err != nil {
-			return errors.NewInternalError(ctx, "An unexpected error occurred while assigning all entries to groups", err)
+		for i := range groups {
+			_, err := tx.ExecContext(ctx, "UPDATE entry SET assigned_group_id = $1 WHERE entry_id IN (SELECT entry_id FROM entry WHERE contest_id = $2 AND disqualified = false ORDER BY entry_id ASC LIMIT $3 OFFSET $4) AND contest_id = $2;", groups[i].ID, contestId, limit, offset)
+			if err != nil {
+				return errors.NewInternalError(ctx, "An unexpected error occurred while assigning all entries to groups", err)
+			}
+			offset += int(limit)
 		}
-		offset += int(limit)
-	}
 
-	return nil
+		return nil
+	})
 }
 
-func AssignNewEntriesToGroups(ctx context.Context, contestId int) error {
-	groups, err := GetActiveJudgingGroups(ctx)
+func (p *Postgres) AssignNewEntriesToGroups(ctx context.Context, contestId int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	groups, err := p.GetActiveJudgingGroups(ctx)
 	if err != nil {
 		return err
 	}
 
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM entry WHERE disqualified = false AND contest_id = $1 AND assigned_group_id IS NULL;", contestId)
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entry WHERE disqualified = false AND contest_id = $1 AND assigned_group_id IS NULL;", contestId)
 
 	var entryCount int
 	if err := row.Scan(&entryCount); err != nil {
@@ -564,7 +964,7 @@ func AssignNewEntriesToGroups(ctx context.Context, contestId int) error {
 	limit := math.Ceil(float64(entryCount) / float64(len(groups)))
 
 	for i := range groups {
-		_, err := db.DB.Exec("UPDATE entry SET assigned_group_id = $1 WHERE entry_id IN (SELECT entry_id FROM entry WHERE contest_id = $2 AND disqualified = false AND assigned_group_id IS NULL ORDER BY entry_id ASC LIMIT $3) AND contest_id = $2;", groups[i].ID, contestId, limit)
+		_, err := p.db.ExecContext(ctx, "UPDATE entry SET assigned_group_id = $1 WHERE entry_id IN (SELECT entry_id FROM entry WHERE contest_id = $2 AND disqualified = false AND assigned_group_id IS NULL ORDER BY entry_id ASC LIMIT $3) AND contest_id = $2;", groups[i].ID, contestId, limit)
 		if err != nil {
 			return errors.NewInternalError(ctx, "An unexpected error occurred while assigning new entries to groups", err)
 		}
@@ -573,8 +973,11 @@ func AssignNewEntriesToGroups(ctx context.Context, contestId int) error {
 	return nil
 }
 
-func TransferEntryGroups(ctx context.Context, contestId int, prevGroup int, newGroup int) error {
-	_, err := db.DB.Exec("UPDATE entry SET assigned_group_id = $1 WHERE assigned_group_id = $2 AND contest_id = $3;", newGroup, prevGroup, contestId)
+func (p *Postgres) TransferEntryGroups(ctx context.Context, contestId int, prevGroup int, newGroup int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE entry SET assigned_group_id = $1 WHERE assigned_group_id = $2 AND contest_id = $3;", newGroup, prevGroup, contestId)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while transferring entry groups", err)
 	}
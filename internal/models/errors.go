@@ -3,6 +3,7 @@ package models
 import (
 	"context"
 	"database/sql"
+	"math"
 
 	"github.com/KA-Challenge-Council/Bema/graph/model"
 	"github.com/KA-Challenge-Council/Bema/internal/db"
@@ -20,10 +21,18 @@ func NewErrorModel() model.Error {
 	return error
 }
 
-func GetErrorsByPage(ctx context.Context, page int) ([]*model.Error, error) {
+// Fills in the deprecated pre-formatted date fields from their DateTime fields
+func formatErrorDates(e *model.Error) {
+	e.Timestamp = util.FormatDate(e.OccurredAt, util.DisplayFancyDateFormat)
+}
+
+func (p *Postgres) GetErrorsByPage(ctx context.Context, page int) ([]*model.Error, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	errs := []*model.Error{}
 
-	rows, err := db.DB.Query("SELECT error_id, error_message, error_stack, to_char(error_tstz, $1), request_origin, request_referer, user_agent, evaluator_id FROM error ORDER BY error_id DESC LIMIT $2 OFFSET $3;", util.DisplayFancyDateFormat, ERROR_PAGE_SIZE, ERROR_PAGE_SIZE*page)
+	rows, err := p.db.QueryContext(ctx, "SELECT error_id, error_message, error_stack, error_tstz, request_origin, request_referer, user_agent, evaluator_id FROM error ORDER BY error_id DESC LIMIT $1 OFFSET $2;", ERROR_PAGE_SIZE, ERROR_PAGE_SIZE*page)
 	if err != nil {
 		return []*model.Error{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of logged errors", err)
 	}
@@ -31,33 +40,98 @@ func GetErrorsByPage(ctx context.Context, page int) ([]*model.Error, error) {
 	for rows.Next() {
 		e := NewErrorModel()
 
-		if err := rows.Scan(&e.ID, &e.Message, &e.Stack, &e.Timestamp, &e.RequestOrigin, &e.RequestReferrer, &e.RequestUserAgent, &e.User.ID); err != nil {
+		if err := rows.Scan(&e.ID, &e.Message, &e.Stack, &e.OccurredAt, &e.RequestOrigin, &e.RequestReferrer, &e.RequestUserAgent, &e.User.ID); err != nil {
 			return []*model.Error{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of logged errors", err)
 		}
 
+		formatErrorDates(&e)
+
 		errs = append(errs, &e)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.Error{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of logged errors", err)
+	}
+
 	return errs, nil
 }
 
-func GetErrorById(ctx context.Context, id int) (*model.Error, error) {
-	row := db.DB.QueryRow("SELECT error_id, error_message, error_stack, to_char(error_tstz, $1), request_origin, request_referer, user_agent, evaluator_id FROM error WHERE error_id = $2;", util.DisplayFancyDateFormat, id)
+// Returns a page of logged errors, newest first
+func (p *Postgres) GetErrorsPage(ctx context.Context, args PageArgs) (*Page[*model.Error], error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	// Error ids are never negative, so this sorts after every error when there is no cursor
+	afterId := math.MaxInt32
+	if args.After != "" {
+		if err := DecodeCursor(args.After, &afterId); err != nil {
+			return nil, errors.NewBadRequestError(ctx, "Oops! The cursor provided is not valid.")
+		}
+	}
+
+	var totalCount int
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM error;")
+	if err := row.Scan(&totalCount); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while counting the logged errors", err)
+	}
+
+	errs := []*model.Error{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT error_id, error_message, error_stack, error_tstz, request_origin, request_referer, user_agent, evaluator_id FROM error WHERE error_id < $1 ORDER BY error_id DESC LIMIT $2;", afterId, args.First+1)
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of logged errors", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e := NewErrorModel()
+
+		if err := rows.Scan(&e.ID, &e.Message, &e.Stack, &e.OccurredAt, &e.RequestOrigin, &e.RequestReferrer, &e.RequestUserAgent, &e.User.ID); err != nil {
+			return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of logged errors", err)
+		}
+
+		formatErrorDates(&e)
+
+		errs = append(errs, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of logged errors", err)
+	}
+
+	return newPage(errs, args.First, totalCount, ErrorCursor), nil
+}
+
+// Returns the cursor of an error in a paginated list of errors
+func ErrorCursor(e *model.Error) string {
+	return EncodeCursor(e.ID)
+}
+
+func (p *Postgres) GetErrorById(ctx context.Context, id int) (*model.Error, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT error_id, error_message, error_stack, error_tstz, request_origin, request_referer, user_agent, evaluator_id FROM error WHERE error_id = $1;", id)
 
 	e := NewErrorModel()
 	e.User = &model.User{}
-	if err := row.Scan(&e.ID, &e.Message, &e.Stack, &e.Timestamp, &e.RequestOrigin, &e.RequestReferrer, &e.RequestUserAgent, &e.User.ID); err != nil {
+	if err := row.Scan(&e.ID, &e.Message, &e.Stack, &e.OccurredAt, &e.RequestOrigin, &e.RequestReferrer, &e.RequestUserAgent, &e.User.ID); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NewNotFoundError(ctx, "Oops! The requested error does not exist.")
 		}
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the requested error", err)
 	}
 
+	formatErrorDates(&e)
+
 	return &e, nil
 }
 
-func DeleteErrorById(ctx context.Context, id int) error {
-	_, err := db.DB.Exec("DELETE FROM error WHERE error_id = $1;", id)
+func (p *Postgres) DeleteErrorById(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM error WHERE error_id = $1;", id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while deleting a logged error", err)
 	}
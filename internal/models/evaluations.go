@@ -23,68 +23,84 @@ func NewEvaluationModel() model.Evaluation {
 	return evaluation
 }
 
-func GetEvaluationById(ctx context.Context, id int) (*model.Evaluation, error) {
-	row := db.DB.QueryRow("SELECT ev.evaluation_id, ev.entry_id, ev.evaluator_id, ev.creativity, ev.complexity, ev.execution, ev.interpretation, to_char(ev.evaluation_tstz, $1), ev.evaluation_level, en.contest_id FROM evaluation ev INNER JOIN entry en ON en.entry_id = ev.entry_id WHERE evaluation_id = $2;", util.DisplayFancyDateFormat, id)
+// Fills in the deprecated pre-formatted date fields from their DateTime fields
+func formatEvaluationDates(e *model.Evaluation) {
+	e.Created = util.FormatDate(e.CreatedAt, util.DisplayFancyDateFormat)
+}
+
+// Reports whether the user can change an evaluation by the owner. Evaluations can only change while
+// the contest is being judged, by their judge or users with Edit All Evaluations permission.
+func EvaluationIsEditable(user *auth.User, ownerId int, state model.ContestState) bool {
+	if user == nil || state != model.ContestStateJudging {
+		return false
+	}
+	return ownerId == user.ID || auth.HasPermission(user, auth.EditAllEvaluations)
+}
+
+func (p *Postgres) GetEvaluationById(ctx context.Context, id int) (*model.Evaluation, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT ev.evaluation_id, ev.entry_id, ev.evaluator_id, ev.creativity, ev.complexity, ev.execution, ev.interpretation, ev.evaluation_tstz, ev.evaluation_level, c.contest_state FROM evaluation ev INNER JOIN entry en ON en.entry_id = ev.entry_id INNER JOIN contest c ON c.contest_id = en.contest_id WHERE evaluation_id = $1;", id)
 
 	e := NewEvaluationModel()
-	var contestId int
-	if err := row.Scan(&e.ID, &e.Entry.ID, &e.User.ID, &e.Creativity, &e.Complexity, &e.Execution, &e.Interpretation, &e.Created, &e.SkillLevel, &contestId); err != nil {
+	var contestState model.ContestState
+	if err := row.Scan(&e.ID, &e.Entry.ID, &e.User.ID, &e.Creativity, &e.Complexity, &e.Execution, &e.Interpretation, &e.CreatedAt, &e.SkillLevel, &contestState); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NewNotFoundError(ctx, "This evaluation does not exist.")
 		}
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving an evaluation.", err)
 	}
-	e.Total = e.Creativity + e.Complexity + e.Execution + e.Interpretation
-	e.CanEdit = false
-
-	currentContest, err := GetCurrentContest(ctx)
-	if err != nil || currentContest == nil {
-		return nil, err
-	}
 
-	user := auth.GetUserFromContext(ctx)
-
-	if (contestId == currentContest.ID && e.User.ID == user.ID) || user.Permissions.EditAllEvaluations {
-		e.CanEdit = true
-	}
+	formatEvaluationDates(&e)
+	e.Total = e.Creativity + e.Complexity + e.Execution + e.Interpretation
+	e.CanEdit = EvaluationIsEditable(auth.GetUserFromContext(ctx), e.User.ID, contestState)
 
 	return &e, nil
 }
 
-func GetEvaluationsForUserAndContest(ctx context.Context, userId int, contestId int) ([]*model.Evaluation, error) {
+func (p *Postgres) GetEvaluationsForUserAndContest(ctx context.Context, userId int, contestId int) ([]*model.Evaluation, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	evaluations := []*model.Evaluation{}
 	user := auth.GetUserFromContext(ctx)
 
-	currentContest, err := GetCurrentContest(ctx)
-	if err != nil || currentContest == nil {
-		return []*model.Evaluation{}, err
+	var contestState model.ContestState
+	if err := p.db.QueryRowContext(ctx, "SELECT contest_state FROM contest WHERE contest_id = $1;", contestId).Scan(&contestState); err != nil && err != sql.ErrNoRows {
+		return []*model.Evaluation{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of evaluations", err)
 	}
 
-	rows, err := db.DB.Query("SELECT ev.evaluation_id, ev.entry_id, ev.evaluator_id, ev.creativity, ev.complexity, ev.execution, ev.interpretation, to_char(ev.evaluation_tstz, $1), ev.evaluation_level FROM evaluation ev INNER JOIN entry e ON e.entry_id = ev.entry_id WHERE ev.evaluator_id = $2 AND e.contest_id = $3 AND ev.evaluation_complete = true ORDER BY ev.evaluation_id ASC;", util.DisplayFancyDateFormat, userId, contestId)
+	rows, err := p.db.QueryContext(ctx, "SELECT ev.evaluation_id, ev.entry_id, ev.evaluator_id, ev.creativity, ev.complexity, ev.execution, ev.interpretation, ev.evaluation_tstz, ev.evaluation_level FROM evaluation ev INNER JOIN entry e ON e.entry_id = ev.entry_id WHERE ev.evaluator_id = $1 AND e.contest_id = $2 AND ev.evaluation_complete = true ORDER BY ev.evaluation_id ASC;", userId, contestId)
 	if err != nil {
 		return []*model.Evaluation{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of evaluations", err)
 	}
 
 	for rows.Next() {
 		e := NewEvaluationModel()
-		if err := rows.Scan(&e.ID, &e.Entry.ID, &e.User.ID, &e.Creativity, &e.Complexity, &e.Execution, &e.Interpretation, &e.Created, &e.SkillLevel); err != nil {
+		if err := rows.Scan(&e.ID, &e.Entry.ID, &e.User.ID, &e.Creativity, &e.Complexity, &e.Execution, &e.Interpretation, &e.CreatedAt, &e.SkillLevel); err != nil {
 			return []*model.Evaluation{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of evaluations", err)
 		}
-		e.Total = e.Creativity + e.Complexity + e.Execution + e.Interpretation
-		e.CanEdit = false
 
-		if (contestId == currentContest.ID && userId == user.ID) || user.Permissions.EditAllEvaluations {
-			e.CanEdit = true
-		}
+		formatEvaluationDates(&e)
+		e.Total = e.Creativity + e.Complexity + e.Execution + e.Interpretation
+		e.CanEdit = EvaluationIsEditable(user, userId, contestState)
 
 		evaluations = append(evaluations, &e)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.Evaluation{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of evaluations", err)
+	}
+
 	return evaluations, nil
 }
 
-func GetUserTotalEvaluations(ctx context.Context, userId int) (*int, error) {
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM evaluation WHERE evaluator_id = $1 AND evaluation_complete = true;", userId)
+func (p *Postgres) GetUserTotalEvaluations(ctx context.Context, userId int) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM evaluation WHERE evaluator_id = $1 AND evaluation_complete = true;", userId)
 
 	var count *int
 	if err := row.Scan(&count); err != nil {
@@ -97,8 +113,11 @@ func GetUserTotalEvaluations(ctx context.Context, userId int) (*int, error) {
 	return count, nil
 }
 
-func GetUserTotalContestsJudged(ctx context.Context, userId int) (*int, error) {
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM contest c WHERE EXISTS (SELECT evaluation_id FROM evaluation ev INNER JOIN entry en ON en.entry_id = ev.entry_id WHERE en.contest_id = c.contest_id AND ev.evaluator_id = $1 AND ev.evaluation_complete = true);", userId)
+func (p *Postgres) GetUserTotalContestsJudged(ctx context.Context, userId int) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM contest c WHERE EXISTS (SELECT evaluation_id FROM evaluation ev INNER JOIN entry en ON en.entry_id = ev.entry_id WHERE en.contest_id = c.contest_id AND ev.evaluator_id = $1 AND ev.evaluation_complete = true);", userId)
 
 	var count *int
 	if err := row.Scan(&count); err != nil {
@@ -111,16 +130,22 @@ func GetUserTotalContestsJudged(ctx context.Context, userId int) (*int, error) {
 	return count, nil
 }
 
-func EditEvaluationById(ctx context.Context, id int, input *model.EditEvaluationInput) error {
-	_, err := db.DB.Exec("UPDATE evaluation SET creativity = $1, complexity = $2, execution = $3, interpretation = $4, evaluation_level = $5 WHERE evaluation_id = $6", input.Creativity, input.Complexity, input.Execution, input.Interpretation, input.SkillLevel, id)
+func (p *Postgres) EditEvaluationById(ctx context.Context, id int, input *model.EditEvaluationInput) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE evaluation SET creativity = $1, complexity = $2, execution = $3, interpretation = $4, evaluation_level = $5 WHERE evaluation_id = $6", input.Creativity, input.Complexity, input.Execution, input.Interpretation, input.SkillLevel, id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while updating an evaluation.", err)
 	}
 	return nil
 }
 
-func DeleteEvaluationById(ctx context.Context, id int) error {
-	_, err := db.DB.Exec("DELETE FROM evaluation WHERE evaluation_id = $1", id)
+func (p *Postgres) DeleteEvaluationById(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM evaluation WHERE evaluation_id = $1", id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while deleting an evaluation.", err)
 	}
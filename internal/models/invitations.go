@@ -0,0 +1,181 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const invitationColumns = "invitation_id, invitee_name, email, invitee_kaid, dt_term_start, dt_term_end, role_id, group_id, invited_by, evaluator_id, created_tstz, expires_tstz, accepted_tstz, revoked_tstz"
+
+func scanInvitation(row interface{ Scan(...interface{}) error }) (*model.Invitation, error) {
+	inv := &model.Invitation{}
+	var roleId, groupId, invitedBy, userId *int
+	if err := row.Scan(&inv.ID, &inv.Name, &inv.Email, &inv.Kaid, &inv.TermStartsAt, &inv.TermEndsAt, &roleId, &groupId, &invitedBy, &userId, &inv.CreatedAt, &inv.ExpiresAt, &inv.AcceptedAt, &inv.RevokedAt); err != nil {
+		return nil, err
+	}
+
+	if roleId != nil {
+		inv.Role = &model.Role{ID: *roleId}
+	}
+	if groupId != nil {
+		inv.Group = &model.JudgingGroup{ID: *groupId}
+	}
+	if invitedBy != nil {
+		inv.InvitedBy = &model.User{ID: *invitedBy}
+	}
+	if userId != nil {
+		inv.User = &model.User{ID: *userId}
+	}
+	inv.Status = InvitationStatus(inv, time.Now())
+	return inv, nil
+}
+
+// Returns whether the invitation can still be used at the given time
+func InvitationStatus(inv *model.Invitation, now time.Time) model.InvitationStatus {
+	switch {
+	case inv.AcceptedAt != nil:
+		return model.InvitationStatusAccepted
+	case inv.RevokedAt != nil:
+		return model.InvitationStatusRevoked
+	case !inv.ExpiresAt.After(now):
+		return model.InvitationStatusExpired
+	}
+	return model.InvitationStatusPending
+}
+
+// Returns the invitations, newest first. Unless includeInactive is set, only invitations that can
+// still be accepted are returned.
+func (p *Postgres) GetInvitations(ctx context.Context, includeInactive bool) ([]*model.Invitation, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	invitations := []*model.Invitation{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT "+invitationColumns+" FROM invitation WHERE $1 OR (accepted_tstz IS NULL AND revoked_tstz IS NULL AND expires_tstz > $2) ORDER BY invitation_id DESC;", includeInactive, time.Now().UTC())
+	if err != nil {
+		return invitations, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving invitations", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return []*model.Invitation{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading invitations", err)
+		}
+		invitations = append(invitations, inv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []*model.Invitation{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading invitations", err)
+	}
+
+	return invitations, nil
+}
+
+func (p *Postgres) GetInvitationById(ctx context.Context, id int) (*model.Invitation, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT "+invitationColumns+" FROM invitation WHERE invitation_id = $1;", id)
+
+	inv, err := scanInvitation(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError(ctx, "Oops! The requested invitation does not exist.")
+		}
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving an invitation", err)
+	}
+	return inv, nil
+}
+
+// Stores an invitation by the hash of its token
+func (p *Postgres) CreateInvitation(ctx context.Context, input *model.InviteUserInput, invitedBy int, tokenHash string, expires time.Time) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var id int
+
+	row := p.db.QueryRowContext(ctx, "INSERT INTO invitation (token_hash, invitee_name, email, invitee_kaid, dt_term_start, dt_term_end, role_id, group_id, invited_by, created_tstz, expires_tstz) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING invitation_id;", tokenHash, input.Name, input.Email, input.Kaid, input.TermStart, input.TermEnd, input.RoleID, input.GroupID, invitedBy, time.Now().UTC(), expires.UTC())
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while creating an invitation", err)
+	}
+
+	return &id, nil
+}
+
+// Revokes an invitation, returning a bad request error if it has already been accepted or revoked
+func (p *Postgres) RevokeInvitation(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := p.db.ExecContext(ctx, "UPDATE invitation SET revoked_tstz = $1 WHERE invitation_id = $2 AND accepted_tstz IS NULL AND revoked_tstz IS NULL;", time.Now().UTC(), id)
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while revoking an invitation", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.NewBadRequestError(ctx, "Oops! Only invitations that have not been accepted or revoked can be revoked.")
+	}
+	return nil
+}
+
+// Uses up the invitation with the given token hash and creates the invitee's account with its
+// term, role and group. Returns the id of the new user.
+func (p *Postgres) AcceptInvitation(ctx context.Context, tokenHash string, username string, password string) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while creating your account", err)
+	}
+
+	var userId int
+	err = p.withTx(ctx, "An unexpected error occurred while creating your account", func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+
+		var invitationId int
+		var roleId *int
+		row := tx.QueryRowContext(ctx, "SELECT invitation_id, role_id FROM invitation WHERE token_hash = $1 AND accepted_tstz IS NULL AND revoked_tstz IS NULL AND expires_tstz > $2 FOR UPDATE;", tokenHash, now)
+		if err := row.Scan(&invitationId, &roleId); err != nil {
+			if err == sql.ErrNoRows {
+				return errors.NewBadRequestError(ctx, "Oops! This invitation link is invalid or has expired.")
+			}
+			return err
+		}
+
+		var taken bool
+		row = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM evaluator WHERE LOWER(username) = LOWER($1));", username)
+		if err := row.Scan(&taken); err != nil {
+			return err
+		}
+		if taken {
+			return errors.NewBadRequestError(ctx, "Oops! That username is already taken.")
+		}
+
+		row = tx.QueryRowContext(ctx, "INSERT INTO evaluator (evaluator_name, email, evaluator_kaid, username, password, dt_term_start, dt_term_end, group_id) SELECT invitee_name, email, invitee_kaid, $1, $2, dt_term_start, dt_term_end, group_id FROM invitation WHERE invitation_id = $3 RETURNING evaluator_id;", username, hash, invitationId)
+		if err := row.Scan(&userId); err != nil {
+			return err
+		}
+
+		if roleId != nil {
+			if _, err := tx.ExecContext(ctx, "INSERT INTO evaluator_role (evaluator_id, role_id) VALUES ($1, $2);", userId, *roleId); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, "UPDATE invitation SET accepted_tstz = $1, evaluator_id = $2 WHERE invitation_id = $3;", now, userId, invitationId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &userId, nil
+}
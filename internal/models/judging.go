@@ -7,6 +7,7 @@ import (
 	"github.com/KA-Challenge-Council/Bema/graph/model"
 	"github.com/KA-Challenge-Council/Bema/internal/db"
 	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/lib/pq"
 )
 
 func NewJudgingGroupModel() model.JudgingGroup {
@@ -19,10 +20,13 @@ func NewJudgingCriteriaModel() model.JudgingCriteria {
 	return criteria
 }
 
-func GetAllCriteria(ctx context.Context) ([]*model.JudgingCriteria, error) {
+func (p *Postgres) GetAllCriteria(ctx context.Context) ([]*model.JudgingCriteria, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	criteria := []*model.JudgingCriteria{}
 
-	rows, err := db.DB.Query("SELECT criteria_id, criteria_name, criteria_description, is_active, sort_order FROM judging_criteria ORDER BY is_active DESC, sort_order ASC;")
+	rows, err := p.db.QueryContext(ctx, "SELECT criteria_id, criteria_name, criteria_description, is_active, sort_order FROM judging_criteria ORDER BY is_active DESC, sort_order ASC;")
 	if err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of judging criteria", err)
 	}
@@ -35,6 +39,10 @@ func GetAllCriteria(ctx context.Context) ([]*model.JudgingCriteria, error) {
 		criteria = append(criteria, &c)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of judging criteria", err)
+	}
+
 	return criteria, nil
 }
 
@@ -71,10 +79,13 @@ func GetPublicCriteria(ctx context.Context) ([]*model.JudgingCriteria, error) {
 	}, nil
 }
 
-func GetActiveCriteria(ctx context.Context) ([]*model.JudgingCriteria, error) {
+func (p *Postgres) GetActiveCriteria(ctx context.Context) ([]*model.JudgingCriteria, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	criteria := []*model.JudgingCriteria{}
 
-	rows, err := db.DB.Query("SELECT criteria_id, criteria_name, criteria_description, is_active, sort_order FROM judging_criteria WHERE is_active = true ORDER BY sort_order ASC;")
+	rows, err := p.db.QueryContext(ctx, "SELECT criteria_id, criteria_name, criteria_description, is_active, sort_order FROM judging_criteria WHERE is_active = true ORDER BY sort_order ASC;")
 	if err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of active judging criteria", err)
 	}
@@ -87,11 +98,18 @@ func GetActiveCriteria(ctx context.Context) ([]*model.JudgingCriteria, error) {
 		criteria = append(criteria, &c)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of active judging criteria", err)
+	}
+
 	return criteria, nil
 }
 
-func GetJudgingCriteriaById(ctx context.Context, id int) (*model.JudgingCriteria, error) {
-	row := db.DB.QueryRow("SELECT criteria_id, criteria_name, criteria_description, is_active, sort_order FROM judging_criteria WHERE criteria_id = $1;", id)
+func (p *Postgres) GetJudgingCriteriaById(ctx context.Context, id int) (*model.JudgingCriteria, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT criteria_id, criteria_name, criteria_description, is_active, sort_order FROM judging_criteria WHERE criteria_id = $1;", id)
 
 	criteria := NewJudgingCriteriaModel()
 	if err := row.Scan(&criteria.ID, &criteria.Name, &criteria.Description, &criteria.IsActive, &criteria.SortOrder); err != nil {
@@ -104,8 +122,11 @@ func GetJudgingCriteriaById(ctx context.Context, id int) (*model.JudgingCriteria
 	return &criteria, nil
 }
 
-func CreateJudgingCriteria(ctx context.Context, input *model.JudgingCriteriaInput) (*int, error) {
-	row := db.DB.QueryRow("INSERT INTO judging_criteria (criteria_name, criteria_description, is_active, sort_order) VALUES ($1, $2, $3, $4) RETURNING criteria_id;", input.Name, input.Description, input.IsActive, input.SortOrder)
+func (p *Postgres) CreateJudgingCriteria(ctx context.Context, input *model.JudgingCriteriaInput) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "INSERT INTO judging_criteria (criteria_name, criteria_description, is_active, sort_order) VALUES ($1, $2, $3, $4) RETURNING criteria_id;", input.Name, input.Description, input.IsActive, input.SortOrder)
 
 	var id *int
 	if err := row.Scan(&id); err != nil {
@@ -115,8 +136,11 @@ func CreateJudgingCriteria(ctx context.Context, input *model.JudgingCriteriaInpu
 	return id, nil
 }
 
-func EditJudgingCriteriaById(ctx context.Context, id int, input *model.JudgingCriteriaInput) error {
-	_, err := db.DB.Exec("UPDATE judging_criteria SET criteria_name = $1, criteria_description = $2, is_active = $3, sort_order = $4 WHERE criteria_id = $5;", input.Name, input.Description, input.IsActive, input.SortOrder, id)
+func (p *Postgres) EditJudgingCriteriaById(ctx context.Context, id int, input *model.JudgingCriteriaInput) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE judging_criteria SET criteria_name = $1, criteria_description = $2, is_active = $3, sort_order = $4 WHERE criteria_id = $5;", input.Name, input.Description, input.IsActive, input.SortOrder, id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while editing a judging criteria", err)
 	}
@@ -124,8 +148,11 @@ func EditJudgingCriteriaById(ctx context.Context, id int, input *model.JudgingCr
 	return nil
 }
 
-func DeleteJudgingCriteriaById(ctx context.Context, id int) error {
-	_, err := db.DB.Exec("DELETE FROM judging_criteria WHERE criteria_id = $1;", id)
+func (p *Postgres) DeleteJudgingCriteriaById(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM judging_criteria WHERE criteria_id = $1;", id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while deleting a judging criteria", err)
 	}
@@ -133,10 +160,13 @@ func DeleteJudgingCriteriaById(ctx context.Context, id int) error {
 	return nil
 }
 
-func GetAllJudgingGroups(ctx context.Context) ([]*model.JudgingGroup, error) {
+func (p *Postgres) GetAllJudgingGroups(ctx context.Context) ([]*model.JudgingGroup, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	groups := []*model.JudgingGroup{}
 
-	rows, err := db.DB.Query("SELECT group_id, group_name, is_active FROM evaluator_group ORDER BY group_id ASC;")
+	rows, err := p.db.QueryContext(ctx, "SELECT group_id, group_name, is_active FROM evaluator_group ORDER BY group_id ASC;")
 	if err != nil {
 		return []*model.JudgingGroup{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of judging groups", err)
 	}
@@ -149,13 +179,20 @@ func GetAllJudgingGroups(ctx context.Context) ([]*model.JudgingGroup, error) {
 		groups = append(groups, &g)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.JudgingGroup{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of judging groups", err)
+	}
+
 	return groups, nil
 }
 
-func GetActiveJudgingGroups(ctx context.Context) ([]*model.JudgingGroup, error) {
+func (p *Postgres) GetActiveJudgingGroups(ctx context.Context) ([]*model.JudgingGroup, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	groups := []*model.JudgingGroup{}
 
-	rows, err := db.DB.Query("SELECT group_id, group_name, is_active FROM evaluator_group WHERE is_active = true ORDER BY group_id ASC;")
+	rows, err := p.db.QueryContext(ctx, "SELECT group_id, group_name, is_active FROM evaluator_group WHERE is_active = true ORDER BY group_id ASC;")
 	if err != nil {
 		return []*model.JudgingGroup{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of active judging groups", err)
 	}
@@ -168,11 +205,18 @@ func GetActiveJudgingGroups(ctx context.Context) ([]*model.JudgingGroup, error)
 		groups = append(groups, &g)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.JudgingGroup{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of active judging groups", err)
+	}
+
 	return groups, nil
 }
 
-func GetJudgingGroupById(ctx context.Context, id int) (*model.JudgingGroup, error) {
-	row := db.DB.QueryRow("SELECT group_id, group_name, is_active FROM evaluator_group WHERE group_id = $1;", id)
+func (p *Postgres) GetJudgingGroupById(ctx context.Context, id int) (*model.JudgingGroup, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT group_id, group_name, is_active FROM evaluator_group WHERE group_id = $1;", id)
 
 	group := NewJudgingGroupModel()
 	err := row.Scan(&group.ID, &group.Name, &group.IsActive)
@@ -186,8 +230,39 @@ func GetJudgingGroupById(ctx context.Context, id int) (*model.JudgingGroup, erro
 	return &group, nil
 }
 
-func CreateJudgingGroup(ctx context.Context, input *model.CreateJudgingGroupInput) (*int, error) {
-	row := db.DB.QueryRow("INSERT INTO evaluator_group (group_name) VALUES ($1) RETURNING group_id;", input.Name)
+// Returns the requested judging groups keyed by id. Ids that do not exist are left out.
+func (p *Postgres) GetJudgingGroupsByIds(ctx context.Context, ids []int) (map[int]*model.JudgingGroup, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	groups := map[int]*model.JudgingGroup{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT group_id, group_name, is_active FROM evaluator_group WHERE group_id = ANY($1);", pq.Array(ids))
+	if err != nil {
+		return groups, errors.NewInternalError(ctx, "An unexpected error occurred while looking up judging groups", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		g := NewJudgingGroupModel()
+		if err := rows.Scan(&g.ID, &g.Name, &g.IsActive); err != nil {
+			return map[int]*model.JudgingGroup{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading judging groups", err)
+		}
+		groups[g.ID] = &g
+	}
+
+	if err := rows.Err(); err != nil {
+		return map[int]*model.JudgingGroup{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading judging groups", err)
+	}
+
+	return groups, nil
+}
+
+func (p *Postgres) CreateJudgingGroup(ctx context.Context, input *model.CreateJudgingGroupInput) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "INSERT INTO evaluator_group (group_name) VALUES ($1) RETURNING group_id;", input.Name)
 
 	var id *int
 	if err := row.Scan(&id); err != nil {
@@ -197,8 +272,11 @@ func CreateJudgingGroup(ctx context.Context, input *model.CreateJudgingGroupInpu
 	return id, nil
 }
 
-func EditJudgingGroupById(ctx context.Context, id int, input *model.EditJudgingGroupInput) error {
-	_, err := db.DB.Exec("UPDATE evaluator_group SET group_name = $1, is_active = $2 WHERE group_id = $3;", input.Name, input.IsActive, id)
+func (p *Postgres) EditJudgingGroupById(ctx context.Context, id int, input *model.EditJudgingGroupInput) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE evaluator_group SET group_name = $1, is_active = $2 WHERE group_id = $3;", input.Name, input.IsActive, id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while editing a judging group", err)
 	}
@@ -206,32 +284,48 @@ func EditJudgingGroupById(ctx context.Context, id int, input *model.EditJudgingG
 	return nil
 }
 
-func DeleteJudgingGroupById(ctx context.Context, id int) error {
-	_, err := db.DB.Exec("DELETE FROM evaluator_group WHERE group_id = $1;", id)
+func (p *Postgres) DeleteJudgingGroupById(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM evaluator_group WHERE group_id = $1;", id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while deleting a judging group", err)
 	}
 	return nil
 }
 
-func ScoreEntry(ctx context.Context, userId int, entryId int, input *model.ScoreEntryInput) (*int, error) {
-	_, err := db.DB.Exec("SELECT evaluate($1, $2, $3, $4, $5, $6, $7)", entryId, userId, input.Creativity, input.Complexity, input.Execution, input.Interpretation, input.SkillLevel)
-	if err != nil {
-		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while submitting scores for an entry", err)
-	}
-
-	row := db.DB.QueryRow("SELECT evaluation_id FROM evaluation WHERE entry_id = $1 AND evaluator_id = $2 LIMIT 1;", entryId, userId)
+func (p *Postgres) ScoreEntry(ctx context.Context, userId int, entryId int, input *model.ScoreEntryInput) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
 
 	var evaluationId *int
-	if err := row.Scan(&evaluationId); err != nil {
-		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while submitting scores for an entry", err)
+
+	err := p.withTx(ctx, "An unexpected error occurred while submitting scores for an entry", func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "SELECT evaluate($1, $2, $3, $4, $5, $6, $7)", entryId, userId, input.Creativity, input.Complexity, input.Execution, input.Interpretation, input.SkillLevel)
+		if err != nil {
+			return errors.NewInternalError(ctx, "An unexpected error occurred while submitting scores for an entry", err)
+		}
+
+		row := tx.QueryRowContext(ctx, "SELECT evaluation_id FROM evaluation WHERE entry_id = $1 AND evaluator_id = $2 LIMIT 1;", entryId, userId)
+		if err := row.Scan(&evaluationId); err != nil {
+			return errors.NewInternalError(ctx, "An unexpected error occurred while submitting scores for an entry", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return evaluationId, nil
 }
 
-func AutoUpdateEntryLevel(ctx context.Context, entryId int) error {
-	rows, err := db.DB.Query("SELECT entry_level FROM entry WHERE entry_author_kaid = (SELECT entry_author_kaid FROM entry WHERE entry_id = $1) AND entry_id != $1 ORDER BY entry_id DESC LIMIT 3;", entryId)
+func (p *Postgres) AutoUpdateEntryLevel(ctx context.Context, entryId int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, "SELECT entry_level FROM entry WHERE entry_author_kaid = (SELECT entry_author_kaid FROM entry WHERE entry_id = $1) AND entry_id != $1 ORDER BY entry_id DESC LIMIT 3;", entryId)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while updating an entry's skill level", err)
 	}
@@ -245,13 +339,17 @@ func AutoUpdateEntryLevel(ctx context.Context, entryId int) error {
 		levels = append(levels, level)
 	}
 
+	if err := rows.Err(); err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while updating an entry's skill level", err)
+	}
+
 	if len(levels) == 3 && levels[0] == "Advanced" && levels[1] == "Advanced" && levels[2] == "Advanced" {
-		_, err := db.DB.Exec("UPDATE entry SET entry_level = 'Advanced', entry_level_locked = true WHERE entry_id = $1;", entryId)
+		_, err := p.db.ExecContext(ctx, "UPDATE entry SET entry_level = 'Advanced', entry_level_locked = true WHERE entry_id = $1;", entryId)
 		if err != nil {
 			return errors.NewInternalError(ctx, "An unexpected error occurred while updating an entry's skill level", err)
 		}
 	} else {
-		_, err = db.DB.Exec("SELECT update_entry_level($1);", entryId)
+		_, err = p.db.ExecContext(ctx, "SELECT update_entry_level($1);", entryId)
 		if err != nil {
 			return errors.NewInternalError(ctx, "An unexpected error occurred while updating an entry's skill level", err)
 		}
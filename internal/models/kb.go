@@ -39,10 +39,23 @@ func NewKBArticleDraftModel() model.KBArticleDraft {
 	return draft
 }
 
-func GetAllKBSections(ctx context.Context) ([]*model.KBSection, error) {
+// Fills in the deprecated pre-formatted date fields from their DateTime fields
+func formatKBArticleDates(a *model.KBArticle) {
+	a.LastUpdated = a.UpdatedAt.Format(time.RFC3339Nano)
+}
+
+// Fills in the deprecated pre-formatted date fields from their DateTime fields
+func formatKBArticleDraftDates(d *model.KBArticleDraft) {
+	d.LastUpdated = d.UpdatedAt.Format(time.RFC3339Nano)
+}
+
+func (p *Postgres) GetAllKBSections(ctx context.Context) ([]*model.KBSection, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	sections := []*model.KBSection{}
 
-	rows, err := db.DB.Query("SELECT section_id, section_name, section_description, section_visibility FROM kb_section ORDER BY section_id ASC;")
+	rows, err := p.db.QueryContext(ctx, "SELECT section_id, section_name, section_description, section_visibility FROM kb_section ORDER BY section_id ASC;")
 	if err != nil {
 		return []*model.KBSection{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of KB sections", err)
 	}
@@ -57,13 +70,20 @@ func GetAllKBSections(ctx context.Context) ([]*model.KBSection, error) {
 		sections = append(sections, &s)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.KBSection{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of KB sections", err)
+	}
+
 	return sections, nil
 }
 
-func GetAllKBArticles(ctx context.Context) ([]*model.KBArticle, error) {
+func (p *Postgres) GetAllKBArticles(ctx context.Context) ([]*model.KBArticle, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	articles := []*model.KBArticle{}
 
-	rows, err := db.DB.Query("SELECT article_id, section_id, article_name, article_content, article_author, article_last_updated, article_visibility, is_published FROM kb_article ORDER BY section_id ASC, article_id ASC;")
+	rows, err := p.db.QueryContext(ctx, "SELECT article_id, section_id, article_name, article_content, article_author, article_last_updated, article_visibility, is_published FROM kb_article ORDER BY section_id ASC, article_id ASC;")
 	if err != nil {
 		return []*model.KBArticle{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of KB articles", err)
 	}
@@ -71,20 +91,29 @@ func GetAllKBArticles(ctx context.Context) ([]*model.KBArticle, error) {
 	for rows.Next() {
 		a := NewKBArticleModel()
 
-		if err := rows.Scan(&a.ID, &a.Section.ID, &a.Title, &a.Content, &a.Author.ID, &a.LastUpdated, &a.Visibility, &a.IsPublished); err != nil {
+		if err := rows.Scan(&a.ID, &a.Section.ID, &a.Title, &a.Content, &a.Author.ID, &a.UpdatedAt, &a.Visibility, &a.IsPublished); err != nil {
 			return []*model.KBArticle{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of KB articles", err)
 		}
 
+		formatKBArticleDates(&a)
+
 		articles = append(articles, &a)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.KBArticle{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of KB articles", err)
+	}
+
 	return articles, nil
 }
 
-func GetAllKBArticlesWithDrafts(ctx context.Context) ([]*model.KBArticle, error) {
+func (p *Postgres) GetAllKBArticlesWithDrafts(ctx context.Context) ([]*model.KBArticle, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	articles := []*model.KBArticle{}
 
-	rows, err := db.DB.Query("SELECT a.article_id, a.section_id, a.article_name, a.article_content, a.article_author, a.article_last_updated, a.article_visibility, a.is_published, d.draft_id FROM kb_article a INNER JOIN kb_article_draft d ON d.article_id = a.article_id WHERE d.is_published = false ORDER BY d.draft_last_updated DESC;")
+	rows, err := p.db.QueryContext(ctx, "SELECT a.article_id, a.section_id, a.article_name, a.article_content, a.article_author, a.article_last_updated, a.article_visibility, a.is_published, d.draft_id FROM kb_article a INNER JOIN kb_article_draft d ON d.article_id = a.article_id WHERE d.is_published = false ORDER BY d.draft_last_updated DESC;")
 	if err != nil {
 		return []*model.KBArticle{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of KB articles with drafts", err)
 	}
@@ -92,20 +121,131 @@ func GetAllKBArticlesWithDrafts(ctx context.Context) ([]*model.KBArticle, error)
 	for rows.Next() {
 		a := NewKBArticleModel()
 
-		if err := rows.Scan(&a.ID, &a.Section.ID, &a.Title, &a.Content, &a.Author.ID, &a.LastUpdated, &a.Visibility, &a.IsPublished, &a.Draft.ID); err != nil {
+		if err := rows.Scan(&a.ID, &a.Section.ID, &a.Title, &a.Content, &a.Author.ID, &a.UpdatedAt, &a.Visibility, &a.IsPublished, &a.Draft.ID); err != nil {
 			return []*model.KBArticle{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of KB articles with drafts", err)
 		}
 
+		formatKBArticleDates(&a)
+
 		articles = append(articles, &a)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.KBArticle{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of KB articles with drafts", err)
+	}
+
 	return articles, nil
 }
 
-func GetPublicKBSections(ctx context.Context) ([]*model.KBSection, error) {
+// Returns a page of KB articles ordered by section
+func (p *Postgres) GetKBArticlesPage(ctx context.Context, args PageArgs) (*Page[*model.KBArticle], error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var afterSectionId, afterId int
+	if args.After != "" {
+		if err := DecodeCursor(args.After, &afterSectionId, &afterId); err != nil {
+			return nil, errors.NewBadRequestError(ctx, "Oops! The cursor provided is not valid.")
+		}
+	}
+
+	var totalCount int
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM kb_article;")
+	if err := row.Scan(&totalCount); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while counting the KB articles", err)
+	}
+
+	articles := []*model.KBArticle{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT article_id, section_id, article_name, article_content, article_author, article_last_updated, article_visibility, is_published FROM kb_article WHERE (section_id, article_id) > ($1, $2) ORDER BY section_id ASC, article_id ASC LIMIT $3;", afterSectionId, afterId, args.First+1)
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of KB articles", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		a := NewKBArticleModel()
+
+		if err := rows.Scan(&a.ID, &a.Section.ID, &a.Title, &a.Content, &a.Author.ID, &a.UpdatedAt, &a.Visibility, &a.IsPublished); err != nil {
+			return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of KB articles", err)
+		}
+
+		formatKBArticleDates(&a)
+
+		articles = append(articles, &a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of KB articles", err)
+	}
+
+	return newPage(articles, args.First, totalCount, KBArticleCursor), nil
+}
+
+// Returns a page of KB articles with unpublished drafts, most recently edited first
+func (p *Postgres) GetKBArticlesWithDraftsPage(ctx context.Context, args PageArgs) (*Page[*model.KBArticle], error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var afterUpdated *time.Time
+	var afterId int
+	if args.After != "" {
+		if err := DecodeCursor(args.After, &afterUpdated, &afterId); err != nil || afterUpdated == nil {
+			return nil, errors.NewBadRequestError(ctx, "Oops! The cursor provided is not valid.")
+		}
+	}
+
+	var totalCount int
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM kb_article_draft WHERE is_published = false;")
+	if err := row.Scan(&totalCount); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while counting the KB articles with drafts", err)
+	}
+
+	articles := []*model.KBArticle{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT a.article_id, a.section_id, a.article_name, a.article_content, a.article_author, a.article_last_updated, a.article_visibility, a.is_published, d.draft_id, d.draft_last_updated FROM kb_article a INNER JOIN kb_article_draft d ON d.article_id = a.article_id WHERE d.is_published = false AND ($1::timestamptz IS NULL OR (d.draft_last_updated, d.draft_id) < ($1, $2)) ORDER BY d.draft_last_updated DESC, d.draft_id DESC LIMIT $3;", afterUpdated, afterId, args.First+1)
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of KB articles with drafts", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		a := NewKBArticleModel()
+
+		if err := rows.Scan(&a.ID, &a.Section.ID, &a.Title, &a.Content, &a.Author.ID, &a.UpdatedAt, &a.Visibility, &a.IsPublished, &a.Draft.ID, &a.Draft.UpdatedAt); err != nil {
+			return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of KB articles with drafts", err)
+		}
+
+		formatKBArticleDates(&a)
+		formatKBArticleDraftDates(a.Draft)
+
+		articles = append(articles, &a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of KB articles with drafts", err)
+	}
+
+	return newPage(articles, args.First, totalCount, KBArticleDraftCursor), nil
+}
+
+// Returns the cursor of an article in a paginated list of articles
+func KBArticleCursor(a *model.KBArticle) string {
+	return EncodeCursor(a.Section.ID, a.ID)
+}
+
+// Returns the cursor of an article in a paginated list of articles with drafts
+func KBArticleDraftCursor(a *model.KBArticle) string {
+	return EncodeCursor(a.Draft.UpdatedAt, a.Draft.ID)
+}
+
+func (p *Postgres) GetPublicKBSections(ctx context.Context) ([]*model.KBSection, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	sections := []*model.KBSection{}
 
-	rows, err := db.DB.Query("SELECT section_id, section_name, section_description, section_visibility FROM kb_section WHERE section_visibility = 'Public' ORDER BY section_id ASC;")
+	rows, err := p.db.QueryContext(ctx, "SELECT section_id, section_name, section_description, section_visibility FROM kb_section WHERE section_visibility = 'Public' ORDER BY section_id ASC;")
 	if err != nil {
 		return []*model.KBSection{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of KB sections", err)
 	}
@@ -120,13 +260,20 @@ func GetPublicKBSections(ctx context.Context) ([]*model.KBSection, error) {
 		sections = append(sections, &s)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.KBSection{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of KB sections", err)
+	}
+
 	return sections, nil
 }
 
-func GetEvaluatorKBSections(ctx context.Context) ([]*model.KBSection, error) {
+func (p *Postgres) GetEvaluatorKBSections(ctx context.Context) ([]*model.KBSection, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	sections := []*model.KBSection{}
 
-	rows, err := db.DB.Query("SELECT section_id, section_name, section_description, section_visibility FROM kb_section WHERE section_visibility = 'Evaluators Only' OR section_visibility = 'Public' ORDER BY section_id ASC;")
+	rows, err := p.db.QueryContext(ctx, "SELECT section_id, section_name, section_description, section_visibility FROM kb_section WHERE section_visibility = 'Evaluators Only' OR section_visibility = 'Public' ORDER BY section_id ASC;")
 	if err != nil {
 		return []*model.KBSection{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of KB sections", err)
 	}
@@ -141,11 +288,18 @@ func GetEvaluatorKBSections(ctx context.Context) ([]*model.KBSection, error) {
 		sections = append(sections, &s)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.KBSection{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of KB sections", err)
+	}
+
 	return sections, nil
 }
 
-func GetKBSectionById(ctx context.Context, id int) (*model.KBSection, error) {
-	row := db.DB.QueryRow("SELECT section_id, section_name, section_description, section_visibility FROM kb_section WHERE section_id = $1", id)
+func (p *Postgres) GetKBSectionById(ctx context.Context, id int) (*model.KBSection, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT section_id, section_name, section_description, section_visibility FROM kb_section WHERE section_id = $1", id)
 
 	s := NewKBSectionModel()
 	if err := row.Scan(&s.ID, &s.Name, &s.Description, &s.Visibility); err != nil {
@@ -158,22 +312,30 @@ func GetKBSectionById(ctx context.Context, id int) (*model.KBSection, error) {
 	return &s, nil
 }
 
-func GetKBArticleById(ctx context.Context, id int) (*model.KBArticle, error) {
-	row := db.DB.QueryRow("SELECT article_id, section_id, article_name, article_content, article_author, article_last_updated, article_visibility, is_published FROM kb_article WHERE article_id = $1", id)
+func (p *Postgres) GetKBArticleById(ctx context.Context, id int) (*model.KBArticle, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT article_id, section_id, article_name, article_content, article_author, article_last_updated, article_visibility, is_published FROM kb_article WHERE article_id = $1", id)
 
 	a := NewKBArticleModel()
-	if err := row.Scan(&a.ID, &a.Section.ID, &a.Title, &a.Content, &a.Author.ID, &a.LastUpdated, &a.Visibility, &a.IsPublished); err != nil {
+	if err := row.Scan(&a.ID, &a.Section.ID, &a.Title, &a.Content, &a.Author.ID, &a.UpdatedAt, &a.Visibility, &a.IsPublished); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NewNotFoundError(ctx, "Oops! This article does not exist.")
 		}
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving a KB article.", err)
 	}
 
+	formatKBArticleDates(&a)
+
 	return &a, nil
 }
 
-func CheckKBArticleHasDraft(ctx context.Context, id int) (bool, error) {
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM kb_article_draft WHERE article_id = $1 AND is_published = false;", id)
+func (p *Postgres) CheckKBArticleHasDraft(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM kb_article_draft WHERE article_id = $1 AND is_published = false;", id)
 
 	var count *int
 	if err := row.Scan(&count); err != nil {
@@ -189,10 +351,13 @@ func CheckKBArticleHasDraft(ctx context.Context, id int) (bool, error) {
 	return false, nil
 }
 
-func GetPublicKBArticlesBySection(ctx context.Context, sectionId int) ([]*model.KBArticle, error) {
+func (p *Postgres) GetPublicKBArticlesBySection(ctx context.Context, sectionId int) ([]*model.KBArticle, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	articles := []*model.KBArticle{}
 
-	rows, err := db.DB.Query("SELECT article_id, section_id, article_name, article_content, article_author, article_last_updated, article_visibility, is_published FROM kb_article WHERE section_id = $1 AND article_visibility = 'Public' AND is_published = true ORDER BY article_id ASC", sectionId)
+	rows, err := p.db.QueryContext(ctx, "SELECT article_id, section_id, article_name, article_content, article_author, article_last_updated, article_visibility, is_published FROM kb_article WHERE section_id = $1 AND article_visibility = 'Public' AND is_published = true ORDER BY article_id ASC", sectionId)
 	if err != nil {
 		return []*model.KBArticle{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving articles for a KB section", err)
 	}
@@ -200,20 +365,29 @@ func GetPublicKBArticlesBySection(ctx context.Context, sectionId int) ([]*model.
 	for rows.Next() {
 		a := NewKBArticleModel()
 
-		if err := rows.Scan(&a.ID, &a.Section.ID, &a.Title, &a.Content, &a.Author.ID, &a.LastUpdated, &a.Visibility, &a.IsPublished); err != nil {
+		if err := rows.Scan(&a.ID, &a.Section.ID, &a.Title, &a.Content, &a.Author.ID, &a.UpdatedAt, &a.Visibility, &a.IsPublished); err != nil {
 			return []*model.KBArticle{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading articles for a KB section", err)
 		}
 
+		formatKBArticleDates(&a)
+
 		articles = append(articles, &a)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.KBArticle{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading articles for a KB section", err)
+	}
+
 	return articles, nil
 }
 
-func GetEvaluatorKBArticlesBySection(ctx context.Context, sectionId int) ([]*model.KBArticle, error) {
+func (p *Postgres) GetEvaluatorKBArticlesBySection(ctx context.Context, sectionId int) ([]*model.KBArticle, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	articles := []*model.KBArticle{}
 
-	rows, err := db.DB.Query("SELECT article_id, section_id, article_name, article_content, article_author, article_last_updated, article_visibility, is_published FROM kb_article WHERE section_id = $1 AND (article_visibility = 'Public' OR article_visibility = 'Evaluators Only') AND is_published = true ORDER BY article_id ASC", sectionId)
+	rows, err := p.db.QueryContext(ctx, "SELECT article_id, section_id, article_name, article_content, article_author, article_last_updated, article_visibility, is_published FROM kb_article WHERE section_id = $1 AND (article_visibility = 'Public' OR article_visibility = 'Evaluators Only') AND is_published = true ORDER BY article_id ASC", sectionId)
 	if err != nil {
 		return []*model.KBArticle{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving articles for a KB section", err)
 	}
@@ -221,20 +395,29 @@ func GetEvaluatorKBArticlesBySection(ctx context.Context, sectionId int) ([]*mod
 	for rows.Next() {
 		a := NewKBArticleModel()
 
-		if err := rows.Scan(&a.ID, &a.Section.ID, &a.Title, &a.Content, &a.Author.ID, &a.LastUpdated, &a.Visibility, &a.IsPublished); err != nil {
+		if err := rows.Scan(&a.ID, &a.Section.ID, &a.Title, &a.Content, &a.Author.ID, &a.UpdatedAt, &a.Visibility, &a.IsPublished); err != nil {
 			return []*model.KBArticle{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading articles for a KB section", err)
 		}
 
+		formatKBArticleDates(&a)
+
 		articles = append(articles, &a)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.KBArticle{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading articles for a KB section", err)
+	}
+
 	return articles, nil
 }
 
-func GetAdminKBArticlesBySection(ctx context.Context, sectionId int) ([]*model.KBArticle, error) {
+func (p *Postgres) GetAdminKBArticlesBySection(ctx context.Context, sectionId int) ([]*model.KBArticle, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	articles := []*model.KBArticle{}
 
-	rows, err := db.DB.Query("SELECT article_id, section_id, article_name, article_content, article_author, article_last_updated, article_visibility, is_published FROM kb_article WHERE section_id = $1 ORDER BY article_id ASC", sectionId)
+	rows, err := p.db.QueryContext(ctx, "SELECT article_id, section_id, article_name, article_content, article_author, article_last_updated, article_visibility, is_published FROM kb_article WHERE section_id = $1 ORDER BY article_id ASC", sectionId)
 	if err != nil {
 		return []*model.KBArticle{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving articles for a KB section", err)
 	}
@@ -242,34 +425,48 @@ func GetAdminKBArticlesBySection(ctx context.Context, sectionId int) ([]*model.K
 	for rows.Next() {
 		a := NewKBArticleModel()
 
-		if err := rows.Scan(&a.ID, &a.Section.ID, &a.Title, &a.Content, &a.Author.ID, &a.LastUpdated, &a.Visibility, &a.IsPublished); err != nil {
+		if err := rows.Scan(&a.ID, &a.Section.ID, &a.Title, &a.Content, &a.Author.ID, &a.UpdatedAt, &a.Visibility, &a.IsPublished); err != nil {
 			return []*model.KBArticle{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading articles for a KB section", err)
 		}
 
+		formatKBArticleDates(&a)
+
 		articles = append(articles, &a)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.KBArticle{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading articles for a KB section", err)
+	}
+
 	return articles, nil
 }
 
-func GetKBArticleDraftByArticleId(ctx context.Context, articleId int) (*model.KBArticleDraft, error) {
-	row := db.DB.QueryRow("SELECT draft_id, draft_name, draft_content, draft_author, draft_last_updated FROM kb_article_draft WHERE article_id = $1 AND is_published = false ORDER BY draft_id DESC LIMIT 1;", articleId)
+func (p *Postgres) GetKBArticleDraftByArticleId(ctx context.Context, articleId int) (*model.KBArticleDraft, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT draft_id, draft_name, draft_content, draft_author, draft_last_updated FROM kb_article_draft WHERE article_id = $1 AND is_published = false ORDER BY draft_id DESC LIMIT 1;", articleId)
 
 	d := NewKBArticleDraftModel()
-	if err := row.Scan(&d.ID, &d.Title, &d.Content, &d.Author.ID, &d.LastUpdated); err != nil {
+	if err := row.Scan(&d.ID, &d.Title, &d.Content, &d.Author.ID, &d.UpdatedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving an article draft", err)
 	}
 
+	formatKBArticleDraftDates(&d)
+
 	return &d, nil
 }
 
-func GetKBArticleRecentDraftsByArticleId(ctx context.Context, articleId int) ([]*model.KBArticleDraft, error) {
+func (p *Postgres) GetKBArticleRecentDraftsByArticleId(ctx context.Context, articleId int) ([]*model.KBArticleDraft, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	drafts := []*model.KBArticleDraft{}
 
-	rows, err := db.DB.Query("SELECT draft_id, draft_name, draft_content, draft_author, draft_last_updated FROM kb_article_draft WHERE article_id = $1 ORDER BY draft_id DESC LIMIT 5;", articleId)
+	rows, err := p.db.QueryContext(ctx, "SELECT draft_id, draft_name, draft_content, draft_author, draft_last_updated FROM kb_article_draft WHERE article_id = $1 ORDER BY draft_id DESC LIMIT 5;", articleId)
 	if err != nil {
 		return []*model.KBArticleDraft{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving recent drafts for a KB article", err)
 	}
@@ -277,18 +474,27 @@ func GetKBArticleRecentDraftsByArticleId(ctx context.Context, articleId int) ([]
 	for rows.Next() {
 		d := NewKBArticleDraftModel()
 
-		if err := rows.Scan(&d.ID, &d.Title, &d.Content, &d.Author.ID, &d.LastUpdated); err != nil {
+		if err := rows.Scan(&d.ID, &d.Title, &d.Content, &d.Author.ID, &d.UpdatedAt); err != nil {
 			return []*model.KBArticleDraft{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving recent drafts for a KB article", err)
 		}
 
+		formatKBArticleDraftDates(&d)
+
 		drafts = append(drafts, &d)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.KBArticleDraft{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving recent drafts for a KB article", err)
+	}
+
 	return drafts, nil
 }
 
-func CreateKBSection(ctx context.Context, input *model.KBSectionInput) (*int, error) {
-	row := db.DB.QueryRow("INSERT INTO kb_section (section_name, section_description, section_visibility) VALUES ($1, $2, $3) RETURNING section_id;", input.Name, input.Description, input.Visibility)
+func (p *Postgres) CreateKBSection(ctx context.Context, input *model.KBSectionInput) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "INSERT INTO kb_section (section_name, section_description, section_visibility) VALUES ($1, $2, $3) RETURNING section_id;", input.Name, input.Description, input.Visibility)
 
 	var id int
 	if err := row.Scan(&id); err != nil {
@@ -298,8 +504,11 @@ func CreateKBSection(ctx context.Context, input *model.KBSectionInput) (*int, er
 	return &id, nil
 }
 
-func EditKBSectionById(ctx context.Context, id int, input *model.KBSectionInput) error {
-	_, err := db.DB.Exec("UPDATE kb_section SET section_name = $1, section_description = $2, section_visibility = $3 WHERE section_id = $4;", input.Name, input.Description, input.Visibility, id)
+func (p *Postgres) EditKBSectionById(ctx context.Context, id int, input *model.KBSectionInput) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE kb_section SET section_name = $1, section_description = $2, section_visibility = $3 WHERE section_id = $4;", input.Name, input.Description, input.Visibility, id)
 
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while updating a KB section", err)
@@ -308,8 +517,11 @@ func EditKBSectionById(ctx context.Context, id int, input *model.KBSectionInput)
 	return nil
 }
 
-func DeleteKBSectionById(ctx context.Context, id int) error {
-	_, err := db.DB.Exec("DELETE FROM kb_section WHERE section_id = $1;", id)
+func (p *Postgres) DeleteKBSectionById(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM kb_section WHERE section_id = $1;", id)
 
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while deleting a KB section", err)
@@ -318,55 +530,78 @@ func DeleteKBSectionById(ctx context.Context, id int) error {
 	return nil
 }
 
-func CreateKBArticle(ctx context.Context, input *model.KBArticleInput, authorId int) (*int, error) {
-	articleRow := db.DB.QueryRow("INSERT INTO kb_article (section_id, article_name, article_content, article_author, article_last_updated, article_visibility, is_published) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING article_id;", input.Section, input.Title, "", authorId, time.Now().UTC(), input.Visibility, false)
+func (p *Postgres) CreateKBArticle(ctx context.Context, input *model.KBArticleInput, authorId int) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
 
 	var id int
-	if err := articleRow.Scan(&id); err != nil {
-		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while creating a KB article", err)
-	}
 
-	_, err := db.DB.Exec("INSERT INTO kb_article_draft (article_id, draft_name, draft_content, draft_author, draft_last_updated, is_published) VALUES ($1, $2, $3, $4, $5, $6);", id, input.Title, input.Content, authorId, time.Now(), false)
+	err := p.withTx(ctx, "An unexpected error occurred while creating a KB article", func(tx *sql.Tx) error {
+		articleRow := tx.QueryRowContext(ctx, "INSERT INTO kb_article (section_id, article_name, article_content, article_author, article_last_updated, article_visibility, is_published) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING article_id;", input.Section, input.Title, "", authorId, time.Now().UTC(), input.Visibility, false)
+		if err := articleRow.Scan(&id); err != nil {
+			return errors.NewInternalError(ctx, "An unexpected error occurred while creating a KB article", err)
+		}
+
+		_, err := tx.ExecContext(ctx, "INSERT INTO kb_article_draft (article_id, draft_name, draft_content, draft_author, draft_last_updated, is_published) VALUES ($1, $2, $3, $4, $5, $6);", id, input.Title, input.Content, authorId, time.Now().UTC(), false)
+		if err != nil {
+			return errors.NewInternalError(ctx, "An unexpected error occurred while creating a KB article draft", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while creating a KB article draft", err)
+		return nil, err
 	}
 
 	return &id, nil
 }
 
-func EditKBArticle(ctx context.Context, articleId int, input *model.KBArticleInput, authorId int) error {
-	draftRow := db.DB.QueryRow("SELECT draft_id FROM kb_article_draft WHERE article_id = $1 AND is_published = false;", articleId)
+func (p *Postgres) EditKBArticle(ctx context.Context, articleId int, input *model.KBArticleInput, authorId int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
 
-	var draftId *int
-	if err := draftRow.Scan(&draftId); err != nil {
-		if err == sql.ErrNoRows {
-			draftId = nil
-		} else {
-			return errors.NewInternalError(ctx, "An unexpected error occurred while looking up an article's most recent draft", err)
+	return p.withTx(ctx, "An unexpected error occurred while editing a KB article", func(tx *sql.Tx) error {
+		// Lock the article so concurrent edits cannot both create a new draft
+		if _, err := tx.ExecContext(ctx, "SELECT article_id FROM kb_article WHERE article_id = $1 FOR UPDATE;", articleId); err != nil {
+			return errors.NewInternalError(ctx, "An unexpected error occurred while looking up a KB article", err)
 		}
-	}
 
-	if draftId == nil {
-		// A draft does not exist, so create a new one
-		_, err := db.DB.Exec("INSERT INTO kb_article_draft (article_id, draft_name, draft_content, draft_author, draft_last_updated, is_published) VALUES ($1, $2, $3, $4, $5, $6);", articleId, input.Title, input.Content, authorId, time.Now().UTC(), false)
+		draftRow := tx.QueryRowContext(ctx, "SELECT draft_id FROM kb_article_draft WHERE article_id = $1 AND is_published = false;", articleId)
 
-		if err != nil {
-			return errors.NewInternalError(ctx, "An unexpected error occurred while creating a KB article draft", err)
+		var draftId *int
+		if err := draftRow.Scan(&draftId); err != nil {
+			if err == sql.ErrNoRows {
+				draftId = nil
+			} else {
+				return errors.NewInternalError(ctx, "An unexpected error occurred while looking up an article's most recent draft", err)
+			}
 		}
-	} else {
-		// A draft already exists, so just update it
-		_, err := db.DB.Exec("UPDATE kb_article_draft SET draft_name = $1, draft_content = $2, draft_author = $3, draft_last_updated = $4 WHERE draft_id = $5;", input.Title, input.Content, authorId, time.Now().UTC(), draftId)
 
-		if err != nil {
-			return errors.NewInternalError(ctx, "An unexpected error occurred while updating a KB article draft", err)
+		if draftId == nil {
+			// A draft does not exist, so create a new one
+			_, err := tx.ExecContext(ctx, "INSERT INTO kb_article_draft (article_id, draft_name, draft_content, draft_author, draft_last_updated, is_published) VALUES ($1, $2, $3, $4, $5, $6);", articleId, input.Title, input.Content, authorId, time.Now().UTC(), false)
+
+			if err != nil {
+				return errors.NewInternalError(ctx, "An unexpected error occurred while creating a KB article draft", err)
+			}
+		} else {
+			// A draft already exists, so just update it
+			_, err := tx.ExecContext(ctx, "UPDATE kb_article_draft SET draft_name = $1, draft_content = $2, draft_author = $3, draft_last_updated = $4 WHERE draft_id = $5;", input.Title, input.Content, authorId, time.Now().UTC(), draftId)
+
+			if err != nil {
+				return errors.NewInternalError(ctx, "An unexpected error occurred while updating a KB article draft", err)
+			}
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
-func EditKBArticleProperties(ctx context.Context, id int, visibility string, sectionId int) error {
-	_, err := db.DB.Exec("UPDATE kb_article SET article_visibility = $1, section_id = $2 WHERE article_id = $3;", visibility, sectionId, id)
+func (p *Postgres) EditKBArticleProperties(ctx context.Context, id int, visibility string, sectionId int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE kb_article SET article_visibility = $1, section_id = $2 WHERE article_id = $3;", visibility, sectionId, id)
 
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while updating an article's properties", err)
@@ -375,8 +610,11 @@ func EditKBArticleProperties(ctx context.Context, id int, visibility string, sec
 	return nil
 }
 
-func DeleteKBArticle(ctx context.Context, articleId int) error {
-	_, err := db.DB.Exec("DELETE FROM kb_article WHERE article_id = $1", articleId)
+func (p *Postgres) DeleteKBArticle(ctx context.Context, articleId int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM kb_article WHERE article_id = $1", articleId)
 
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while deleting a KB article", err)
@@ -385,8 +623,11 @@ func DeleteKBArticle(ctx context.Context, articleId int) error {
 	return nil
 }
 
-func DeleteKBArticleDraft(ctx context.Context, draftId int) error {
-	_, err := db.DB.Exec("DELETE FROM kb_article_draft WHERE draft_id = $1", draftId)
+func (p *Postgres) DeleteKBArticleDraft(ctx context.Context, draftId int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM kb_article_draft WHERE draft_id = $1", draftId)
 
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while deleting a KB article draft", err)
@@ -395,34 +636,43 @@ func DeleteKBArticleDraft(ctx context.Context, draftId int) error {
 	return nil
 }
 
-func PublishKBArticle(ctx context.Context, id int) error {
-	row := db.DB.QueryRow("SELECT draft_id, draft_name, draft_content, draft_author FROM kb_article_draft WHERE article_id = $1 AND is_published = false", id)
+func (p *Postgres) PublishKBArticle(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
 
-	var draftId, draftAuthor int
-	var draftName, draftContent string
+	return p.withTx(ctx, "An unexpected error occurred while publishing a KB article", func(tx *sql.Tx) error {
+		// Lock the draft so two publishes of the same article cannot interleave
+		row := tx.QueryRowContext(ctx, "SELECT draft_id, draft_name, draft_content, draft_author FROM kb_article_draft WHERE article_id = $1 AND is_published = false FOR UPDATE", id)
 
-	if err := row.Scan(&draftId, &draftName, &draftContent, &draftAuthor); err != nil {
-		if err == sql.ErrNoRows {
-			return errors.NewNotFoundError(ctx, "Oops! An unpublished draft does not exist for this article.")
+		var draftId, draftAuthor int
+		var draftName, draftContent string
+
+		if err := row.Scan(&draftId, &draftName, &draftContent, &draftAuthor); err != nil {
+			if err == sql.ErrNoRows {
+				return errors.NewNotFoundError(ctx, "Oops! An unpublished draft does not exist for this article.")
+			}
+			return errors.NewInternalError(ctx, "An unexpected error occurred while publishing a KB article", err)
 		}
-		return errors.NewInternalError(ctx, "An unexpected error occurred while publishing a KB article", err)
-	}
 
-	_, err := db.DB.Exec("UPDATE kb_article SET article_name = $1, article_content = $2, article_author = $3, article_last_updated = $4, is_published = $5 WHERE article_id = $6", draftName, draftContent, draftAuthor, time.Now().UTC(), true, id)
-	if err != nil {
-		return errors.NewInternalError(ctx, "An unexpected error occurred while publishing a KB article", err)
-	}
+		_, err := tx.ExecContext(ctx, "UPDATE kb_article SET article_name = $1, article_content = $2, article_author = $3, article_last_updated = $4, is_published = $5 WHERE article_id = $6", draftName, draftContent, draftAuthor, time.Now().UTC(), true, id)
+		if err != nil {
+			return errors.NewInternalError(ctx, "An unexpected error occurred while publishing a KB article", err)
+		}
 
-	_, err = db.DB.Exec("UPDATE kb_article_draft SET is_published = $1 WHERE draft_id = $2", true, draftId)
-	if err != nil {
-		return errors.NewInternalError(ctx, "An unexpected error occurred while publishing a KB article", err)
-	}
+		_, err = tx.ExecContext(ctx, "UPDATE kb_article_draft SET is_published = $1 WHERE draft_id = $2", true, draftId)
+		if err != nil {
+			return errors.NewInternalError(ctx, "An unexpected error occurred while publishing a KB article", err)
+		}
 
-	return nil
+		return nil
+	})
 }
 
-func UnpublishKBArticle(ctx context.Context, id int) error {
-	_, err := db.DB.Exec("UPDATE kb_article SET is_published = false WHERE article_id = $1;", id)
+func (p *Postgres) UnpublishKBArticle(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE kb_article SET is_published = false WHERE article_id = $1;", id)
 
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while unpublishing a KB article", err)
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/lib/pq"
+)
+
+// Locks the active accounts whose term ended on or before today, removes them from their judging
+// group and ends their sessions. Returns the ids of the locked accounts. Like the other term
+// lifecycle queries, errors are returned as is since this runs as a background job.
+func (p *Postgres) LockUsersWithEndedTerms(ctx context.Context, today time.Time) ([]int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	ids := []int{}
+	err := db.WithTx(ctx, p.db, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, "UPDATE evaluator SET account_locked = true, term_locked_tstz = $1, group_id = NULL WHERE account_locked = false AND dt_term_end <= $2 RETURNING evaluator_id;", time.Now().UTC(), today)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM user_session WHERE user_id = ANY($1);", pq.Array(ids))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Unlocks the accounts that were locked when their term ended and whose term has since been
+// extended or cleared. Returns the ids of the reactivated accounts.
+func (p *Postgres) ReactivateUsersWithRenewedTerms(ctx context.Context, today time.Time) ([]int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, "UPDATE evaluator SET account_locked = false, term_locked_tstz = NULL WHERE account_locked = true AND term_locked_tstz IS NOT NULL AND (dt_term_end IS NULL OR dt_term_end > $1) RETURNING evaluator_id;", today)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Returns the active users whose term ends after today and no later than until, and who have not
+// been warned about that end date yet
+func (p *Postgres) GetUsersToWarnOfTermEnd(ctx context.Context, today time.Time, until time.Time) ([]*model.User, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, "SELECT evaluator_id, evaluator_kaid, evaluator_name, nickname, username, email, account_locked, is_admin, logged_in_tstz, dt_term_start, dt_term_end, receive_emails FROM evaluator WHERE account_locked = false AND dt_term_end > $1 AND dt_term_end <= $2 AND term_end_warned_date IS DISTINCT FROM dt_term_end ORDER BY dt_term_end, evaluator_id;", today, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*model.User{}
+	for rows.Next() {
+		user := NewUserModel()
+		if err := rows.Scan(&user.ID, &user.Kaid, &user.Name, &user.Nickname, &user.Username, &user.Email, &user.AccountLocked, &user.IsAdmin, &user.LastLoginAt, &user.TermStartsAt, &user.TermEndsAt, &user.NotificationsEnabled); err != nil {
+			return nil, err
+		}
+
+		formatUserDates(&user)
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
+
+// Records that the user and admins were warned that the user's term ends on termEnd
+func (p *Postgres) SetTermEndWarned(ctx context.Context, userId int, termEnd time.Time) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE evaluator SET term_end_warned_date = $1 WHERE evaluator_id = $2;", termEnd, userId)
+	return err
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+)
+
+// Returns when the later of the username's and the IP address's lockouts ends, or nil if neither is locked out
+func (p *Postgres) GetLoginLockedUntil(ctx context.Context, username string, ipAddress string) (*time.Time, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT MAX(locked_until) FROM login_throttle WHERE ((throttle_kind = 'USERNAME' AND throttle_value = $1) OR (throttle_kind = 'IP_ADDRESS' AND throttle_value = $2)) AND locked_until > $3;", username, ipAddress, time.Now().UTC())
+
+	var lockedUntil *time.Time
+	if err := row.Scan(&lockedUntil); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while logging in", err)
+	}
+	return lockedUntil, nil
+}
+
+// Counts a failed login and returns the number of recent failures. Failures are forgotten once the
+// window has passed since the last failure or the end of the last lockout.
+func (p *Postgres) RecordFailedLogin(ctx context.Context, kind model.LoginThrottleKind, value string, window time.Duration) (int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now().UTC()
+	row := p.db.QueryRowContext(ctx, `INSERT INTO login_throttle (throttle_kind, throttle_value, failed_count, last_failed_tstz) VALUES ($1, $2, 1, $3)
+		ON CONFLICT (throttle_kind, throttle_value) DO UPDATE SET
+			failed_count = CASE WHEN COALESCE(login_throttle.locked_until, login_throttle.last_failed_tstz) < $4 THEN 1 ELSE login_throttle.failed_count + 1 END,
+			locked_until = CASE WHEN COALESCE(login_throttle.locked_until, login_throttle.last_failed_tstz) < $4 THEN NULL ELSE login_throttle.locked_until END,
+			last_failed_tstz = $3
+		RETURNING failed_count;`, kind, value, now, now.Add(-window))
+
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, errors.NewInternalError(ctx, "An unexpected error occurred while logging in", err)
+	}
+	return count, nil
+}
+
+func (p *Postgres) LockLogin(ctx context.Context, kind model.LoginThrottleKind, value string, until time.Time) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE login_throttle SET locked_until = $1 WHERE throttle_kind = $2 AND throttle_value = $3;", until.UTC(), kind, value)
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while logging in", err)
+	}
+	return nil
+}
+
+// Forgets the failed logins for a username or IP address
+func (p *Postgres) ClearLoginThrottle(ctx context.Context, kind model.LoginThrottleKind, value string) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM login_throttle WHERE throttle_kind = $1 AND throttle_value = $2;", kind, value)
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while logging in", err)
+	}
+	return nil
+}
+
+// Returns the lockouts that have not ended, ending last first
+func (p *Postgres) GetActiveLoginLockouts(ctx context.Context) ([]*model.LoginLockout, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	lockouts := []*model.LoginLockout{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT login_throttle_id, throttle_kind, throttle_value, failed_count, last_failed_tstz, locked_until FROM login_throttle WHERE locked_until > $1 ORDER BY locked_until DESC, login_throttle_id DESC;", time.Now().UTC())
+	if err != nil {
+		return lockouts, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving login lockouts", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		lockout := model.LoginLockout{}
+		if err := rows.Scan(&lockout.ID, &lockout.Kind, &lockout.Value, &lockout.FailedAttempts, &lockout.LastFailedAt, &lockout.LockedUntil); err != nil {
+			return []*model.LoginLockout{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading login lockouts", err)
+		}
+		lockouts = append(lockouts, &lockout)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []*model.LoginLockout{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading login lockouts", err)
+	}
+
+	return lockouts, nil
+}
+
+func (p *Postgres) GetActiveLoginLockoutById(ctx context.Context, id int) (*model.LoginLockout, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT login_throttle_id, throttle_kind, throttle_value, failed_count, last_failed_tstz, locked_until FROM login_throttle WHERE login_throttle_id = $1 AND locked_until > $2;", id, time.Now().UTC())
+
+	lockout := model.LoginLockout{}
+	if err := row.Scan(&lockout.ID, &lockout.Kind, &lockout.Value, &lockout.FailedAttempts, &lockout.LastFailedAt, &lockout.LockedUntil); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError(ctx, "Oops! The requested login lockout does not exist.")
+		}
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the requested login lockout", err)
+	}
+
+	return &lockout, nil
+}
+
+func (p *Postgres) DeleteLoginLockoutById(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := p.db.ExecContext(ctx, "DELETE FROM login_throttle WHERE login_throttle_id = $1;", id)
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while clearing a login lockout", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.NewNotFoundError(ctx, "Oops! The requested login lockout does not exist.")
+	}
+	return nil
+}
+
+// Deletes the failed logins that would be forgotten on the next attempt and returns how many were
+// deleted. Errors are returned as is since this runs as a background job rather than for a request.
+func (p *Postgres) DeleteExpiredLoginThrottles(ctx context.Context, window time.Duration) (int64, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := p.db.ExecContext(ctx, "DELETE FROM login_throttle WHERE COALESCE(locked_until, last_failed_tstz) < $1;", time.Now().UTC().Add(-window))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
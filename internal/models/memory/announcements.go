@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+)
+
+func (a *announcementRow) toModel() *model.Announcement {
+	announcement := models.NewAnnouncementModel()
+	announcement.ID = a.id
+	announcement.Author.ID = a.authorId
+	announcement.CreatedAt = a.created
+	announcement.Created = a.created.Format(time.RFC3339Nano)
+	announcement.Title = a.title
+	announcement.Content = a.content
+	announcement.IsPublic = a.isPublic
+	return &announcement
+}
+
+func (s *Store) findAnnouncement(id int) *announcementRow {
+	for _, a := range s.announcements {
+		if a.id == id {
+			return a
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetAnnouncementById(ctx context.Context, id int) (*model.Announcement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.findAnnouncement(id)
+	if a == nil {
+		return nil, errors.NewNotFoundError(ctx, "Oops! The requested announcement does not exist.")
+	}
+	return a.toModel(), nil
+}
+
+func (s *Store) GetAllAnnouncements(ctx context.Context) ([]*model.Announcement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	announcements := []*model.Announcement{}
+	for _, a := range s.announcements {
+		announcements = append(announcements, a.toModel())
+	}
+	return announcements, nil
+}
+
+func (s *Store) GetPublicAnnouncements(ctx context.Context) ([]*model.Announcement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	announcements := []*model.Announcement{}
+	for _, a := range s.announcements {
+		if a.isPublic {
+			announcements = append(announcements, a.toModel())
+		}
+	}
+	return announcements, nil
+}
+
+func (s *Store) CreateAnnouncement(ctx context.Context, input *model.AnnouncementInput, authorId int) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := &announcementRow{
+		id:       s.nextId("messages"),
+		authorId: authorId,
+		created:  time.Now().UTC(),
+		title:    input.Title,
+		content:  input.Content,
+		isPublic: input.IsPublic,
+	}
+	s.announcements = append(s.announcements, a)
+
+	return intPtr(a.id), nil
+}
+
+func (s *Store) EditAnnouncementById(ctx context.Context, id int, input *model.AnnouncementInput) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if a := s.findAnnouncement(id); a != nil {
+		a.title = input.Title
+		a.content = input.Content
+		a.isPublic = input.IsPublic
+	}
+	return nil
+}
+
+func (s *Store) DeleteAnnouncementById(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.announcements = removeWhere(s.announcements, func(a *announcementRow) bool { return a.id == id })
+	return nil
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+)
+
+func (r *apiTokenRow) toModel() *model.APIToken {
+	permissions := append([]string{}, r.permissions...)
+	sort.Strings(permissions)
+
+	return &model.APIToken{
+		ID:          r.id,
+		Name:        r.name,
+		Prefix:      r.prefix,
+		Permissions: permissions,
+		CreatedAt:   r.created,
+		ExpiresAt:   r.expires,
+		LastUsedAt:  r.lastUsed,
+	}
+}
+
+func (s *Store) GetAPITokensByUserId(ctx context.Context, userId int) ([]*model.APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens := []*model.APIToken{}
+	for i := len(s.apiTokens) - 1; i >= 0; i-- {
+		if r := s.apiTokens[i]; r.userId == userId {
+			tokens = append(tokens, r.toModel())
+		}
+	}
+	return tokens, nil
+}
+
+func (s *Store) GetAPITokenById(ctx context.Context, userId int, id int) (*model.APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.apiTokens {
+		if r.id == id && r.userId == userId {
+			return r.toModel(), nil
+		}
+	}
+	return nil, errors.NewNotFoundError(ctx, "Oops! The requested API token does not exist.")
+}
+
+func (s *Store) CreateAPIToken(ctx context.Context, userId int, input *model.CreateAPITokenInput, tokenHash string, prefix string) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.checkPermissionKeys(ctx, input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &apiTokenRow{
+		id:          s.nextId("api_token"),
+		userId:      userId,
+		name:        input.Name,
+		tokenHash:   tokenHash,
+		prefix:      prefix,
+		permissions: keys,
+		created:     time.Now(),
+		expires:     input.ExpiresAt,
+	}
+	s.apiTokens = append(s.apiTokens, r)
+
+	return intPtr(r.id), nil
+}
+
+func (s *Store) DeleteAPITokenById(ctx context.Context, userId int, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, r := range s.apiTokens {
+		if r.id == id && r.userId == userId {
+			s.apiTokens = append(s.apiTokens[:i:i], s.apiTokens[i+1:]...)
+			return nil
+		}
+	}
+	return errors.NewNotFoundError(ctx, "Oops! The requested API token does not exist.")
+}
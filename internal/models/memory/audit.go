@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+)
+
+func (r *auditLogRow) toModel() *model.AuditLogEntry {
+	e := &model.AuditLogEntry{
+		ID:         r.id,
+		Mutation:   r.record.Mutation,
+		TargetType: r.record.TargetType,
+		TargetIds:  append([]int{}, r.record.TargetIDs...),
+		Before:     r.record.Before,
+		After:      r.record.After,
+		CreatedAt:  r.created,
+	}
+	if r.record.ActorID != nil {
+		e.Actor = &model.User{ID: *r.record.ActorID}
+	}
+	if r.record.RealActorID != nil {
+		e.RealActor = &model.User{ID: *r.record.RealActorID}
+	}
+	if r.record.IPAddress != "" {
+		e.IPAddress = stringPtr(r.record.IPAddress)
+	}
+	return e
+}
+
+// Whether the entry matches every field set in the filter
+func (r *auditLogRow) matches(filter *model.AuditLogFilter) bool {
+	if filter == nil {
+		return true
+	}
+
+	rec := r.record
+	if filter.ActorID != nil && !((rec.ActorID != nil && *rec.ActorID == *filter.ActorID) || (rec.RealActorID != nil && *rec.RealActorID == *filter.ActorID)) {
+		return false
+	}
+	if filter.Mutation != nil && rec.Mutation != *filter.Mutation {
+		return false
+	}
+	if filter.TargetType != nil && rec.TargetType != *filter.TargetType {
+		return false
+	}
+	if filter.TargetID != nil {
+		found := false
+		for _, id := range rec.TargetIDs {
+			found = found || id == *filter.TargetID
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.Since != nil && r.created.Before(*filter.Since) {
+		return false
+	}
+	if filter.Until != nil && !r.created.Before(*filter.Until) {
+		return false
+	}
+	return true
+}
+
+func (s *Store) CreateAuditLogEntry(ctx context.Context, record *models.AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec := *record
+	rec.TargetIDs = append([]int{}, record.TargetIDs...)
+	s.auditLog = append(s.auditLog, &auditLogRow{id: s.nextId("audit_log"), record: rec, created: time.Now()})
+	return nil
+}
+
+func (s *Store) GetAuditLogPage(ctx context.Context, filter *model.AuditLogFilter, args models.PageArgs) (*models.Page[*model.AuditLogEntry], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := []*model.AuditLogEntry{}
+	for i := len(s.auditLog) - 1; i >= 0; i-- {
+		if r := s.auditLog[i]; r.matches(filter) {
+			entries = append(entries, r.toModel())
+		}
+	}
+
+	var isAfter func(*model.AuditLogEntry) bool
+	if args.After != "" {
+		var afterId int
+		if err := decodeCursor(ctx, args.After, &afterId); err != nil {
+			return nil, err
+		}
+		isAfter = func(e *model.AuditLogEntry) bool { return e.ID < afterId }
+	}
+	return models.PageOf(entries, args.First, isAfter, models.AuditLogCursor), nil
+}
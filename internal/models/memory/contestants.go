@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+)
+
+func (s *Store) GetContestantByKaid(ctx context.Context, kaid string) (*model.Contestant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.entriesAscending() {
+		if e.authorKaid != nil && *e.authorKaid == kaid {
+			contestant := models.NewContestantModel()
+			contestant.Kaid = kaid
+			contestant.Name = e.authorName
+			return &contestant, nil
+		}
+	}
+	return nil, errors.NewNotFoundError(ctx, "The requested contestant does not exist.")
+}
+
+func (s *Store) GetContestantsByKaids(ctx context.Context, kaids []string) (map[string]*model.Contestant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contestants := map[string]*model.Contestant{}
+	for _, e := range s.entriesAscending() {
+		if e.authorKaid == nil || !containsString(kaids, *e.authorKaid) {
+			continue
+		}
+		if _, ok := contestants[*e.authorKaid]; ok {
+			continue
+		}
+
+		contestant := models.NewContestantModel()
+		contestant.Kaid = *e.authorKaid
+		contestant.Name = e.authorName
+		contestants[contestant.Kaid] = &contestant
+	}
+	return contestants, nil
+}
+
+func (s *Store) GetContestantsBySearchQuery(ctx context.Context, searchQuery string) ([]*model.Contestant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contestants := []*model.Contestant{}
+	entries := s.entriesAscending()
+
+	if strings.Contains(searchQuery, "kaid_") {
+		// Only the most recent entry matching the kaid is returned
+		for i := len(entries) - 1; i >= 0; i-- {
+			e := entries[i]
+			if e.authorKaid != nil && strings.Contains(*e.authorKaid, searchQuery) {
+				c := models.NewContestantModel()
+				c.Kaid = *e.authorKaid
+				c.Name = e.authorName
+				contestants = append(contestants, &c)
+				break
+			}
+		}
+		return contestants, nil
+	}
+
+	// Group the matching entries by author, collecting every name they have used
+	namesByKaid := map[string][]string{}
+	kaids := []string{}
+	for _, e := range entries {
+		if !strings.Contains(e.authorName, searchQuery) {
+			continue
+		}
+
+		kaid := ""
+		if e.authorKaid != nil {
+			kaid = *e.authorKaid
+		}
+
+		if _, ok := namesByKaid[kaid]; !ok {
+			kaids = append(kaids, kaid)
+		}
+		if !containsString(namesByKaid[kaid], e.authorName) {
+			namesByKaid[kaid] = append(namesByKaid[kaid], e.authorName)
+		}
+	}
+
+	for _, kaid := range kaids {
+		names := namesByKaid[kaid]
+		sort.Strings(names)
+
+		c := models.NewContestantModel()
+		c.Kaid = kaid
+		c.Name = strings.Join(names, ", ")
+		contestants = append(contestants, &c)
+	}
+
+	return contestants, nil
+}
+
+func (s *Store) GetContestantsPageBySearchQuery(ctx context.Context, searchQuery string, args models.PageArgs) (*models.Page[*model.Contestant], error) {
+	contestants, err := s.GetContestantsBySearchQuery(ctx, searchQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(contestants, func(i, j int) bool {
+		return contestants[i].Kaid < contestants[j].Kaid
+	})
+
+	var isAfter func(*model.Contestant) bool
+	if args.After != "" {
+		var afterKaid string
+		if err := decodeCursor(ctx, args.After, &afterKaid); err != nil {
+			return nil, err
+		}
+		isAfter = func(c *model.Contestant) bool { return c.Kaid > afterKaid }
+	}
+	return models.PageOf(contestants, args.First, isAfter, models.ContestantCursor), nil
+}
+
+func (s *Store) GetContestantEntryCount(ctx context.Context, kaid string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, e := range s.entries {
+		if e.authorKaid != nil && *e.authorKaid == kaid {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Store) GetContestantContestCount(ctx context.Context, kaid string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contests := map[int]bool{}
+	for _, e := range s.entries {
+		if e.authorKaid != nil && *e.authorKaid == kaid && s.findContest(e.contestId) != nil {
+			contests[e.contestId] = true
+		}
+	}
+	return len(contests), nil
+}
@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+	"github.com/KA-Challenge-Council/Bema/internal/util"
+)
+
+func (c *contestRow) toModel() *model.Contest {
+	contest := models.NewContestModel()
+	contest.ID = c.id
+	contest.Name = c.name
+	contest.URL = c.url
+	contest.Author = c.author
+	contest.StartsAt = copyTime(c.startDate)
+	contest.EndsAt = copyTime(c.endDate)
+	contest.StartDate = util.FormatNullableDate(c.startDate, util.DisplayDateFormat)
+	contest.EndDate = util.FormatNullableDate(c.endDate, util.DisplayDateFormat)
+	contest.State = c.state
+	contest.IsCurrent = models.ContestIsActive(c.state)
+	contest.IsVotingEnabled = boolPtr(c.state == model.ContestStateVoting)
+	contest.BadgeSlug = c.badgeSlug
+	contest.BadgeImageURL = c.badgeImageURL
+	return &contest
+}
+
+func (s *Store) findContest(id int) *contestRow {
+	for _, c := range s.contests {
+		if c.id == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// Returns the state of the contest, or "" if it does not exist
+func (s *Store) contestState(id int) model.ContestState {
+	if c := s.findContest(id); c != nil {
+		return c.state
+	}
+	return ""
+}
+
+// Returns the contests sorted by id, newest first
+func (s *Store) contestsDescending() []*contestRow {
+	contests := append([]*contestRow{}, s.contests...)
+	sort.Slice(contests, func(i, j int) bool {
+		return contests[i].id > contests[j].id
+	})
+	return contests
+}
+
+func (s *Store) GetAllContests(ctx context.Context) ([]*model.Contest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contests := []*model.Contest{}
+	for _, c := range s.contestsDescending() {
+		contests = append(contests, c.toModel())
+	}
+	return contests, nil
+}
+
+func (s *Store) GetContestById(ctx context.Context, id int) (*model.Contest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.findContest(id)
+	if c == nil {
+		return nil, errors.NewNotFoundError(ctx, "Oops! This contest does not exist.")
+	}
+	return c.toModel(), nil
+}
+
+func (s *Store) GetContestsByIds(ctx context.Context, ids []int) (map[int]*model.Contest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contests := map[int]*model.Contest{}
+	for _, id := range ids {
+		if c := s.findContest(id); c != nil {
+			contests[id] = c.toModel()
+		}
+	}
+	return contests, nil
+}
+
+func (s *Store) GetCurrentContest(ctx context.Context) (*model.Contest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.currentContest(ctx)
+}
+
+// Returns the newest active contest, or else the newest contest that is not a draft
+func (s *Store) currentContest(ctx context.Context) (*model.Contest, error) {
+	var current *contestRow
+	for _, c := range s.contestsDescending() {
+		if models.ContestIsActive(c.state) {
+			return c.toModel(), nil
+		}
+		if current == nil && c.state != model.ContestStateDraft {
+			current = c
+		}
+	}
+	if current == nil {
+		return nil, errors.NewNotFoundError(ctx, "Oops! No contests have been opened yet.")
+	}
+	return current.toModel(), nil
+}
+
+func (s *Store) GetContestsEvaluatedByUser(ctx context.Context, userId int) ([]*model.Contest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contests := []*model.Contest{}
+	for _, c := range s.contestsDescending() {
+		if s.userJudgedContest(userId, c.id) {
+			contests = append(contests, c.toModel())
+		}
+	}
+	return contests, nil
+}
+
+// Returns true if the user has completed at least one evaluation for the contest
+func (s *Store) userJudgedContest(userId int, contestId int) bool {
+	for _, ev := range s.evaluations {
+		if ev.userId != userId || !ev.isComplete {
+			continue
+		}
+		if en := s.findEntry(ev.entryId); en != nil && en.contestId == contestId {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) CreateContest(ctx context.Context, input *model.CreateContestInput) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := &contestRow{
+		id:        s.nextId("contest"),
+		name:      input.Name,
+		url:       stringPtr(input.URL),
+		author:    stringPtr(input.Author),
+		startDate: parseDate(input.StartDate),
+		endDate:   parseDate(input.EndDate),
+		state:     model.ContestStateDraft,
+	}
+	s.contests = append(s.contests, c)
+
+	return intPtr(c.id), nil
+}
+
+func (s *Store) EditContestById(ctx context.Context, id int, input *model.EditContestInput) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c := s.findContest(id); c != nil {
+		c.name = input.Name
+		c.url = stringPtr(input.URL)
+		c.author = stringPtr(input.Author)
+		c.startDate = parseDate(input.StartDate)
+		c.endDate = parseDate(input.EndDate)
+		c.badgeSlug = input.BadgeSlug
+		c.badgeImageURL = input.BadgeImageURL
+	}
+	return nil
+}
+
+func (s *Store) TransitionContest(ctx context.Context, id int, from model.ContestState, to model.ContestState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.findContest(id)
+	if c == nil || c.state != from {
+		return errors.NewBadRequestError(ctx, "Oops! This contest was changed by someone else. Please reload and try again.")
+	}
+	c.state = to
+	return nil
+}
+
+func (s *Store) DeleteContestById(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.contests = removeWhere(s.contests, func(c *contestRow) bool { return c.id == id })
+	return nil
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+	"github.com/KA-Challenge-Council/Bema/internal/util"
+)
+
+func (e *errorRow) toModel() *model.Error {
+	err := models.NewErrorModel()
+	err.ID = e.id
+	err.Message = e.message
+	err.Stack = e.stack
+	err.OccurredAt = e.created
+	err.Timestamp = util.FormatDate(e.created, util.DisplayFancyDateFormat)
+	err.RequestOrigin = e.origin
+	err.RequestReferrer = e.referer
+	err.RequestUserAgent = e.userAgent
+	if e.userId != nil {
+		err.User.ID = *e.userId
+	}
+	return &err
+}
+
+// Records an error, like the log_error stored function. Lets tests seed the error log.
+func (s *Store) LogError(message string, stack string, userId *int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := &errorRow{
+		id:      s.nextId("error"),
+		message: message,
+		stack:   stringPtr(stack),
+		created: time.Now().UTC(),
+		userId:  userId,
+	}
+	s.errors = append(s.errors, e)
+
+	return e.id
+}
+
+func (s *Store) GetErrorsByPage(ctx context.Context, page int) ([]*model.Error, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := append([]*errorRow{}, s.errors...)
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].id > rows[j].id
+	})
+
+	errs := []*model.Error{}
+	for i := models.ERROR_PAGE_SIZE * page; i < len(rows) && len(errs) < models.ERROR_PAGE_SIZE; i++ {
+		errs = append(errs, rows[i].toModel())
+	}
+	return errs, nil
+}
+
+func (s *Store) GetErrorsPage(ctx context.Context, args models.PageArgs) (*models.Page[*model.Error], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := append([]*errorRow{}, s.errors...)
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].id > rows[j].id
+	})
+
+	errs := []*model.Error{}
+	for _, e := range rows {
+		errs = append(errs, e.toModel())
+	}
+
+	var isAfter func(*model.Error) bool
+	if args.After != "" {
+		var afterId int
+		if err := decodeCursor(ctx, args.After, &afterId); err != nil {
+			return nil, err
+		}
+		isAfter = func(e *model.Error) bool { return e.ID < afterId }
+	}
+	return models.PageOf(errs, args.First, isAfter, models.ErrorCursor), nil
+}
+
+func (s *Store) GetErrorById(ctx context.Context, id int) (*model.Error, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.errors {
+		if e.id == id {
+			return e.toModel(), nil
+		}
+	}
+	return nil, errors.NewNotFoundError(ctx, "Oops! The requested error does not exist.")
+}
+
+func (s *Store) DeleteErrorById(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.errors = removeWhere(s.errors, func(e *errorRow) bool { return e.id == id })
+	return nil
+}
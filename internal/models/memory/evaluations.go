@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+	"github.com/KA-Challenge-Council/Bema/internal/util"
+)
+
+func (ev *evaluationRow) toModel() *model.Evaluation {
+	e := models.NewEvaluationModel()
+	e.ID = ev.id
+	e.Entry.ID = ev.entryId
+	e.User.ID = ev.userId
+	e.Creativity = ev.creativity
+	e.Complexity = ev.complexity
+	e.Execution = ev.execution
+	e.Interpretation = ev.interpretation
+	e.Total = e.Creativity + e.Complexity + e.Execution + e.Interpretation
+	e.CreatedAt = ev.created
+	e.Created = util.FormatDate(ev.created, util.DisplayFancyDateFormat)
+	e.SkillLevel = ev.skillLevel
+	return &e
+}
+
+func (s *Store) findEvaluation(id int) *evaluationRow {
+	for _, ev := range s.evaluations {
+		if ev.id == id {
+			return ev
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetEvaluationById(ctx context.Context, id int) (*model.Evaluation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ev := s.findEvaluation(id)
+	if ev == nil {
+		return nil, errors.NewNotFoundError(ctx, "This evaluation does not exist.")
+	}
+
+	entry := s.findEntry(ev.entryId)
+	if entry == nil {
+		return nil, errors.NewNotFoundError(ctx, "This evaluation does not exist.")
+	}
+
+	e := ev.toModel()
+	e.CanEdit = models.EvaluationIsEditable(auth.GetUserFromContext(ctx), e.User.ID, s.contestState(entry.contestId))
+
+	return e, nil
+}
+
+func (s *Store) GetEvaluationsForUserAndContest(ctx context.Context, userId int, contestId int) ([]*model.Evaluation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := auth.GetUserFromContext(ctx)
+
+	contestState := s.contestState(contestId)
+
+	rows := append([]*evaluationRow{}, s.evaluations...)
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].id < rows[j].id
+	})
+
+	evaluations := []*model.Evaluation{}
+	for _, ev := range rows {
+		if ev.userId != userId || !ev.isComplete {
+			continue
+		}
+		if entry := s.findEntry(ev.entryId); entry == nil || entry.contestId != contestId {
+			continue
+		}
+
+		e := ev.toModel()
+		e.CanEdit = models.EvaluationIsEditable(user, userId, contestState)
+		evaluations = append(evaluations, e)
+	}
+
+	return evaluations, nil
+}
+
+func (s *Store) GetUserTotalEvaluations(ctx context.Context, userId int) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, ev := range s.evaluations {
+		if ev.userId == userId && ev.isComplete {
+			count++
+		}
+	}
+	return intPtr(count), nil
+}
+
+func (s *Store) GetUserTotalContestsJudged(ctx context.Context, userId int) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, c := range s.contests {
+		if s.userJudgedContest(userId, c.id) {
+			count++
+		}
+	}
+	return intPtr(count), nil
+}
+
+func (s *Store) EditEvaluationById(ctx context.Context, id int, input *model.EditEvaluationInput) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ev := s.findEvaluation(id); ev != nil {
+		ev.creativity = input.Creativity
+		ev.complexity = input.Complexity
+		ev.execution = input.Execution
+		ev.interpretation = input.Interpretation
+		ev.skillLevel = input.SkillLevel
+	}
+	return nil
+}
+
+func (s *Store) DeleteEvaluationById(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evaluations = removeWhere(s.evaluations, func(ev *evaluationRow) bool { return ev.id == id })
+	return nil
+}
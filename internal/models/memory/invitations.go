@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (r *invitationRow) toModel() *model.Invitation {
+	inv := &model.Invitation{
+		ID:         r.id,
+		Name:       r.input.Name,
+		Email:      r.input.Email,
+		Kaid:       r.input.Kaid,
+		InvitedBy:  &model.User{ID: r.invitedBy},
+		CreatedAt:  r.created,
+		ExpiresAt:  r.expires,
+		AcceptedAt: copyTime(r.accepted),
+		RevokedAt:  copyTime(r.revoked),
+	}
+	if termStart := parseDate(r.input.TermStart); termStart != nil {
+		inv.TermStartsAt = *termStart
+	}
+	if r.input.TermEnd != nil {
+		inv.TermEndsAt = parseDate(*r.input.TermEnd)
+	}
+	if r.input.RoleID != nil {
+		inv.Role = &model.Role{ID: *r.input.RoleID}
+	}
+	if r.input.GroupID != nil {
+		inv.Group = &model.JudgingGroup{ID: *r.input.GroupID}
+	}
+	if r.userId != nil {
+		inv.User = &model.User{ID: *r.userId}
+	}
+	inv.Status = models.InvitationStatus(inv, time.Now())
+	return inv
+}
+
+func (s *Store) GetInvitations(ctx context.Context, includeInactive bool) ([]*model.Invitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invitations := []*model.Invitation{}
+	for i := len(s.invitations) - 1; i >= 0; i-- {
+		inv := s.invitations[i].toModel()
+		if includeInactive || inv.Status == model.InvitationStatusPending {
+			invitations = append(invitations, inv)
+		}
+	}
+	return invitations, nil
+}
+
+func (s *Store) GetInvitationById(ctx context.Context, id int) (*model.Invitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.invitations {
+		if r.id == id {
+			return r.toModel(), nil
+		}
+	}
+	return nil, errors.NewNotFoundError(ctx, "Oops! The requested invitation does not exist.")
+}
+
+func (s *Store) CreateInvitation(ctx context.Context, input *model.InviteUserInput, invitedBy int, tokenHash string, expires time.Time) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := &invitationRow{
+		id:        s.nextId("invitation"),
+		tokenHash: tokenHash,
+		input:     *input,
+		invitedBy: invitedBy,
+		created:   time.Now(),
+		expires:   expires,
+	}
+	s.invitations = append(s.invitations, r)
+
+	return intPtr(r.id), nil
+}
+
+func (s *Store) RevokeInvitation(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.invitations {
+		if r.id == id && r.accepted == nil && r.revoked == nil {
+			now := time.Now()
+			r.revoked = &now
+			return nil
+		}
+	}
+	return errors.NewBadRequestError(ctx, "Oops! Only invitations that have not been accepted or revoked can be revoked.")
+}
+
+func (s *Store) AcceptInvitation(ctx context.Context, tokenHash string, username string, password string) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var r *invitationRow
+	for _, inv := range s.invitations {
+		if inv.tokenHash == tokenHash && inv.accepted == nil && inv.revoked == nil && inv.expires.After(now) {
+			r = inv
+		}
+	}
+	if r == nil {
+		return nil, errors.NewBadRequestError(ctx, "Oops! This invitation link is invalid or has expired.")
+	}
+
+	for _, u := range s.users {
+		if strings.EqualFold(u.username, username) {
+			return nil, errors.NewBadRequestError(ctx, "Oops! That username is already taken.")
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while creating your account", err)
+	}
+	hashed := string(hash)
+	email := r.input.Email
+
+	u := &userRow{
+		id:                   s.nextId("evaluator"),
+		kaid:                 r.input.Kaid,
+		name:                 r.input.Name,
+		username:             username,
+		email:                &email,
+		password:             &hashed,
+		termStart:            parseDate(r.input.TermStart),
+		groupId:              r.input.GroupID,
+		notificationsEnabled: true,
+	}
+	if r.input.TermEnd != nil {
+		u.termEnd = parseDate(*r.input.TermEnd)
+	}
+	if r.input.RoleID != nil {
+		u.roleIds = []int{*r.input.RoleID}
+	}
+	s.users = append(s.users, u)
+
+	r.accepted = &now
+	r.userId = intPtr(u.id)
+	return intPtr(u.id), nil
+}
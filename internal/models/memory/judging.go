@@ -0,0 +1,297 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+)
+
+func (s *Store) findCriteria(id int) *model.JudgingCriteria {
+	for _, c := range s.criteria {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *Store) findGroup(id int) *model.JudgingGroup {
+	for _, g := range s.groups {
+		if g.ID == id {
+			return g
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetAllCriteria(ctx context.Context) ([]*model.JudgingCriteria, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	criteria := []*model.JudgingCriteria{}
+	for _, c := range s.criteria {
+		row := *c
+		criteria = append(criteria, &row)
+	}
+
+	sort.SliceStable(criteria, func(i, j int) bool {
+		if criteria[i].IsActive != criteria[j].IsActive {
+			return criteria[i].IsActive
+		}
+		return criteria[i].SortOrder < criteria[j].SortOrder
+	})
+
+	return criteria, nil
+}
+
+func (s *Store) GetActiveCriteria(ctx context.Context) ([]*model.JudgingCriteria, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	criteria := []*model.JudgingCriteria{}
+	for _, c := range s.criteria {
+		if c.IsActive {
+			row := *c
+			criteria = append(criteria, &row)
+		}
+	}
+
+	sort.SliceStable(criteria, func(i, j int) bool {
+		return criteria[i].SortOrder < criteria[j].SortOrder
+	})
+
+	return criteria, nil
+}
+
+func (s *Store) GetJudgingCriteriaById(ctx context.Context, id int) (*model.JudgingCriteria, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.findCriteria(id)
+	if c == nil {
+		return nil, errors.NewNotFoundError(ctx, "This criteria does not exist.")
+	}
+
+	row := *c
+	return &row, nil
+}
+
+func (s *Store) CreateJudgingCriteria(ctx context.Context, input *model.JudgingCriteriaInput) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := &model.JudgingCriteria{
+		ID:          s.nextId("judging_criteria"),
+		Name:        input.Name,
+		Description: input.Description,
+		IsActive:    input.IsActive,
+		SortOrder:   input.SortOrder,
+	}
+	s.criteria = append(s.criteria, c)
+
+	return intPtr(c.ID), nil
+}
+
+func (s *Store) EditJudgingCriteriaById(ctx context.Context, id int, input *model.JudgingCriteriaInput) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c := s.findCriteria(id); c != nil {
+		c.Name = input.Name
+		c.Description = input.Description
+		c.IsActive = input.IsActive
+		c.SortOrder = input.SortOrder
+	}
+	return nil
+}
+
+func (s *Store) DeleteJudgingCriteriaById(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.criteria = removeWhere(s.criteria, func(c *model.JudgingCriteria) bool { return c.ID == id })
+	return nil
+}
+
+func (s *Store) GetAllJudgingGroups(ctx context.Context) ([]*model.JudgingGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.judgingGroups(false), nil
+}
+
+func (s *Store) GetActiveJudgingGroups(ctx context.Context) ([]*model.JudgingGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.judgingGroups(true), nil
+}
+
+// Returns copies of the judging groups sorted by id
+func (s *Store) judgingGroups(activeOnly bool) []*model.JudgingGroup {
+	groups := []*model.JudgingGroup{}
+	for _, g := range s.groups {
+		if !activeOnly || g.IsActive {
+			row := *g
+			groups = append(groups, &row)
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].ID < groups[j].ID
+	})
+
+	return groups
+}
+
+func (s *Store) GetJudgingGroupById(ctx context.Context, id int) (*model.JudgingGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.findGroup(id)
+	if g == nil {
+		return nil, errors.NewNotFoundError(ctx, "Oops! The requested judging group does not exist.")
+	}
+
+	row := *g
+	return &row, nil
+}
+
+func (s *Store) GetJudgingGroupsByIds(ctx context.Context, ids []int) (map[int]*model.JudgingGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	groups := map[int]*model.JudgingGroup{}
+	for _, id := range ids {
+		if g := s.findGroup(id); g != nil {
+			row := *g
+			groups[id] = &row
+		}
+	}
+	return groups, nil
+}
+
+func (s *Store) CreateJudgingGroup(ctx context.Context, input *model.CreateJudgingGroupInput) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := &model.JudgingGroup{
+		ID:       s.nextId("evaluator_group"),
+		Name:     input.Name,
+		IsActive: true,
+	}
+	s.groups = append(s.groups, g)
+
+	return intPtr(g.ID), nil
+}
+
+func (s *Store) EditJudgingGroupById(ctx context.Context, id int, input *model.EditJudgingGroupInput) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if g := s.findGroup(id); g != nil {
+		g.Name = input.Name
+		g.IsActive = input.IsActive
+	}
+	return nil
+}
+
+func (s *Store) DeleteJudgingGroupById(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.groups = removeWhere(s.groups, func(g *model.JudgingGroup) bool { return g.ID == id })
+	return nil
+}
+
+// Mirrors the evaluate stored function, completing the placeholder evaluation if one exists
+func (s *Store) ScoreEntry(ctx context.Context, userId int, entryId int, input *model.ScoreEntryInput) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var evaluation *evaluationRow
+	for _, ev := range s.evaluations {
+		if ev.entryId == entryId && ev.userId == userId {
+			evaluation = ev
+			break
+		}
+	}
+
+	if evaluation == nil {
+		evaluation = &evaluationRow{
+			id:      s.nextId("evaluation"),
+			entryId: entryId,
+			userId:  userId,
+		}
+		s.evaluations = append(s.evaluations, evaluation)
+	}
+
+	evaluation.creativity = input.Creativity
+	evaluation.complexity = input.Complexity
+	evaluation.execution = input.Execution
+	evaluation.interpretation = input.Interpretation
+	evaluation.skillLevel = input.SkillLevel
+	evaluation.created = time.Now().UTC()
+	evaluation.isComplete = true
+
+	return intPtr(evaluation.id), nil
+}
+
+func (s *Store) AutoUpdateEntryLevel(ctx context.Context, entryId int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.findEntry(entryId)
+	if entry == nil {
+		return nil
+	}
+
+	// Authors whose last three entries were all Advanced are placed in Advanced automatically
+	levels := []string{}
+	entries := s.entriesAscending()
+	for i := len(entries) - 1; i >= 0 && len(levels) < 3; i-- {
+		e := entries[i]
+		if e.id != entryId && e.authorKaid != nil && entry.authorKaid != nil && *e.authorKaid == *entry.authorKaid {
+			levels = append(levels, e.skillLevel)
+		}
+	}
+
+	if len(levels) == 3 && levels[0] == "Advanced" && levels[1] == "Advanced" && levels[2] == "Advanced" {
+		entry.skillLevel = "Advanced"
+		entry.isSkillLevelLocked = true
+		return nil
+	}
+
+	// Otherwise use the level suggested most often, like the update_entry_level stored function
+	if entry.isSkillLevelLocked {
+		return nil
+	}
+
+	counts := map[string]int{}
+	latest := map[string]time.Time{}
+	for _, ev := range s.evaluations {
+		if ev.entryId != entryId || !ev.isComplete {
+			continue
+		}
+		counts[ev.skillLevel]++
+		if ev.created.After(latest[ev.skillLevel]) {
+			latest[ev.skillLevel] = ev.created
+		}
+	}
+
+	best := ""
+	for level, count := range counts {
+		if best == "" || count > counts[best] || (count == counts[best] && latest[level].After(latest[best])) {
+			best = level
+		}
+	}
+
+	if best != "" {
+		entry.skillLevel = best
+	}
+
+	return nil
+}
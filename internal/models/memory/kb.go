@@ -0,0 +1,454 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+)
+
+func (a *articleRow) toModel() *model.KBArticle {
+	article := models.NewKBArticleModel()
+	article.ID = a.id
+	article.Section.ID = a.sectionId
+	article.Title = a.title
+	article.Content = a.content
+	article.Author.ID = a.authorId
+	article.UpdatedAt = a.lastUpdated
+	article.LastUpdated = a.lastUpdated.Format(time.RFC3339Nano)
+	article.Visibility = stringPtr(a.visibility)
+	article.IsPublished = boolPtr(a.isPublished)
+	return &article
+}
+
+func (d *draftRow) toModel() *model.KBArticleDraft {
+	draft := models.NewKBArticleDraftModel()
+	draft.ID = d.id
+	draft.Title = d.title
+	draft.Content = d.content
+	draft.Author.ID = d.authorId
+	draft.UpdatedAt = d.lastUpdated
+	draft.LastUpdated = d.lastUpdated.Format(time.RFC3339Nano)
+	return &draft
+}
+
+func (s *Store) findSection(id int) *model.KBSection {
+	for _, section := range s.sections {
+		if section.ID == id {
+			return section
+		}
+	}
+	return nil
+}
+
+func (s *Store) findArticle(id int) *articleRow {
+	for _, a := range s.articles {
+		if a.id == id {
+			return a
+		}
+	}
+	return nil
+}
+
+// Returns the article's unpublished draft, if it has one
+func (s *Store) findUnpublishedDraft(articleId int) *draftRow {
+	var draft *draftRow
+	for _, d := range s.drafts {
+		if d.articleId == articleId && !d.isPublished && (draft == nil || d.id > draft.id) {
+			draft = d
+		}
+	}
+	return draft
+}
+
+// Returns copies of the sections with one of the given visibilities, sorted by id
+func (s *Store) kbSections(visibilities ...string) []*model.KBSection {
+	sections := []*model.KBSection{}
+	for _, section := range s.sections {
+		if len(visibilities) == 0 || (section.Visibility != nil && containsString(visibilities, *section.Visibility)) {
+			row := *section
+			sections = append(sections, &row)
+		}
+	}
+
+	sort.Slice(sections, func(i, j int) bool {
+		return sections[i].ID < sections[j].ID
+	})
+
+	return sections
+}
+
+// Returns the section's articles that match the filter, sorted by id
+func (s *Store) kbArticles(sectionId int, match func(a *articleRow) bool) []*model.KBArticle {
+	rows := append([]*articleRow{}, s.articles...)
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].id < rows[j].id
+	})
+
+	articles := []*model.KBArticle{}
+	for _, a := range rows {
+		if a.sectionId == sectionId && match(a) {
+			articles = append(articles, a.toModel())
+		}
+	}
+	return articles
+}
+
+func (s *Store) GetAllKBSections(ctx context.Context) ([]*model.KBSection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.kbSections(), nil
+}
+
+func (s *Store) GetAllKBArticles(ctx context.Context) ([]*model.KBArticle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := append([]*articleRow{}, s.articles...)
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].sectionId != rows[j].sectionId {
+			return rows[i].sectionId < rows[j].sectionId
+		}
+		return rows[i].id < rows[j].id
+	})
+
+	articles := []*model.KBArticle{}
+	for _, a := range rows {
+		articles = append(articles, a.toModel())
+	}
+	return articles, nil
+}
+
+func (s *Store) GetAllKBArticlesWithDrafts(ctx context.Context) ([]*model.KBArticle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	drafts := []*draftRow{}
+	for _, d := range s.drafts {
+		if !d.isPublished && s.findArticle(d.articleId) != nil {
+			drafts = append(drafts, d)
+		}
+	}
+
+	sort.Slice(drafts, func(i, j int) bool {
+		return drafts[i].lastUpdated.After(drafts[j].lastUpdated)
+	})
+
+	articles := []*model.KBArticle{}
+	for _, d := range drafts {
+		article := s.findArticle(d.articleId).toModel()
+		article.Draft.ID = d.id
+		articles = append(articles, article)
+	}
+	return articles, nil
+}
+
+func (s *Store) GetKBArticlesPage(ctx context.Context, args models.PageArgs) (*models.Page[*model.KBArticle], error) {
+	articles, err := s.GetAllKBArticles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var isAfter func(*model.KBArticle) bool
+	if args.After != "" {
+		var afterSectionId, afterId int
+		if err := decodeCursor(ctx, args.After, &afterSectionId, &afterId); err != nil {
+			return nil, err
+		}
+		isAfter = func(a *model.KBArticle) bool {
+			return a.Section.ID > afterSectionId || (a.Section.ID == afterSectionId && a.ID > afterId)
+		}
+	}
+	return models.PageOf(articles, args.First, isAfter, models.KBArticleCursor), nil
+}
+
+func (s *Store) GetKBArticlesWithDraftsPage(ctx context.Context, args models.PageArgs) (*models.Page[*model.KBArticle], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	drafts := []*draftRow{}
+	for _, d := range s.drafts {
+		if !d.isPublished && s.findArticle(d.articleId) != nil {
+			drafts = append(drafts, d)
+		}
+	}
+
+	sort.Slice(drafts, func(i, j int) bool {
+		if !drafts[i].lastUpdated.Equal(drafts[j].lastUpdated) {
+			return drafts[i].lastUpdated.After(drafts[j].lastUpdated)
+		}
+		return drafts[i].id > drafts[j].id
+	})
+
+	articles := []*model.KBArticle{}
+	for _, d := range drafts {
+		article := s.findArticle(d.articleId).toModel()
+		article.Draft = d.toModel()
+		articles = append(articles, article)
+	}
+
+	var isAfter func(*model.KBArticle) bool
+	if args.After != "" {
+		var afterUpdated time.Time
+		var afterId int
+		if err := decodeCursor(ctx, args.After, &afterUpdated, &afterId); err != nil {
+			return nil, err
+		}
+		isAfter = func(a *model.KBArticle) bool {
+			return a.Draft.UpdatedAt.Before(afterUpdated) || (a.Draft.UpdatedAt.Equal(afterUpdated) && a.Draft.ID < afterId)
+		}
+	}
+	return models.PageOf(articles, args.First, isAfter, models.KBArticleDraftCursor), nil
+}
+
+func (s *Store) GetPublicKBSections(ctx context.Context) ([]*model.KBSection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.kbSections("Public"), nil
+}
+
+func (s *Store) GetEvaluatorKBSections(ctx context.Context) ([]*model.KBSection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.kbSections("Evaluators Only", "Public"), nil
+}
+
+func (s *Store) GetKBSectionById(ctx context.Context, id int) (*model.KBSection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	section := s.findSection(id)
+	if section == nil {
+		return nil, errors.NewNotFoundError(ctx, "Oops! This section does not exist.")
+	}
+
+	row := *section
+	return &row, nil
+}
+
+func (s *Store) GetKBArticleById(ctx context.Context, id int) (*model.KBArticle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.findArticle(id)
+	if a == nil {
+		return nil, errors.NewNotFoundError(ctx, "Oops! This article does not exist.")
+	}
+	return a.toModel(), nil
+}
+
+func (s *Store) CheckKBArticleHasDraft(ctx context.Context, id int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.findUnpublishedDraft(id) != nil, nil
+}
+
+func (s *Store) GetPublicKBArticlesBySection(ctx context.Context, sectionId int) ([]*model.KBArticle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.kbArticles(sectionId, func(a *articleRow) bool {
+		return a.visibility == "Public" && a.isPublished
+	}), nil
+}
+
+func (s *Store) GetEvaluatorKBArticlesBySection(ctx context.Context, sectionId int) ([]*model.KBArticle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.kbArticles(sectionId, func(a *articleRow) bool {
+		return (a.visibility == "Public" || a.visibility == "Evaluators Only") && a.isPublished
+	}), nil
+}
+
+func (s *Store) GetAdminKBArticlesBySection(ctx context.Context, sectionId int) ([]*model.KBArticle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.kbArticles(sectionId, func(a *articleRow) bool {
+		return true
+	}), nil
+}
+
+func (s *Store) GetKBArticleDraftByArticleId(ctx context.Context, articleId int) (*model.KBArticleDraft, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := s.findUnpublishedDraft(articleId)
+	if d == nil {
+		return nil, nil
+	}
+	return d.toModel(), nil
+}
+
+func (s *Store) GetKBArticleRecentDraftsByArticleId(ctx context.Context, articleId int) ([]*model.KBArticleDraft, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []*draftRow{}
+	for _, d := range s.drafts {
+		if d.articleId == articleId {
+			rows = append(rows, d)
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].id > rows[j].id
+	})
+
+	drafts := []*model.KBArticleDraft{}
+	for i := 0; i < len(rows) && i < 5; i++ {
+		drafts = append(drafts, rows[i].toModel())
+	}
+	return drafts, nil
+}
+
+func (s *Store) CreateKBSection(ctx context.Context, input *model.KBSectionInput) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	section := &model.KBSection{
+		ID:          s.nextId("kb_section"),
+		Name:        input.Name,
+		Description: input.Description,
+		Visibility:  stringPtr(input.Visibility),
+	}
+	s.sections = append(s.sections, section)
+
+	return intPtr(section.ID), nil
+}
+
+func (s *Store) EditKBSectionById(ctx context.Context, id int, input *model.KBSectionInput) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if section := s.findSection(id); section != nil {
+		section.Name = input.Name
+		section.Description = input.Description
+		section.Visibility = stringPtr(input.Visibility)
+	}
+	return nil
+}
+
+func (s *Store) DeleteKBSectionById(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sections = removeWhere(s.sections, func(section *model.KBSection) bool { return section.ID == id })
+	return nil
+}
+
+func (s *Store) CreateKBArticle(ctx context.Context, input *model.KBArticleInput, authorId int) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	a := &articleRow{
+		id:          s.nextId("kb_article"),
+		sectionId:   input.Section,
+		title:       input.Title,
+		authorId:    authorId,
+		lastUpdated: now,
+		visibility:  input.Visibility,
+	}
+	s.articles = append(s.articles, a)
+
+	s.drafts = append(s.drafts, &draftRow{
+		id:          s.nextId("kb_article_draft"),
+		articleId:   a.id,
+		title:       input.Title,
+		content:     input.Content,
+		authorId:    authorId,
+		lastUpdated: now,
+	})
+
+	return intPtr(a.id), nil
+}
+
+func (s *Store) EditKBArticle(ctx context.Context, articleId int, input *model.KBArticleInput, authorId int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := s.findUnpublishedDraft(articleId)
+	if d == nil {
+		// A draft does not exist, so create a new one
+		d = &draftRow{
+			id:        s.nextId("kb_article_draft"),
+			articleId: articleId,
+		}
+		s.drafts = append(s.drafts, d)
+	}
+
+	d.title = input.Title
+	d.content = input.Content
+	d.authorId = authorId
+	d.lastUpdated = time.Now().UTC()
+
+	return nil
+}
+
+func (s *Store) EditKBArticleProperties(ctx context.Context, id int, visibility string, sectionId int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if a := s.findArticle(id); a != nil {
+		a.visibility = visibility
+		a.sectionId = sectionId
+	}
+	return nil
+}
+
+func (s *Store) DeleteKBArticle(ctx context.Context, articleId int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.articles = removeWhere(s.articles, func(a *articleRow) bool { return a.id == articleId })
+	s.drafts = removeWhere(s.drafts, func(d *draftRow) bool { return d.articleId == articleId })
+	return nil
+}
+
+func (s *Store) DeleteKBArticleDraft(ctx context.Context, draftId int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.drafts = removeWhere(s.drafts, func(d *draftRow) bool { return d.id == draftId })
+	return nil
+}
+
+func (s *Store) PublishKBArticle(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := s.findUnpublishedDraft(id)
+	a := s.findArticle(id)
+	if d == nil || a == nil {
+		return errors.NewNotFoundError(ctx, "Oops! An unpublished draft does not exist for this article.")
+	}
+
+	a.title = d.title
+	a.content = d.content
+	a.authorId = d.authorId
+	a.lastUpdated = time.Now().UTC()
+	a.isPublished = true
+	d.isPublished = true
+
+	return nil
+}
+
+func (s *Store) UnpublishKBArticle(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if a := s.findArticle(id); a != nil {
+		a.isPublished = false
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+)
+
+func (s *Store) LockUsersWithEndedTerms(ctx context.Context, today time.Time) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	ids := []int{}
+	for _, u := range s.users {
+		if u.accountLocked || u.termEnd == nil || u.termEnd.After(today) {
+			continue
+		}
+
+		u.accountLocked = true
+		u.termLocked = &now
+		u.groupId = nil
+		ids = append(ids, u.id)
+	}
+
+	s.sessions = s.filterSessions(func(r *sessionRow) bool {
+		return containsInt(ids, r.userId)
+	})
+	return ids, nil
+}
+
+func (s *Store) ReactivateUsersWithRenewedTerms(ctx context.Context, today time.Time) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := []int{}
+	for _, u := range s.users {
+		if u.accountLocked && u.termLocked != nil && (u.termEnd == nil || u.termEnd.After(today)) {
+			u.accountLocked = false
+			u.termLocked = nil
+			ids = append(ids, u.id)
+		}
+	}
+	return ids, nil
+}
+
+func (s *Store) GetUsersToWarnOfTermEnd(ctx context.Context, today time.Time, until time.Time) ([]*model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := []*model.User{}
+	for _, u := range s.users {
+		if u.accountLocked || u.termEnd == nil || !u.termEnd.After(today) || u.termEnd.After(until) {
+			continue
+		}
+		if u.termEndWarned != nil && u.termEndWarned.Equal(*u.termEnd) {
+			continue
+		}
+		users = append(users, u.toModel())
+	}
+
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].TermEndsAt.Before(*users[j].TermEndsAt)
+	})
+	return users, nil
+}
+
+func (s *Store) SetTermEndWarned(ctx context.Context, userId int, termEnd time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u := s.findUser(userId); u != nil {
+		u.termEndWarned = &termEnd
+	}
+	return nil
+}
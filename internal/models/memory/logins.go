@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+)
+
+func (s *Store) findThrottle(kind model.LoginThrottleKind, value string) *throttleRow {
+	for _, r := range s.throttles {
+		if r.kind == kind && r.value == value {
+			return r
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetLoginLockedUntil(ctx context.Context, username string, ipAddress string) (*time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lockedUntil *time.Time
+	for _, r := range []*throttleRow{s.findThrottle(model.LoginThrottleKindUsername, username), s.findThrottle(model.LoginThrottleKindIPAddress, ipAddress)} {
+		if r != nil && r.lockedUntil != nil && r.lockedUntil.After(time.Now()) && (lockedUntil == nil || r.lockedUntil.After(*lockedUntil)) {
+			lockedUntil = r.lockedUntil
+		}
+	}
+	return lockedUntil, nil
+}
+
+func (s *Store) RecordFailedLogin(ctx context.Context, kind model.LoginThrottleKind, value string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	r := s.findThrottle(kind, value)
+	if r == nil {
+		r = &throttleRow{id: s.nextId("login_throttle"), kind: kind, value: value}
+		s.throttles = append(s.throttles, r)
+	}
+
+	if r.lastSeenBefore(now.Add(-window)) {
+		r.failedCount = 0
+		r.lockedUntil = nil
+	}
+	r.failedCount++
+	r.lastFailed = now
+
+	return r.failedCount, nil
+}
+
+// Reports whether the last failure, or the end of the last lockout, was before t
+func (r *throttleRow) lastSeenBefore(t time.Time) bool {
+	if r.lockedUntil != nil {
+		return r.lockedUntil.Before(t)
+	}
+	return r.lastFailed.Before(t)
+}
+
+func (s *Store) LockLogin(ctx context.Context, kind model.LoginThrottleKind, value string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r := s.findThrottle(kind, value); r != nil {
+		r.lockedUntil = &until
+	}
+	return nil
+}
+
+func (s *Store) ClearLoginThrottle(ctx context.Context, kind model.LoginThrottleKind, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.throttles = s.filterThrottles(func(r *throttleRow) bool {
+		return r.kind == kind && r.value == value
+	})
+	return nil
+}
+
+func (s *Store) GetActiveLoginLockoutById(ctx context.Context, id int) (*model.LoginLockout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.throttles {
+		if r.id == id && r.lockedUntil != nil && r.lockedUntil.After(time.Now()) {
+			return &model.LoginLockout{
+				ID:             r.id,
+				Kind:           r.kind,
+				Value:          r.value,
+				FailedAttempts: r.failedCount,
+				LastFailedAt:   r.lastFailed,
+				LockedUntil:    *r.lockedUntil,
+			}, nil
+		}
+	}
+	return nil, errors.NewNotFoundError(ctx, "Oops! The requested login lockout does not exist.")
+}
+
+func (s *Store) GetActiveLoginLockouts(ctx context.Context) ([]*model.LoginLockout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lockouts := []*model.LoginLockout{}
+	for _, r := range s.throttles {
+		if r.lockedUntil != nil && r.lockedUntil.After(time.Now()) {
+			lockouts = append(lockouts, &model.LoginLockout{
+				ID:             r.id,
+				Kind:           r.kind,
+				Value:          r.value,
+				FailedAttempts: r.failedCount,
+				LastFailedAt:   r.lastFailed,
+				LockedUntil:    *r.lockedUntil,
+			})
+		}
+	}
+	sort.Slice(lockouts, func(i, j int) bool {
+		if !lockouts[i].LockedUntil.Equal(lockouts[j].LockedUntil) {
+			return lockouts[i].LockedUntil.After(lockouts[j].LockedUntil)
+		}
+		return lockouts[i].ID > lockouts[j].ID
+	})
+	return lockouts, nil
+}
+
+func (s *Store) DeleteLoginLockoutById(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.throttles)
+	s.throttles = s.filterThrottles(func(r *throttleRow) bool {
+		return r.id == id
+	})
+
+	if len(s.throttles) == before {
+		return errors.NewNotFoundError(ctx, "Oops! The requested login lockout does not exist.")
+	}
+	return nil
+}
+
+func (s *Store) DeleteExpiredLoginThrottles(ctx context.Context, window time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-window)
+	before := len(s.throttles)
+	s.throttles = s.filterThrottles(func(r *throttleRow) bool {
+		return r.lastSeenBefore(cutoff)
+	})
+	return int64(before - len(s.throttles)), nil
+}
+
+// Returns the throttles that do not match the condition
+func (s *Store) filterThrottles(remove func(r *throttleRow) bool) []*throttleRow {
+	kept := []*throttleRow{}
+	for _, r := range s.throttles {
+		if !remove(r) {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
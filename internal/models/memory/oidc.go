@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+)
+
+func (s *Store) CreateOIDCLoginState(ctx context.Context, stateHash string, codeVerifier string, nonce string, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.oidcStates = append(s.oidcStates, &oidcStateRow{
+		id:           s.nextId("oidc_login_state"),
+		stateHash:    stateHash,
+		codeVerifier: codeVerifier,
+		nonce:        nonce,
+		expires:      expires,
+	})
+	return nil
+}
+
+func (s *Store) UseOIDCLoginState(ctx context.Context, stateHash string) (*models.OIDCLoginState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, r := range s.oidcStates {
+		if r.stateHash != stateHash {
+			continue
+		}
+
+		s.oidcStates = append(s.oidcStates[:i], s.oidcStates[i+1:]...)
+		if !r.expires.After(time.Now()) {
+			return nil, nil
+		}
+		return &models.OIDCLoginState{CodeVerifier: r.codeVerifier, Nonce: r.nonce}, nil
+	}
+	return nil, nil
+}
+
+func (s *Store) DeleteExpiredOIDCLoginStates(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	kept := []*oidcStateRow{}
+	for _, r := range s.oidcStates {
+		if r.expires.After(now) {
+			kept = append(kept, r)
+		}
+	}
+
+	n := len(s.oidcStates) - len(kept)
+	s.oidcStates = kept
+	return int64(n), nil
+}
+
+func (s *Store) RecordOIDCLogin(ctx context.Context, issuer string, subject string) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.oidcIdentities {
+		if r.issuer == issuer && r.subject == subject {
+			now := time.Now()
+			r.lastLogin = &now
+			return intPtr(r.userId), nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Store) LinkOIDCIdentity(ctx context.Context, userId int, issuer string, subject string, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.oidcIdentities = append(s.oidcIdentities, &oidcIdentityRow{
+		id:        s.nextId("evaluator_oidc_identity"),
+		userId:    userId,
+		issuer:    issuer,
+		subject:   subject,
+		email:     email,
+		linked:    now,
+		lastLogin: &now,
+	})
+	return nil
+}
+
+func (s *Store) DeleteOIDCIdentitiesByUserId(ctx context.Context, userId int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := []*oidcIdentityRow{}
+	for _, r := range s.oidcIdentities {
+		if r.userId != userId {
+			kept = append(kept, r)
+		}
+	}
+	s.oidcIdentities = kept
+	return nil
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (s *Store) GetLastPasswordResetTime(ctx context.Context, userId int) (*time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var created *time.Time
+	for _, r := range s.resets {
+		if r.userId == userId && (created == nil || r.created.After(*created)) {
+			created = &r.created
+		}
+	}
+	return created, nil
+}
+
+func (s *Store) CreatePasswordReset(ctx context.Context, userId int, tokenHash string, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, r := range s.resets {
+		if r.userId == userId && r.used == nil && r.expires.After(now) {
+			r.expires = now
+		}
+	}
+
+	s.resets = append(s.resets, &passwordResetRow{
+		id:        s.nextId("password_reset"),
+		userId:    userId,
+		tokenHash: tokenHash,
+		created:   now,
+		expires:   expires,
+	})
+	return nil
+}
+
+func (s *Store) UsePasswordReset(ctx context.Context, tokenHash string, password string) (*int, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while resetting your password", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, r := range s.resets {
+		if r.tokenHash != tokenHash || r.used != nil || !r.expires.After(now) {
+			continue
+		}
+
+		u := s.findUser(r.userId)
+		if u == nil || u.accountLocked {
+			break
+		}
+
+		r.used = &now
+		u.password = stringPtr(string(hash))
+		s.sessions = s.filterSessions(func(session *sessionRow) bool {
+			return session.userId == u.id
+		})
+		id := u.id
+		return &id, nil
+	}
+
+	return nil, errors.NewBadRequestError(ctx, "Oops! This password reset link is invalid or has expired.")
+}
+
+func (s *Store) DeleteExpiredPasswordResets(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	kept := []*passwordResetRow{}
+	for _, r := range s.resets {
+		if r.expires.After(now) {
+			kept = append(kept, r)
+		}
+	}
+
+	n := len(s.resets) - len(kept)
+	s.resets = kept
+	return int64(n), nil
+}
@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+)
+
+// Returns true if the entry is still being judged
+func (e *entryRow) isJudgeable() bool {
+	return !e.isFlagged && !e.isDisqualified
+}
+
+// Returns the number of completed evaluations matching the filter
+func (s *Store) countEvaluations(match func(ev *evaluationRow, en *entryRow) bool) int {
+	count := 0
+	for _, ev := range s.evaluations {
+		if !ev.isComplete {
+			continue
+		}
+		if en := s.findEntry(ev.entryId); en != nil && match(ev, en) {
+			count++
+		}
+	}
+	return count
+}
+
+// Returns the number of unlocked users in each group who can judge entries
+func (s *Store) evaluatorsPerGroup() map[int]int {
+	counts := map[int]int{}
+	for _, u := range s.users {
+		if u.groupId != nil && !u.accountLocked && s.userHasPermission(u, auth.JudgeEntries) {
+			counts[*u.groupId]++
+		}
+	}
+	return counts
+}
+
+// Returns the number of judgeable entries assigned to each group for the contest
+func (s *Store) entriesPerGroup(contestId int) map[int]int {
+	counts := map[int]int{}
+	for _, e := range s.entries {
+		if e.contestId == contestId && e.isJudgeable() && e.groupId != nil {
+			counts[*e.groupId]++
+		}
+	}
+	return counts
+}
+
+func (s *Store) GetUserProgressByContestId(ctx context.Context, userId int, contestId int) (*model.Progress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	progress := &model.Progress{}
+	progress.Count = s.countEvaluations(func(ev *evaluationRow, en *entryRow) bool {
+		return en.contestId == contestId && ev.userId == userId && en.isJudgeable()
+	})
+
+	if u := s.findUser(userId); u != nil && u.groupId != nil {
+		progress.Total = s.entriesPerGroup(contestId)[*u.groupId]
+	}
+
+	return progress, nil
+}
+
+func (s *Store) GetGroupProgressByContestId(ctx context.Context, groupId int, contestId int) (*model.Progress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	progress := &model.Progress{}
+	progress.Count = s.countEvaluations(func(ev *evaluationRow, en *entryRow) bool {
+		return en.contestId == contestId && en.groupId != nil && *en.groupId == groupId && en.isJudgeable()
+	})
+	progress.Total = s.entriesPerGroup(contestId)[groupId] * s.evaluatorsPerGroup()[groupId]
+
+	return progress, nil
+}
+
+func (s *Store) GetEntryProgressByContestId(ctx context.Context, contestId int) (*model.Progress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	progress := &model.Progress{}
+	for _, e := range s.entries {
+		if e.contestId != contestId || !e.isJudgeable() {
+			continue
+		}
+
+		progress.Total++
+
+		for _, ev := range s.evaluations {
+			if ev.entryId == e.id && ev.isComplete {
+				progress.Count++
+				break
+			}
+		}
+	}
+
+	return progress, nil
+}
+
+func (s *Store) GetEvaluationProgressByContestId(ctx context.Context, contestId int) (*model.Progress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	progress := &model.Progress{}
+	progress.Count = s.countEvaluations(func(ev *evaluationRow, en *entryRow) bool {
+		return en.contestId == contestId && en.isJudgeable()
+	})
+
+	evaluators := s.evaluatorsPerGroup()
+	for groupId, entries := range s.entriesPerGroup(contestId) {
+		progress.Total += entries * evaluators[groupId]
+	}
+
+	return progress, nil
+}
+
+func (s *Store) GetEvaluatorProgressByContestId(ctx context.Context, contestId int) ([]*model.EvaluatorProgress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := []*userRow{}
+	for _, u := range s.users {
+		if u.groupId == nil || u.accountLocked || !s.userHasPermission(u, auth.JudgeEntries) {
+			continue
+		}
+		if g := s.findGroup(*u.groupId); g == nil || !g.IsActive {
+			continue
+		}
+		users = append(users, u)
+	}
+
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].nickname < users[j].nickname
+	})
+
+	entries := s.entriesPerGroup(contestId)
+
+	progress := []*model.EvaluatorProgress{}
+	for _, u := range users {
+		count := model.EvaluatorProgress{}
+		count.User = &model.User{ID: u.id}
+		count.Count = s.countEvaluations(func(ev *evaluationRow, en *entryRow) bool {
+			return en.contestId == contestId && ev.userId == u.id && en.isJudgeable()
+		})
+		count.Total = entries[*u.groupId]
+
+		progress = append(progress, &count)
+	}
+
+	return progress, nil
+}
+
+func (s *Store) GetFlaggedEntryCountByContestId(ctx context.Context, contestId int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, e := range s.entries {
+		if e.contestId == contestId && e.isFlagged && !e.isDisqualified {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Store) GetDisqualifiedEntryCountByContestId(ctx context.Context, contestId int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, e := range s.entries {
+		if e.contestId == contestId && e.isDisqualified {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Store) GetTotalEntryCountByContestId(ctx context.Context, contestId int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, e := range s.entries {
+		if e.contestId == contestId && e.isJudgeable() {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,292 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+)
+
+// The permissions and roles created by the roles migration
+var defaultPermissions = []*model.PermissionDefinition{
+	{Key: string(auth.AddEntries), Description: "Allows the user to add individual and bulk import entries."},
+	{Key: string(auth.AddUsers), Description: "Allows the user to create new user accounts."},
+	{Key: string(auth.AssignEntryGroups), Description: "Allows the user to assign entries to judging groups."},
+	{Key: string(auth.AssignEvaluatorGroups), Description: "Allows the user to assign evaluators to judging groups."},
+	{Key: string(auth.AssumeUserIdentities), Description: "Allows the user to impersonate other users."},
+	{Key: string(auth.ChangeUserPasswords), Description: "Allows the user to change the passwords of other users."},
+	{Key: string(auth.DeleteAllEvaluations), Description: "Allows the user to delete all evaluations."},
+	{Key: string(auth.DeleteAllTasks), Description: "Allows the user to delete all tasks."},
+	{Key: string(auth.DeleteContests), Description: "Allows the user to delete all contests and associated data."},
+	{Key: string(auth.DeleteEntries), Description: "Allows the user to delete all entries."},
+	{Key: string(auth.DeleteErrors), Description: "Allows the user to delete all errors."},
+	{Key: string(auth.DeleteKbContent), Description: "Allows the user to delete all KB articles and sections."},
+	{Key: string(auth.EditAllEvaluations), Description: "Allows the user to edit all evaluations."},
+	{Key: string(auth.EditAllTasks), Description: "Allows the user to edit all tasks."},
+	{Key: string(auth.EditContests), Description: "Allows the user to edit all contests."},
+	{Key: string(auth.EditEntries), Description: "Allows the user to edit all entries."},
+	{Key: string(auth.EditKbContent), Description: "Allows the user to edit all KB articles and sections."},
+	{Key: string(auth.EditUserProfiles), Description: "Allows the user to edit all user profiles."},
+	{Key: string(auth.JudgeEntries), Description: "Allows the user to score entries."},
+	{Key: string(auth.ManageAnnouncements), Description: "Allows the user to create, edit, and delete announcements."},
+	{Key: string(auth.ManageJudgingCriteria), Description: "Allows the user to create, edit, and delete judging criteria."},
+	{Key: string(auth.ManageJudgingGroups), Description: "Allows the user to create, edit, and delete judging groups. Needs the assign_evaluator_groups permission to also assign users to groups."},
+	{Key: string(auth.ManageWinners), Description: "Allows the user to add and remove winning entries."},
+	{Key: string(auth.PublishKbContent), Description: "Allows the user to publish draft KB articles."},
+	{Key: string(auth.ViewAdminStats), Description: "Allows the user to view admin stats on the dashboard."},
+	{Key: string(auth.ViewAllEvaluations), Description: "Allows the user to view all evaluations."},
+	{Key: string(auth.ViewAllTasks), Description: "Allows the user to view all tasks."},
+	{Key: string(auth.ViewAllUsers), Description: "Allows the user to view all user accounts."},
+	{Key: string(auth.ViewErrors), Description: "Allows the user to view all errors."},
+	{Key: string(auth.ViewJudgingSettings), Description: "Allows the user to view all judging settings."},
+}
+
+func defaultRoles() []*roleRow {
+	all := []string{}
+	for _, p := range defaultPermissions {
+		all = append(all, p.Key)
+	}
+
+	return []*roleRow{
+		{id: 1, name: "Evaluator", description: "Scores entries in their assigned judging group.", permissions: []string{string(auth.JudgeEntries)}},
+		{id: 2, name: "Lead", description: "Runs judging: manages entries, groups and winners and sees everyone's progress.", permissions: []string{
+			string(auth.JudgeEntries), string(auth.ViewAdminStats), string(auth.AddEntries), string(auth.EditEntries), string(auth.AssignEntryGroups), string(auth.AssignEvaluatorGroups),
+			string(auth.ManageJudgingGroups), string(auth.ViewJudgingSettings), string(auth.ViewAllEvaluations), string(auth.ViewAllTasks), string(auth.ViewAllUsers), string(auth.ManageWinners),
+		}},
+		{id: 3, name: "KB Editor", description: "Writes, publishes and removes knowledge base content.", permissions: []string{string(auth.EditKbContent), string(auth.DeleteKbContent), string(auth.PublishKbContent)}},
+		{id: 4, name: "Admin", description: "Holds every permission.", permissions: all},
+	}
+}
+
+func (r *roleRow) toModel() *model.Role {
+	permissions := append([]string{}, r.permissions...)
+	sort.Strings(permissions)
+
+	return &model.Role{
+		ID:          r.id,
+		Name:        r.name,
+		Description: r.description,
+		Permissions: permissions,
+	}
+}
+
+func (s *Store) findRole(id int) *roleRow {
+	for _, r := range s.roles {
+		if r.id == id {
+			return r
+		}
+	}
+	return nil
+}
+
+// Returns the keys of every permission a user holds through their roles or direct grants
+func (s *Store) userPermissionKeys(u *userRow) []string {
+	granted := map[string]bool{}
+	for key := range u.permissions {
+		granted[key] = true
+	}
+	for _, id := range u.roleIds {
+		if r := s.findRole(id); r != nil {
+			for _, key := range r.permissions {
+				granted[key] = true
+			}
+		}
+	}
+
+	keys := []string{}
+	for key := range granted {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *Store) userHasPermission(u *userRow, permission auth.Permission) bool {
+	for _, key := range s.userPermissionKeys(u) {
+		if key == string(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the permission keys without duplicates, or a bad request error if one of them is not a permission
+func (s *Store) checkPermissionKeys(ctx context.Context, keys []string) ([]string, error) {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+
+		known := false
+		for _, p := range s.permissions {
+			known = known || p.Key == key
+		}
+		if !known {
+			return nil, errors.NewBadRequestError(ctx, "Oops! "+key+" is not a permission.")
+		}
+
+		seen[key] = true
+		unique = append(unique, key)
+	}
+	return unique, nil
+}
+
+func (s *Store) isRoleNameTaken(name string, exceptId int) bool {
+	for _, r := range s.roles {
+		if r.name == name && r.id != exceptId {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) GetAllRoles(ctx context.Context) ([]*model.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	roles := []*model.Role{}
+	for _, r := range s.roles {
+		roles = append(roles, r.toModel())
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+	return roles, nil
+}
+
+func (s *Store) GetRoleById(ctx context.Context, id int) (*model.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.findRole(id)
+	if r == nil {
+		return nil, errors.NewNotFoundError(ctx, "Oops! The requested role does not exist.")
+	}
+	return r.toModel(), nil
+}
+
+func (s *Store) GetRolesByUserId(ctx context.Context, userId int) ([]*model.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	roles := []*model.Role{}
+	if u := s.findUser(userId); u != nil {
+		for _, id := range u.roleIds {
+			if r := s.findRole(id); r != nil {
+				roles = append(roles, r.toModel())
+			}
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+	return roles, nil
+}
+
+func (s *Store) GetAllPermissionDefinitions(ctx context.Context) ([]*model.PermissionDefinition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	definitions := []*model.PermissionDefinition{}
+	for _, p := range s.permissions {
+		definition := *p
+		definitions = append(definitions, &definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Key < definitions[j].Key
+	})
+	return definitions, nil
+}
+
+func (s *Store) CreateRole(ctx context.Context, input *model.RoleInput) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.checkPermissionKeys(ctx, input.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if s.isRoleNameTaken(input.Name, 0) {
+		return nil, errors.NewBadRequestError(ctx, "Oops! A role with this name already exists.")
+	}
+
+	r := &roleRow{
+		id:          s.nextId("role"),
+		name:        input.Name,
+		description: input.Description,
+		permissions: keys,
+	}
+	s.roles = append(s.roles, r)
+
+	return intPtr(r.id), nil
+}
+
+func (s *Store) EditRoleById(ctx context.Context, id int, input *model.RoleInput) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.checkPermissionKeys(ctx, input.Permissions)
+	if err != nil {
+		return err
+	}
+	if s.isRoleNameTaken(input.Name, id) {
+		return errors.NewBadRequestError(ctx, "Oops! A role with this name already exists.")
+	}
+
+	if r := s.findRole(id); r != nil {
+		r.name = input.Name
+		r.description = input.Description
+		r.permissions = keys
+	}
+	return nil
+}
+
+func (s *Store) DeleteRoleById(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	roles := []*roleRow{}
+	for _, r := range s.roles {
+		if r.id != id {
+			roles = append(roles, r)
+		}
+	}
+	s.roles = roles
+
+	for _, u := range s.users {
+		roleIds := []int{}
+		for _, roleId := range u.roleIds {
+			if roleId != id {
+				roleIds = append(roleIds, roleId)
+			}
+		}
+		u.roleIds = roleIds
+	}
+	return nil
+}
+
+func (s *Store) SetUserRolesById(ctx context.Context, userId int, roleIds []int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unique := []int{}
+	seen := map[int]bool{}
+	for _, id := range roleIds {
+		if s.findRole(id) == nil {
+			return errors.NewNotFoundError(ctx, "Oops! One of the requested roles does not exist.")
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	if u := s.findUser(userId); u != nil {
+		u.roleIds = unique
+	}
+	return nil
+}
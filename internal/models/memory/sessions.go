@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+	"github.com/KA-Challenge-Council/Bema/internal/util"
+)
+
+func (r *sessionRow) toModel() *model.Session {
+	userAgent := ""
+	if r.userAgent != nil {
+		userAgent = *r.userAgent
+	}
+
+	return &model.Session{
+		ID:         r.id,
+		Device:     util.DeviceFromUserAgent(userAgent),
+		UserAgent:  r.userAgent,
+		IPAddress:  r.ipAddress,
+		CreatedAt:  r.created,
+		LastSeenAt: r.lastSeen,
+		ExpiresAt:  r.expires,
+	}
+}
+
+func (s *Store) GetActiveSessionsByUserId(ctx context.Context, userId int) ([]*model.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	rows := []*sessionRow{}
+	for _, r := range s.sessions {
+		if r.userId == userId && r.impersonatedBy == nil && r.expires.After(now) {
+			rows = append(rows, r)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].lastSeen.Equal(rows[j].lastSeen) {
+			return rows[i].lastSeen.After(rows[j].lastSeen)
+		}
+		return rows[i].id > rows[j].id
+	})
+
+	sessions := []*model.Session{}
+	for _, r := range rows {
+		sessions = append(sessions, r.toModel())
+	}
+	return sessions, nil
+}
+
+func (s *Store) DeleteSessionById(ctx context.Context, userId int, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := false
+	s.sessions = s.filterSessions(func(r *sessionRow) bool {
+		matches := r.id == id && r.userId == userId
+		found = found || matches
+		return matches
+	})
+
+	if !found {
+		return errors.NewNotFoundError(ctx, "Oops! The requested session does not exist.")
+	}
+	return nil
+}
+
+func (s *Store) DeleteSessionsByUserId(ctx context.Context, userId int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions = s.filterSessions(func(r *sessionRow) bool {
+		return r.userId == userId
+	})
+	return nil
+}
+
+func (s *Store) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	before := len(s.sessions)
+	s.sessions = s.filterSessions(func(r *sessionRow) bool {
+		return !r.expires.After(now)
+	})
+	return int64(before - len(s.sessions)), nil
+}
+
+// Returns the sessions that do not match the condition
+func (s *Store) EndImpersonation(ctx context.Context, sessionId int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, r := range s.impersonations {
+		if r.sessionId != nil && *r.sessionId == sessionId && r.ended == nil {
+			r.ended = &now
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetImpersonationsPage(ctx context.Context, activeOnly bool, args models.PageArgs) (*models.Page[*model.Impersonation], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	impersonations := []*model.Impersonation{}
+	for i := len(s.impersonations) - 1; i >= 0; i-- {
+		r := s.impersonations[i]
+		isActive := false
+		for _, session := range s.sessions {
+			isActive = isActive || (r.sessionId != nil && session.id == *r.sessionId && r.ended == nil && session.expires.After(now))
+		}
+		if activeOnly && !isActive {
+			continue
+		}
+
+		impersonations = append(impersonations, &model.Impersonation{
+			ID:           r.id,
+			Impersonator: &model.User{ID: r.impersonatorId},
+			User:         &model.User{ID: r.userId},
+			Reason:       r.reason,
+			IsReadOnly:   r.readOnly,
+			IPAddress:    r.ipAddress,
+			StartedAt:    r.started,
+			ExpiresAt:    r.expires,
+			EndedAt:      r.ended,
+			IsActive:     isActive,
+		})
+	}
+
+	var isAfter func(*model.Impersonation) bool
+	if args.After != "" {
+		var afterId int
+		if err := decodeCursor(ctx, args.After, &afterId); err != nil {
+			return nil, err
+		}
+		isAfter = func(i *model.Impersonation) bool { return i.ID < afterId }
+	}
+	return models.PageOf(impersonations, args.First, isAfter, models.ImpersonationCursor), nil
+}
+
+func (s *Store) filterSessions(remove func(r *sessionRow) bool) []*sessionRow {
+	kept := []*sessionRow{}
+	for _, r := range s.sessions {
+		if !remove(r) {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,409 @@
+// Package memory provides an in-memory implementation of the model repositories.
+// It is intended for tests, so resolvers can run without a Postgres database.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+	"github.com/KA-Challenge-Council/Bema/internal/util"
+)
+
+// Holds every table in memory. All repositories share a single lock.
+type Store struct {
+	mu      sync.Mutex
+	lastIds map[string]int
+
+	announcements []*announcementRow
+	auditLog      []*auditLogRow
+	contests      []*contestRow
+	entries       []*entryRow
+	votes         []*voteRow
+	evaluations   []*evaluationRow
+	criteria      []*model.JudgingCriteria
+	groups        []*model.JudgingGroup
+	tasks         []*taskRow
+	errors        []*errorRow
+	users         []*userRow
+	roles         []*roleRow
+	invitations   []*invitationRow
+	sessions      []*sessionRow
+	// Impersonations outlive their sessions
+	impersonations []*impersonationRow
+	throttles      []*throttleRow
+	resets         []*passwordResetRow
+	recoveryCodes  []*recoveryCodeRow
+	challenges     []*loginChallengeRow
+	apiTokens      []*apiTokenRow
+	oidcStates     []*oidcStateRow
+	// Provider accounts linked to users
+	oidcIdentities []*oidcIdentityRow
+	// Permission keys whose holders must enable two-factor authentication
+	twoFactorRequired []string
+	permissions       []*model.PermissionDefinition
+	sections          []*model.KBSection
+	articles          []*articleRow
+	drafts            []*draftRow
+}
+
+type announcementRow struct {
+	id       int
+	authorId int
+	created  time.Time
+	title    string
+	content  string
+	isPublic bool
+}
+
+type auditLogRow struct {
+	id      int
+	record  models.AuditRecord
+	created time.Time
+}
+
+type contestRow struct {
+	id            int
+	name          string
+	url           *string
+	author        *string
+	startDate     *time.Time
+	endDate       *time.Time
+	state         model.ContestState
+	badgeSlug     *string
+	badgeImageURL *string
+}
+
+type entryRow struct {
+	id                 int
+	contestId          int
+	url                string
+	kaid               string
+	title              string
+	authorName         string
+	authorKaid         *string
+	skillLevel         string
+	votes              int
+	created            time.Time
+	height             int
+	isWinner           bool
+	groupId            *int
+	isFlagged          bool
+	flagReason         *string
+	isDisqualified     bool
+	isSkillLevelLocked bool
+}
+
+type voteRow struct {
+	id       int
+	entryId  int
+	userId   int
+	feedback string
+}
+
+type evaluationRow struct {
+	id             int
+	entryId        int
+	userId         int
+	creativity     float64
+	complexity     float64
+	execution      float64
+	interpretation float64
+	skillLevel     string
+	created        time.Time
+	isComplete     bool
+}
+
+type taskRow struct {
+	id           int
+	title        string
+	assignedUser *int
+	status       string
+	dueDate      *time.Time
+}
+
+type errorRow struct {
+	id        int
+	message   string
+	stack     *string
+	created   time.Time
+	origin    *string
+	referer   *string
+	userAgent *string
+	userId    *int
+}
+
+type userRow struct {
+	id            int
+	kaid          string
+	name          string
+	nickname      string
+	username      string
+	email         *string
+	password      *string
+	accountLocked bool
+	isAdmin       bool
+	lastLogin     *time.Time
+	termStart     *time.Time
+	termEnd       *time.Time
+	// Set when the account was locked because its term ended
+	termLocked *time.Time
+	// The term end the user and admins were last warned about
+	termEndWarned        *time.Time
+	notificationsEnabled bool
+	groupId              *int
+	totpSecret           *string
+	totpPendingSecret    *string
+	totpEnabled          *time.Time
+	totpLastStep         *int64
+	roleIds              []int
+	// Permission keys granted directly to the user
+	permissions map[string]bool
+}
+
+type roleRow struct {
+	id          int
+	name        string
+	description string
+	permissions []string
+}
+
+type articleRow struct {
+	id          int
+	sectionId   int
+	title       string
+	content     string
+	authorId    int
+	lastUpdated time.Time
+	visibility  string
+	isPublished bool
+}
+
+type draftRow struct {
+	id          int
+	articleId   int
+	title       string
+	content     string
+	authorId    int
+	lastUpdated time.Time
+	isPublished bool
+}
+
+type sessionRow struct {
+	id             int
+	userId         int
+	created        time.Time
+	lastSeen       time.Time
+	expires        time.Time
+	impersonatedBy *int
+	ipAddress      *string
+	userAgent      *string
+}
+
+type invitationRow struct {
+	id        int
+	tokenHash string
+	input     model.InviteUserInput
+	invitedBy int
+	userId    *int
+	created   time.Time
+	expires   time.Time
+	accepted  *time.Time
+	revoked   *time.Time
+}
+
+type impersonationRow struct {
+	id             int
+	impersonatorId int
+	userId         int
+	// Nil once the session is gone
+	sessionId *int
+	reason    string
+	readOnly  bool
+	ipAddress *string
+	started   time.Time
+	expires   time.Time
+	ended     *time.Time
+}
+
+type throttleRow struct {
+	id          int
+	kind        model.LoginThrottleKind
+	value       string
+	failedCount int
+	lastFailed  time.Time
+	lockedUntil *time.Time
+}
+
+type passwordResetRow struct {
+	id        int
+	userId    int
+	tokenHash string
+	created   time.Time
+	expires   time.Time
+	used      *time.Time
+}
+
+type recoveryCodeRow struct {
+	id       int
+	userId   int
+	codeHash string
+	used     *time.Time
+}
+
+type loginChallengeRow struct {
+	id          int
+	userId      int
+	tokenHash   string
+	expires     time.Time
+	failedCount int
+}
+
+type apiTokenRow struct {
+	id          int
+	userId      int
+	name        string
+	tokenHash   string
+	prefix      string
+	permissions []string
+	created     time.Time
+	expires     *time.Time
+	lastUsed    *time.Time
+}
+
+type oidcStateRow struct {
+	id           int
+	stateHash    string
+	codeVerifier string
+	nonce        string
+	expires      time.Time
+}
+
+type oidcIdentityRow struct {
+	id        int
+	userId    int
+	issuer    string
+	subject   string
+	email     string
+	linked    time.Time
+	lastLogin *time.Time
+}
+
+func NewStore() *Store {
+	roles := defaultRoles()
+	return &Store{
+		lastIds:     map[string]int{"role": len(roles)},
+		roles:       roles,
+		permissions: defaultPermissions,
+	}
+}
+
+// Returns a set of repositories that are all backed by a new, empty store
+func NewRepositories() *models.Repositories {
+	return NewStore().Repositories()
+}
+
+// Returns a set of repositories that are all backed by this store
+func (s *Store) Repositories() *models.Repositories {
+	return &models.Repositories{
+		APITokens:      s,
+		Announcements:  s,
+		AuditLog:       s,
+		Contests:       s,
+		Contestants:    s,
+		Entries:        s,
+		Errors:         s,
+		Evaluations:    s,
+		Invitations:    s,
+		Judging:        s,
+		KB:             s,
+		Lifecycle:      s,
+		LoginThrottles: s,
+		OIDC:           s,
+		PasswordResets: s,
+		Reports:        s,
+		Roles:          s,
+		Sessions:       s,
+		System:         s,
+		Tasks:          s,
+		TwoFactor:      s,
+		Users:          s,
+	}
+}
+
+// Returns the next id for the given table, like a serial column would
+func (s *Store) nextId(table string) int {
+	s.lastIds[table]++
+	return s.lastIds[table]
+}
+
+// Decodes a page cursor, returning a bad request error if it is not valid
+func decodeCursor(ctx context.Context, cursor string, keys ...interface{}) error {
+	if err := models.DecodeCursor(cursor, keys...); err != nil {
+		return errors.NewBadRequestError(ctx, "Oops! The cursor provided is not valid.")
+	}
+	return nil
+}
+
+// Parses a date the same way Postgres would when it is passed in as a string
+func parseDate(value string) *time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02", util.DateFormat, util.DisplayDateFormat} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// Returns a copy of t so callers cannot modify the stored value
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// Returns the rows that do not match, like a DELETE ... WHERE
+func removeWhere[T any](rows []T, match func(T) bool) []T {
+	kept := rows[:0]
+	for _, row := range rows {
+		if !match(row) {
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+)
+
+func (s *Store) Ping(ctx context.Context) error {
+	return nil
+}
+
+// The store has no connection pool, so every statistic is zero
+func (s *Store) GetDatabaseStats(ctx context.Context) sql.DBStats {
+	return sql.DBStats{}
+}
+
+// The store always matches the latest migration
+func (s *Store) GetSchemaVersion(ctx context.Context) (int, error) {
+	return db.LatestMigrationVersion()
+}
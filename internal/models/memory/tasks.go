@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+	"github.com/KA-Challenge-Council/Bema/internal/util"
+)
+
+func (t *taskRow) toModel() *model.Task {
+	task := models.NewTaskModel()
+	task.ID = t.id
+	task.Title = t.title
+	task.Status = t.status
+	if t.dueDate != nil {
+		task.DueAt = *t.dueDate
+		task.DueDate = util.FormatDate(*t.dueDate, util.DateFormat)
+	}
+
+	if t.assignedUser != nil {
+		task.AssignedUser.ID = *t.assignedUser
+	} else {
+		task.AssignedUser = nil
+	}
+
+	return &task
+}
+
+func (s *Store) findTask(id int) *taskRow {
+	for _, t := range s.tasks {
+		if t.id == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// Returns the tasks that match the filter, sorted by id
+func (s *Store) filterTasks(descending bool, match func(t *taskRow) bool) []*model.Task {
+	rows := append([]*taskRow{}, s.tasks...)
+	sort.Slice(rows, func(i, j int) bool {
+		if descending {
+			return rows[i].id > rows[j].id
+		}
+		return rows[i].id < rows[j].id
+	})
+
+	tasks := []*model.Task{}
+	for _, t := range rows {
+		if match(t) {
+			tasks = append(tasks, t.toModel())
+		}
+	}
+	return tasks
+}
+
+func (t *taskRow) isIncomplete() bool {
+	return t.status == "Not Started" || t.status == "Started"
+}
+
+func (s *Store) GetTaskById(ctx context.Context, id int) (*model.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.findTask(id)
+	if t == nil {
+		return nil, errors.NewNotFoundError(ctx, "This task does not exist.")
+	}
+	return t.toModel(), nil
+}
+
+func (s *Store) GetIncompleteTasks(ctx context.Context) ([]*model.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.filterTasks(false, func(t *taskRow) bool {
+		return t.isIncomplete()
+	}), nil
+}
+
+func (s *Store) GetCompletedTasks(ctx context.Context) ([]*model.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.filterTasks(true, func(t *taskRow) bool {
+		return t.status == "Completed"
+	}), nil
+}
+
+func (s *Store) GetAvailableTasks(ctx context.Context) ([]*model.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.filterTasks(false, func(t *taskRow) bool {
+		return t.assignedUser == nil
+	}), nil
+}
+
+func (s *Store) GetTasksForUser(ctx context.Context, userId int) ([]*model.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.filterTasks(false, func(t *taskRow) bool {
+		return t.assignedUser != nil && *t.assignedUser == userId && t.isIncomplete()
+	}), nil
+}
+
+func (s *Store) CreateTask(ctx context.Context, input *model.CreateTaskInput) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := &taskRow{
+		id:           s.nextId("task"),
+		title:        input.Title,
+		assignedUser: input.AssignedUser,
+		status:       "Not Started",
+		dueDate:      parseDate(input.DueDate),
+	}
+	s.tasks = append(s.tasks, t)
+
+	return intPtr(t.id), nil
+}
+
+func (s *Store) EditTaskById(ctx context.Context, id int, input *model.EditTaskInput) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t := s.findTask(id); t != nil {
+		t.title = input.Title
+		t.assignedUser = input.AssignedUser
+		t.dueDate = parseDate(input.DueDate)
+		t.status = input.Status
+	}
+	return nil
+}
+
+func (s *Store) DeleteTaskById(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tasks = removeWhere(s.tasks, func(t *taskRow) bool { return t.id == id })
+	return nil
+}
@@ -0,0 +1,214 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+)
+
+func (s *Store) GetTwoFactorByUserId(ctx context.Context, userId int) (*models.TwoFactor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.findUser(userId)
+	if u == nil {
+		return nil, errors.NewNotFoundError(ctx, "Oops! The requested user does not exist.")
+	}
+
+	tf := &models.TwoFactor{Secret: u.totpSecret, PendingSecret: u.totpPendingSecret, EnabledAt: u.totpEnabled}
+	for _, r := range s.recoveryCodes {
+		if r.userId == userId && r.used == nil {
+			tf.RecoveryCodesRemaining++
+		}
+	}
+	return tf, nil
+}
+
+func (s *Store) SetPendingTOTPSecret(ctx context.Context, userId int, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u := s.findUser(userId); u != nil {
+		u.totpPendingSecret = &secret
+	}
+	return nil
+}
+
+func (s *Store) EnableTwoFactor(ctx context.Context, userId int, step int64, recoveryCodeHashes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.findUser(userId)
+	if u == nil || u.totpPendingSecret == nil {
+		return nil
+	}
+
+	now := time.Now()
+	u.totpSecret = u.totpPendingSecret
+	u.totpPendingSecret = nil
+	u.totpEnabled = &now
+	u.totpLastStep = &step
+	s.replaceRecoveryCodes(userId, recoveryCodeHashes)
+	return nil
+}
+
+func (s *Store) UseTOTPStep(ctx context.Context, userId int, step int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.findUser(userId)
+	if u == nil || (u.totpLastStep != nil && *u.totpLastStep >= step) {
+		return false, nil
+	}
+	u.totpLastStep = &step
+	return true, nil
+}
+
+func (s *Store) UseRecoveryCode(ctx context.Context, userId int, codeHash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.recoveryCodes {
+		if r.userId == userId && r.codeHash == codeHash && r.used == nil {
+			now := time.Now()
+			r.used = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *Store) ReplaceRecoveryCodes(ctx context.Context, userId int, codeHashes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.replaceRecoveryCodes(userId, codeHashes)
+	return nil
+}
+
+func (s *Store) replaceRecoveryCodes(userId int, codeHashes []string) {
+	kept := []*recoveryCodeRow{}
+	for _, r := range s.recoveryCodes {
+		if r.userId != userId {
+			kept = append(kept, r)
+		}
+	}
+	for _, hash := range codeHashes {
+		kept = append(kept, &recoveryCodeRow{id: s.nextId("recovery_code"), userId: userId, codeHash: hash})
+	}
+	s.recoveryCodes = kept
+}
+
+func (s *Store) DisableTwoFactor(ctx context.Context, userId int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u := s.findUser(userId); u != nil {
+		u.totpSecret = nil
+		u.totpPendingSecret = nil
+		u.totpEnabled = nil
+		u.totpLastStep = nil
+	}
+	s.replaceRecoveryCodes(userId, nil)
+	s.challenges = s.filterChallenges(func(r *loginChallengeRow) bool {
+		return r.userId == userId
+	})
+	return nil
+}
+
+func (s *Store) CreateLoginChallenge(ctx context.Context, userId int, tokenHash string, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.challenges = append(s.challenges, &loginChallengeRow{
+		id:        s.nextId("login_challenge"),
+		userId:    userId,
+		tokenHash: tokenHash,
+		expires:   expires,
+	})
+	return nil
+}
+
+func (s *Store) GetLoginChallengeUserId(ctx context.Context, tokenHash string) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.challenges {
+		if r.tokenHash == tokenHash && r.expires.After(time.Now()) {
+			if u := s.findUser(r.userId); u != nil && !u.accountLocked {
+				return intPtr(u.id), nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (s *Store) FailLoginChallenge(ctx context.Context, tokenHash string, maxFailures int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.challenges = s.filterChallenges(func(r *loginChallengeRow) bool {
+		if r.tokenHash != tokenHash {
+			return false
+		}
+		r.failedCount++
+		return r.failedCount >= maxFailures
+	})
+	return nil
+}
+
+func (s *Store) DeleteLoginChallenge(ctx context.Context, tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.challenges = s.filterChallenges(func(r *loginChallengeRow) bool {
+		return r.tokenHash == tokenHash
+	})
+	return nil
+}
+
+func (s *Store) DeleteExpiredLoginChallenges(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	before := len(s.challenges)
+	s.challenges = s.filterChallenges(func(r *loginChallengeRow) bool {
+		return !r.expires.After(now)
+	})
+	return int64(before - len(s.challenges)), nil
+}
+
+// Returns the pending logins that do not match the condition
+func (s *Store) filterChallenges(remove func(r *loginChallengeRow) bool) []*loginChallengeRow {
+	kept := []*loginChallengeRow{}
+	for _, r := range s.challenges {
+		if !remove(r) {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+func (s *Store) GetTwoFactorRequiredPermissions(ctx context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.twoFactorRequired...), nil
+}
+
+func (s *Store) SetTwoFactorRequiredPermissions(ctx context.Context, keys []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.checkPermissionKeys(ctx, keys)
+	if err != nil {
+		return err
+	}
+	sort.Strings(keys)
+	s.twoFactorRequired = keys
+	return nil
+}
@@ -0,0 +1,292 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+	"github.com/KA-Challenge-Council/Bema/internal/util"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (u *userRow) toModel() *model.User {
+	user := models.NewUserModel()
+	user.ID = u.id
+	user.Kaid = u.kaid
+	user.Name = stringPtr(u.name)
+	user.Nickname = stringPtr(u.nickname)
+	user.Username = stringPtr(u.username)
+	user.Email = u.email
+	user.AccountLocked = boolPtr(u.accountLocked)
+	user.IsAdmin = boolPtr(u.isAdmin)
+	user.LastLoginAt = copyTime(u.lastLogin)
+	user.TermStartsAt = copyTime(u.termStart)
+	user.TermEndsAt = copyTime(u.termEnd)
+	user.LastLogin = util.FormatNullableDate(u.lastLogin, util.DisplayDateFormat)
+	user.TermStart = util.FormatNullableDate(u.termStart, util.DisplayDateFormat)
+	user.TermEnd = util.FormatNullableDate(u.termEnd, util.DisplayDateFormat)
+	user.NotificationsEnabled = boolPtr(u.notificationsEnabled)
+	return &user
+}
+
+func (s *Store) findUser(id int) *userRow {
+	for _, u := range s.users {
+		if u.id == id {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Store) findUserByUsername(username string) *userRow {
+	for _, u := range s.users {
+		if u.username == username {
+			return u
+		}
+	}
+	return nil
+}
+
+// Returns the users with the given lock status, newest first
+func (s *Store) usersByLockStatus(locked bool) []*model.User {
+	rows := append([]*userRow{}, s.users...)
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].id > rows[j].id
+	})
+
+	users := []*model.User{}
+	for _, u := range rows {
+		if u.accountLocked == locked {
+			users = append(users, u.toModel())
+		}
+	}
+	return users
+}
+
+func (s *Store) GetAllActiveUsers(ctx context.Context) ([]*model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.usersByLockStatus(false), nil
+}
+
+func (s *Store) GetActiveUsersPage(ctx context.Context, args models.PageArgs) (*models.Page[*model.User], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var isAfter func(*model.User) bool
+	if args.After != "" {
+		var afterId int
+		if err := decodeCursor(ctx, args.After, &afterId); err != nil {
+			return nil, err
+		}
+		isAfter = func(u *model.User) bool { return u.ID < afterId }
+	}
+	return models.PageOf(s.usersByLockStatus(false), args.First, isAfter, models.UserCursor), nil
+}
+
+func (s *Store) GetAllInactiveUsers(ctx context.Context) ([]*model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.usersByLockStatus(true), nil
+}
+
+func (s *Store) GetUserById(ctx context.Context, id int) (*model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.findUser(id)
+	if u == nil {
+		return nil, errors.NewNotFoundError(ctx, "Oops! The requested user does not exist.")
+	}
+	return u.toModel(), nil
+}
+
+func (s *Store) GetUsersByIds(ctx context.Context, ids []int) (map[int]*model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := map[int]*model.User{}
+	for _, id := range ids {
+		if u := s.findUser(id); u != nil {
+			users[id] = u.toModel()
+		}
+	}
+	return users, nil
+}
+
+func (s *Store) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.findUserByUsername(username)
+	if u == nil {
+		return nil, nil
+	}
+	return u.toModel(), nil
+}
+
+func (s *Store) GetActiveUsersByEmail(ctx context.Context, email string) ([]*model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := []*model.User{}
+	for _, u := range s.users {
+		if u.email != nil && strings.EqualFold(*u.email, email) && !u.accountLocked && u.username != "" {
+			users = append(users, u.toModel())
+		}
+	}
+	return users, nil
+}
+
+func (s *Store) GetUserPermissionsById(ctx context.Context, id int) (*model.Permissions, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.findUser(id)
+	if u == nil {
+		return nil, errors.NewNotFoundError(ctx, "Oops! The requested user does not exist.")
+	}
+
+	return models.PermissionsFromKeys(s.userPermissionKeys(u)), nil
+}
+
+// Returns the keys of every permission the user holds through their roles or direct grants
+func (s *Store) GetUserPermissionKeysById(ctx context.Context, id int) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.findUser(id)
+	if u == nil {
+		return nil, errors.NewNotFoundError(ctx, "Oops! The requested user does not exist.")
+	}
+
+	return s.userPermissionKeys(u), nil
+}
+
+// Returns the group id assigned to the specified user
+func (s *Store) GetUserGroupById(ctx context.Context, id int) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.findUser(id)
+	if u == nil {
+		return nil, errors.NewNotFoundError(ctx, "Oops! The requested user does not exist.")
+	}
+	return u.groupId, nil
+}
+
+func (s *Store) GetUserPasswordHashByUsername(ctx context.Context, username string) (*string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.findUserByUsername(username)
+	if u == nil || u.password == nil {
+		return nil, nil
+	}
+
+	hash := *u.password
+	return &hash, nil
+}
+
+func (s *Store) SetUserLastLoginById(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u := s.findUser(id); u != nil {
+		now := time.Now()
+		u.lastLogin = &now
+	}
+	return nil
+}
+
+func (s *Store) ChangeUserPasswordById(ctx context.Context, id int, password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while changing a user's password", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u := s.findUser(id); u != nil {
+		u.password = stringPtr(string(hash))
+	}
+	return nil
+}
+
+func (s *Store) CreateUser(ctx context.Context, input *model.CreateUserInput) (*int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := &userRow{
+		id:                   s.nextId("evaluator"),
+		kaid:                 input.Kaid,
+		name:                 input.Name,
+		username:             input.Username,
+		email:                input.Email,
+		termStart:            parseDate(input.TermStart),
+		notificationsEnabled: true,
+	}
+	s.users = append(s.users, u)
+
+	return intPtr(u.id), nil
+}
+
+func (s *Store) EditUserById(ctx context.Context, id int, input *model.EditUserProfileInput) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u := s.findUser(id); u != nil {
+		u.name = input.Name
+		u.kaid = input.Kaid
+		u.isAdmin = input.IsAdmin
+		u.termStart = parseDate(input.TermStart)
+		u.termEnd = nil
+		if input.TermEnd != nil {
+			u.termEnd = parseDate(*input.TermEnd)
+		}
+		u.accountLocked = input.AccountLocked
+		if !u.accountLocked {
+			u.termLocked = nil
+		}
+		u.email = input.Email
+		u.username = input.Username
+		u.nickname = input.Nickname
+		u.notificationsEnabled = input.NotificationsEnabled
+	}
+	return nil
+}
+
+func (s *Store) EditUserPermissionsById(ctx context.Context, id int, keys []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.checkPermissionKeys(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	if u := s.findUser(id); u != nil {
+		u.permissions = map[string]bool{}
+		for _, key := range keys {
+			u.permissions[key] = true
+		}
+	}
+	return nil
+}
+
+func (s *Store) AssignUserToJudgingGroup(ctx context.Context, userId int, groupId *int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u := s.findUser(userId); u != nil {
+		u.groupId = groupId
+	}
+	return nil
+}
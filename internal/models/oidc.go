@@ -0,0 +1,106 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+)
+
+// What was sent to the OpenID Connect provider with a login, needed to finish it
+type OIDCLoginState struct {
+	CodeVerifier string
+	Nonce        string
+}
+
+// Stores a login that was sent to the OpenID Connect provider, by the hash of its state
+func (p *Postgres) CreateOIDCLoginState(ctx context.Context, stateHash string, codeVerifier string, nonce string, expires time.Time) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "INSERT INTO oidc_login_state (state_hash, code_verifier, nonce, created_tstz, expires_tstz) VALUES ($1, $2, $3, $4, $5);", stateHash, codeVerifier, nonce, time.Now().UTC(), expires.UTC())
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while logging in", err)
+	}
+	return nil
+}
+
+// Removes and returns the login with the given state hash, or nil if it does not exist or has
+// expired. Each state can only be used once.
+func (p *Postgres) UseOIDCLoginState(ctx context.Context, stateHash string) (*OIDCLoginState, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "DELETE FROM oidc_login_state WHERE state_hash = $1 RETURNING code_verifier, nonce, expires_tstz;", stateHash)
+
+	var state OIDCLoginState
+	var expires time.Time
+	if err := row.Scan(&state.CodeVerifier, &state.Nonce, &expires); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while logging in", err)
+	}
+	if !expires.After(time.Now()) {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// Deletes logins that were never finished and returns how many were deleted. Errors are returned
+// as is since this runs as a background job rather than for a request.
+func (p *Postgres) DeleteExpiredOIDCLoginStates(ctx context.Context) (int64, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := p.db.ExecContext(ctx, "DELETE FROM oidc_login_state WHERE expires_tstz <= $1;", time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Records a login with a linked provider account and returns the id of the user it is linked to,
+// or nil if it is not linked to anyone
+func (p *Postgres) RecordOIDCLogin(ctx context.Context, issuer string, subject string) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "UPDATE evaluator_oidc_identity SET last_login_tstz = $1 WHERE issuer = $2 AND subject = $3 RETURNING evaluator_id;", time.Now().UTC(), issuer, subject)
+
+	var userId int
+	if err := row.Scan(&userId); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while logging in", err)
+	}
+	return &userId, nil
+}
+
+// Links a provider account to a user, so they can log in with it from now on
+func (p *Postgres) LinkOIDCIdentity(ctx context.Context, userId int, issuer string, subject string, email string) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now().UTC()
+	_, err := p.db.ExecContext(ctx, "INSERT INTO evaluator_oidc_identity (evaluator_id, issuer, subject, email, linked_tstz, last_login_tstz) VALUES ($1, $2, $3, $4, $5, $5);", userId, issuer, subject, email, now)
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while logging in", err)
+	}
+	return nil
+}
+
+// Unlinks every provider account from a user. They can be linked again by email on their next login.
+func (p *Postgres) DeleteOIDCIdentitiesByUserId(ctx context.Context, userId int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM evaluator_oidc_identity WHERE evaluator_id = $1;", userId)
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while unlinking the user's single sign-on accounts", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// The page size used when a client does not ask for one
+const DefaultPageSize = 20
+
+// The largest page a client can request
+const MaxPageSize = 100
+
+// Identifies a page of results. After is the cursor of the last item on the
+// previous page, or empty for the first page.
+type PageArgs struct {
+	First int
+	After string
+}
+
+// A page of results from a paginated query. Cursors[i] is the cursor of Items[i].
+type Page[T any] struct {
+	Items       []T
+	Cursors     []string
+	HasNextPage bool
+	TotalCount  int
+}
+
+// Encodes the sort key values of an item into an opaque cursor
+func EncodeCursor(keys ...interface{}) string {
+	data, _ := json.Marshal(keys)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decodes a cursor created by EncodeCursor into pointers to its sort key values
+func DecodeCursor(cursor string, keys ...interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("invalid cursor: %w", err)
+	}
+	if len(raw) != len(keys) {
+		return fmt.Errorf("invalid cursor: expected %d keys, got %d", len(keys), len(raw))
+	}
+
+	for i := range raw {
+		if err := json.Unmarshal(raw[i], keys[i]); err != nil {
+			return fmt.Errorf("invalid cursor: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Trims the extra row fetched to detect a next page and fills in the page's cursors
+func newPage[T any](items []T, first int, totalCount int, cursor func(T) string) *Page[T] {
+	page := &Page[T]{Items: items, Cursors: []string{}, TotalCount: totalCount}
+	if len(items) > first {
+		page.Items = items[:first]
+		page.HasNextPage = true
+	}
+
+	for _, item := range page.Items {
+		page.Cursors = append(page.Cursors, cursor(item))
+	}
+
+	return page
+}
+
+// Builds a page from a fully sorted in memory list. isAfter reports whether an
+// item comes after the requested cursor; it is nil when no cursor was given.
+func PageOf[T any](items []T, first int, isAfter func(T) bool, cursor func(T) string) *Page[T] {
+	remaining := []T{}
+	for _, item := range items {
+		if isAfter == nil || isAfter(item) {
+			remaining = append(remaining, item)
+		}
+	}
+
+	if len(remaining) > first+1 {
+		remaining = remaining[:first+1]
+	}
+
+	return newPage(remaining, first, len(items), cursor)
+}
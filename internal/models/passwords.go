@@ -0,0 +1,91 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Returns when the user was last sent a password reset link, or nil if they never were
+func (p *Postgres) GetLastPasswordResetTime(ctx context.Context, userId int) (*time.Time, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT MAX(created_tstz) FROM password_reset WHERE evaluator_id = $1;", userId)
+
+	var created *time.Time
+	if err := row.Scan(&created); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while requesting a password reset", err)
+	}
+	return created, nil
+}
+
+// Stores a password reset token by its hash. Any earlier unused tokens for the user stop working,
+// so only the most recent link can be used.
+func (p *Postgres) CreatePasswordReset(ctx context.Context, userId int, tokenHash string, expires time.Time) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return p.withTx(ctx, "An unexpected error occurred while requesting a password reset", func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+		if _, err := tx.ExecContext(ctx, "UPDATE password_reset SET expires_tstz = $1 WHERE evaluator_id = $2 AND used_tstz IS NULL AND expires_tstz > $1;", now, userId); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, "INSERT INTO password_reset (evaluator_id, token_hash, created_tstz, expires_tstz) VALUES ($1, $2, $3, $4);", userId, tokenHash, now, expires.UTC())
+		return err
+	})
+}
+
+// Uses up the password reset token with the given hash and sets the user's password. Every session
+// the user has is ended. Returns the id of the user whose password was reset.
+func (p *Postgres) UsePasswordReset(ctx context.Context, tokenHash string, password string) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while resetting your password", err)
+	}
+
+	var userId int
+	err = p.withTx(ctx, "An unexpected error occurred while resetting your password", func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+		row := tx.QueryRowContext(ctx, "UPDATE password_reset r SET used_tstz = $1 FROM evaluator e WHERE r.evaluator_id = e.evaluator_id AND r.token_hash = $2 AND r.used_tstz IS NULL AND r.expires_tstz > $1 AND e.account_locked = false RETURNING r.evaluator_id;", now, tokenHash)
+		if err := row.Scan(&userId); err != nil {
+			if err == sql.ErrNoRows {
+				return errors.NewBadRequestError(ctx, "Oops! This password reset link is invalid or has expired.")
+			}
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE evaluator SET password = $1 WHERE evaluator_id = $2;", hash, userId); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, "DELETE FROM user_session WHERE user_id = $1;", userId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &userId, nil
+}
+
+// Deletes password reset tokens that have expired and returns how many were deleted. Errors are
+// returned as is since this runs as a background job rather than for a request.
+func (p *Postgres) DeleteExpiredPasswordResets(ctx context.Context) (int64, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := p.db.ExecContext(ctx, "DELETE FROM password_reset WHERE expires_tstz <= $1;", time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
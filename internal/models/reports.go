@@ -13,73 +13,85 @@ type Progress struct {
 	Count int
 }
 
-func GetUserProgressByContestId(ctx context.Context, userId int, contestId int) (*model.Progress, error) {
-	p := &model.Progress{}
+func (p *Postgres) GetUserProgressByContestId(ctx context.Context, userId int, contestId int) (*model.Progress, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
 
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM evaluation ev INNER JOIN entry en ON en.entry_id = ev.entry_id WHERE en.contest_id = $1 AND ev.evaluator_id = $2 AND en.disqualified = false AND en.flagged = false AND ev.evaluation_complete = true;", contestId, userId)
-	if err := row.Scan(&p.Count); err != nil {
+	progress := &model.Progress{}
+
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM evaluation ev INNER JOIN entry en ON en.entry_id = ev.entry_id WHERE en.contest_id = $1 AND ev.evaluator_id = $2 AND en.disqualified = false AND en.flagged = false AND ev.evaluation_complete = true;", contestId, userId)
+	if err := row.Scan(&progress.Count); err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the user's total evaluations for a contest", err)
 	}
 
-	row = db.DB.QueryRow("SELECT COUNT(*) FROM entry WHERE contest_id = $1 AND assigned_group_id = (SELECT group_id FROM evaluator WHERE evaluator_id = $2) AND flagged = false AND disqualified = false;", contestId, userId)
-	if err := row.Scan(&p.Total); err != nil {
+	row = p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entry WHERE contest_id = $1 AND assigned_group_id = (SELECT group_id FROM evaluator WHERE evaluator_id = $2) AND flagged = false AND disqualified = false;", contestId, userId)
+	if err := row.Scan(&progress.Total); err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the user's total evaluations for a contest", err)
 	}
 
-	return p, nil
+	return progress, nil
 }
 
-func GetGroupProgressByContestId(ctx context.Context, groupId int, contestId int) (*model.Progress, error) {
-	p := &model.Progress{}
+func (p *Postgres) GetGroupProgressByContestId(ctx context.Context, groupId int, contestId int) (*model.Progress, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	progress := &model.Progress{}
 
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM evaluation ev INNER JOIN entry en ON en.entry_id = ev.entry_id WHERE en.assigned_group_id = $1 AND en.contest_id = $2 AND en.disqualified = false AND en.flagged = false AND ev.evaluation_complete = true;", groupId, contestId)
-	if err := row.Scan(&p.Count); err != nil {
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM evaluation ev INNER JOIN entry en ON en.entry_id = ev.entry_id WHERE en.assigned_group_id = $1 AND en.contest_id = $2 AND en.disqualified = false AND en.flagged = false AND ev.evaluation_complete = true;", groupId, contestId)
+	if err := row.Scan(&progress.Count); err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the total number of evaluations for a group", err)
 	}
 
 	var entryCount int
-	row = db.DB.QueryRow("SELECT COUNT(*) FROM entry WHERE contest_id = $1 AND assigned_group_id = $2 AND disqualified = false AND flagged = false;", contestId, groupId)
+	row = p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entry WHERE contest_id = $1 AND assigned_group_id = $2 AND disqualified = false AND flagged = false;", contestId, groupId)
 	if err := row.Scan(&entryCount); err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the total number of entries in a group", err)
 	}
 
 	var evaluatorCount int
-	row = db.DB.QueryRow("SELECT COUNT(*) FROM evaluator e INNER JOIN evaluator_permissions p ON p.evaluator_id = e.evaluator_id WHERE e.group_id = $1 AND e.account_locked = false AND p.judge_entries = true;", groupId)
+	row = p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM evaluator e WHERE e.group_id = $1 AND e.account_locked = false AND 'JUDGE_ENTRIES' IN (SELECT evaluator_permission_keys(e.evaluator_id));", groupId)
 	if err := row.Scan(&evaluatorCount); err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the total number of evaluators in a group", err)
 	}
 
-	p.Total = entryCount * evaluatorCount
+	progress.Total = entryCount * evaluatorCount
 
-	return p, nil
+	return progress, nil
 }
 
-func GetEntryProgressByContestId(ctx context.Context, contestId int) (*model.Progress, error) {
-	p := &model.Progress{}
+func (p *Postgres) GetEntryProgressByContestId(ctx context.Context, contestId int) (*model.Progress, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	progress := &model.Progress{}
 
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM entry en WHERE en.contest_id = $1 AND en.flagged = false AND en.disqualified = false AND EXISTS (SELECT ev.evaluation_id FROM evaluation ev WHERE ev.entry_id = en.entry_id AND ev.evaluation_complete = true);", contestId)
-	if err := row.Scan(&p.Count); err != nil {
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entry en WHERE en.contest_id = $1 AND en.flagged = false AND en.disqualified = false AND EXISTS (SELECT ev.evaluation_id FROM evaluation ev WHERE ev.entry_id = en.entry_id AND ev.evaluation_complete = true);", contestId)
+	if err := row.Scan(&progress.Count); err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the number of entries that have been scored", err)
 	}
 
-	row = db.DB.QueryRow("SELECT COUNT(*) FROM entry WHERE contest_id = $1 AND flagged = false AND disqualified = false;", contestId)
-	if err := row.Scan(&p.Total); err != nil {
+	row = p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entry WHERE contest_id = $1 AND flagged = false AND disqualified = false;", contestId)
+	if err := row.Scan(&progress.Total); err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while determining the total number of entries for a contest", err)
 	}
 
-	return p, nil
+	return progress, nil
 }
 
-func GetEvaluationProgressByContestId(ctx context.Context, contestId int) (*model.Progress, error) {
-	p := &model.Progress{}
+func (p *Postgres) GetEvaluationProgressByContestId(ctx context.Context, contestId int) (*model.Progress, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	progress := &model.Progress{}
 
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM evaluation ev INNER JOIN entry en ON en.entry_id = ev.entry_id WHERE en.contest_id = $1 AND en.disqualified = false AND en.flagged = false AND ev.evaluation_complete = true;", contestId)
-	if err := row.Scan(&p.Count); err != nil {
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM evaluation ev INNER JOIN entry en ON en.entry_id = ev.entry_id WHERE en.contest_id = $1 AND en.disqualified = false AND en.flagged = false AND ev.evaluation_complete = true;", contestId)
+	if err := row.Scan(&progress.Count); err != nil {
 		return nil, errors.NewInternalError(ctx, "", err)
 	}
 
 	// Get the number of entries per group
-	rows, err := db.DB.Query("SELECT assigned_group_id, COUNT(*) FROM entry WHERE contest_id = $1 AND disqualified = false AND flagged = false AND assigned_group_id IS NOT NULL GROUP BY assigned_group_id ORDER BY assigned_group_id ASC;", contestId)
+	rows, err := p.db.QueryContext(ctx, "SELECT assigned_group_id, COUNT(*) FROM entry WHERE contest_id = $1 AND disqualified = false AND flagged = false AND assigned_group_id IS NOT NULL GROUP BY assigned_group_id ORDER BY assigned_group_id ASC;", contestId)
 	if err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the entry count per group", err)
 	}
@@ -93,8 +105,12 @@ func GetEvaluationProgressByContestId(ctx context.Context, contestId int) (*mode
 		groupEntries = append(groupEntries, count)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading the entry count per group", err)
+	}
+
 	// Get the number of evaluators per group
-	rows, err = db.DB.Query("SELECT e.group_id, COUNT(*) FROM evaluator e INNER JOIN evaluator_permissions p ON p.evaluator_id = e.evaluator_id WHERE e.account_locked = false AND p.judge_entries = true AND e.group_id IS NOT NULL GROUP BY group_id ORDER BY group_id ASC;")
+	rows, err = p.db.QueryContext(ctx, "SELECT e.group_id, COUNT(*) FROM evaluator e WHERE e.account_locked = false AND 'JUDGE_ENTRIES' IN (SELECT evaluator_permission_keys(e.evaluator_id)) AND e.group_id IS NOT NULL GROUP BY group_id ORDER BY group_id ASC;")
 	if err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the evaluator count per group", err)
 	}
@@ -108,6 +124,10 @@ func GetEvaluationProgressByContestId(ctx context.Context, contestId int) (*mode
 		groupEvaluators = append(groupEvaluators, count)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading the evaluator count per group", err)
+	}
+
 	// Calculate expected number of evaluations
 	var total int
 	for _, en := range groupEntries {
@@ -117,14 +137,17 @@ func GetEvaluationProgressByContestId(ctx context.Context, contestId int) (*mode
 			}
 		}
 	}
-	p.Total = total
+	progress.Total = total
 
-	return p, nil
+	return progress, nil
 }
 
-func GetEvaluatorProgressByContestId(ctx context.Context, contestId int) ([]*model.EvaluatorProgress, error) {
+func (p *Postgres) GetEvaluatorProgressByContestId(ctx context.Context, contestId int) ([]*model.EvaluatorProgress, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	// Get evaluator evaluation counts
-	rows, err := db.DB.Query("SELECT e.evaluator_id, e.group_id, (SELECT COUNT(*) FROM evaluation ev INNER JOIN entry en ON en.entry_id = ev.entry_id WHERE en.contest_id = $1 AND en.disqualified = false AND en.flagged = false AND ev.evaluation_complete = true AND ev.evaluator_id = e.evaluator_id) FROM evaluator e INNER JOIN evaluator_permissions p ON p.evaluator_id = e.evaluator_id INNER JOIN evaluator_group g ON g.group_id = e.group_id WHERE e.account_locked = false AND p.judge_entries = true AND e.group_id IS NOT NULL AND g.is_active = true ORDER BY e.nickname;", contestId)
+	rows, err := p.db.QueryContext(ctx, "SELECT e.evaluator_id, e.group_id, (SELECT COUNT(*) FROM evaluation ev INNER JOIN entry en ON en.entry_id = ev.entry_id WHERE en.contest_id = $1 AND en.disqualified = false AND en.flagged = false AND ev.evaluation_complete = true AND ev.evaluator_id = e.evaluator_id) FROM evaluator e INNER JOIN evaluator_group g ON g.group_id = e.group_id WHERE e.account_locked = false AND 'JUDGE_ENTRIES' IN (SELECT evaluator_permission_keys(e.evaluator_id)) AND e.group_id IS NOT NULL AND g.is_active = true ORDER BY e.nickname;", contestId)
 	if err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the evaluation counts per evaluator", err)
 	}
@@ -146,8 +169,12 @@ func GetEvaluatorProgressByContestId(ctx context.Context, contestId int) ([]*mod
 		evaluatorCounts = append(evaluatorCounts, count)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading the evaluation counts per evaluator", err)
+	}
+
 	// Get the number of entries per group
-	rows, err = db.DB.Query("SELECT assigned_group_id, COUNT(*) FROM entry WHERE contest_id = $1 AND disqualified = false AND flagged = false AND assigned_group_id IS NOT NULL GROUP BY assigned_group_id ORDER BY assigned_group_id ASC;", contestId)
+	rows, err = p.db.QueryContext(ctx, "SELECT assigned_group_id, COUNT(*) FROM entry WHERE contest_id = $1 AND disqualified = false AND flagged = false AND assigned_group_id IS NOT NULL GROUP BY assigned_group_id ORDER BY assigned_group_id ASC;", contestId)
 	if err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the entry count per group", err)
 	}
@@ -167,6 +194,10 @@ func GetEvaluatorProgressByContestId(ctx context.Context, contestId int) ([]*mod
 		groupEntries = append(groupEntries, count)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading the entry count per group", err)
+	}
+
 	// Format the data
 	progress := []*model.EvaluatorProgress{}
 	for _, v := range evaluatorCounts {
@@ -187,10 +218,13 @@ func GetEvaluatorProgressByContestId(ctx context.Context, contestId int) ([]*mod
 	return progress, nil
 }
 
-func GetFlaggedEntryCountByContestId(ctx context.Context, contestId int) (int, error) {
+func (p *Postgres) GetFlaggedEntryCountByContestId(ctx context.Context, contestId int) (int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	var count int
 
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM entry WHERE contest_id = $1 AND flagged = true AND disqualified = false;", contestId)
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entry WHERE contest_id = $1 AND flagged = true AND disqualified = false;", contestId)
 	if err := row.Scan(&count); err != nil {
 		return 0, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the number of flagged entries", err)
 	}
@@ -198,10 +232,13 @@ func GetFlaggedEntryCountByContestId(ctx context.Context, contestId int) (int, e
 	return count, nil
 }
 
-func GetDisqualifiedEntryCountByContestId(ctx context.Context, contestId int) (int, error) {
+func (p *Postgres) GetDisqualifiedEntryCountByContestId(ctx context.Context, contestId int) (int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	var count int
 
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM entry WHERE contest_id = $1 AND disqualified = true;", contestId)
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entry WHERE contest_id = $1 AND disqualified = true;", contestId)
 	if err := row.Scan(&count); err != nil {
 		return 0, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the number of disqualified entries", err)
 	}
@@ -209,10 +246,13 @@ func GetDisqualifiedEntryCountByContestId(ctx context.Context, contestId int) (i
 	return count, nil
 }
 
-func GetTotalEntryCountByContestId(ctx context.Context, contestId int) (int, error) {
+func (p *Postgres) GetTotalEntryCountByContestId(ctx context.Context, contestId int) (int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	var count int
 
-	row := db.DB.QueryRow("SELECT COUNT(*) FROM entry WHERE contest_id = $1 AND flagged = false AND disqualified = false;", contestId)
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entry WHERE contest_id = $1 AND flagged = false AND disqualified = false;", contestId)
 	if err := row.Scan(&count); err != nil {
 		return 0, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the number of total entries for a contest", err)
 	}
@@ -0,0 +1,363 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+type APITokenRepository interface {
+	GetAPITokensByUserId(ctx context.Context, userId int) ([]*model.APIToken, error)
+	GetAPITokenById(ctx context.Context, userId int, id int) (*model.APIToken, error)
+	CreateAPIToken(ctx context.Context, userId int, input *model.CreateAPITokenInput, tokenHash string, prefix string) (*int, error)
+	DeleteAPITokenById(ctx context.Context, userId int, id int) error
+}
+
+type AnnouncementRepository interface {
+	GetAnnouncementById(ctx context.Context, id int) (*model.Announcement, error)
+	GetAllAnnouncements(ctx context.Context) ([]*model.Announcement, error)
+	GetPublicAnnouncements(ctx context.Context) ([]*model.Announcement, error)
+	CreateAnnouncement(ctx context.Context, input *model.AnnouncementInput, authorId int) (*int, error)
+	EditAnnouncementById(ctx context.Context, id int, input *model.AnnouncementInput) error
+	DeleteAnnouncementById(ctx context.Context, id int) error
+}
+
+type AuditLogRepository interface {
+	CreateAuditLogEntry(ctx context.Context, record *AuditRecord) error
+	GetAuditLogPage(ctx context.Context, filter *model.AuditLogFilter, args PageArgs) (*Page[*model.AuditLogEntry], error)
+}
+
+type ContestRepository interface {
+	GetAllContests(ctx context.Context) ([]*model.Contest, error)
+	GetContestById(ctx context.Context, id int) (*model.Contest, error)
+	GetContestsByIds(ctx context.Context, ids []int) (map[int]*model.Contest, error)
+	GetCurrentContest(ctx context.Context) (*model.Contest, error)
+	GetContestsEvaluatedByUser(ctx context.Context, userId int) ([]*model.Contest, error)
+	CreateContest(ctx context.Context, input *model.CreateContestInput) (*int, error)
+	EditContestById(ctx context.Context, id int, input *model.EditContestInput) error
+	TransitionContest(ctx context.Context, id int, from model.ContestState, to model.ContestState) error
+	DeleteContestById(ctx context.Context, id int) error
+}
+
+type ContestantRepository interface {
+	GetContestantByKaid(ctx context.Context, kaid string) (*model.Contestant, error)
+	GetContestantsByKaids(ctx context.Context, kaids []string) (map[string]*model.Contestant, error)
+	GetContestantsBySearchQuery(ctx context.Context, searchQuery string) ([]*model.Contestant, error)
+	GetContestantsPageBySearchQuery(ctx context.Context, searchQuery string, args PageArgs) (*Page[*model.Contestant], error)
+	GetContestantEntryCount(ctx context.Context, kaid string) (int, error)
+	GetContestantContestCount(ctx context.Context, kaid string) (int, error)
+}
+
+type EntryRepository interface {
+	GetEntriesByContestId(ctx context.Context, contestId int) ([]*model.Entry, error)
+	GetEntriesPageByContestId(ctx context.Context, contestId int, args PageArgs) (*Page[*model.Entry], error)
+	GetEntriesByAverageScore(ctx context.Context, contestId int) ([]*model.Entry, error)
+	GetEntryById(ctx context.Context, id int) (*model.Entry, error)
+	GetEntriesByIds(ctx context.Context, ids []int) (map[int]*model.Entry, error)
+	GetEntriesByContestantKaid(ctx context.Context, contestantKaid string) ([]*model.Entry, error)
+	GetWinningEntriesByContestId(ctx context.Context, contestId int) ([]*model.Entry, error)
+	GetEntryAverageScore(ctx context.Context, id int) (*float64, error)
+	GetEntryAverageScores(ctx context.Context, ids []int) (map[int]*float64, error)
+	GetFlaggedEntries(ctx context.Context) ([]*model.Entry, error)
+	GetFlaggedEntriesPage(ctx context.Context, args PageArgs) (*Page[*model.Entry], error)
+	GetEntryEvaluationCount(ctx context.Context, id int) (int, error)
+	GetEntryEvaluationCounts(ctx context.Context, ids []int) (map[int]int, error)
+	GetEntryVoteCount(ctx context.Context, id int) (int, error)
+	GetEntryVoteCounts(ctx context.Context, ids []int) (map[int]int, error)
+	GetEntryVotes(ctx context.Context, entryId int) ([]*model.EntryVote, error)
+	GetEntriesPerLevel(ctx context.Context, contestId int) ([]*model.EntriesPerLevel, error)
+	IsEntryVotedByUser(ctx context.Context, entryId int) (*bool, error)
+	GetEntriesVotedByUser(ctx context.Context, userId int, ids []int) (map[int]bool, error)
+	GetNextEntryToJudge(ctx context.Context) (*int, error)
+	GetNextEntryToReviewSkillLevel(ctx context.Context) (*int, error)
+	AddWinnerByEntryId(ctx context.Context, id int) error
+	RemoveWinnerByEntryId(ctx context.Context, id int) error
+	FlagEntryById(ctx context.Context, id int, reason string) error
+	ApproveEntryById(ctx context.Context, id int) error
+	DisqualifyEntryById(ctx context.Context, id int) error
+	DeleteEntryById(ctx context.Context, id int) error
+	EditEntryById(ctx context.Context, id int, input *model.EditEntryInput) error
+	SetEntryLevelById(ctx context.Context, id int, skillLevel string) error
+	GetEntryVoteById(ctx context.Context, id int) (*model.EntryVote, error)
+	CreateEntryVote(ctx context.Context, entryId int, userId int, reason string) (*int, error)
+	DeleteEntryVoteById(ctx context.Context, id int) error
+	GetContestIdByVoteId(ctx context.Context, voteId int) (*int, error)
+	CreateEntry(ctx context.Context, contestId int, input *EntryInput) (*int, error)
+	AssignAllEntriesToGroups(ctx context.Context, contestId int) error
+	AssignNewEntriesToGroups(ctx context.Context, contestId int) error
+	TransferEntryGroups(ctx context.Context, contestId int, prevGroup int, newGroup int) error
+}
+
+type ErrorRepository interface {
+	GetErrorsByPage(ctx context.Context, page int) ([]*model.Error, error)
+	GetErrorsPage(ctx context.Context, args PageArgs) (*Page[*model.Error], error)
+	GetErrorById(ctx context.Context, id int) (*model.Error, error)
+	DeleteErrorById(ctx context.Context, id int) error
+}
+
+type EvaluationRepository interface {
+	GetEvaluationById(ctx context.Context, id int) (*model.Evaluation, error)
+	GetEvaluationsForUserAndContest(ctx context.Context, userId int, contestId int) ([]*model.Evaluation, error)
+	GetUserTotalEvaluations(ctx context.Context, userId int) (*int, error)
+	GetUserTotalContestsJudged(ctx context.Context, userId int) (*int, error)
+	EditEvaluationById(ctx context.Context, id int, input *model.EditEvaluationInput) error
+	DeleteEvaluationById(ctx context.Context, id int) error
+}
+
+type InvitationRepository interface {
+	GetInvitations(ctx context.Context, includeInactive bool) ([]*model.Invitation, error)
+	GetInvitationById(ctx context.Context, id int) (*model.Invitation, error)
+	CreateInvitation(ctx context.Context, input *model.InviteUserInput, invitedBy int, tokenHash string, expires time.Time) (*int, error)
+	RevokeInvitation(ctx context.Context, id int) error
+	AcceptInvitation(ctx context.Context, tokenHash string, username string, password string) (*int, error)
+}
+
+type JudgingRepository interface {
+	GetAllCriteria(ctx context.Context) ([]*model.JudgingCriteria, error)
+	GetActiveCriteria(ctx context.Context) ([]*model.JudgingCriteria, error)
+	GetJudgingCriteriaById(ctx context.Context, id int) (*model.JudgingCriteria, error)
+	CreateJudgingCriteria(ctx context.Context, input *model.JudgingCriteriaInput) (*int, error)
+	EditJudgingCriteriaById(ctx context.Context, id int, input *model.JudgingCriteriaInput) error
+	DeleteJudgingCriteriaById(ctx context.Context, id int) error
+	GetAllJudgingGroups(ctx context.Context) ([]*model.JudgingGroup, error)
+	GetActiveJudgingGroups(ctx context.Context) ([]*model.JudgingGroup, error)
+	GetJudgingGroupById(ctx context.Context, id int) (*model.JudgingGroup, error)
+	GetJudgingGroupsByIds(ctx context.Context, ids []int) (map[int]*model.JudgingGroup, error)
+	CreateJudgingGroup(ctx context.Context, input *model.CreateJudgingGroupInput) (*int, error)
+	EditJudgingGroupById(ctx context.Context, id int, input *model.EditJudgingGroupInput) error
+	DeleteJudgingGroupById(ctx context.Context, id int) error
+	ScoreEntry(ctx context.Context, userId int, entryId int, input *model.ScoreEntryInput) (*int, error)
+	AutoUpdateEntryLevel(ctx context.Context, entryId int) error
+}
+
+type KBRepository interface {
+	GetAllKBSections(ctx context.Context) ([]*model.KBSection, error)
+	GetAllKBArticles(ctx context.Context) ([]*model.KBArticle, error)
+	GetAllKBArticlesWithDrafts(ctx context.Context) ([]*model.KBArticle, error)
+	GetKBArticlesPage(ctx context.Context, args PageArgs) (*Page[*model.KBArticle], error)
+	GetKBArticlesWithDraftsPage(ctx context.Context, args PageArgs) (*Page[*model.KBArticle], error)
+	GetPublicKBSections(ctx context.Context) ([]*model.KBSection, error)
+	GetEvaluatorKBSections(ctx context.Context) ([]*model.KBSection, error)
+	GetKBSectionById(ctx context.Context, id int) (*model.KBSection, error)
+	GetKBArticleById(ctx context.Context, id int) (*model.KBArticle, error)
+	CheckKBArticleHasDraft(ctx context.Context, id int) (bool, error)
+	GetPublicKBArticlesBySection(ctx context.Context, sectionId int) ([]*model.KBArticle, error)
+	GetEvaluatorKBArticlesBySection(ctx context.Context, sectionId int) ([]*model.KBArticle, error)
+	GetAdminKBArticlesBySection(ctx context.Context, sectionId int) ([]*model.KBArticle, error)
+	GetKBArticleDraftByArticleId(ctx context.Context, articleId int) (*model.KBArticleDraft, error)
+	GetKBArticleRecentDraftsByArticleId(ctx context.Context, articleId int) ([]*model.KBArticleDraft, error)
+	CreateKBSection(ctx context.Context, input *model.KBSectionInput) (*int, error)
+	EditKBSectionById(ctx context.Context, id int, input *model.KBSectionInput) error
+	DeleteKBSectionById(ctx context.Context, id int) error
+	CreateKBArticle(ctx context.Context, input *model.KBArticleInput, authorId int) (*int, error)
+	EditKBArticle(ctx context.Context, articleId int, input *model.KBArticleInput, authorId int) error
+	EditKBArticleProperties(ctx context.Context, id int, visibility string, sectionId int) error
+	DeleteKBArticle(ctx context.Context, articleId int) error
+	DeleteKBArticleDraft(ctx context.Context, draftId int) error
+	PublishKBArticle(ctx context.Context, id int) error
+	UnpublishKBArticle(ctx context.Context, id int) error
+}
+
+type LifecycleRepository interface {
+	LockUsersWithEndedTerms(ctx context.Context, today time.Time) ([]int, error)
+	ReactivateUsersWithRenewedTerms(ctx context.Context, today time.Time) ([]int, error)
+	GetUsersToWarnOfTermEnd(ctx context.Context, today time.Time, until time.Time) ([]*model.User, error)
+	SetTermEndWarned(ctx context.Context, userId int, termEnd time.Time) error
+}
+
+type LoginThrottleRepository interface {
+	GetLoginLockedUntil(ctx context.Context, username string, ipAddress string) (*time.Time, error)
+	RecordFailedLogin(ctx context.Context, kind model.LoginThrottleKind, value string, window time.Duration) (int, error)
+	LockLogin(ctx context.Context, kind model.LoginThrottleKind, value string, until time.Time) error
+	ClearLoginThrottle(ctx context.Context, kind model.LoginThrottleKind, value string) error
+	GetActiveLoginLockouts(ctx context.Context) ([]*model.LoginLockout, error)
+	GetActiveLoginLockoutById(ctx context.Context, id int) (*model.LoginLockout, error)
+	DeleteLoginLockoutById(ctx context.Context, id int) error
+	DeleteExpiredLoginThrottles(ctx context.Context, window time.Duration) (int64, error)
+}
+
+type OIDCRepository interface {
+	CreateOIDCLoginState(ctx context.Context, stateHash string, codeVerifier string, nonce string, expires time.Time) error
+	UseOIDCLoginState(ctx context.Context, stateHash string) (*OIDCLoginState, error)
+	DeleteExpiredOIDCLoginStates(ctx context.Context) (int64, error)
+	RecordOIDCLogin(ctx context.Context, issuer string, subject string) (*int, error)
+	LinkOIDCIdentity(ctx context.Context, userId int, issuer string, subject string, email string) error
+	DeleteOIDCIdentitiesByUserId(ctx context.Context, userId int) error
+}
+
+type PasswordResetRepository interface {
+	GetLastPasswordResetTime(ctx context.Context, userId int) (*time.Time, error)
+	CreatePasswordReset(ctx context.Context, userId int, tokenHash string, expires time.Time) error
+	UsePasswordReset(ctx context.Context, tokenHash string, password string) (*int, error)
+	DeleteExpiredPasswordResets(ctx context.Context) (int64, error)
+}
+
+type ReportRepository interface {
+	GetUserProgressByContestId(ctx context.Context, userId int, contestId int) (*model.Progress, error)
+	GetGroupProgressByContestId(ctx context.Context, groupId int, contestId int) (*model.Progress, error)
+	GetEntryProgressByContestId(ctx context.Context, contestId int) (*model.Progress, error)
+	GetEvaluationProgressByContestId(ctx context.Context, contestId int) (*model.Progress, error)
+	GetEvaluatorProgressByContestId(ctx context.Context, contestId int) ([]*model.EvaluatorProgress, error)
+	GetFlaggedEntryCountByContestId(ctx context.Context, contestId int) (int, error)
+	GetDisqualifiedEntryCountByContestId(ctx context.Context, contestId int) (int, error)
+	GetTotalEntryCountByContestId(ctx context.Context, contestId int) (int, error)
+}
+
+type RoleRepository interface {
+	GetAllRoles(ctx context.Context) ([]*model.Role, error)
+	GetRoleById(ctx context.Context, id int) (*model.Role, error)
+	GetRolesByUserId(ctx context.Context, userId int) ([]*model.Role, error)
+	GetAllPermissionDefinitions(ctx context.Context) ([]*model.PermissionDefinition, error)
+	CreateRole(ctx context.Context, input *model.RoleInput) (*int, error)
+	EditRoleById(ctx context.Context, id int, input *model.RoleInput) error
+	DeleteRoleById(ctx context.Context, id int) error
+	SetUserRolesById(ctx context.Context, userId int, roleIds []int) error
+}
+
+type SessionRepository interface {
+	GetActiveSessionsByUserId(ctx context.Context, userId int) ([]*model.Session, error)
+	DeleteSessionById(ctx context.Context, userId int, id int) error
+	DeleteSessionsByUserId(ctx context.Context, userId int) error
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+	EndImpersonation(ctx context.Context, sessionId int) error
+	GetImpersonationsPage(ctx context.Context, activeOnly bool, args PageArgs) (*Page[*model.Impersonation], error)
+}
+
+type TaskRepository interface {
+	GetTaskById(ctx context.Context, id int) (*model.Task, error)
+	GetIncompleteTasks(ctx context.Context) ([]*model.Task, error)
+	GetCompletedTasks(ctx context.Context) ([]*model.Task, error)
+	GetAvailableTasks(ctx context.Context) ([]*model.Task, error)
+	GetTasksForUser(ctx context.Context, userId int) ([]*model.Task, error)
+	CreateTask(ctx context.Context, input *model.CreateTaskInput) (*int, error)
+	EditTaskById(ctx context.Context, id int, input *model.EditTaskInput) error
+	DeleteTaskById(ctx context.Context, id int) error
+}
+
+type TwoFactorRepository interface {
+	GetTwoFactorByUserId(ctx context.Context, userId int) (*TwoFactor, error)
+	SetPendingTOTPSecret(ctx context.Context, userId int, secret string) error
+	EnableTwoFactor(ctx context.Context, userId int, step int64, recoveryCodeHashes []string) error
+	UseTOTPStep(ctx context.Context, userId int, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userId int, codeHash string) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userId int, codeHashes []string) error
+	DisableTwoFactor(ctx context.Context, userId int) error
+	CreateLoginChallenge(ctx context.Context, userId int, tokenHash string, expires time.Time) error
+	GetLoginChallengeUserId(ctx context.Context, tokenHash string) (*int, error)
+	FailLoginChallenge(ctx context.Context, tokenHash string, maxFailures int) error
+	DeleteLoginChallenge(ctx context.Context, tokenHash string) error
+	DeleteExpiredLoginChallenges(ctx context.Context) (int64, error)
+	GetTwoFactorRequiredPermissions(ctx context.Context) ([]string, error)
+	SetTwoFactorRequiredPermissions(ctx context.Context, keys []string) error
+}
+
+type UserRepository interface {
+	GetAllActiveUsers(ctx context.Context) ([]*model.User, error)
+	GetActiveUsersPage(ctx context.Context, args PageArgs) (*Page[*model.User], error)
+	GetAllInactiveUsers(ctx context.Context) ([]*model.User, error)
+	GetUserById(ctx context.Context, id int) (*model.User, error)
+	GetUsersByIds(ctx context.Context, ids []int) (map[int]*model.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
+	GetActiveUsersByEmail(ctx context.Context, email string) ([]*model.User, error)
+	GetUserPermissionsById(ctx context.Context, id int) (*model.Permissions, error)
+	GetUserPermissionKeysById(ctx context.Context, id int) ([]string, error)
+	GetUserGroupById(ctx context.Context, id int) (*int, error)
+	GetUserPasswordHashByUsername(ctx context.Context, username string) (*string, error)
+	SetUserLastLoginById(ctx context.Context, id int) error
+	ChangeUserPasswordById(ctx context.Context, id int, password string) error
+	CreateUser(ctx context.Context, input *model.CreateUserInput) (*int, error)
+	EditUserById(ctx context.Context, id int, input *model.EditUserProfileInput) error
+	EditUserPermissionsById(ctx context.Context, id int, keys []string) error
+	AssignUserToJudgingGroup(ctx context.Context, userId int, groupId *int) error
+}
+
+// Reports on the database itself rather than the data in it. Errors are returned
+// as is instead of as user facing errors since they are used by health checks.
+type SystemRepository interface {
+	Ping(ctx context.Context) error
+	GetDatabaseStats(ctx context.Context) sql.DBStats
+	GetSchemaVersion(ctx context.Context) (int, error)
+}
+
+// Groups the repositories used by the resolvers
+type Repositories struct {
+	APITokens      APITokenRepository
+	Announcements  AnnouncementRepository
+	AuditLog       AuditLogRepository
+	Contests       ContestRepository
+	Contestants    ContestantRepository
+	Entries        EntryRepository
+	Errors         ErrorRepository
+	Evaluations    EvaluationRepository
+	Invitations    InvitationRepository
+	Judging        JudgingRepository
+	KB             KBRepository
+	Lifecycle      LifecycleRepository
+	LoginThrottles LoginThrottleRepository
+	OIDC           OIDCRepository
+	PasswordResets PasswordResetRepository
+	Reports        ReportRepository
+	Roles          RoleRepository
+	Sessions       SessionRepository
+	System         SystemRepository
+	Tasks          TaskRepository
+	TwoFactor      TwoFactorRepository
+	Users          UserRepository
+}
+
+// Implements every repository on top of a Postgres database
+type Postgres struct {
+	db *sql.DB
+}
+
+func NewPostgres(db *sql.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+// Runs fn inside a transaction. Errors that fn did not already turn into a
+// user facing error, such as a failed commit, are logged as internal errors.
+func (p *Postgres) withTx(ctx context.Context, publicMessage string, fn func(tx *sql.Tx) error) error {
+	err := db.WithTx(ctx, p.db, fn)
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(*gqlerror.Error); ok {
+		return err
+	}
+	return errors.NewInternalError(ctx, publicMessage, err)
+}
+
+// Returns a set of repositories that are all backed by the given database
+func NewPostgresRepositories(db *sql.DB) *Repositories {
+	p := NewPostgres(db)
+	return &Repositories{
+		APITokens:      p,
+		Announcements:  p,
+		AuditLog:       p,
+		Contests:       p,
+		Contestants:    p,
+		Entries:        p,
+		Errors:         p,
+		Evaluations:    p,
+		Invitations:    p,
+		Judging:        p,
+		KB:             p,
+		Lifecycle:      p,
+		LoginThrottles: p,
+		OIDC:           p,
+		PasswordResets: p,
+		Reports:        p,
+		Roles:          p,
+		Sessions:       p,
+		System:         p,
+		Tasks:          p,
+		TwoFactor:      p,
+		Users:          p,
+	}
+}
@@ -0,0 +1,275 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/lib/pq"
+)
+
+// Postgres reports a duplicate value in a unique column with this code
+const uniqueViolationCode = "23505"
+
+func NewRoleModel() model.Role {
+	role := model.Role{}
+	role.Permissions = []string{}
+	return role
+}
+
+// Returns the deprecated permission set with a field set for each permission key. The fields of
+// the Permissions type are named by the lower case keys in their json tags, and keys without a
+// field are left out.
+func PermissionsFromKeys(keys []string) *model.Permissions {
+	granted := map[string]bool{}
+	for _, key := range keys {
+		granted[strings.ToLower(key)] = true
+	}
+
+	permissions := NewPermissionsModel()
+	fields := reflect.ValueOf(&permissions).Elem()
+	for i := 0; i < fields.NumField(); i++ {
+		fields.Field(i).SetBool(granted[fields.Type().Field(i).Tag.Get("json")])
+	}
+	return &permissions
+}
+
+// Returns the permission keys without duplicates
+func uniqueKeys(keys []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, key)
+		}
+	}
+	return unique
+}
+
+// Returns a bad request error naming the first key that does not have a row in the permission table
+func checkPermissionKeys(ctx context.Context, tx *sql.Tx, keys []string) error {
+	row := tx.QueryRowContext(ctx, "SELECT k FROM unnest($1::text[]) k WHERE NOT EXISTS (SELECT 1 FROM permission p WHERE p.permission_key = k) LIMIT 1;", pq.Array(keys))
+
+	var unknown string
+	if err := row.Scan(&unknown); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return errors.NewInternalError(ctx, "An unexpected error occurred while checking permissions", err)
+	}
+
+	return errors.NewBadRequestError(ctx, "Oops! "+unknown+" is not a permission.")
+}
+
+func scanRoleRows(ctx context.Context, rows *sql.Rows) ([]*model.Role, error) {
+	roles := []*model.Role{}
+	for rows.Next() {
+		role := NewRoleModel()
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, pq.Array(&role.Permissions)); err != nil {
+			return []*model.Role{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading roles", err)
+		}
+		roles = append(roles, &role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []*model.Role{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading roles", err)
+	}
+
+	return roles, nil
+}
+
+func (p *Postgres) GetAllRoles(ctx context.Context) ([]*model.Role, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, "SELECT r.role_id, r.role_name, r.role_description, ARRAY(SELECT rp.permission_key FROM role_permission rp WHERE rp.role_id = r.role_id ORDER BY rp.permission_key) FROM role r ORDER BY r.role_name ASC;")
+	if err != nil {
+		return []*model.Role{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of roles", err)
+	}
+	defer rows.Close()
+
+	return scanRoleRows(ctx, rows)
+}
+
+func (p *Postgres) GetRoleById(ctx context.Context, id int) (*model.Role, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT r.role_id, r.role_name, r.role_description, ARRAY(SELECT rp.permission_key FROM role_permission rp WHERE rp.role_id = r.role_id ORDER BY rp.permission_key) FROM role r WHERE r.role_id = $1;", id)
+
+	role := NewRoleModel()
+	if err := row.Scan(&role.ID, &role.Name, &role.Description, pq.Array(&role.Permissions)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError(ctx, "Oops! The requested role does not exist.")
+		}
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving a role", err)
+	}
+
+	return &role, nil
+}
+
+// Returns the roles held by a user, ordered by name
+func (p *Postgres) GetRolesByUserId(ctx context.Context, userId int) ([]*model.Role, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, "SELECT r.role_id, r.role_name, r.role_description, ARRAY(SELECT rp.permission_key FROM role_permission rp WHERE rp.role_id = r.role_id ORDER BY rp.permission_key) FROM role r INNER JOIN evaluator_role er ON er.role_id = r.role_id WHERE er.evaluator_id = $1 ORDER BY r.role_name ASC;", userId)
+	if err != nil {
+		return []*model.Role{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving a user's roles", err)
+	}
+	defer rows.Close()
+
+	return scanRoleRows(ctx, rows)
+}
+
+func (p *Postgres) GetAllPermissionDefinitions(ctx context.Context) ([]*model.PermissionDefinition, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	definitions := []*model.PermissionDefinition{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT permission_key, permission_description FROM permission ORDER BY permission_key ASC;")
+	if err != nil {
+		return definitions, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of permissions", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		definition := model.PermissionDefinition{}
+		if err := rows.Scan(&definition.Key, &definition.Description); err != nil {
+			return []*model.PermissionDefinition{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of permissions", err)
+		}
+		definitions = append(definitions, &definition)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []*model.PermissionDefinition{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of permissions", err)
+	}
+
+	return definitions, nil
+}
+
+func (p *Postgres) CreateRole(ctx context.Context, input *model.RoleInput) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var id int
+
+	err := p.withTx(ctx, "An unexpected error occurred while creating a role", func(tx *sql.Tx) error {
+		keys := uniqueKeys(input.Permissions)
+		if err := checkPermissionKeys(ctx, tx, keys); err != nil {
+			return err
+		}
+
+		row := tx.QueryRowContext(ctx, "INSERT INTO role (role_name, role_description) VALUES ($1, $2) RETURNING role_id;", input.Name, input.Description)
+		if err := row.Scan(&id); err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolationCode {
+				return errors.NewBadRequestError(ctx, "Oops! A role with this name already exists.")
+			}
+			return errors.NewInternalError(ctx, "An unexpected error occurred while creating a role", err)
+		}
+
+		_, err := tx.ExecContext(ctx, "INSERT INTO role_permission (role_id, permission_key) SELECT $1, unnest($2::text[]);", id, pq.Array(keys))
+		if err != nil {
+			return errors.NewInternalError(ctx, "An unexpected error occurred while creating a role", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &id, nil
+}
+
+func (p *Postgres) EditRoleById(ctx context.Context, id int, input *model.RoleInput) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return p.withTx(ctx, "An unexpected error occurred while updating a role", func(tx *sql.Tx) error {
+		keys := uniqueKeys(input.Permissions)
+		if err := checkPermissionKeys(ctx, tx, keys); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, "UPDATE role SET role_name = $1, role_description = $2 WHERE role_id = $3;", input.Name, input.Description, id)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolationCode {
+				return errors.NewBadRequestError(ctx, "Oops! A role with this name already exists.")
+			}
+			return errors.NewInternalError(ctx, "An unexpected error occurred while updating a role", err)
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM role_permission WHERE role_id = $1;", id)
+		if err != nil {
+			return errors.NewInternalError(ctx, "An unexpected error occurred while updating a role", err)
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO role_permission (role_id, permission_key) SELECT $1, unnest($2::text[]);", id, pq.Array(keys))
+		if err != nil {
+			return errors.NewInternalError(ctx, "An unexpected error occurred while updating a role", err)
+		}
+
+		return nil
+	})
+}
+
+func (p *Postgres) DeleteRoleById(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM role WHERE role_id = $1;", id)
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while deleting a role", err)
+	}
+	return nil
+}
+
+// Replaces the roles held by a user
+func (p *Postgres) SetUserRolesById(ctx context.Context, userId int, roleIds []int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return p.withTx(ctx, "An unexpected error occurred while updating a user's roles", func(tx *sql.Tx) error {
+		row := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM role WHERE role_id = ANY($1);", pq.Array(roleIds))
+
+		var found int
+		if err := row.Scan(&found); err != nil {
+			return errors.NewInternalError(ctx, "An unexpected error occurred while updating a user's roles", err)
+		}
+		if found != len(uniqueIds(roleIds)) {
+			return errors.NewNotFoundError(ctx, "Oops! One of the requested roles does not exist.")
+		}
+
+		_, err := tx.ExecContext(ctx, "DELETE FROM evaluator_role WHERE evaluator_id = $1;", userId)
+		if err != nil {
+			return errors.NewInternalError(ctx, "An unexpected error occurred while updating a user's roles", err)
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO evaluator_role (evaluator_id, role_id) SELECT DISTINCT $1::int, unnest($2::int[]);", userId, pq.Array(roleIds))
+		if err != nil {
+			return errors.NewInternalError(ctx, "An unexpected error occurred while updating a user's roles", err)
+		}
+
+		return nil
+	})
+}
+
+// Returns the ids without duplicates
+func uniqueIds(ids []int) []int {
+	seen := map[int]bool{}
+	unique := []int{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
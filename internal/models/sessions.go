@@ -0,0 +1,156 @@
+package models
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/util"
+)
+
+// Returns the sessions a user started by logging in that have not expired, most recently used first.
+// Sessions an admin started by impersonating the user are not included.
+func (p *Postgres) GetActiveSessionsByUserId(ctx context.Context, userId int) ([]*model.Session, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	sessions := []*model.Session{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT session_id, user_agent, ip_address, created_tstz, last_seen_tstz, expires FROM user_session WHERE user_id = $1 AND impersonated_by_user IS NULL AND expires > $2 ORDER BY last_seen_tstz DESC, session_id DESC;", userId, time.Now().UTC())
+	if err != nil {
+		return sessions, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving sessions", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		session := model.Session{}
+		if err := rows.Scan(&session.ID, &session.UserAgent, &session.IPAddress, &session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt); err != nil {
+			return []*model.Session{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading sessions", err)
+		}
+		session.Device = util.DeviceFromUserAgent(stringOrEmpty(session.UserAgent))
+		sessions = append(sessions, &session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []*model.Session{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading sessions", err)
+	}
+
+	return sessions, nil
+}
+
+// Deletes one of a user's sessions, returning a not found error if the user has no session with the id
+func (p *Postgres) DeleteSessionById(ctx context.Context, userId int, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := p.db.ExecContext(ctx, "DELETE FROM user_session WHERE session_id = $1 AND user_id = $2;", id, userId)
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while ending a session", err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.NewNotFoundError(ctx, "Oops! The requested session does not exist.")
+	}
+	return nil
+}
+
+// Deletes every session of a user, including sessions impersonating them
+func (p *Postgres) DeleteSessionsByUserId(ctx context.Context, userId int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM user_session WHERE user_id = $1;", userId)
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while ending a user's sessions", err)
+	}
+	return nil
+}
+
+// Deletes every expired session and returns how many were deleted. Errors are returned as is since
+// this runs as a background job rather than for a request.
+func (p *Postgres) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := p.db.ExecContext(ctx, "DELETE FROM user_session WHERE expires <= $1;", time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func NewImpersonationModel() model.Impersonation {
+	return model.Impersonation{Impersonator: &model.User{}, User: &model.User{}}
+}
+
+// Records that the impersonator returned to their own account from an impersonation session
+func (p *Postgres) EndImpersonation(ctx context.Context, sessionId int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE impersonation SET ended_tstz = $1 WHERE session_id = $2 AND ended_tstz IS NULL;", time.Now().UTC(), sessionId)
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while ending the impersonation", err)
+	}
+	return nil
+}
+
+// Returns a page of impersonations, most recently started first. An impersonation is active until
+// its session ends or expires.
+func (p *Postgres) GetImpersonationsPage(ctx context.Context, activeOnly bool, args PageArgs) (*Page[*model.Impersonation], error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	// Impersonation ids are never negative, so this sorts after every impersonation when there is no cursor
+	afterId := math.MaxInt32
+	if args.After != "" {
+		if err := DecodeCursor(args.After, &afterId); err != nil {
+			return nil, errors.NewBadRequestError(ctx, "Oops! The cursor provided is not valid.")
+		}
+	}
+
+	const isActive = "(i.ended_tstz IS NULL AND s.expires > $1)"
+	now := time.Now().UTC()
+
+	var totalCount int
+	row := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM impersonation i LEFT JOIN user_session s ON i.session_id = s.session_id WHERE $2 = false OR "+isActive+";", now, activeOnly)
+	if err := row.Scan(&totalCount); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while counting impersonations", err)
+	}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT i.impersonation_id, i.impersonator_id, i.user_id, i.reason, i.read_only, i.ip_address, i.started_tstz, i.expires_tstz, i.ended_tstz, COALESCE("+isActive+", false) FROM impersonation i LEFT JOIN user_session s ON i.session_id = s.session_id WHERE ($2 = false OR "+isActive+") AND i.impersonation_id < $3 ORDER BY i.impersonation_id DESC LIMIT $4;", now, activeOnly, afterId, args.First+1)
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving impersonations", err)
+	}
+	defer rows.Close()
+
+	impersonations := []*model.Impersonation{}
+	for rows.Next() {
+		i := NewImpersonationModel()
+		if err := rows.Scan(&i.ID, &i.Impersonator.ID, &i.User.ID, &i.Reason, &i.IsReadOnly, &i.IPAddress, &i.StartedAt, &i.ExpiresAt, &i.EndedAt, &i.IsActive); err != nil {
+			return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading impersonations", err)
+		}
+		impersonations = append(impersonations, &i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while reading impersonations", err)
+	}
+
+	return newPage(impersonations, args.First, totalCount, ImpersonationCursor), nil
+}
+
+// Returns the cursor of an impersonation in a paginated list of impersonations
+func ImpersonationCursor(i *model.Impersonation) string {
+	return EncodeCursor(i.ID)
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/lib/pq"
+)
+
+// Postgres reports a missing table with this code
+const undefinedTableCode = "42P01"
+
+// Checks that the database can be reached
+func (p *Postgres) Ping(ctx context.Context) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return p.db.PingContext(ctx)
+}
+
+// Returns the connection pool statistics
+func (p *Postgres) GetDatabaseStats(ctx context.Context) sql.DBStats {
+	return p.db.Stats()
+}
+
+// Returns the version of the most recently applied migration, or 0 if none have been applied
+func (p *Postgres) GetSchemaVersion(ctx context.Context) (int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var version int
+	row := p.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations;")
+	if err := row.Scan(&version); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == undefinedTableCode {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return version, nil
+}
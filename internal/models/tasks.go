@@ -20,18 +20,28 @@ func NewTaskModel() model.Task {
 	return task
 }
 
-func GetTaskById(ctx context.Context, id int) (*model.Task, error) {
-	row := db.DB.QueryRow("SELECT task_id, task_title, assigned_member, task_status, to_char(due_date, $1) FROM task WHERE task_id = $2 ORDER BY task_id ASC;", util.DateFormat, id)
+// Fills in the deprecated pre-formatted date fields from their DateTime fields
+func formatTaskDates(t *model.Task) {
+	t.DueDate = util.FormatDate(t.DueAt, util.DateFormat)
+}
+
+func (p *Postgres) GetTaskById(ctx context.Context, id int) (*model.Task, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT task_id, task_title, assigned_member, task_status, due_date FROM task WHERE task_id = $1 ORDER BY task_id ASC;", id)
 
 	task := NewTaskModel()
 	var userId *int
-	if err := row.Scan(&task.ID, &task.Title, &userId, &task.Status, &task.DueDate); err != nil {
+	if err := row.Scan(&task.ID, &task.Title, &userId, &task.Status, &task.DueAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NewNotFoundError(ctx, "This task does not exist.")
 		}
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving a task", err)
 	}
 
+	formatTaskDates(&task)
+
 	if userId != nil {
 		task.AssignedUser.ID = *userId
 	} else {
@@ -41,10 +51,13 @@ func GetTaskById(ctx context.Context, id int) (*model.Task, error) {
 	return &task, nil
 }
 
-func GetIncompleteTasks(ctx context.Context) ([]*model.Task, error) {
+func (p *Postgres) GetIncompleteTasks(ctx context.Context) ([]*model.Task, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	tasks := []*model.Task{}
 
-	rows, err := db.DB.Query("SELECT task_id, task_title, assigned_member, task_status, to_char(due_date, $1) FROM task WHERE task_status = 'Not Started' OR task_status = 'Started' ORDER BY task_id ASC;", util.DateFormat)
+	rows, err := p.db.QueryContext(ctx, "SELECT task_id, task_title, assigned_member, task_status, due_date FROM task WHERE task_status = 'Not Started' OR task_status = 'Started' ORDER BY task_id ASC;")
 	if err != nil {
 		return []*model.Task{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of incomplete tasks.", err)
 	}
@@ -53,10 +66,12 @@ func GetIncompleteTasks(ctx context.Context) ([]*model.Task, error) {
 		t := NewTaskModel()
 		var userId *int
 
-		if err := rows.Scan(&t.ID, &t.Title, &userId, &t.Status, &t.DueDate); err != nil {
+		if err := rows.Scan(&t.ID, &t.Title, &userId, &t.Status, &t.DueAt); err != nil {
 			return []*model.Task{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of incomplete tasks.", err)
 		}
 
+		formatTaskDates(&t)
+
 		if userId != nil {
 			t.AssignedUser.ID = *userId
 		} else {
@@ -66,13 +81,20 @@ func GetIncompleteTasks(ctx context.Context) ([]*model.Task, error) {
 		tasks = append(tasks, &t)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.Task{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of incomplete tasks.", err)
+	}
+
 	return tasks, nil
 }
 
-func GetCompletedTasks(ctx context.Context) ([]*model.Task, error) {
+func (p *Postgres) GetCompletedTasks(ctx context.Context) ([]*model.Task, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	tasks := []*model.Task{}
 
-	rows, err := db.DB.Query("SELECT task_id, task_title, assigned_member, task_status, to_char(due_date, $1) FROM task WHERE task_status = 'Completed' ORDER BY task_id DESC;", util.DateFormat)
+	rows, err := p.db.QueryContext(ctx, "SELECT task_id, task_title, assigned_member, task_status, due_date FROM task WHERE task_status = 'Completed' ORDER BY task_id DESC;")
 	if err != nil {
 		return []*model.Task{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of completed tasks.", err)
 	}
@@ -81,10 +103,12 @@ func GetCompletedTasks(ctx context.Context) ([]*model.Task, error) {
 		t := NewTaskModel()
 		var userId *int
 
-		if err := rows.Scan(&t.ID, &t.Title, &userId, &t.Status, &t.DueDate); err != nil {
+		if err := rows.Scan(&t.ID, &t.Title, &userId, &t.Status, &t.DueAt); err != nil {
 			return []*model.Task{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of completed tasks.", err)
 		}
 
+		formatTaskDates(&t)
+
 		if userId != nil {
 			t.AssignedUser.ID = *userId
 		} else {
@@ -94,13 +118,20 @@ func GetCompletedTasks(ctx context.Context) ([]*model.Task, error) {
 		tasks = append(tasks, &t)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.Task{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of completed tasks.", err)
+	}
+
 	return tasks, nil
 }
 
-func GetAvailableTasks(ctx context.Context) ([]*model.Task, error) {
+func (p *Postgres) GetAvailableTasks(ctx context.Context) ([]*model.Task, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	tasks := []*model.Task{}
 
-	rows, err := db.DB.Query("SELECT task_id, task_title, task_status, to_char(due_date, $1) FROM task WHERE assigned_member IS NULL ORDER BY task_id ASC;", util.DateFormat)
+	rows, err := p.db.QueryContext(ctx, "SELECT task_id, task_title, task_status, due_date FROM task WHERE assigned_member IS NULL ORDER BY task_id ASC;")
 	if err != nil {
 		return []*model.Task{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of available tasks.", err)
 	}
@@ -109,20 +140,29 @@ func GetAvailableTasks(ctx context.Context) ([]*model.Task, error) {
 		t := NewTaskModel()
 		t.AssignedUser = nil
 
-		if err := rows.Scan(&t.ID, &t.Title, &t.Status, &t.DueDate); err != nil {
+		if err := rows.Scan(&t.ID, &t.Title, &t.Status, &t.DueAt); err != nil {
 			return []*model.Task{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of available tasks.", err)
 		}
 
+		formatTaskDates(&t)
+
 		tasks = append(tasks, &t)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.Task{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of available tasks.", err)
+	}
+
 	return tasks, nil
 }
 
-func GetTasksForUser(ctx context.Context, userId int) ([]*model.Task, error) {
+func (p *Postgres) GetTasksForUser(ctx context.Context, userId int) ([]*model.Task, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	tasks := []*model.Task{}
 
-	rows, err := db.DB.Query("SELECT task_id, task_title, assigned_member, task_status, to_char(due_date, $1) FROM task WHERE assigned_member = $2 AND (task_status = 'Not Started' OR task_status = 'Started') ORDER BY task_id ASC;", util.DateFormat, userId)
+	rows, err := p.db.QueryContext(ctx, "SELECT task_id, task_title, assigned_member, task_status, due_date FROM task WHERE assigned_member = $1 AND (task_status = 'Not Started' OR task_status = 'Started') ORDER BY task_id ASC;", userId)
 	if err != nil {
 		return []*model.Task{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the list of user tasks.", err)
 	}
@@ -130,19 +170,28 @@ func GetTasksForUser(ctx context.Context, userId int) ([]*model.Task, error) {
 	for rows.Next() {
 		t := NewTaskModel()
 
-		if err := rows.Scan(&t.ID, &t.Title, &t.AssignedUser.ID, &t.Status, &t.DueDate); err != nil {
+		if err := rows.Scan(&t.ID, &t.Title, &t.AssignedUser.ID, &t.Status, &t.DueAt); err != nil {
 			return []*model.Task{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of user tasks.", err)
 		}
 
+		formatTaskDates(&t)
+
 		tasks = append(tasks, &t)
 	}
 
+	if err := rows.Err(); err != nil {
+		return []*model.Task{}, errors.NewInternalError(ctx, "An unexpected error occurred while reading the list of user tasks.", err)
+	}
+
 	return tasks, nil
 }
 
-func CreateTask(ctx context.Context, input *model.CreateTaskInput) (*int, error) {
+func (p *Postgres) CreateTask(ctx context.Context, input *model.CreateTaskInput) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
 	var id int
-	row := db.DB.QueryRow("INSERT INTO task (task_title, assigned_member, due_date) VALUES ($1, $2, $3) RETURNING task_id;", input.Title, input.AssignedUser, input.DueDate)
+	row := p.db.QueryRowContext(ctx, "INSERT INTO task (task_title, assigned_member, due_date) VALUES ($1, $2, $3) RETURNING task_id;", input.Title, input.AssignedUser, input.DueDate)
 	if err := row.Scan(&id); err != nil {
 		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while creating a task", err)
 	}
@@ -150,16 +199,22 @@ func CreateTask(ctx context.Context, input *model.CreateTaskInput) (*int, error)
 	return &id, nil
 }
 
-func EditTaskById(ctx context.Context, id int, input *model.EditTaskInput) error {
-	_, err := db.DB.Exec("UPDATE task SET task_title = $1, assigned_member = $2, due_date = $3, task_status = $4 WHERE task_id = $5", input.Title, input.AssignedUser, input.DueDate, input.Status, id)
+func (p *Postgres) EditTaskById(ctx context.Context, id int, input *model.EditTaskInput) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE task SET task_title = $1, assigned_member = $2, due_date = $3, task_status = $4 WHERE task_id = $5", input.Title, input.AssignedUser, input.DueDate, input.Status, id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while updating a task", err)
 	}
 	return nil
 }
 
-func DeleteTaskById(ctx context.Context, id int) error {
-	_, err := db.DB.Exec("DELETE FROM task WHERE task_id = $1", id)
+func (p *Postgres) DeleteTaskById(ctx context.Context, id int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM task WHERE task_id = $1", id)
 	if err != nil {
 		return errors.NewInternalError(ctx, "An unexpected error occurred while deleting a task", err)
 	}
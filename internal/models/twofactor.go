@@ -0,0 +1,246 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/lib/pq"
+)
+
+// A user's two-factor authentication settings
+type TwoFactor struct {
+	// The confirmed TOTP secret, or nil if two-factor authentication is not enabled
+	Secret *string
+	// A secret from an enrollment that has not been confirmed yet
+	PendingSecret          *string
+	EnabledAt              *time.Time
+	RecoveryCodesRemaining int
+}
+
+func (p *Postgres) GetTwoFactorByUserId(ctx context.Context, userId int) (*TwoFactor, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT totp_secret, totp_pending_secret, totp_enabled_tstz, (SELECT COUNT(*) FROM recovery_code c WHERE c.evaluator_id = e.evaluator_id AND c.used_tstz IS NULL) FROM evaluator e WHERE evaluator_id = $1;", userId)
+
+	tf := &TwoFactor{}
+	if err := row.Scan(&tf.Secret, &tf.PendingSecret, &tf.EnabledAt, &tf.RecoveryCodesRemaining); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError(ctx, "Oops! The requested user does not exist.")
+		}
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving two-factor authentication settings", err)
+	}
+	return tf, nil
+}
+
+func (p *Postgres) SetPendingTOTPSecret(ctx context.Context, userId int, secret string) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "UPDATE evaluator SET totp_pending_secret = $1 WHERE evaluator_id = $2;", secret, userId)
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while setting up two-factor authentication", err)
+	}
+	return nil
+}
+
+// Makes the pending secret the user's secret and replaces their recovery codes. The step the
+// confirming code belonged to counts as used.
+func (p *Postgres) EnableTwoFactor(ctx context.Context, userId int, step int64, recoveryCodeHashes []string) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return p.withTx(ctx, "An unexpected error occurred while setting up two-factor authentication", func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "UPDATE evaluator SET totp_secret = totp_pending_secret, totp_pending_secret = NULL, totp_enabled_tstz = $1, totp_last_step = $2 WHERE evaluator_id = $3 AND totp_pending_secret IS NOT NULL;", time.Now().UTC(), step, userId)
+		if err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(ctx, tx, userId, recoveryCodeHashes)
+	})
+}
+
+// Records that a code from the given time step was used. Returns false if a code from that step or a
+// later one was already used, so the code must be rejected.
+func (p *Postgres) UseTOTPStep(ctx context.Context, userId int, step int64) (bool, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := p.db.ExecContext(ctx, "UPDATE evaluator SET totp_last_step = $1 WHERE evaluator_id = $2 AND (totp_last_step IS NULL OR totp_last_step < $1);", step, userId)
+	if err != nil {
+		return false, errors.NewInternalError(ctx, "An unexpected error occurred while checking a two-factor code", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.NewInternalError(ctx, "An unexpected error occurred while checking a two-factor code", err)
+	}
+	return n > 0, nil
+}
+
+// Uses up the recovery code with the given hash. Returns false if the user has no such unused code.
+func (p *Postgres) UseRecoveryCode(ctx context.Context, userId int, codeHash string) (bool, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := p.db.ExecContext(ctx, "UPDATE recovery_code SET used_tstz = $1 WHERE evaluator_id = $2 AND code_hash = $3 AND used_tstz IS NULL;", time.Now().UTC(), userId, codeHash)
+	if err != nil {
+		return false, errors.NewInternalError(ctx, "An unexpected error occurred while checking a two-factor code", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.NewInternalError(ctx, "An unexpected error occurred while checking a two-factor code", err)
+	}
+	return n > 0, nil
+}
+
+func (p *Postgres) ReplaceRecoveryCodes(ctx context.Context, userId int, codeHashes []string) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return p.withTx(ctx, "An unexpected error occurred while replacing recovery codes", func(tx *sql.Tx) error {
+		return replaceRecoveryCodes(ctx, tx, userId, codeHashes)
+	})
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userId int, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM recovery_code WHERE evaluator_id = $1;", userId); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, "INSERT INTO recovery_code (evaluator_id, code_hash) SELECT $1, unnest($2::text[]);", userId, pq.Array(codeHashes))
+	return err
+}
+
+// Turns off two-factor authentication, deleting the secret, recovery codes and pending logins
+func (p *Postgres) DisableTwoFactor(ctx context.Context, userId int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return p.withTx(ctx, "An unexpected error occurred while turning off two-factor authentication", func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE evaluator SET totp_secret = NULL, totp_pending_secret = NULL, totp_enabled_tstz = NULL, totp_last_step = NULL WHERE evaluator_id = $1;", userId); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM recovery_code WHERE evaluator_id = $1;", userId); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "DELETE FROM login_challenge WHERE evaluator_id = $1;", userId)
+		return err
+	})
+}
+
+// Stores a login that is waiting for a two-factor code by the hash of its pending token
+func (p *Postgres) CreateLoginChallenge(ctx context.Context, userId int, tokenHash string, expires time.Time) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "INSERT INTO login_challenge (evaluator_id, token_hash, expires_tstz) VALUES ($1, $2, $3);", userId, tokenHash, expires.UTC())
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while logging in", err)
+	}
+	return nil
+}
+
+// Returns the user a pending login is for, or nil if it does not exist, has expired or the
+// account has been locked since
+func (p *Postgres) GetLoginChallengeUserId(ctx context.Context, tokenHash string) (*int, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	row := p.db.QueryRowContext(ctx, "SELECT c.evaluator_id FROM login_challenge c INNER JOIN evaluator e ON c.evaluator_id = e.evaluator_id WHERE c.token_hash = $1 AND c.expires_tstz > $2 AND e.account_locked = false;", tokenHash, time.Now().UTC())
+
+	var userId int
+	if err := row.Scan(&userId); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.NewInternalError(ctx, "An unexpected error occurred while logging in", err)
+	}
+	return &userId, nil
+}
+
+// Counts a wrong code against a pending login, deleting it once there have been too many
+func (p *Postgres) FailLoginChallenge(ctx context.Context, tokenHash string, maxFailures int) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return p.withTx(ctx, "An unexpected error occurred while logging in", func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE login_challenge SET failed_count = failed_count + 1 WHERE token_hash = $1;", tokenHash); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "DELETE FROM login_challenge WHERE token_hash = $1 AND failed_count >= $2;", tokenHash, maxFailures)
+		return err
+	})
+}
+
+func (p *Postgres) DeleteLoginChallenge(ctx context.Context, tokenHash string) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := p.db.ExecContext(ctx, "DELETE FROM login_challenge WHERE token_hash = $1;", tokenHash)
+	if err != nil {
+		return errors.NewInternalError(ctx, "An unexpected error occurred while logging in", err)
+	}
+	return nil
+}
+
+// Deletes pending logins that have expired and returns how many were deleted. Errors are returned
+// as is since this runs as a background job rather than for a request.
+func (p *Postgres) DeleteExpiredLoginChallenges(ctx context.Context) (int64, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := p.db.ExecContext(ctx, "DELETE FROM login_challenge WHERE expires_tstz <= $1;", time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (p *Postgres) GetTwoFactorRequiredPermissions(ctx context.Context) ([]string, error) {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	keys := []string{}
+
+	rows, err := p.db.QueryContext(ctx, "SELECT permission_key FROM two_factor_required_permission ORDER BY permission_key;")
+	if err != nil {
+		return keys, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the two-factor authentication policy", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return []string{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the two-factor authentication policy", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return []string{}, errors.NewInternalError(ctx, "An unexpected error occurred while retrieving the two-factor authentication policy", err)
+	}
+
+	return keys, nil
+}
+
+func (p *Postgres) SetTwoFactorRequiredPermissions(ctx context.Context, keys []string) error {
+	ctx, cancel := db.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return p.withTx(ctx, "An unexpected error occurred while updating the two-factor authentication policy", func(tx *sql.Tx) error {
+		keys := uniqueKeys(keys)
+		if err := checkPermissionKeys(ctx, tx, keys); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM two_factor_required_permission;"); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "INSERT INTO two_factor_required_permission (permission_key) SELECT unnest($1::text[]);", pq.Array(keys))
+		return err
+	})
+}
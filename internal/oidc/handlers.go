@@ -0,0 +1,189 @@
+package oidc
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+)
+
+const (
+	LoginPath    = "/auth/oidc/login"
+	CallbackPath = "/auth/oidc/callback"
+)
+
+// How long a user has to log in at the provider
+const loginStateLifetime = 10 * time.Minute
+
+// Holds the state in the browser that started the login, so a callback URL made for someone else
+// cannot log the browser into their account
+const stateCookie = "oidc_state"
+
+// Sends the user to the provider to log in. The state, PKCE verifier and nonce are kept until the
+// provider sends the user back to the callback.
+func Login(provider *Provider, states models.OIDCRepository, appURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if auth.GetUserFromContext(ctx) != nil {
+			http.Redirect(w, r, appURL+"/", http.StatusFound)
+			return
+		}
+
+		state, errState := auth.NewSecretToken()
+		nonce, errNonce := auth.NewSecretToken()
+		verifier, errVerifier := auth.NewSecretToken()
+		if errState != nil || errNonce != nil || errVerifier != nil {
+			log.Printf("oidc: generating login state: %v %v %v", errState, errNonce, errVerifier)
+			failLogin(w, r, appURL, "sso_failed")
+			return
+		}
+
+		redirect, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+		if err != nil {
+			log.Printf("oidc: %v", err)
+			failLogin(w, r, appURL, "sso_unavailable")
+			return
+		}
+
+		if err := states.CreateOIDCLoginState(ctx, auth.HashSecretToken(state), verifier, nonce, time.Now().Add(loginStateLifetime)); err != nil {
+			failLogin(w, r, appURL, "sso_failed")
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     stateCookie,
+			Value:    state,
+			Path:     CallbackPath,
+			MaxAge:   int(loginStateLifetime.Seconds()),
+			Secure:   strings.HasPrefix(appURL, "https://"),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, r, redirect, http.StatusFound)
+	}
+}
+
+// Finishes a login when the provider sends the user back. The provider account is mapped to a user
+// by a linked subject, or else by a verified email that belongs to exactly one active user, which
+// links the two. Users with two-factor authentication are sent to the login page with a pending
+// token, as after a password login.
+func Callback(provider *Provider, repos *models.Repositories, appURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		q := r.URL.Query()
+		secure := strings.HasPrefix(appURL, "https://")
+
+		http.SetCookie(w, &http.Cookie{Name: stateCookie, Path: CallbackPath, MaxAge: -1, Secure: secure, HttpOnly: true, SameSite: http.SameSiteLaxMode})
+
+		if auth.GetUserFromContext(ctx) != nil {
+			http.Redirect(w, r, appURL+"/", http.StatusFound)
+			return
+		}
+
+		if q.Get("error") != "" {
+			failLogin(w, r, appURL, "sso_cancelled")
+			return
+		}
+
+		state := q.Get("state")
+		cookie, err := r.Cookie(stateCookie)
+		if state == "" || err != nil || cookie.Value != state {
+			failLogin(w, r, appURL, "sso_expired")
+			return
+		}
+
+		loginState, err := repos.OIDC.UseOIDCLoginState(ctx, auth.HashSecretToken(state))
+		if err != nil {
+			failLogin(w, r, appURL, "sso_failed")
+			return
+		}
+		if loginState == nil {
+			failLogin(w, r, appURL, "sso_expired")
+			return
+		}
+
+		claims, err := provider.Exchange(ctx, q.Get("code"), loginState.CodeVerifier, loginState.Nonce)
+		if err != nil {
+			log.Printf("oidc: %v", err)
+			failLogin(w, r, appURL, "sso_failed")
+			return
+		}
+
+		userId, err := repos.OIDC.RecordOIDCLogin(ctx, claims.Issuer, claims.Subject)
+		if err != nil {
+			failLogin(w, r, appURL, "sso_failed")
+			return
+		}
+
+		// Only link by an address the provider has verified, and only when it is not ambiguous
+		if userId == nil && claims.Email != "" && claims.EmailVerified {
+			users, err := repos.Users.GetActiveUsersByEmail(ctx, claims.Email)
+			if err != nil {
+				failLogin(w, r, appURL, "sso_failed")
+				return
+			}
+			if len(users) == 1 {
+				if err := repos.OIDC.LinkOIDCIdentity(ctx, users[0].ID, claims.Issuer, claims.Subject, claims.Email); err != nil {
+					failLogin(w, r, appURL, "sso_failed")
+					return
+				}
+				userId = &users[0].ID
+			}
+		}
+		if userId == nil {
+			failLogin(w, r, appURL, "sso_no_account")
+			return
+		}
+
+		user, err := repos.Users.GetUserById(ctx, *userId)
+		if err != nil {
+			failLogin(w, r, appURL, "sso_failed")
+			return
+		}
+		if *user.AccountLocked {
+			failLogin(w, r, appURL, "sso_disabled")
+			return
+		}
+
+		tf, err := repos.TwoFactor.GetTwoFactorByUserId(ctx, user.ID)
+		if err != nil {
+			failLogin(w, r, appURL, "sso_failed")
+			return
+		}
+		if tf.Secret != nil {
+			pendingToken, err := auth.NewSecretToken()
+			if err != nil {
+				log.Printf("oidc: generating pending token: %v", err)
+				failLogin(w, r, appURL, "sso_failed")
+				return
+			}
+			if err := repos.TwoFactor.CreateLoginChallenge(ctx, user.ID, auth.HashSecretToken(pendingToken), time.Now().Add(auth.LoginChallengeLifetime)); err != nil {
+				failLogin(w, r, appURL, "sso_failed")
+				return
+			}
+
+			// In the fragment, so the token is not sent to servers or logged with the URL
+			http.Redirect(w, r, appURL+"/login#"+url.Values{"pendingToken": {pendingToken}}.Encode(), http.StatusFound)
+			return
+		}
+
+		token := auth.CreateAuthToken(ctx, user.ID, nil, true)
+		if token == nil {
+			failLogin(w, r, appURL, "sso_failed")
+			return
+		}
+
+		http.SetCookie(w, auth.NewSessionCookie(*token, auth.SessionMaxLifetime))
+		http.Redirect(w, r, appURL+"/", http.StatusFound)
+	}
+}
+
+// Sends the user back to the login page with the reason the login failed
+func failLogin(w http.ResponseWriter, r *http.Request, appURL string, reason string) {
+	http.Redirect(w, r, appURL+"/login?error="+reason, http.StatusFound)
+}
@@ -0,0 +1,277 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/graph/model"
+	"github.com/KA-Challenge-Council/Bema/internal/models/memory"
+	"github.com/KA-Challenge-Council/Bema/internal/oidc/oidctest"
+)
+
+func newTestProvider(idp *oidctest.Server, secret string) *Provider {
+	return NewProvider(Config{
+		Issuer:       idp.URL,
+		ClientID:     idp.ClientID,
+		ClientSecret: secret,
+		RedirectURL:  "http://app.test/auth/oidc/callback",
+		Scopes:       []string{"openid", "email"},
+	})
+}
+
+// Runs the authorization request against the stand-in provider and returns the code it issued
+func authorize(t *testing.T, p *Provider, state string, nonce string, verifier string) string {
+	t.Helper()
+
+	authURL, err := p.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("authorize: status %d", res.StatusCode)
+	}
+
+	location, _ := url.Parse(res.Header.Get("Location"))
+	if got := location.Query().Get("state"); got != state {
+		t.Fatalf("authorize returned state %q, want %q", got, state)
+	}
+	return location.Query().Get("code")
+}
+
+func TestExchange(t *testing.T) {
+	idp := oidctest.NewServer("bema", "secret")
+	defer idp.Close()
+	idp.SetIdentity(oidctest.Identity{Subject: "user-1", Email: "judge@example.com", EmailVerified: true})
+
+	t.Run("valid login", func(t *testing.T) {
+		p := newTestProvider(idp, "secret")
+		code := authorize(t, p, "state", "nonce", "verifier-0123456789-0123456789-0123456789")
+
+		claims, err := p.Exchange(context.Background(), code, "verifier-0123456789-0123456789-0123456789", "nonce")
+		if err != nil {
+			t.Fatalf("Exchange: %v", err)
+		}
+		want := Claims{Issuer: idp.URL, Subject: "user-1", Email: "judge@example.com", EmailVerified: true}
+		if *claims != want {
+			t.Fatalf("Exchange = %+v, want %+v", *claims, want)
+		}
+	})
+
+	t.Run("codes can only be used once", func(t *testing.T) {
+		p := newTestProvider(idp, "secret")
+		code := authorize(t, p, "state", "nonce", "verifier")
+
+		if _, err := p.Exchange(context.Background(), code, "verifier", "nonce"); err != nil {
+			t.Fatalf("Exchange: %v", err)
+		}
+		if _, err := p.Exchange(context.Background(), code, "verifier", "nonce"); err == nil {
+			t.Fatal("Exchange succeeded twice with the same code")
+		}
+	})
+
+	t.Run("wrong PKCE verifier", func(t *testing.T) {
+		p := newTestProvider(idp, "secret")
+		code := authorize(t, p, "state", "nonce", "verifier")
+
+		if _, err := p.Exchange(context.Background(), code, "another-verifier", "nonce"); err == nil {
+			t.Fatal("Exchange succeeded with the wrong code verifier")
+		}
+	})
+
+	t.Run("wrong nonce", func(t *testing.T) {
+		p := newTestProvider(idp, "secret")
+		code := authorize(t, p, "state", "nonce", "verifier")
+
+		if _, err := p.Exchange(context.Background(), code, "verifier", "another-nonce"); err == nil || !strings.Contains(err.Error(), "nonce") {
+			t.Fatalf("Exchange with the wrong nonce returned %v", err)
+		}
+	})
+
+	t.Run("wrong client secret", func(t *testing.T) {
+		p := newTestProvider(idp, "not-the-secret")
+		code := authorize(t, p, "state", "nonce", "verifier")
+
+		if _, err := p.Exchange(context.Background(), code, "verifier", "nonce"); err == nil {
+			t.Fatal("Exchange succeeded with the wrong client secret")
+		}
+	})
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	idp := oidctest.NewServer("bema", "")
+	defer idp.Close()
+
+	p := newTestProvider(idp, "")
+	m, err := p.discover(context.Background())
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+
+	now := time.Now()
+	claims := func(changes map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss":   idp.URL,
+			"sub":   "user-1",
+			"aud":   "bema",
+			"exp":   now.Add(time.Minute).Unix(),
+			"iat":   now.Unix(),
+			"nonce": "nonce",
+		}
+		for k, v := range changes {
+			c[k] = v
+		}
+		return c
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{"valid token", idp.SignToken(claims(nil)), true},
+		{"audience list with this client as authorized party", idp.SignToken(claims(map[string]interface{}{"aud": []string{"other", "bema"}, "azp": "bema"})), true},
+		{"audience list without an authorized party", idp.SignToken(claims(map[string]interface{}{"aud": []string{"other", "bema"}})), false},
+		{"another client", idp.SignToken(claims(map[string]interface{}{"aud": "other"})), false},
+		{"another issuer", idp.SignToken(claims(map[string]interface{}{"iss": "https://idp.example.com"})), false},
+		{"expired", idp.SignToken(claims(map[string]interface{}{"exp": now.Add(-2 * time.Minute).Unix()})), false},
+		{"issued in the future", idp.SignToken(claims(map[string]interface{}{"iat": now.Add(5 * time.Minute).Unix()})), false},
+		{"no subject", idp.SignToken(claims(map[string]interface{}{"sub": ""})), false},
+		{"tampered claims", tamper(idp.SignToken(claims(nil)), idp.SignToken(claims(map[string]interface{}{"sub": "admin"}))), false},
+		{"unsigned", "eyJhbGciOiJub25lIn0." + strings.Split(idp.SignToken(claims(nil)), ".")[1] + ".", false},
+		{"malformed", "not-a-token", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.verifyIDToken(context.Background(), m, tt.token, "nonce")
+			if (err == nil) != tt.ok {
+				t.Fatalf("verifyIDToken returned %v, want ok = %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+// Returns the first token with the claims of the second
+func tamper(token string, other string) string {
+	parts := strings.Split(token, ".")
+	parts[1] = strings.Split(other, ".")[1]
+	return strings.Join(parts, ".")
+}
+
+func TestCallback(t *testing.T) {
+	idp := oidctest.NewServer("bema", "secret")
+	defer idp.Close()
+
+	store := memory.NewStore()
+	repos := store.Repositories()
+	ctx := context.Background()
+
+	email := "judge@example.com"
+	judgeId, _ := repos.Users.CreateUser(ctx, &model.CreateUserInput{Name: "Judge", Email: &email, Kaid: "kaid_1", Username: "judge", TermStart: "2022-01-01"})
+	_ = repos.TwoFactor.SetPendingTOTPSecret(ctx, *judgeId, "JBSWY3DPEHPK3PXP")
+	_ = repos.TwoFactor.EnableTwoFactor(ctx, *judgeId, 0, nil)
+
+	mux := http.NewServeMux()
+	app := httptest.NewServer(mux)
+	defer app.Close()
+
+	p := NewProvider(Config{Issuer: idp.URL, ClientID: "bema", ClientSecret: "secret", RedirectURL: app.URL + CallbackPath, Scopes: []string{"openid", "email"}})
+	mux.Handle(LoginPath, Login(p, repos.OIDC, app.URL))
+	mux.Handle(CallbackPath, Callback(p, repos, app.URL))
+
+	// Logs in from a new browser and returns where it ends up in the app
+	login := func(t *testing.T) *url.URL {
+		t.Helper()
+
+		jar, _ := cookiejar.New(nil)
+		client := &http.Client{Jar: jar, CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Path == "/" || req.URL.Path == "/login" {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		}}
+
+		res, err := client.Get(app.URL + LoginPath)
+		if err != nil {
+			t.Fatalf("login: %v", err)
+		}
+		res.Body.Close()
+
+		location, err := url.Parse(res.Header.Get("Location"))
+		if err != nil || res.StatusCode != http.StatusFound {
+			t.Fatalf("login ended with status %d at %q", res.StatusCode, res.Header.Get("Location"))
+		}
+		return location
+	}
+
+	t.Run("unverified email is not linked", func(t *testing.T) {
+		idp.SetIdentity(oidctest.Identity{Subject: "user-1", Email: email, EmailVerified: false})
+
+		if got := login(t); got.Path != "/login" || got.Query().Get("error") != "sso_no_account" {
+			t.Fatalf("login redirected to %s, want the sso_no_account error", got)
+		}
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		idp.SetIdentity(oidctest.Identity{Subject: "user-2", Email: "nobody@example.com", EmailVerified: true})
+
+		if got := login(t); got.Query().Get("error") != "sso_no_account" {
+			t.Fatalf("login redirected to %s, want the sso_no_account error", got)
+		}
+	})
+
+	t.Run("verified email links the account", func(t *testing.T) {
+		idp.SetIdentity(oidctest.Identity{Subject: "user-1", Email: "Judge@Example.com", EmailVerified: true})
+
+		got := login(t)
+		fragment, _ := url.ParseQuery(got.Fragment)
+		if got.Path != "/login" || fragment.Get("pendingToken") == "" {
+			t.Fatalf("login redirected to %s, want a pending two-factor login", got)
+		}
+	})
+
+	t.Run("linked subject logs in after the email changes", func(t *testing.T) {
+		idp.SetIdentity(oidctest.Identity{Subject: "user-1", Email: "someone-else@example.com", EmailVerified: true})
+
+		got := login(t)
+		fragment, _ := url.ParseQuery(got.Fragment)
+		if fragment.Get("pendingToken") == "" {
+			t.Fatalf("login redirected to %s, want a pending two-factor login", got)
+		}
+	})
+
+	t.Run("unlinked subject must match by email again", func(t *testing.T) {
+		_ = repos.OIDC.DeleteOIDCIdentitiesByUserId(ctx, *judgeId)
+		idp.SetIdentity(oidctest.Identity{Subject: "user-1", Email: "someone-else@example.com", EmailVerified: true})
+
+		if got := login(t); got.Query().Get("error") != "sso_no_account" {
+			t.Fatalf("login redirected to %s, want the sso_no_account error", got)
+		}
+	})
+
+	t.Run("callback without the state cookie", func(t *testing.T) {
+		client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+		res, err := client.Get(app.URL + CallbackPath + "?code=code&state=state")
+		if err != nil {
+			t.Fatalf("callback: %v", err)
+		}
+		res.Body.Close()
+
+		if got := res.Header.Get("Location"); got != app.URL+"/login?error=sso_expired" {
+			t.Fatalf("callback redirected to %q, want the sso_expired error", got)
+		}
+	})
+}
@@ -1,5 +1,7 @@
 package util
 
-const DateFormat string = "MM-DD-YYYY"
-const DisplayDateFormat string = "FMMM-FMDD-YYYY"
-const DisplayFancyDateFormat string = "FMMM-FMDD-YYYY FMHH12:FMMI:FMSS AM"
+// Go layouts for the deprecated pre-formatted date fields. They match the
+// to_char formats the database used to produce so existing clients keep working.
+const DateFormat string = "01-02-2006"
+const DisplayDateFormat string = "1-2-2006"
+const DisplayFancyDateFormat string = "1-2-2006 3:4:5 PM"
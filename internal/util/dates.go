@@ -0,0 +1,18 @@
+package util
+
+import "time"
+
+// Formats t in UTC using one of the date format layouts
+func FormatDate(t time.Time, layout string) string {
+	return t.UTC().Format(layout)
+}
+
+// Formats t in UTC using one of the date format layouts, or returns nil if t is nil
+func FormatNullableDate(t *time.Time, layout string) *string {
+	if t == nil {
+		return nil
+	}
+
+	formatted := FormatDate(*t, layout)
+	return &formatted
+}
@@ -2,63 +2,172 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
-	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/KA-Challenge-Council/Bema/graph/generated"
 	"github.com/KA-Challenge-Council/Bema/graph/resolvers"
 	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	"github.com/KA-Challenge-Council/Bema/internal/config"
+	"github.com/KA-Challenge-Council/Bema/internal/dataloaders"
 	"github.com/KA-Challenge-Council/Bema/internal/db"
 	"github.com/KA-Challenge-Council/Bema/internal/errors"
+	"github.com/KA-Challenge-Council/Bema/internal/health"
+	"github.com/KA-Challenge-Council/Bema/internal/jobs"
+	"github.com/KA-Challenge-Council/Bema/internal/mail"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+	"github.com/KA-Challenge-Council/Bema/internal/oidc"
 	"github.com/gorilla/mux"
-	"github.com/joho/godotenv"
 	"github.com/rs/cors"
 
 	_ "github.com/lib/pq"
 )
 
-const defaultPort = "8080"
-
 func main() {
-	godotenv.Load(".env")
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Create database connection
+	db.InitDB(cfg.Database)
+	defer db.DB.Close()
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = os.Getenv("GRAPHQL_PORT")
-		if port == "" {
-			port = defaultPort
+	// Bring the schema up to date before serving requests
+	if cfg.AutoMigrate {
+		if err := db.Migrate(db.DB); err != nil {
+			log.Fatal(err)
 		}
 	}
 
-	// Create database connection
-	db.InitDB()
+	auth.SecureCookies = strings.HasPrefix(cfg.AppURL, "https://")
+	auth.TrustedProxies, err = auth.ParseTrustedProxies(cfg.HTTP.TrustedProxies)
+	if err != nil {
+		log.Fatal(err)
+	}
+	auth.SessionLifetime = cfg.Session.Lifetime
+	auth.SessionMaxLifetime = cfg.Session.MaxLifetime
+	auth.ImpersonationLifetime = cfg.Session.ImpersonationLifetime
+	auth.LoginThrottle = auth.LoginThrottlePolicy{
+		MaxAttempts:      cfg.Login.MaxAttempts,
+		MaxAttemptsPerIP: cfg.Login.MaxAttemptsPerIP,
+		Window:           cfg.Login.AttemptWindow,
+		Lockout:          cfg.Login.Lockout,
+		MaxLockout:       cfg.Login.MaxLockout,
+	}
+	auth.PasswordResetLifetime = cfg.Login.PasswordResetLifetime
+	auth.InvitationLifetime = cfg.Login.InvitationLifetime
+
+	mailer, err := mail.New(cfg.Mail)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	repos := models.NewPostgresRepositories(db.DB)
+
+	server := &http.Server{
+		Addr:              ":" + strconv.Itoa(cfg.Port),
+		Handler:           newRouter(cfg, repos, mailer),
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+
+	// Stop accepting connections on SIGINT or SIGTERM and let in-flight requests finish
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go jobs.Every(ctx, cfg.Session.PurgeInterval, "purge expired sessions", jobs.PurgeExpiredSessions(repos.Sessions))
+	go jobs.Every(ctx, cfg.Session.PurgeInterval, "purge old failed logins", jobs.PurgeLoginThrottles(repos.LoginThrottles))
+	go jobs.Every(ctx, cfg.Session.PurgeInterval, "purge expired password resets", jobs.PurgeExpiredPasswordResets(repos.PasswordResets))
+	go jobs.Every(ctx, cfg.Session.PurgeInterval, "purge expired two-factor logins", jobs.PurgeExpiredLoginChallenges(repos.TwoFactor))
+	go jobs.Every(ctx, cfg.Session.PurgeInterval, "purge unfinished single sign-on logins", jobs.PurgeExpiredOIDCLoginStates(repos.OIDC))
+	go jobs.Every(ctx, cfg.Terms.CheckInterval, "apply term dates", jobs.ApplyTermDates(repos.Lifecycle, repos.Users, mailer, cfg.AppURL, cfg.Terms.EndWarning))
 
+	go func() {
+		log.Println("Running server on port :" + strconv.Itoa(cfg.Port))
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Println("Shutting down, waiting for in-flight requests to finish")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server did not shut down cleanly: %v", err)
+	}
+
+	log.Println("Server stopped")
+}
+
+// Builds the HTTP handler serving the GraphQL API, the health checks and the React app
+func newRouter(cfg *config.Config, repos *models.Repositories, mailer mail.Mailer) http.Handler {
 	// Create configuration and set directive handlers
-	config := generated.Config{Resolvers: &resolvers.Resolver{}}
+	schemaConfig := generated.Config{Resolvers: &resolvers.Resolver{
+		Repos:  repos,
+		Mailer: mailer,
+		AppURL: cfg.AppURL,
+
+		OIDCEnabled: cfg.OIDC.Issuer != "",
+	}, Directives: resolvers.Directives(repos)}
 
 	// Create router
 	router := mux.NewRouter()
 	router.Use(cors.New(cors.Options{
-		AllowedOrigins:   []string{"https://www.kachallengecouncil.org", "https://bema-development.herokuapp.com/", "https://studio.apollographql.com", "http://localhost:6001"},
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
 		AllowCredentials: true,
+		AllowedHeaders:   []string{"Origin", "Accept", "Content-Type", "X-Requested-With", auth.CSRFHeader},
 		Debug:            false,
 	}).Handler)
 	router.Use(auth.Middleware())
 	router.Use(errors.Middleware(nil))
+	router.Use(dataloaders.Middleware(repos))
 
 	// Create graphql handler
-	srv := handler.NewDefaultServer(generated.NewExecutableSchema(config))
+	schema := generated.NewExecutableSchema(schemaConfig)
+	if err := resolvers.CheckPermissionDirectives(schema.Schema()); err != nil {
+		log.Fatal(err)
+	}
+	srv := handler.NewDefaultServer(schema)
+	srv.AroundRootFields(resolvers.RejectForgedMutations)
+	srv.AroundRootFields(resolvers.RejectReadOnlyMutations)
 	router.Handle("/api/internal/graphql", srv)
 
+	// Health checks for the deploy platform
+	router.Handle("/healthz", health.Healthz())
+	router.Handle("/readyz", health.Readyz(repos.System))
+
+	// Single sign-on through the OpenID Connect provider, alongside password logins
+	if cfg.OIDC.Issuer != "" {
+		provider := oidc.NewProvider(oidc.Config{
+			Issuer:       cfg.OIDC.Issuer,
+			ClientID:     cfg.OIDC.ClientID,
+			ClientSecret: cfg.OIDC.ClientSecret,
+			RedirectURL:  cfg.OIDC.RedirectURL,
+			Scopes:       cfg.OIDC.Scopes,
+		})
+		router.Handle(oidc.LoginPath, oidc.Login(provider, repos.OIDC, cfg.AppURL)).Methods(http.MethodGet)
+		router.Handle(oidc.CallbackPath, oidc.Callback(provider, repos, cfg.AppURL)).Methods(http.MethodGet)
+	}
+
 	// Serve the react app
-	router.PathPrefix("/static").Handler(http.StripPrefix("/", http.FileServer(http.Dir("./client/build"))))
+	router.PathPrefix("/static").Handler(http.StripPrefix("/", http.FileServer(http.Dir(cfg.StaticDir))))
 	router.PathPrefix("/").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "./client/build/index.html")
+		http.ServeFile(w, r, filepath.Join(cfg.StaticDir, "index.html"))
 	})
 
-	// Start the server
-	log.Println("Running server on port :" + port)
-	log.Fatal(http.ListenAndServe(":"+port, router))
+	return router
 }
@@ -0,0 +1,1957 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KA-Challenge-Council/Bema/internal/auth"
+	"github.com/KA-Challenge-Council/Bema/internal/config"
+	"github.com/KA-Challenge-Council/Bema/internal/db"
+	"github.com/KA-Challenge-Council/Bema/internal/jobs"
+	"github.com/KA-Challenge-Council/Bema/internal/mail"
+	"github.com/KA-Challenge-Council/Bema/internal/models"
+	"github.com/KA-Challenge-Council/Bema/internal/oidc"
+	"github.com/KA-Challenge-Council/Bema/internal/oidc/oidctest"
+)
+
+// The end-to-end tests run the GraphQL API from newRouter against the Postgres database named by
+// TEST_DATABASE_URL. The database is wiped and migrated before the tests run and every test reloads
+// testdata/fixtures.sql, so it must be a throwaway database. The tests are skipped when it is not set.
+var testServer *httptest.Server
+
+// Emails sent by the server under test are written here instead of being sent
+var testMailDir string
+
+// The stand-in identity provider for single sign-on
+var testIdP *oidctest.Server
+
+const fixturePassword = "password"
+
+func TestMain(m *testing.M) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url != "" {
+		db.InitDB(config.Database{
+			URL:          url,
+			MaxOpenConns: 5,
+			MaxIdleConns: 5,
+			QueryTimeout: 10 * time.Second,
+		})
+
+		if err := resetSchema(); err != nil {
+			log.Fatal(err)
+		}
+
+		var err error
+		testMailDir, err = os.MkdirTemp("", "bema-mail")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// The provider redirects back to the test server, so its address must be known before the
+		// router is built
+		testIdP = oidctest.NewServer("bema", "secret")
+		testServer = httptest.NewServer(http.NotFoundHandler())
+
+		cfg := &config.Config{
+			StaticDir: "testdata",
+			AppURL:    "http://localhost",
+			CORS:      config.CORS{AllowedOrigins: []string{"http://localhost"}},
+			OIDC: config.OIDC{
+				Issuer:       testIdP.URL,
+				ClientID:     "bema",
+				ClientSecret: "secret",
+				RedirectURL:  testServer.URL + oidc.CallbackPath,
+				Scopes:       []string{"openid", "email"},
+			},
+		}
+		testServer.Config.Handler = newRouter(cfg, models.NewPostgresRepositories(db.DB), &mail.LogMailer{Dir: testMailDir, From: "noreply@example.com"})
+	}
+
+	code := m.Run()
+
+	if testServer != nil {
+		testServer.Close()
+		testIdP.Close()
+		db.DB.Close()
+		os.RemoveAll(testMailDir)
+	}
+
+	os.Exit(code)
+}
+
+// Reverts every migration and applies them again, so the stored functions under test are the ones in this tree
+func resetSchema() error {
+	migrations, err := db.LoadMigrations()
+	if err != nil {
+		return err
+	}
+	if err := db.MigrateDown(db.DB, len(migrations)); err != nil {
+		return err
+	}
+	return db.Migrate(db.DB)
+}
+
+// Empties every table except the permission definitions, which come from the migrations, and loads
+// the fixtures. Skips the test when no test database is configured.
+func seed(t *testing.T) {
+	t.Helper()
+
+	if testServer == nil {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	rows, err := db.DB.Query("SELECT tablename FROM pg_tables WHERE schemaname = current_schema() AND tablename NOT IN ('schema_migrations', 'permission');")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	tables := []string{}
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			t.Fatal(err)
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.DB.Exec("TRUNCATE " + strings.Join(tables, ", ") + " RESTART IDENTITY CASCADE;"); err != nil {
+		t.Fatal(err)
+	}
+
+	fixtures, err := os.ReadFile("testdata/fixtures.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.DB.Exec(string(fixtures)); err != nil {
+		t.Fatalf("loading fixtures: %v", err)
+	}
+}
+
+type graphQLError struct {
+	Message    string                 `json:"message"`
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+type graphQLResponse struct {
+	Data    json.RawMessage `json:"data"`
+	Errors  []graphQLError  `json:"errors"`
+	cookies []*http.Cookie
+}
+
+// Returns the HTTP status attached to the first error, or 0 if there were no errors
+func (r graphQLResponse) status() int {
+	if len(r.Errors) == 0 {
+		return 0
+	}
+	status, _ := r.Errors[0].Extensions["status"].(float64)
+	return int(status)
+}
+
+// Returns the session token the response set in the auth cookie, or "" if it did not set one
+func (r graphQLResponse) sessionToken() string {
+	for _, cookie := range r.cookies {
+		if cookie.Name == auth.SessionCookie && cookie.MaxAge >= 0 {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// The CSRF token every request sends, as the app does
+const testCSRFToken = "test-csrf-token"
+
+// Sends a GraphQL request as the user holding token, or anonymously if token is empty, and decodes
+// the data into out. API tokens are sent in the Authorization header and session tokens as the cookie.
+// Requests carry a CSRF token in both the cookie and the header.
+func query(t *testing.T, token string, q string, variables map[string]interface{}, out interface{}) graphQLResponse {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]interface{}{"query": q, "variables": variables})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, testServer.URL+"/api/internal/graphql", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(auth.CSRFHeader, testCSRFToken)
+	req.AddCookie(&http.Cookie{Name: auth.CSRFCookie, Value: testCSRFToken})
+	if strings.HasPrefix(token, auth.APITokenPrefix) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if token != "" {
+		req.AddCookie(&http.Cookie{Name: auth.SessionCookie, Value: token})
+	}
+
+	return send(t, req, out)
+}
+
+// Sends a GraphQL request and decodes the data into out
+func send(t *testing.T, req *http.Request, out interface{}) graphQLResponse {
+	t.Helper()
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var response graphQLResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	response.cookies = res.Cookies()
+
+	if out != nil && len(response.Data) > 0 && string(response.Data) != "null" {
+		if err := json.Unmarshal(response.Data, out); err != nil {
+			t.Fatalf("decoding %s: %v", response.Data, err)
+		}
+	}
+
+	return response
+}
+
+// Like query, but fails the test if the response has errors
+func mustQuery(t *testing.T, token string, q string, variables map[string]interface{}, out interface{}) graphQLResponse {
+	t.Helper()
+
+	res := query(t, token, q, variables, out)
+	if len(res.Errors) > 0 {
+		t.Fatalf("unexpected errors: %+v", res.Errors)
+	}
+	return res
+}
+
+type loginResponse struct {
+	Login *struct {
+		Success    bool `json:"success"`
+		IsDisabled bool `json:"isDisabled"`
+	} `json:"login"`
+}
+
+const loginMutation = `mutation($username: String!, $password: String!) {
+	login(username: $username, password: $password) { success isDisabled }
+}`
+
+// Logs in as one of the fixture users and returns the session token
+func login(t *testing.T, username string) string {
+	t.Helper()
+
+	var data loginResponse
+	res := mustQuery(t, "", loginMutation, map[string]interface{}{"username": username, "password": fixturePassword}, &data)
+	if data.Login == nil || !data.Login.Success || res.sessionToken() == "" {
+		t.Fatalf("logging in as %s failed: %+v", username, data.Login)
+	}
+	return res.sessionToken()
+}
+
+func TestLogin(t *testing.T) {
+	seed(t)
+
+	tests := []struct {
+		name         string
+		username     string
+		password     string
+		wantSuccess  bool
+		wantDisabled bool
+	}{
+		{"valid credentials", "judge", fixturePassword, true, false},
+		{"wrong password", "judge", "wrong", false, false},
+		{"unknown user", "nobody", fixturePassword, false, false},
+		{"locked account", "locked", fixturePassword, false, true},
+		{"locked account with a wrong password", "locked", "wrong", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data loginResponse
+			res := mustQuery(t, "", loginMutation, map[string]interface{}{"username": tt.username, "password": tt.password}, &data)
+
+			if data.Login.Success != tt.wantSuccess || data.Login.IsDisabled != tt.wantDisabled {
+				t.Fatalf("got success=%v isDisabled=%v, want success=%v isDisabled=%v", data.Login.Success, data.Login.IsDisabled, tt.wantSuccess, tt.wantDisabled)
+			}
+			if tt.wantSuccess != (res.sessionToken() != "") {
+				t.Fatalf("got session cookie %q, want one only on success", res.sessionToken())
+			}
+		})
+	}
+
+	t.Run("token authenticates later requests", func(t *testing.T) {
+		token := login(t, "judge")
+
+		var data struct {
+			CurrentUser struct {
+				LoggedIn bool `json:"loggedIn"`
+				User     *struct {
+					ID string `json:"id"`
+				} `json:"user"`
+			} `json:"currentUser"`
+		}
+		mustQuery(t, token, `{ currentUser { loggedIn user { id } } }`, nil, &data)
+
+		if !data.CurrentUser.LoggedIn || data.CurrentUser.User == nil || data.CurrentUser.User.ID != "2" {
+			t.Fatalf("got %+v, want the judge to be logged in", data.CurrentUser)
+		}
+	})
+
+	t.Run("logged in users cannot log in again", func(t *testing.T) {
+		token := login(t, "judge")
+
+		res := query(t, token, loginMutation, map[string]interface{}{"username": "judge", "password": fixturePassword}, nil)
+		if res.status() != http.StatusForbidden {
+			t.Fatalf("got %+v, want a 403 error", res.Errors)
+		}
+	})
+}
+
+const nextEntryQuery = `{ nextEntryToJudge { id } }`
+
+type nextEntryResponse struct {
+	NextEntryToJudge *struct {
+		ID string `json:"id"`
+	} `json:"nextEntryToJudge"`
+}
+
+func TestNextEntryToJudge(t *testing.T) {
+	seed(t)
+
+	t.Run("returns an entry assigned to the judge's group", func(t *testing.T) {
+		token := login(t, "judge")
+
+		var first nextEntryResponse
+		mustQuery(t, token, nextEntryQuery, nil, &first)
+		if first.NextEntryToJudge == nil {
+			t.Fatal("got no entry, want one of Group A's entries")
+		}
+		if id := first.NextEntryToJudge.ID; id != "1" && id != "2" {
+			t.Fatalf("got entry %s, want entry 1 or 2", id)
+		}
+
+		// The entry is reserved by a placeholder evaluation, so asking again returns it until it is scored
+		var second nextEntryResponse
+		mustQuery(t, token, nextEntryQuery, nil, &second)
+		if second.NextEntryToJudge == nil || second.NextEntryToJudge.ID != first.NextEntryToJudge.ID {
+			t.Fatalf("got %+v, want entry %s again", second.NextEntryToJudge, first.NextEntryToJudge.ID)
+		}
+	})
+
+	t.Run("is forbidden without the judge entries permission", func(t *testing.T) {
+		var data nextEntryResponse
+		res := query(t, login(t, "viewer"), nextEntryQuery, nil, &data)
+		if res.status() != http.StatusForbidden || data.NextEntryToJudge != nil {
+			t.Fatalf("got %+v and %+v, want a 403 error and no entry", data.NextEntryToJudge, res.Errors)
+		}
+	})
+
+	t.Run("is forbidden for anonymous users", func(t *testing.T) {
+		var data nextEntryResponse
+		res := query(t, "", nextEntryQuery, nil, &data)
+		if res.status() != http.StatusForbidden || data.NextEntryToJudge != nil {
+			t.Fatalf("got %+v and %+v, want a 403 error and no entry", data.NextEntryToJudge, res.Errors)
+		}
+	})
+}
+
+const scoreEntryMutation = `mutation($id: ID!, $input: ScoreEntryInput!) {
+	scoreEntry(id: $id, input: $input) { id creativity complexity execution interpretation skillLevel entry { id skillLevel } }
+}`
+
+type scoreEntryResponse struct {
+	ScoreEntry *struct {
+		ID             string  `json:"id"`
+		Creativity     float64 `json:"creativity"`
+		Complexity     float64 `json:"complexity"`
+		Execution      float64 `json:"execution"`
+		Interpretation float64 `json:"interpretation"`
+		SkillLevel     string  `json:"skillLevel"`
+		Entry          struct {
+			ID         string  `json:"id"`
+			SkillLevel *string `json:"skillLevel"`
+		} `json:"entry"`
+	} `json:"scoreEntry"`
+}
+
+func scoreInput(level string) map[string]interface{} {
+	return map[string]interface{}{
+		"creativity":     4,
+		"complexity":     3.5,
+		"execution":      5,
+		"interpretation": 2,
+		"skillLevel":     level,
+	}
+}
+
+func TestScoreEntry(t *testing.T) {
+	seed(t)
+
+	t.Run("judges every entry in their group once", func(t *testing.T) {
+		token := login(t, "judge")
+		scored := map[string]bool{}
+
+		for i := 0; i < 3; i++ {
+			var next nextEntryResponse
+			mustQuery(t, token, nextEntryQuery, nil, &next)
+			if next.NextEntryToJudge == nil {
+				break
+			}
+
+			id := next.NextEntryToJudge.ID
+			if scored[id] {
+				t.Fatalf("entry %s was returned again after it was scored", id)
+			}
+
+			var data scoreEntryResponse
+			mustQuery(t, token, scoreEntryMutation, map[string]interface{}{"id": id, "input": scoreInput("Intermediate")}, &data)
+
+			evaluation := data.ScoreEntry
+			if evaluation == nil || evaluation.Entry.ID != id {
+				t.Fatalf("got %+v, want an evaluation of entry %s", evaluation, id)
+			}
+			if evaluation.Creativity != 4 || evaluation.Complexity != 3.5 || evaluation.Execution != 5 || evaluation.Interpretation != 2 || evaluation.SkillLevel != "Intermediate" {
+				t.Fatalf("got %+v, want the submitted scores", evaluation)
+			}
+
+			// update_entry_level sets the entry to the level suggested by its evaluations
+			if evaluation.Entry.SkillLevel == nil || *evaluation.Entry.SkillLevel != "Intermediate" {
+				t.Fatalf("got entry skill level %v, want Intermediate", evaluation.Entry.SkillLevel)
+			}
+
+			scored[id] = true
+		}
+
+		if len(scored) != 2 || !scored["1"] || !scored["2"] {
+			t.Fatalf("scored entries %v, want entries 1 and 2", scored)
+		}
+
+		var completed int
+		if err := db.DB.QueryRow("SELECT COUNT(*) FROM evaluation WHERE evaluator_id = 2 AND evaluation_complete = true;").Scan(&completed); err != nil {
+			t.Fatal(err)
+		}
+		if completed != 2 {
+			t.Fatalf("got %d completed evaluations, want 2", completed)
+		}
+	})
+
+	t.Run("scoring again replaces the evaluation", func(t *testing.T) {
+		token := login(t, "judge")
+
+		var first, second scoreEntryResponse
+		mustQuery(t, token, scoreEntryMutation, map[string]interface{}{"id": "3", "input": scoreInput("Beginner")}, &first)
+		mustQuery(t, token, scoreEntryMutation, map[string]interface{}{"id": "3", "input": scoreInput("Advanced")}, &second)
+
+		if first.ScoreEntry == nil || second.ScoreEntry == nil || first.ScoreEntry.ID != second.ScoreEntry.ID {
+			t.Fatalf("got evaluations %+v and %+v, want the same evaluation", first.ScoreEntry, second.ScoreEntry)
+		}
+		if second.ScoreEntry.SkillLevel != "Advanced" {
+			t.Fatalf("got skill level %s, want Advanced", second.ScoreEntry.SkillLevel)
+		}
+	})
+
+	t.Run("requires the judge entries permission", func(t *testing.T) {
+		for _, token := range []string{"", login(t, "viewer")} {
+			res := query(t, token, scoreEntryMutation, map[string]interface{}{"id": "1", "input": scoreInput("Beginner")}, nil)
+			if res.status() != http.StatusForbidden {
+				t.Fatalf("got %+v, want a 403 error", res.Errors)
+			}
+		}
+	})
+}
+
+func TestAssignAllEntriesToGroups(t *testing.T) {
+	seed(t)
+
+	const mutation = `mutation($contestId: ID!) { assignAllEntriesToGroups(contestId: $contestId) }`
+
+	t.Run("requires the assign entry groups permission", func(t *testing.T) {
+		for _, token := range []string{"", login(t, "judge")} {
+			res := query(t, token, mutation, map[string]interface{}{"contestId": "2"}, nil)
+			if res.status() != http.StatusForbidden {
+				t.Fatalf("got %+v, want a 403 error", res.Errors)
+			}
+		}
+	})
+
+	t.Run("splits entries evenly between active groups", func(t *testing.T) {
+		var data struct {
+			AssignAllEntriesToGroups bool `json:"assignAllEntriesToGroups"`
+		}
+		mustQuery(t, login(t, "organizer"), mutation, map[string]interface{}{"contestId": "2"}, &data)
+		if !data.AssignAllEntriesToGroups {
+			t.Fatal("got false, want true")
+		}
+
+		rows, err := db.DB.Query("SELECT entry_id, assigned_group_id FROM entry WHERE contest_id = 2 ORDER BY entry_id;")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+
+		got := map[int]*int{}
+		for rows.Next() {
+			var id int
+			var group *int
+			if err := rows.Scan(&id, &group); err != nil {
+				t.Fatal(err)
+			}
+			got[id] = group
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Entries are handed out in id order, and disqualified entries and inactive groups are skipped
+		want := map[int]int{5: 1, 6: 1, 7: 2, 8: 2}
+		for id, group := range want {
+			if got[id] == nil || *got[id] != group {
+				t.Errorf("entry %d: got group %v, want %d", id, got[id], group)
+			}
+		}
+		if got[9] != nil {
+			t.Errorf("disqualified entry 9: got group %d, want none", *got[9])
+		}
+	})
+
+	t.Run("leaves other contests alone", func(t *testing.T) {
+		var group int
+		if err := db.DB.QueryRow("SELECT assigned_group_id FROM entry WHERE entry_id = 3;").Scan(&group); err != nil {
+			t.Fatal(err)
+		}
+		if group != 2 {
+			t.Fatalf("got group %d, want 2", group)
+		}
+	})
+}
+
+func TestPublishArticle(t *testing.T) {
+	seed(t)
+
+	const mutation = `mutation($id: ID!) { publishArticle(id: $id) { id title content isPublished } }`
+
+	type publishResponse struct {
+		PublishArticle *struct {
+			ID          string `json:"id"`
+			Title       string `json:"title"`
+			Content     string `json:"content"`
+			IsPublished *bool  `json:"isPublished"`
+		} `json:"publishArticle"`
+	}
+
+	t.Run("requires the publish KB content permission", func(t *testing.T) {
+		for _, token := range []string{"", login(t, "judge")} {
+			res := query(t, token, mutation, map[string]interface{}{"id": "1"}, nil)
+			if res.status() != http.StatusForbidden {
+				t.Fatalf("got %+v, want a 403 error", res.Errors)
+			}
+		}
+	})
+
+	t.Run("copies the draft into the article", func(t *testing.T) {
+		var data publishResponse
+		mustQuery(t, login(t, "editor"), mutation, map[string]interface{}{"id": "1"}, &data)
+
+		article := data.PublishArticle
+		if article == nil || article.Title != "Scoring Guide (Revised)" || article.Content != "New content" {
+			t.Fatalf("got %+v, want the draft's title and content", article)
+		}
+		if article.IsPublished == nil || !*article.IsPublished {
+			t.Fatal("got an unpublished article, want it published")
+		}
+
+		var draftPublished bool
+		if err := db.DB.QueryRow("SELECT is_published FROM kb_article_draft WHERE draft_id = 1;").Scan(&draftPublished); err != nil {
+			t.Fatal(err)
+		}
+		if !draftPublished {
+			t.Fatal("got an unpublished draft, want it marked as published")
+		}
+	})
+
+	t.Run("fails when there is no unpublished draft", func(t *testing.T) {
+		token := login(t, "editor")
+
+		// Article 1's draft was published above, and article 2's draft was already published
+		for _, id := range []string{"1", "2"} {
+			res := query(t, token, mutation, map[string]interface{}{"id": id}, nil)
+			if res.status() != http.StatusNotFound {
+				t.Fatalf("article %s: got %+v, want a 404 error", id, res.Errors)
+			}
+		}
+	})
+}
+
+// Admins pass every permission check, and other users only the ones they were granted
+func TestPermissionChecks(t *testing.T) {
+	seed(t)
+
+	checks := []struct {
+		name     string
+		mutation string
+		vars     map[string]interface{}
+		allowed  []string
+	}{
+		{"score entry", scoreEntryMutation, map[string]interface{}{"id": "3", "input": scoreInput("Beginner")}, []string{"admin", "judge"}},
+		{"assign entry groups", `mutation { assignAllEntriesToGroups(contestId: 2) }`, nil, []string{"admin", "organizer"}},
+		{"unpublish article", `mutation { unpublishArticle(id: 2) { id } }`, nil, []string{"admin", "editor"}},
+	}
+
+	users := []string{"admin", "judge", "viewer", "editor", "organizer"}
+	tokens := map[string]string{}
+	for _, username := range users {
+		tokens[username] = login(t, username)
+	}
+
+	for _, check := range checks {
+		for _, username := range users {
+			t.Run(fmt.Sprintf("%s as %s", check.name, username), func(t *testing.T) {
+				allowed := false
+				for _, a := range check.allowed {
+					allowed = allowed || a == username
+				}
+
+				res := query(t, tokens[username], check.mutation, check.vars, nil)
+				if allowed && len(res.Errors) > 0 {
+					t.Fatalf("got %+v, want the request to be allowed", res.Errors)
+				}
+				if !allowed && res.status() != http.StatusForbidden {
+					t.Fatalf("got %+v, want a 403 error", res.Errors)
+				}
+			})
+		}
+	}
+}
+
+// Users hold the permissions of their roles, so changing a role changes what its holders can do
+func TestRoles(t *testing.T) {
+	seed(t)
+	admin := login(t, "admin")
+
+	t.Run("assigning a role grants its permissions", func(t *testing.T) {
+		viewer := login(t, "viewer")
+		mustQuery(t, admin, `mutation { setUserRoles(userId: 3, roleIds: [1]) { id } }`, nil, nil)
+
+		var data nextEntryResponse
+		mustQuery(t, viewer, nextEntryQuery, nil, &data)
+		if data.NextEntryToJudge == nil {
+			t.Fatal("got no entry, want one after being given the Evaluator role")
+		}
+	})
+
+	t.Run("editing a role updates its holders", func(t *testing.T) {
+		editor := login(t, "editor")
+		mustQuery(t, admin, `mutation { editRole(id: 2, input: { name: "KB Editor", description: "Edits KB content", permissions: ["EDIT_KB_CONTENT"] }) { id } }`, nil, nil)
+
+		res := query(t, editor, `mutation { unpublishArticle(id: 2) { id } }`, nil, nil)
+		if res.status() != http.StatusForbidden {
+			t.Fatalf("got %+v, want a 403 error after the role lost PUBLISH_KB_CONTENT", res.Errors)
+		}
+	})
+
+	t.Run("rejects unknown permissions", func(t *testing.T) {
+		res := query(t, admin, `mutation { createRole(input: { name: "Broken", description: "", permissions: ["NOT_A_PERMISSION"] }) { id } }`, nil, nil)
+		if res.status() != http.StatusBadRequest {
+			t.Fatalf("got %+v, want a 400 error", res.Errors)
+		}
+	})
+
+	t.Run("only admins manage roles", func(t *testing.T) {
+		res := query(t, login(t, "organizer"), `mutation { setUserRoles(userId: 6, roleIds: [1, 2]) { id } }`, nil, nil)
+		if res.status() != http.StatusForbidden {
+			t.Fatalf("got %+v, want a 403 error", res.Errors)
+		}
+	})
+}
+
+const editUserPermissionsMutation = `mutation($id: ID!, $permissions: [String!]!) {
+	editUserPermissions(id: $id, permissions: $permissions) { id permissionKeys }
+}`
+
+// Users can only grant permissions they hold and only act on users with no more access than them
+func TestPrivilegeEscalation(t *testing.T) {
+	seed(t)
+	manager := login(t, "manager")
+
+	tests := []struct {
+		name     string
+		mutation string
+		vars     map[string]interface{}
+		status   int
+	}{
+		{"grant a permission the manager holds", editUserPermissionsMutation, map[string]interface{}{"id": "3", "permissions": []string{"JUDGE_ENTRIES"}}, 0},
+		{"grant a permission the manager does not hold", editUserPermissionsMutation, map[string]interface{}{"id": "3", "permissions": []string{"ADD_USERS"}}, http.StatusForbidden},
+		{"grant permissions to themselves", editUserPermissionsMutation, map[string]interface{}{"id": "7", "permissions": []string{"EDIT_USER_PROFILES", "ADD_USERS"}}, http.StatusForbidden},
+		{"edit the permissions of a user with other permissions", editUserPermissionsMutation, map[string]interface{}{"id": "5", "permissions": []string{}}, http.StatusForbidden},
+		{"edit the permissions of an admin", editUserPermissionsMutation, map[string]interface{}{"id": "1", "permissions": []string{}}, http.StatusForbidden},
+		{"make a user an admin", `mutation { editUserProfile(id: 3, input: { name: "Viewer User", kaid: "kaid_viewer", username: "viewer", nickname: "Viewer", termStart: "2022-01-01", isAdmin: true, accountLocked: false, notificationsEnabled: false }) { id } }`, nil, http.StatusForbidden},
+		{"make themselves an admin", `mutation { editUserProfile(id: 7, input: { name: "Manager User", kaid: "kaid_manager", username: "manager", nickname: "Manager", termStart: "2022-01-01", isAdmin: true, accountLocked: false, notificationsEnabled: false }) { id } }`, nil, http.StatusForbidden},
+		{"change the password of a user with fewer permissions", `mutation { changePassword(id: 2, password: "new password") }`, nil, 0},
+		{"change the password of an admin", `mutation { changePassword(id: 1, password: "new password") }`, nil, http.StatusForbidden},
+		{"impersonate a user with other permissions", `mutation { impersonateUser(id: 6, reason: "Support request") { success } }`, nil, http.StatusForbidden},
+		{"impersonate an admin", `mutation { impersonateUser(id: 1, reason: "Support request") { success } }`, nil, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := query(t, manager, tt.mutation, tt.vars, nil)
+			if res.status() != tt.status {
+				t.Fatalf("got %+v, want status %d", res.Errors, tt.status)
+			}
+		})
+	}
+
+	// Permission keys must be defined permissions, even for admins
+	res := query(t, login(t, "admin"), editUserPermissionsMutation, map[string]interface{}{"id": "3", "permissions": []string{"JUDGE_ENTRIES", "NOT_A_PERMISSION"}}, nil)
+	if res.status() != http.StatusBadRequest {
+		t.Fatalf("got %+v, want a 400 error for an unknown permission", res.Errors)
+	}
+}
+
+// Fields marked with @authenticated or @hasPermission fail with a forbidden error for other users
+func TestDirectives(t *testing.T) {
+	seed(t)
+
+	tests := []struct {
+		name   string
+		user   string
+		query  string
+		status int
+	}{
+		{"authenticated field as an anonymous user", "", `{ activeJudgingGroups { id } }`, http.StatusForbidden},
+		{"authenticated field as a user", "viewer", `{ activeJudgingGroups { id } }`, 0},
+		{"permission field without the permission", "viewer", `{ entryCounts { total } }`, http.StatusForbidden},
+		{"permission field as an anonymous user", "", `{ entryCounts { total } }`, http.StatusForbidden},
+		{"permission field as an admin", "admin", `{ entryCounts { total } }`, 0},
+		{"single role without the permission", "viewer", `{ role(id: 1) { id } }`, http.StatusForbidden},
+		{"single error without the permission", "viewer", `{ error(id: 1) { id } }`, http.StatusForbidden},
+		{"judging stats without the permission", "viewer", `{ judgingProgress { evaluators { count } } }`, http.StatusForbidden},
+		{"own judging progress without the permission", "viewer", `{ judgingProgress { user { count } } }`, 0},
+		{"system status as a non-admin", "manager", `{ systemStatus { version } }`, http.StatusForbidden},
+		{"system status as an admin", "admin", `{ systemStatus { version } }`, 0},
+		{"login lockouts as a non-admin", "manager", `{ loginLockouts { id } }`, http.StatusForbidden},
+		{"skill level review as a non-admin", "manager", `{ nextEntryToReviewSkillLevel { id } }`, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := ""
+			if tt.user != "" {
+				token = login(t, tt.user)
+			}
+
+			res := query(t, token, tt.query, nil, nil)
+			if res.status() != tt.status {
+				t.Fatalf("got %+v, want status %d", res.Errors, tt.status)
+			}
+		})
+	}
+}
+
+const mySessionsQuery = `{ mySessions { id device ipAddress lastSeenAt isCurrent } }`
+
+type mySessionsResponse struct {
+	MySessions []struct {
+		ID        string  `json:"id"`
+		Device    string  `json:"device"`
+		IPAddress *string `json:"ipAddress"`
+		IsCurrent bool    `json:"isCurrent"`
+	} `json:"mySessions"`
+}
+
+// Each login is its own session, which can be listed and ended without affecting the others
+func TestSessions(t *testing.T) {
+	seed(t)
+
+	laptop := login(t, "judge")
+	phone := login(t, "judge")
+
+	var data mySessionsResponse
+	mustQuery(t, laptop, mySessionsQuery, nil, &data)
+	if len(data.MySessions) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(data.MySessions))
+	}
+
+	var other string
+	for _, session := range data.MySessions {
+		if session.IPAddress == nil || *session.IPAddress == "" {
+			t.Errorf("session %s has no IP address", session.ID)
+		}
+		if !session.IsCurrent {
+			other = session.ID
+		}
+	}
+	if other == "" {
+		t.Fatalf("got %+v, want exactly one current session", data.MySessions)
+	}
+
+	t.Run("revoking a session logs that device out", func(t *testing.T) {
+		mustQuery(t, laptop, `mutation($id: ID!) { revokeSession(id: $id) }`, map[string]interface{}{"id": other}, nil)
+
+		res := query(t, phone, mySessionsQuery, nil, nil)
+		if res.status() != http.StatusForbidden {
+			t.Fatalf("got %+v, want the revoked session to be logged out", res.Errors)
+		}
+		mustQuery(t, laptop, mySessionsQuery, nil, nil)
+	})
+
+	t.Run("users cannot revoke other users' sessions", func(t *testing.T) {
+		var admin mySessionsResponse
+		mustQuery(t, login(t, "admin"), mySessionsQuery, nil, &admin)
+
+		res := query(t, laptop, `mutation($id: ID!) { revokeSession(id: $id) }`, map[string]interface{}{"id": admin.MySessions[0].ID}, nil)
+		if res.status() != http.StatusNotFound {
+			t.Fatalf("got %+v, want a 404 error", res.Errors)
+		}
+	})
+
+	t.Run("logging out only ends the current session", func(t *testing.T) {
+		tablet := login(t, "judge")
+		mustQuery(t, tablet, `mutation { logout }`, nil, nil)
+
+		mustQuery(t, laptop, mySessionsQuery, nil, nil)
+	})
+
+	t.Run("only admins can revoke every session of a user", func(t *testing.T) {
+		res := query(t, laptop, `mutation { revokeAllSessions(userId: 2) }`, nil, nil)
+		if res.status() != http.StatusForbidden {
+			t.Fatalf("got %+v, want a 403 error", res.Errors)
+		}
+
+		mustQuery(t, login(t, "admin"), `mutation { revokeAllSessions(userId: 2) }`, nil, nil)
+		if res := query(t, laptop, mySessionsQuery, nil, nil); res.status() != http.StatusForbidden {
+			t.Fatalf("got %+v, want every session of the judge to be logged out", res.Errors)
+		}
+	})
+}
+
+// Too many failed logins lock the username out until the lockout ends or an admin clears it
+func TestLoginThrottling(t *testing.T) {
+	seed(t)
+
+	for i := 0; i < 5; i++ {
+		mustQuery(t, "", loginMutation, map[string]interface{}{"username": "judge", "password": "wrong"}, nil)
+	}
+
+	res := query(t, "", loginMutation, map[string]interface{}{"username": "Judge", "password": fixturePassword}, nil)
+	if res.status() != http.StatusTooManyRequests {
+		t.Fatalf("got %+v, want a 429 error even with the right password", res.Errors)
+	}
+
+	// Unknown usernames are locked out the same way, so lockouts do not reveal which accounts exist
+	for i := 0; i < 5; i++ {
+		mustQuery(t, "", loginMutation, map[string]interface{}{"username": "nobody", "password": "wrong"}, nil)
+	}
+	if res := query(t, "", loginMutation, map[string]interface{}{"username": "nobody", "password": "wrong"}, nil); res.status() != http.StatusTooManyRequests {
+		t.Fatalf("got %+v, want a 429 error for an unknown username", res.Errors)
+	}
+
+	var data struct {
+		LoginLockouts []struct {
+			ID             string `json:"id"`
+			Kind           string `json:"kind"`
+			Value          string `json:"value"`
+			FailedAttempts int    `json:"failedAttempts"`
+		} `json:"loginLockouts"`
+	}
+	admin := login(t, "admin")
+	mustQuery(t, admin, `{ loginLockouts { id kind value failedAttempts } }`, nil, &data)
+
+	judgeLockout := ""
+	for _, lockout := range data.LoginLockouts {
+		if lockout.Kind == "USERNAME" && lockout.Value == "judge" {
+			judgeLockout = lockout.ID
+			if lockout.FailedAttempts != 5 {
+				t.Errorf("got %d failed attempts, want 5", lockout.FailedAttempts)
+			}
+		}
+	}
+	if judgeLockout == "" {
+		t.Fatalf("got %+v, want a lockout for the judge", data.LoginLockouts)
+	}
+
+	if res := query(t, login(t, "viewer"), `mutation($id: ID!) { clearLoginLockout(id: $id) }`, map[string]interface{}{"id": judgeLockout}, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error", res.Errors)
+	}
+
+	mustQuery(t, admin, `mutation($id: ID!) { clearLoginLockout(id: $id) }`, map[string]interface{}{"id": judgeLockout}, nil)
+	login(t, "judge")
+}
+
+func TestLoginWithoutPassword(t *testing.T) {
+	seed(t)
+	if _, err := db.DB.Exec("UPDATE evaluator SET password = NULL WHERE evaluator_id = 3;"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Looks like a wrong password, so it does not reveal that the account exists
+	var data loginResponse
+	mustQuery(t, "", loginMutation, map[string]interface{}{"username": "viewer", "password": fixturePassword}, &data)
+	if data.Login.Success || data.Login.IsDisabled {
+		t.Fatalf("got %+v, want a failed login", data.Login)
+	}
+
+	var failed int
+	if err := db.DB.QueryRow("SELECT failed_count FROM login_throttle WHERE throttle_kind = 'USERNAME' AND throttle_value = 'viewer';").Scan(&failed); err != nil || failed != 1 {
+		t.Fatalf("got %d failed attempts (%v), want the failure counted", failed, err)
+	}
+}
+
+// Waits for the newest email sent to the address and returns the token from the password reset link in it
+func waitForResetToken(t *testing.T, to string) string {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		files, err := filepath.Glob(filepath.Join(testMailDir, "*-"+to+".eml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(files) > 0 {
+			sort.Strings(files)
+			data, err := os.ReadFile(files[len(files)-1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if match := resetLinkPattern.FindSubmatch(data); match != nil {
+				return string(match[1])
+			}
+			t.Fatalf("no reset link in %s", data)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Fatalf("no email was sent to %s", to)
+	return ""
+}
+
+var resetLinkPattern = regexp.MustCompile(`http://localhost/reset-password\?token=([A-Za-z0-9_-]+)`)
+
+const resetPasswordMutation = `mutation($token: String!, $password: String!) { resetPassword(token: $token, newPassword: $password) }`
+
+func TestPasswordReset(t *testing.T) {
+	seed(t)
+
+	session := login(t, "judge")
+
+	// Accounts without a username cannot log in, so they are not sent a link
+	if _, err := db.DB.Exec("UPDATE evaluator SET username = NULL WHERE evaluator_id = 3;"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every address gets the same answer, whether or not an unlocked account uses it
+	for _, email := range []string{"Judge@Example.com", "nobody@example.com", "locked@example.com", "viewer@example.com"} {
+		var data struct {
+			RequestPasswordReset bool `json:"requestPasswordReset"`
+		}
+		mustQuery(t, "", `mutation($email: String!) { requestPasswordReset(email: $email) }`, map[string]interface{}{"email": email}, &data)
+		if !data.RequestPasswordReset {
+			t.Fatalf("requestPasswordReset(%q) = false, want true", email)
+		}
+	}
+	token := waitForResetToken(t, "judge@example.com")
+
+	for _, email := range []string{"locked@example.com", "viewer@example.com"} {
+		if files, _ := filepath.Glob(filepath.Join(testMailDir, "*-"+email+".eml")); len(files) > 0 {
+			t.Fatalf("a reset link was sent to %s", email)
+		}
+	}
+
+	if res := query(t, "", resetPasswordMutation, map[string]interface{}{"token": token + "x", "password": "new password"}, nil); res.status() != http.StatusBadRequest {
+		t.Fatalf("got %+v, want a 400 error for a wrong token", res.Errors)
+	}
+
+	mustQuery(t, "", resetPasswordMutation, map[string]interface{}{"token": token, "password": "new password"}, nil)
+
+	// The link only works once
+	if res := query(t, "", resetPasswordMutation, map[string]interface{}{"token": token, "password": "another password"}, nil); res.status() != http.StatusBadRequest {
+		t.Fatalf("got %+v, want a 400 error for a used token", res.Errors)
+	}
+
+	// Sessions from before the reset are ended and only the new password works
+	var current struct {
+		CurrentUser struct {
+			LoggedIn bool `json:"loggedIn"`
+		} `json:"currentUser"`
+	}
+	mustQuery(t, session, `{ currentUser { loggedIn } }`, nil, &current)
+	if current.CurrentUser.LoggedIn {
+		t.Fatal("a session from before the reset is still logged in")
+	}
+
+	var data loginResponse
+	mustQuery(t, "", loginMutation, map[string]interface{}{"username": "judge", "password": fixturePassword}, &data)
+	if data.Login.Success {
+		t.Fatal("the old password still works")
+	}
+	mustQuery(t, "", loginMutation, map[string]interface{}{"username": "judge", "password": "new password"}, &data)
+	if !data.Login.Success {
+		t.Fatal("the new password does not work")
+	}
+}
+
+type twoFactorLoginResponse struct {
+	Success           bool    `json:"success"`
+	TwoFactorRequired bool    `json:"twoFactorRequired"`
+	PendingToken      *string `json:"pendingToken"`
+	// The session token from the auth cookie
+	Session string `json:"-"`
+}
+
+// Logs in with the fixture password and returns the pending token for the second step
+func beginTwoFactorLogin(t *testing.T, username string) string {
+	t.Helper()
+
+	var data struct {
+		Login twoFactorLoginResponse `json:"login"`
+	}
+	res := mustQuery(t, "", `mutation($username: String!, $password: String!) {
+		login(username: $username, password: $password) { success twoFactorRequired pendingToken }
+	}`, map[string]interface{}{"username": username, "password": fixturePassword}, &data)
+	if data.Login.Success || res.sessionToken() != "" || !data.Login.TwoFactorRequired || data.Login.PendingToken == nil {
+		t.Fatalf("got %+v, want a pending two-factor login", data.Login)
+	}
+	return *data.Login.PendingToken
+}
+
+func verifyTwoFactorLogin(t *testing.T, pendingToken string, code string) twoFactorLoginResponse {
+	t.Helper()
+
+	var data struct {
+		VerifyTwoFactorLogin twoFactorLoginResponse `json:"verifyTwoFactorLogin"`
+	}
+	res := mustQuery(t, "", `mutation($pendingToken: String!, $code: String!) {
+		verifyTwoFactorLogin(pendingToken: $pendingToken, code: $code) { success }
+	}`, map[string]interface{}{"pendingToken": pendingToken, "code": code}, &data)
+	data.VerifyTwoFactorLogin.Session = res.sessionToken()
+	return data.VerifyTwoFactorLogin
+}
+
+func TestTwoFactor(t *testing.T) {
+	seed(t)
+
+	manager := login(t, "manager")
+
+	var enrollment struct {
+		BeginTwoFactorEnrollment struct {
+			Secret          string `json:"secret"`
+			ProvisioningURI string `json:"provisioningUri"`
+		} `json:"beginTwoFactorEnrollment"`
+	}
+	mustQuery(t, manager, `mutation { beginTwoFactorEnrollment { secret provisioningUri } }`, nil, &enrollment)
+	secret := enrollment.BeginTwoFactorEnrollment.Secret
+	if !strings.HasPrefix(enrollment.BeginTwoFactorEnrollment.ProvisioningURI, "otpauth://totp/") || !strings.Contains(enrollment.BeginTwoFactorEnrollment.ProvisioningURI, "secret="+secret) {
+		t.Fatalf("got provisioning URI %q", enrollment.BeginTwoFactorEnrollment.ProvisioningURI)
+	}
+
+	// A pending secret is not used for logging in until it is confirmed
+	login(t, "manager")
+
+	const confirmMutation = `mutation($code: String!) { confirmTwoFactorEnrollment(code: $code) }`
+	if res := query(t, manager, confirmMutation, map[string]interface{}{"code": "000000"}, nil); res.status() != http.StatusBadRequest {
+		t.Fatalf("got %+v, want a 400 error for a wrong code", res.Errors)
+	}
+
+	now := time.Now()
+	code, err := auth.TOTPCode(secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var confirmed struct {
+		ConfirmTwoFactorEnrollment []string `json:"confirmTwoFactorEnrollment"`
+	}
+	mustQuery(t, manager, confirmMutation, map[string]interface{}{"code": code}, &confirmed)
+	recoveryCodes := confirmed.ConfirmTwoFactorEnrollment
+	if len(recoveryCodes) != auth.RecoveryCodeCount {
+		t.Fatalf("got %d recovery codes, want %d", len(recoveryCodes), auth.RecoveryCodeCount)
+	}
+
+	// Logging in now takes a second step, and wrong or replayed codes are refused
+	pending := beginTwoFactorLogin(t, "manager")
+	if res := verifyTwoFactorLogin(t, pending, "000000"); res.Success {
+		t.Fatal("a wrong code was accepted")
+	}
+	if res := verifyTwoFactorLogin(t, pending, code); res.Success {
+		t.Fatal("the code used to confirm enrollment was accepted again")
+	}
+	res := verifyTwoFactorLogin(t, pending, recoveryCodes[0])
+	if !res.Success || res.Session == "" {
+		t.Fatalf("got %+v, want the recovery code to finish logging in", res)
+	}
+	if res := query(t, "", `mutation($pendingToken: String!) { verifyTwoFactorLogin(pendingToken: $pendingToken, code: "000000") { success } }`, map[string]interface{}{"pendingToken": pending}, nil); res.status() != http.StatusBadRequest {
+		t.Fatalf("got %+v, want a 400 error for a used pending token", res.Errors)
+	}
+
+	if res := verifyTwoFactorLogin(t, beginTwoFactorLogin(t, "manager"), recoveryCodes[0]); res.Success {
+		t.Fatal("a used recovery code was accepted")
+	}
+
+	nextCode, err := auth.TOTPCode(secret, now.Add(30*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := verifyTwoFactorLogin(t, beginTwoFactorLogin(t, "manager"), nextCode); !res.Success {
+		t.Fatal("a code from the authenticator was refused")
+	}
+
+	// Accounts locked between the two steps are refused
+	pending = beginTwoFactorLogin(t, "manager")
+	if _, err := db.DB.Exec("UPDATE evaluator SET account_locked = true WHERE evaluator_id = 7;"); err != nil {
+		t.Fatal(err)
+	}
+	if res := verifyTwoFactorLogin(t, pending, "000000"); res.Success || res.Session != "" {
+		t.Fatalf("got %+v, want the locked account refused", res)
+	}
+	if _, err := db.DB.Exec("UPDATE evaluator SET account_locked = false WHERE evaluator_id = 7;"); err != nil {
+		t.Fatal(err)
+	}
+
+	var status struct {
+		TwoFactorStatus struct {
+			Enabled                bool `json:"enabled"`
+			SetupRequired          bool `json:"setupRequired"`
+			RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+		} `json:"twoFactorStatus"`
+	}
+	mustQuery(t, manager, `{ twoFactorStatus { enabled setupRequired recoveryCodesRemaining } }`, nil, &status)
+	if !status.TwoFactorStatus.Enabled || status.TwoFactorStatus.RecoveryCodesRemaining != auth.RecoveryCodeCount-1 {
+		t.Fatalf("got %+v, want enabled with one recovery code used", status.TwoFactorStatus)
+	}
+
+	// Requiring two-factor authentication for a permission withholds it, and admin rights, from
+	// holders who have not enabled it
+	const policyMutation = `mutation($permissions: [String!]!) { setTwoFactorRequiredPermissions(permissions: $permissions) }`
+	admin := login(t, "admin")
+	if res := query(t, manager, policyMutation, map[string]interface{}{"permissions": []string{}}, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error", res.Errors)
+	}
+	if res := query(t, admin, policyMutation, map[string]interface{}{"permissions": []string{"NOT_A_PERMISSION"}}, nil); res.status() != http.StatusBadRequest {
+		t.Fatalf("got %+v, want a 400 error for an unknown permission", res.Errors)
+	}
+	mustQuery(t, admin, policyMutation, map[string]interface{}{"permissions": []string{"ASSUME_USER_IDENTITIES"}}, nil)
+
+	var current struct {
+		CurrentUser struct {
+			IsAdmin                bool `json:"isAdmin"`
+			TwoFactorSetupRequired bool `json:"twoFactorSetupRequired"`
+		} `json:"currentUser"`
+	}
+	mustQuery(t, admin, `{ currentUser { isAdmin twoFactorSetupRequired } }`, nil, &current)
+	if current.CurrentUser.IsAdmin || !current.CurrentUser.TwoFactorSetupRequired {
+		t.Fatalf("got %+v, want admin rights withheld until two-factor authentication is enabled", current.CurrentUser)
+	}
+	if res := query(t, admin, `mutation { resetTwoFactor(userId: 7) }`, nil, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error", res.Errors)
+	}
+
+	// The manager has enabled it, so keeps the permission but cannot turn it off
+	mustQuery(t, manager, `mutation { impersonateUser(id: 3, reason: "Support request") { success } }`, nil, nil)
+	if res := query(t, manager, `mutation { disableTwoFactor(code: "000000") }`, nil, nil); res.status() != http.StatusBadRequest {
+		t.Fatalf("got %+v, want a 400 error", res.Errors)
+	}
+}
+
+const createAPITokenMutation = `mutation($input: CreateApiTokenInput!) {
+	createApiToken(input: $input) { token apiToken { id prefix permissions } }
+}`
+
+// Creates an API token for the user holding the session token and returns it
+func createAPIToken(t *testing.T, session string, permissions ...string) string {
+	t.Helper()
+
+	var data struct {
+		CreateAPIToken struct {
+			Token    string `json:"token"`
+			APIToken struct {
+				Prefix string `json:"prefix"`
+			} `json:"apiToken"`
+		} `json:"createApiToken"`
+	}
+	mustQuery(t, session, createAPITokenMutation, map[string]interface{}{"input": map[string]interface{}{"name": "script", "permissions": permissions}}, &data)
+	if !strings.HasPrefix(data.CreateAPIToken.Token, data.CreateAPIToken.APIToken.Prefix) {
+		t.Fatalf("got %+v, want the prefix to start the token", data.CreateAPIToken)
+	}
+	return data.CreateAPIToken.Token
+}
+
+func TestAPITokens(t *testing.T) {
+	seed(t)
+
+	judge := login(t, "judge")
+
+	if res := query(t, judge, createAPITokenMutation, map[string]interface{}{"input": map[string]interface{}{"name": "import", "permissions": []string{"ADD_USERS"}}}, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error for a permission the user does not hold", res.Errors)
+	}
+	if res := query(t, judge, createAPITokenMutation, map[string]interface{}{"input": map[string]interface{}{"name": "import", "permissions": []string{}, "expiresAt": "2020-01-01T00:00:00Z"}}, nil); res.status() != http.StatusBadRequest {
+		t.Fatalf("got %+v, want a 400 error for an expiry in the past", res.Errors)
+	}
+
+	// Tokens can only use the permissions they are scoped to
+	judging := createAPIToken(t, judge, "JUDGE_ENTRIES")
+	unscoped := createAPIToken(t, judge)
+	var entry nextEntryResponse
+	mustQuery(t, judging, nextEntryQuery, nil, &entry)
+	if entry.NextEntryToJudge == nil {
+		t.Fatal("got no entry, want the token to be able to judge")
+	}
+	if res := query(t, unscoped, nextEntryQuery, nil, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error for a token without the permission", res.Errors)
+	}
+	if res := query(t, "bema_not-a-token", nextEntryQuery, nil, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error for an unknown token", res.Errors)
+	}
+
+	// Admins' tokens do not have admin rights
+	var current struct {
+		CurrentUser struct {
+			IsAdmin  bool `json:"isAdmin"`
+			LoggedIn bool `json:"loggedIn"`
+		} `json:"currentUser"`
+	}
+	mustQuery(t, createAPIToken(t, login(t, "admin"), "VIEW_ALL_USERS"), `{ currentUser { isAdmin loggedIn } }`, nil, &current)
+	if !current.CurrentUser.LoggedIn || current.CurrentUser.IsAdmin {
+		t.Fatalf("got %+v, want a logged in user without admin rights", current.CurrentUser)
+	}
+
+	// Tokens cannot be used to manage credentials
+	if res := query(t, judging, createAPITokenMutation, map[string]interface{}{"input": map[string]interface{}{"name": "more", "permissions": []string{}}}, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error when creating a token with a token", res.Errors)
+	}
+	if res := query(t, judging, `mutation { changePassword(id: 2, password: "new password") }`, nil, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error when changing a password with a token", res.Errors)
+	}
+	if res := query(t, judging, `mutation { editUserProfile(id: 2, input: { name: "Judge User", email: "attacker@example.com", kaid: "kaid_judge", username: "judge", nickname: "Judge", termStart: "2022-01-01", isAdmin: false, accountLocked: false, notificationsEnabled: false }) { id } }`, nil, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error when editing a profile with a token", res.Errors)
+	}
+
+	var tokens struct {
+		MyAPITokens []struct {
+			ID         string     `json:"id"`
+			LastUsedAt *time.Time `json:"lastUsedAt"`
+		} `json:"myApiTokens"`
+	}
+	mustQuery(t, judge, `{ myApiTokens { id lastUsedAt } }`, nil, &tokens)
+	if len(tokens.MyAPITokens) != 2 || tokens.MyAPITokens[0].LastUsedAt == nil || tokens.MyAPITokens[1].LastUsedAt == nil {
+		t.Fatalf("got %+v, want both tokens marked as used", tokens.MyAPITokens)
+	}
+
+	if res := query(t, login(t, "viewer"), `mutation($id: ID!) { revokeApiToken(id: $id) }`, map[string]interface{}{"id": tokens.MyAPITokens[0].ID}, nil); res.status() != http.StatusNotFound {
+		t.Fatalf("got %+v, want a 404 error for another user's token", res.Errors)
+	}
+	mustQuery(t, judge, `mutation($id: ID!) { revokeApiToken(id: $id) }`, map[string]interface{}{"id": tokens.MyAPITokens[0].ID}, nil)
+	mustQuery(t, unscoped, `{ currentUser { isAdmin loggedIn } }`, nil, &current)
+	if current.CurrentUser.LoggedIn {
+		t.Fatal("a revoked token still works")
+	}
+}
+
+// Logs in through the stand-in identity provider as the given identity. Returns the session token
+// from the auth cookie, or the page the login ended on if it did not set one.
+func oidcLogin(t *testing.T, identity oidctest.Identity) (string, *url.URL) {
+	t.Helper()
+
+	testIdP.SetIdentity(identity)
+
+	// Follow the redirects through the provider until the browser is sent back to the app
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar, CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if req.URL.Host == "localhost" {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}}
+
+	res, err := client.Get(testServer.URL + oidc.LoginPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == "auth" {
+			return cookie.Value, location
+		}
+	}
+	return "", location
+}
+
+func TestOIDCLogin(t *testing.T) {
+	seed(t)
+
+	var loginURL struct {
+		OidcLoginURL *string `json:"oidcLoginUrl"`
+	}
+	mustQuery(t, "", `{ oidcLoginUrl }`, nil, &loginURL)
+	if loginURL.OidcLoginURL == nil || *loginURL.OidcLoginURL != "http://localhost/auth/oidc/login" {
+		t.Fatalf("got %v, want the single sign-on login URL", loginURL.OidcLoginURL)
+	}
+
+	var current struct {
+		CurrentUser struct {
+			LoggedIn bool `json:"loggedIn"`
+			User     struct {
+				ID string `json:"id"`
+			} `json:"user"`
+		} `json:"currentUser"`
+	}
+
+	// A verified email that belongs to one active user links the provider account to them
+	token, location := oidcLogin(t, oidctest.Identity{Subject: "sub-judge", Email: "judge@example.com", EmailVerified: true})
+	if token == "" || location.Path != "/" {
+		t.Fatalf("login ended at %s without a session, want to be logged in as the judge", location)
+	}
+	mustQuery(t, token, `{ currentUser { loggedIn user { id } } }`, nil, &current)
+	if !current.CurrentUser.LoggedIn || current.CurrentUser.User.ID != "2" {
+		t.Fatalf("got %+v, want to be logged in as the judge", current.CurrentUser)
+	}
+
+	// The linked subject keeps working when the email at the provider changes, and password
+	// logins keep working alongside it
+	token, location = oidcLogin(t, oidctest.Identity{Subject: "sub-judge", Email: "judge@elsewhere.example.com", EmailVerified: true})
+	if token == "" {
+		t.Fatalf("login ended at %s without a session, want the linked subject to log in", location)
+	}
+	login(t, "judge")
+
+	// Unverified and unknown emails, and locked accounts, are not logged in
+	for _, identity := range []oidctest.Identity{
+		{Subject: "sub-viewer", Email: "viewer@example.com", EmailVerified: false},
+		{Subject: "sub-unknown", Email: "nobody@example.com", EmailVerified: true},
+		{Subject: "sub-locked", Email: "locked@example.com", EmailVerified: true},
+	} {
+		if token, location := oidcLogin(t, identity); token != "" || location.Query().Get("error") != "sso_no_account" {
+			t.Fatalf("login as %+v ended at %s, want the sso_no_account error", identity, location)
+		}
+	}
+
+	// Once unlinked, the subject has to match by email again
+	if res := query(t, login(t, "manager"), `mutation { unlinkOidcIdentities(userId: 2) }`, nil, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error for a non-admin", res.Errors)
+	}
+	mustQuery(t, login(t, "admin"), `mutation { unlinkOidcIdentities(userId: 2) }`, nil, nil)
+	if token, location := oidcLogin(t, oidctest.Identity{Subject: "sub-judge", Email: "judge@elsewhere.example.com", EmailVerified: true}); token != "" {
+		t.Fatalf("login ended at %s with a session, want the unlinked subject to be refused", location)
+	}
+}
+
+const auditLogQuery = `query($filter: AuditLogFilter) {
+	auditLog(filter: $filter) {
+		totalCount
+		edges { node { mutation targetType targetIds before after actor { id } realActor { id } } }
+	}
+}`
+
+type auditLogNode struct {
+	Mutation   string  `json:"mutation"`
+	TargetType string  `json:"targetType"`
+	TargetIds  []int   `json:"targetIds"`
+	Before     *string `json:"before"`
+	After      *string `json:"after"`
+	Actor      *struct {
+		ID string `json:"id"`
+	} `json:"actor"`
+	RealActor *struct {
+		ID string `json:"id"`
+	} `json:"realActor"`
+}
+
+// Lists the audit log entries matching filter as the user holding token
+func auditLog(t *testing.T, token string, filter map[string]interface{}) (int, []auditLogNode) {
+	t.Helper()
+	var data struct {
+		AuditLog struct {
+			TotalCount int `json:"totalCount"`
+			Edges      []struct {
+				Node auditLogNode `json:"node"`
+			} `json:"edges"`
+		} `json:"auditLog"`
+	}
+	mustQuery(t, token, auditLogQuery, map[string]interface{}{"filter": filter}, &data)
+	nodes := make([]auditLogNode, len(data.AuditLog.Edges))
+	for i, edge := range data.AuditLog.Edges {
+		nodes[i] = edge.Node
+	}
+	return data.AuditLog.TotalCount, nodes
+}
+
+func TestAuditLog(t *testing.T) {
+	seed(t)
+	admin := login(t, "admin")
+
+	// Refused mutations are not recorded
+	if res := query(t, login(t, "viewer"), `mutation { disqualifyEntry(id: 1) { id } }`, nil, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error", res.Errors)
+	}
+	if total, _ := auditLog(t, admin, nil); total != 0 {
+		t.Fatalf("got %d entries, want none for a refused mutation", total)
+	}
+
+	// A change records the target as it was before and after
+	mustQuery(t, admin, `mutation { disqualifyEntry(id: 1) { id } }`, nil, nil)
+	total, nodes := auditLog(t, admin, map[string]interface{}{"targetType": "ENTRY", "targetId": 1})
+	if total != 1 || nodes[0].Mutation != "disqualifyEntry" || nodes[0].Actor == nil || nodes[0].Actor.ID != "1" || nodes[0].RealActor != nil {
+		t.Fatalf("got %+v, want one disqualifyEntry entry by the admin", nodes)
+	}
+	if nodes[0].Before == nil || !strings.Contains(*nodes[0].Before, `"isDisqualified":false`) ||
+		nodes[0].After == nil || !strings.Contains(*nodes[0].After, `"isDisqualified":true`) {
+		t.Fatalf("got before %v and after %v, want the entry to change to disqualified", nodes[0].Before, nodes[0].After)
+	}
+
+	// Changes made while impersonating record both the impersonated user and the real one
+	organizer := mustQuery(t, admin, `mutation { impersonateUser(id: 6, reason: "Support request") { success } }`, nil, nil).sessionToken()
+	mustQuery(t, organizer, `mutation { assignAllEntriesToGroups(contestId: 1) }`, nil, nil)
+	total, nodes = auditLog(t, admin, map[string]interface{}{"mutation": "assignAllEntriesToGroups"})
+	if total != 1 || nodes[0].TargetType != "CONTEST" || len(nodes[0].TargetIds) != 1 || nodes[0].TargetIds[0] != 1 ||
+		nodes[0].Actor == nil || nodes[0].Actor.ID != "6" || nodes[0].RealActor == nil || nodes[0].RealActor.ID != "1" {
+		t.Fatalf("got %+v, want one entry by the organizer on behalf of the admin", nodes)
+	}
+
+	// Filtering by actor matches the real actor as well
+	if total, _ := auditLog(t, admin, map[string]interface{}{"actorId": 1}); total != 3 {
+		t.Fatalf("got %d entries, want the admin's 3 entries", total)
+	}
+
+	// Only admins can read the log
+	if res := query(t, login(t, "manager"), auditLogQuery, nil, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error for a non-admin", res.Errors)
+	}
+}
+
+const impersonationsQuery = `query($activeOnly: Boolean) {
+	impersonations(activeOnly: $activeOnly) {
+		totalCount
+		edges { node { reason isReadOnly isActive endedAt impersonator { id } user { id } } }
+	}
+}`
+
+func TestImpersonation(t *testing.T) {
+	seed(t)
+	admin := login(t, "admin")
+	manager := login(t, "manager")
+
+	type impersonationsResult struct {
+		Impersonations struct {
+			TotalCount int `json:"totalCount"`
+			Edges      []struct {
+				Node struct {
+					Reason       string     `json:"reason"`
+					IsReadOnly   bool       `json:"isReadOnly"`
+					IsActive     bool       `json:"isActive"`
+					EndedAt      *time.Time `json:"endedAt"`
+					Impersonator struct {
+						ID string `json:"id"`
+					} `json:"impersonator"`
+					User struct {
+						ID string `json:"id"`
+					} `json:"user"`
+				} `json:"node"`
+			} `json:"edges"`
+		} `json:"impersonations"`
+	}
+
+	// A reason is required
+	if res := query(t, manager, `mutation { impersonateUser(id: 3, reason: "  ") { success } }`, nil, nil); res.status() != http.StatusBadRequest {
+		t.Fatalf("got %+v, want a 400 error", res.Errors)
+	}
+
+	// The session ends after the impersonation lifetime rather than the usual session lifetime
+	var started struct {
+		ImpersonateUser struct {
+			ExpiresAt *time.Time `json:"expiresAt"`
+		} `json:"impersonateUser"`
+	}
+	viewer := mustQuery(t, manager, `mutation { impersonateUser(id: 3, reason: "Checking what viewers see", readOnly: true) { expiresAt } }`, nil, &started).sessionToken()
+	if started.ImpersonateUser.ExpiresAt == nil || time.Until(*started.ImpersonateUser.ExpiresAt) > auth.ImpersonationLifetime {
+		t.Fatalf("got expiry %v, want it within %s", started.ImpersonateUser.ExpiresAt, auth.ImpersonationLifetime)
+	}
+
+	var current struct {
+		CurrentUser struct {
+			LoggedIn       bool `json:"loggedIn"`
+			IsImpersonated bool `json:"isImpersonated"`
+			IsReadOnly     bool `json:"isReadOnly"`
+		} `json:"currentUser"`
+	}
+	mustQuery(t, viewer, `{ currentUser { loggedIn isImpersonated isReadOnly } }`, nil, &current)
+	if !current.CurrentUser.IsImpersonated || !current.CurrentUser.IsReadOnly {
+		t.Fatalf("got %+v, want a read-only impersonation", current.CurrentUser)
+	}
+
+	// Read-only sessions can run queries but not mutations
+	res := query(t, viewer, `mutation { revokeSession(id: 1) }`, nil, nil)
+	if res.status() != http.StatusForbidden || !strings.Contains(res.Errors[0].Message, "read-only") {
+		t.Fatalf("got %+v, want a 403 error for a read-only session", res.Errors)
+	}
+
+	// Admins can see who is impersonating whom and why
+	var list impersonationsResult
+	mustQuery(t, admin, impersonationsQuery, map[string]interface{}{"activeOnly": true}, &list)
+	if list.Impersonations.TotalCount != 1 {
+		t.Fatalf("got %d active impersonations, want 1", list.Impersonations.TotalCount)
+	}
+	node := list.Impersonations.Edges[0].Node
+	if node.Reason != "Checking what viewers see" || !node.IsReadOnly || !node.IsActive || node.Impersonator.ID != "7" || node.User.ID != "3" {
+		t.Fatalf("got %+v, want the manager's read-only impersonation of the viewer", node)
+	}
+	if res := query(t, manager, impersonationsQuery, nil, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error for a non-admin", res.Errors)
+	}
+
+	// Returning is allowed in read-only mode and ends the impersonation
+	mustQuery(t, viewer, `mutation { returnFromImpersonation { success } }`, nil, nil)
+	mustQuery(t, admin, impersonationsQuery, nil, &list)
+	if list.Impersonations.TotalCount != 1 || list.Impersonations.Edges[0].Node.IsActive || list.Impersonations.Edges[0].Node.EndedAt == nil {
+		t.Fatalf("got %+v, want one ended impersonation", list.Impersonations)
+	}
+
+	// Impersonations that are not read-only can make changes until they expire
+	organizer := mustQuery(t, admin, `mutation { impersonateUser(id: 6, reason: "Fixing entry groups") { expiresAt } }`, nil, &started).sessionToken()
+	mustQuery(t, organizer, `mutation { assignAllEntriesToGroups(contestId: 1) }`, nil, nil)
+
+	// Credentials would outlast the impersonation, so they cannot be made while impersonating
+	if res := query(t, organizer, createAPITokenMutation, map[string]interface{}{"input": map[string]interface{}{"name": "script", "permissions": []string{}}}, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error when creating a token while impersonating", res.Errors)
+	}
+	if res := query(t, organizer, `mutation { changePassword(id: 6, password: "new password") }`, nil, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error when changing a password while impersonating", res.Errors)
+	}
+
+	if _, err := db.DB.Exec("UPDATE user_session SET expires = now() - interval '1 second' WHERE impersonated_by_user IS NOT NULL;"); err != nil {
+		t.Fatal(err)
+	}
+	mustQuery(t, organizer, `{ currentUser { loggedIn isImpersonated isReadOnly } }`, nil, &current)
+	if current.CurrentUser.LoggedIn {
+		t.Fatalf("got %+v, want the expired impersonation to be logged out", current.CurrentUser)
+	}
+	mustQuery(t, admin, impersonationsQuery, map[string]interface{}{"activeOnly": true}, &list)
+	if list.Impersonations.TotalCount != 0 {
+		t.Fatalf("got %d active impersonations, want none after they expire", list.Impersonations.TotalCount)
+	}
+}
+
+func TestTermDates(t *testing.T) {
+	seed(t)
+	admin := login(t, "admin")
+	judge := login(t, "judge")
+
+	mailDir := t.TempDir()
+	repos := models.NewPostgresRepositories(db.DB)
+	applyTermDates := jobs.ApplyTermDates(repos.Lifecycle, repos.Users, &mail.LogMailer{Dir: mailDir, From: "noreply@example.com"}, "https://example.com", 14*24*time.Hour)
+	run := func() {
+		t.Helper()
+		if err := applyTermDates(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mailsTo := func(to string) int {
+		files, _ := filepath.Glob(filepath.Join(mailDir, "*-"+to+".eml"))
+		return len(files)
+	}
+	setTermEnd := func(id int, username string, nickname string, days int, locked bool) {
+		t.Helper()
+		termEnd := time.Now().UTC().AddDate(0, 0, days).Format("2006-01-02")
+		mustQuery(t, admin, `mutation($id: ID!, $input: EditUserProfileInput!) { editUserProfile(id: $id, input: $input) { id } }`, map[string]interface{}{
+			"id": id,
+			"input": map[string]interface{}{
+				"name": nickname + " User", "email": username + "@example.com", "kaid": "kaid_" + username, "username": username, "nickname": nickname,
+				"termStart": "2022-01-01", "termEnd": termEnd, "isAdmin": false, "accountLocked": locked, "notificationsEnabled": false,
+			},
+		}, nil)
+	}
+
+	// The judge's term has ended and the viewer's ends soon
+	setTermEnd(2, "judge", "Judge", -1, false)
+	setTermEnd(3, "viewer", "Viewer", 3, false)
+	run()
+
+	var current struct {
+		CurrentUser struct {
+			LoggedIn bool `json:"loggedIn"`
+		} `json:"currentUser"`
+	}
+	mustQuery(t, judge, `{ currentUser { loggedIn } }`, nil, &current)
+	if current.CurrentUser.LoggedIn {
+		t.Fatal("the judge is still logged in, want their sessions ended")
+	}
+	var data loginResponse
+	mustQuery(t, "", loginMutation, map[string]interface{}{"username": "judge", "password": fixturePassword}, &data)
+	if data.Login.Success || !data.Login.IsDisabled {
+		t.Fatalf("got %+v, want the judge's account locked", data.Login)
+	}
+	var group *int
+	if err := db.DB.QueryRow("SELECT group_id FROM evaluator WHERE evaluator_id = 2;").Scan(&group); err != nil || group != nil {
+		t.Fatalf("got group %v (%v), want the judge removed from their group", group, err)
+	}
+	login(t, "viewer")
+
+	// Warnings are sent once
+	run()
+	if mailsTo("viewer@example.com") != 1 || mailsTo("admin@example.com") != 1 {
+		t.Fatalf("got %d emails to the viewer and %d to the admin, want one each", mailsTo("viewer@example.com"), mailsTo("admin@example.com"))
+	}
+
+	// Renewing the term reactivates the account, while accounts locked by an admin stay locked
+	setTermEnd(2, "judge", "Judge", 365, true)
+	run()
+	login(t, "judge")
+	mustQuery(t, "", loginMutation, map[string]interface{}{"username": "locked", "password": fixturePassword}, &data)
+	if !data.Login.IsDisabled {
+		t.Fatalf("got %+v, want the locked account to stay locked", data.Login)
+	}
+}
+
+const inviteUserMutation = `mutation($input: InviteUserInput!) { inviteUser(input: $input) { id status link role { id } group { id } } }`
+
+const acceptInvitationMutation = `mutation($token: String!, $username: String!) { acceptInvitation(token: $token, username: $username, password: "new password") }`
+
+func inviteInput(email string, roleId int) map[string]interface{} {
+	return map[string]interface{}{
+		"name": "New User", "email": email, "kaid": "kaid_" + email, "termStart": "2022-01-01", "termEnd": "2030-01-01", "roleId": roleId, "groupId": 1,
+	}
+}
+
+func TestInvitations(t *testing.T) {
+	seed(t)
+	admin := login(t, "admin")
+
+	var invited struct {
+		InviteUser struct {
+			ID     int    `json:"id"`
+			Status string `json:"status"`
+			Link   string `json:"link"`
+			Role   struct {
+				ID int `json:"id"`
+			} `json:"role"`
+			Group struct {
+				ID int `json:"id"`
+			} `json:"group"`
+		} `json:"inviteUser"`
+	}
+	mustQuery(t, admin, inviteUserMutation, map[string]interface{}{"input": inviteInput("new@example.com", 1)}, &invited)
+	if invited.InviteUser.Status != "PENDING" || invited.InviteUser.Role.ID != 1 || invited.InviteUser.Group.ID != 1 {
+		t.Fatalf("got %+v, want a pending invitation with the role and group", invited.InviteUser)
+	}
+	link, err := url.Parse(invited.InviteUser.Link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := link.Query().Get("token")
+
+	// The link is also emailed to the invitee
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+		if files, _ := filepath.Glob(filepath.Join(testMailDir, "*-new@example.com.eml")); len(files) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no invitation email was sent")
+		}
+	}
+
+	// Taken usernames are rejected without using up the link
+	if res := query(t, "", acceptInvitationMutation, map[string]interface{}{"token": token, "username": "Judge"}, nil); res.status() != http.StatusBadRequest {
+		t.Fatalf("got %+v, want a 400 error for a taken username", res.Errors)
+	}
+
+	mustQuery(t, "", acceptInvitationMutation, map[string]interface{}{"token": token, "username": "newuser"}, nil)
+	var data loginResponse
+	mustQuery(t, "", loginMutation, map[string]interface{}{"username": "newuser", "password": "new password"}, &data)
+	if !data.Login.Success {
+		t.Fatalf("got %+v, want the new account to log in", data.Login)
+	}
+
+	// The link only works once
+	if res := query(t, "", acceptInvitationMutation, map[string]interface{}{"token": token, "username": "otheruser"}, nil); res.status() != http.StatusBadRequest {
+		t.Fatalf("got %+v, want a 400 error for a used invitation", res.Errors)
+	}
+
+	// Revoked invitations cannot be accepted
+	mustQuery(t, admin, inviteUserMutation, map[string]interface{}{"input": inviteInput("revoked@example.com", 1)}, &invited)
+	link, _ = url.Parse(invited.InviteUser.Link)
+	mustQuery(t, admin, `mutation($id: ID!) { revokeInvitation(id: $id) }`, map[string]interface{}{"id": invited.InviteUser.ID}, nil)
+	if res := query(t, "", acceptInvitationMutation, map[string]interface{}{"token": link.Query().Get("token"), "username": "revokeduser"}, nil); res.status() != http.StatusBadRequest {
+		t.Fatalf("got %+v, want a 400 error for a revoked invitation", res.Errors)
+	}
+
+	var list struct {
+		Invitations []struct {
+			Status string  `json:"status"`
+			Link   *string `json:"link"`
+		} `json:"invitations"`
+	}
+	mustQuery(t, admin, `{ invitations(includeInactive: true) { status link } }`, nil, &list)
+	if len(list.Invitations) != 2 {
+		t.Fatalf("got %d invitations, want 2", len(list.Invitations))
+	}
+	for _, inv := range list.Invitations {
+		if inv.Link != nil {
+			t.Fatal("a listed invitation includes its link")
+		}
+	}
+
+	if res := query(t, login(t, "viewer"), inviteUserMutation, map[string]interface{}{"input": inviteInput("viewer2@example.com", 1)}, nil); res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error", res.Errors)
+	}
+}
+
+// Builds a GraphQL request with the session and CSRF cookies and the CSRF header, leaving out any that are empty
+func csrfRequest(t *testing.T, session string, cookie string, header string, q string) *http.Request {
+	t.Helper()
+
+	body, err := json.Marshal(map[string]interface{}{"query": q})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest(http.MethodPost, testServer.URL+"/api/internal/graphql", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if session != "" {
+		req.AddCookie(&http.Cookie{Name: auth.SessionCookie, Value: session})
+	}
+	if cookie != "" {
+		req.AddCookie(&http.Cookie{Name: auth.CSRFCookie, Value: cookie})
+	}
+	if header != "" {
+		req.Header.Set(auth.CSRFHeader, header)
+	}
+	return req
+}
+
+func TestSessionCookies(t *testing.T) {
+	seed(t)
+
+	// The session token is only given out in an HttpOnly cookie
+	var data struct {
+		Login struct {
+			Success bool    `json:"success"`
+			Token   *string `json:"token"`
+		} `json:"login"`
+	}
+	res := mustQuery(t, "", `mutation { login(username: "manager", password: "password") { success token } }`, nil, &data)
+	if !data.Login.Success || data.Login.Token != nil {
+		t.Fatalf("got %+v, want a successful login without the token in the body", data.Login)
+	}
+	var session *http.Cookie
+	for _, cookie := range res.cookies {
+		if cookie.Name == auth.SessionCookie {
+			session = cookie
+		}
+	}
+	if session == nil || !session.HttpOnly || session.SameSite != http.SameSiteLaxMode || session.Path != "/" || session.MaxAge <= 0 {
+		t.Fatalf("got session cookie %+v, want an HttpOnly, SameSite cookie", session)
+	}
+	manager := session.Value
+
+	// Gets past the CSRF check only to find no such session
+	const mutation = `mutation { revokeSession(id: 0) }`
+	const current = `{ currentUser { loggedIn } }`
+
+	// Clients without a CSRF token are given one, but cannot make changes until they send it back
+	res = send(t, csrfRequest(t, manager, "", "", mutation), nil)
+	if res.status() != http.StatusForbidden {
+		t.Fatalf("got %+v, want a 403 error without a CSRF token", res.Errors)
+	}
+	issued := ""
+	for _, cookie := range res.cookies {
+		if cookie.Name == auth.CSRFCookie && !cookie.HttpOnly {
+			issued = cookie.Value
+		}
+	}
+	if issued == "" {
+		t.Fatal("no CSRF cookie was issued")
+	}
+
+	for _, tt := range []struct {
+		name   string
+		header string
+	}{
+		{"cookie without the header", ""},
+		{"header that does not match the cookie", issued + "x"},
+	} {
+		if res := send(t, csrfRequest(t, manager, issued, tt.header, mutation), nil); res.status() != http.StatusForbidden {
+			t.Fatalf("%s: got %+v, want a 403 error", tt.name, res.Errors)
+		}
+		if res := send(t, csrfRequest(t, "", issued, tt.header, `mutation { login(username: "judge", password: "password") { success } }`), nil); res.status() != http.StatusForbidden {
+			t.Fatalf("%s: got %+v, want a 403 error for a login", tt.name, res.Errors)
+		}
+	}
+
+	// Queries do not need the token
+	var loggedIn struct {
+		CurrentUser struct {
+			LoggedIn bool `json:"loggedIn"`
+		} `json:"currentUser"`
+	}
+	if res := send(t, csrfRequest(t, manager, "", "", current), &loggedIn); len(res.Errors) > 0 || !loggedIn.CurrentUser.LoggedIn {
+		t.Fatalf("got %+v (%+v), want the query to run without a CSRF token", loggedIn, res.Errors)
+	}
+	if res := send(t, csrfRequest(t, manager, issued, issued, mutation), nil); res.status() != http.StatusNotFound {
+		t.Fatalf("got %+v, want the mutation to run with the CSRF token", res.Errors)
+	}
+
+	// Browsers never send API tokens on their own, so requests using them need no CSRF token
+	req := csrfRequest(t, "", "", "", mutation)
+	req.Header.Set("Authorization", "Bearer "+createAPIToken(t, login(t, "judge")))
+	if res := send(t, req, nil); res.status() != http.StatusForbidden || strings.Contains(res.Errors[0].Message, auth.CSRFHeader) {
+		t.Fatalf("got %+v, want only the API token restriction to apply", res.Errors)
+	}
+
+	// Impersonating swaps the cookie for a short-lived one, and returning swaps it back
+	res = mustQuery(t, manager, `mutation { impersonateUser(id: 3, reason: "Support request") { success } }`, nil, nil)
+	viewer := res.sessionToken()
+	for _, cookie := range res.cookies {
+		if cookie.Name == auth.SessionCookie && (!cookie.HttpOnly || cookie.MaxAge > int(auth.ImpersonationLifetime.Seconds())) {
+			t.Fatalf("got %+v, want an HttpOnly cookie lasting the impersonation", cookie)
+		}
+	}
+	manager = mustQuery(t, viewer, `mutation { returnFromImpersonation { success } }`, nil, nil).sessionToken()
+	if manager == "" {
+		t.Fatal("returning from impersonation did not set a session cookie")
+	}
+
+	// Logging out clears the cookie
+	res = mustQuery(t, manager, `mutation { logout }`, nil, nil)
+	cleared := false
+	for _, cookie := range res.cookies {
+		cleared = cleared || (cookie.Name == auth.SessionCookie && cookie.MaxAge < 0)
+	}
+	if !cleared {
+		t.Fatalf("got cookies %+v, want the session cookie cleared", res.cookies)
+	}
+	mustQuery(t, manager, current, nil, &loggedIn)
+	if loggedIn.CurrentUser.LoggedIn {
+		t.Fatal("the session still works after logging out")
+	}
+}
+
+const editEvaluationMutation = `mutation($id: ID!, $input: EditEvaluationInput!) { editEvaluation(id: $id, input: $input) { id skillLevel } }`
+
+const transitionContestMutation = `mutation($id: ID!, $state: ContestState!) { transitionContest(id: $id, state: $state) { id state isCurrent isVotingEnabled } }`
+
+func TestContestStates(t *testing.T) {
+	seed(t)
+	admin := login(t, "admin")
+	judge := login(t, "judge")
+	viewer := login(t, "viewer")
+
+	transition := func(state string) {
+		t.Helper()
+		var data struct {
+			TransitionContest struct {
+				State string `json:"state"`
+			} `json:"transitionContest"`
+		}
+		mustQuery(t, admin, transitionContestMutation, map[string]interface{}{"id": "1", "state": state}, &data)
+		if data.TransitionContest.State != state {
+			t.Fatalf("got state %s, want %s", data.TransitionContest.State, state)
+		}
+	}
+	winners := func(token string) (int, bool) {
+		t.Helper()
+		var data struct {
+			Contest struct {
+				Winners []struct {
+					ID string `json:"id"`
+				} `json:"winners"`
+			} `json:"contest"`
+			Entry struct {
+				IsWinner bool `json:"isWinner"`
+			} `json:"entry"`
+		}
+		mustQuery(t, token, `{ contest(id: 1) { winners { id } } entry(id: 1) { isWinner } }`, nil, &data)
+		return len(data.Contest.Winners), data.Entry.IsWinner
+	}
+	vote := `mutation { createEntryVote(entryId: 1, reason: "Great entry") { id } }`
+
+	t.Run("rejects transitions that skip a state", func(t *testing.T) {
+		for _, state := range []string{"DRAFT", "RESULTS_PUBLISHED", "JUDGING"} {
+			res := query(t, admin, transitionContestMutation, map[string]interface{}{"id": "1", "state": state}, nil)
+			if res.status() != http.StatusBadRequest {
+				t.Fatalf("moving to %s: got %+v, want a 400 error", state, res.Errors)
+			}
+		}
+	})
+
+	t.Run("requires the edit contests permission", func(t *testing.T) {
+		res := query(t, judge, transitionContestMutation, map[string]interface{}{"id": "1", "state": "VOTING"}, nil)
+		if res.status() != http.StatusForbidden {
+			t.Fatalf("got %+v, want a 403 error", res.Errors)
+		}
+	})
+
+	t.Run("gates actions on the state", func(t *testing.T) {
+		// Judging: entries can be scored, but not voted for
+		var scored scoreEntryResponse
+		mustQuery(t, judge, scoreEntryMutation, map[string]interface{}{"id": "1", "input": scoreInput("Beginner")}, &scored)
+		editEvaluation := map[string]interface{}{"id": scored.ScoreEntry.ID, "input": scoreInput("Advanced")}
+		mustQuery(t, judge, editEvaluationMutation, editEvaluation, nil)
+		if res := query(t, judge, vote, nil, nil); res.status() != http.StatusForbidden {
+			t.Fatalf("got %+v, want voting to be forbidden while judging", res.Errors)
+		}
+		mustQuery(t, admin, `mutation { addWinner(id: 1) { id } }`, nil, nil)
+
+		// Voting: entries can be voted for, but not scored or imported
+		transition("VOTING")
+		mustQuery(t, judge, vote, nil, nil)
+		if res := query(t, judge, scoreEntryMutation, map[string]interface{}{"id": "1", "input": scoreInput("Beginner")}, nil); res.status() != http.StatusForbidden {
+			t.Fatalf("got %+v, want scoring to be forbidden while voting", res.Errors)
+		}
+		for _, token := range []string{judge, admin} {
+			if res := query(t, token, editEvaluationMutation, editEvaluation, nil); res.status() != http.StatusForbidden {
+				t.Fatalf("got %+v, want editing evaluations to be forbidden while voting", res.Errors)
+			}
+		}
+		if res := query(t, admin, `mutation($id: ID!) { deleteEvaluation(id: $id) { id } }`, map[string]interface{}{"id": scored.ScoreEntry.ID}, nil); res.status() != http.StatusForbidden {
+			t.Fatalf("got %+v, want deleting evaluations to be forbidden while voting", res.Errors)
+		}
+		if res := query(t, admin, `mutation { importEntry(contestId: 1, kaid: "1005") { id } }`, nil, nil); res.status() != http.StatusForbidden {
+			t.Fatalf("got %+v, want importing to be forbidden while voting", res.Errors)
+		}
+
+		// Winners are hidden until the results are published, except from those who pick them
+		if count, isWinner := winners(viewer); count != 0 || isWinner {
+			t.Fatalf("got %d winners (entry 1 winner: %v), want winners hidden from the viewer", count, isWinner)
+		}
+		if count, isWinner := winners(admin); count != 1 || !isWinner {
+			t.Fatalf("got %d winners (entry 1 winner: %v), want the admin to see the winner", count, isWinner)
+		}
+
+		transition("RESULTS_PUBLISHED")
+		if count, isWinner := winners(viewer); count != 1 || !isWinner {
+			t.Fatalf("got %d winners (entry 1 winner: %v), want the published winner", count, isWinner)
+		}
+		if res := query(t, judge, vote, nil, nil); res.status() != http.StatusForbidden {
+			t.Fatalf("got %+v, want voting to be forbidden once the results are published", res.Errors)
+		}
+	})
+
+	t.Run("archived contests cannot move", func(t *testing.T) {
+		transition("ARCHIVED")
+		res := query(t, admin, transitionContestMutation, map[string]interface{}{"id": "1", "state": "RESULTS_PUBLISHED"}, nil)
+		if res.status() != http.StatusBadRequest {
+			t.Fatalf("got %+v, want a 400 error", res.Errors)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+-- Fixtures loaded by the end-to-end tests in server_test.go. Every user's password is "password".
+
+INSERT INTO evaluator_group (group_id, group_name, is_active) VALUES
+    (1, 'Group A', true),
+    (2, 'Group B', true),
+    (3, 'Retired Group', false);
+
+INSERT INTO evaluator (evaluator_id, evaluator_kaid, evaluator_name, nickname, username, email, password, account_locked, is_admin, dt_term_start, dt_term_end, group_id) VALUES
+    (1, 'kaid_admin', 'Admin User', 'Admin', 'admin', 'admin@example.com', '$2a$10$SIdp8W4oblaRqyYGIx63d.VNk3oUtOEvzMjToH702xX0A7tiSoZbq', false, true, '2022-01-01', '2030-01-01', NULL),
+    (2, 'kaid_judge', 'Judge User', 'Judge', 'judge', 'judge@example.com', '$2a$10$SIdp8W4oblaRqyYGIx63d.VNk3oUtOEvzMjToH702xX0A7tiSoZbq', false, false, '2022-01-01', '2030-01-01', 1),
+    (3, 'kaid_viewer', 'Viewer User', 'Viewer', 'viewer', 'viewer@example.com', '$2a$10$SIdp8W4oblaRqyYGIx63d.VNk3oUtOEvzMjToH702xX0A7tiSoZbq', false, false, '2022-01-01', '2030-01-01', 1),
+    (4, 'kaid_locked', 'Locked User', 'Locked', 'locked', 'locked@example.com', '$2a$10$SIdp8W4oblaRqyYGIx63d.VNk3oUtOEvzMjToH702xX0A7tiSoZbq', true, false, '2022-01-01', '2023-01-01', 1),
+    (5, 'kaid_editor', 'Editor User', 'Editor', 'editor', 'editor@example.com', '$2a$10$SIdp8W4oblaRqyYGIx63d.VNk3oUtOEvzMjToH702xX0A7tiSoZbq', false, false, '2022-01-01', '2030-01-01', NULL),
+    (6, 'kaid_organizer', 'Organizer User', 'Organizer', 'organizer', 'organizer@example.com', '$2a$10$SIdp8W4oblaRqyYGIx63d.VNk3oUtOEvzMjToH702xX0A7tiSoZbq', false, false, '2022-01-01', '2030-01-01', NULL),
+    (7, 'kaid_manager', 'Manager User', 'Manager', 'manager', 'manager@example.com', '$2a$10$SIdp8W4oblaRqyYGIx63d.VNk3oUtOEvzMjToH702xX0A7tiSoZbq', false, false, '2022-01-01', '2030-01-01', NULL);
+
+-- The judge and editor get their permissions from roles, the others from direct grants
+INSERT INTO role (role_id, role_name, role_description) VALUES
+    (1, 'Evaluator', 'Scores entries'),
+    (2, 'KB Editor', 'Edits and publishes KB content');
+
+INSERT INTO role_permission (role_id, permission_key) VALUES
+    (1, 'JUDGE_ENTRIES'),
+    (2, 'EDIT_KB_CONTENT'),
+    (2, 'PUBLISH_KB_CONTENT');
+
+INSERT INTO evaluator_role (evaluator_id, role_id) VALUES
+    (2, 1),
+    (5, 2);
+
+INSERT INTO evaluator_permission (evaluator_id, permission_key) VALUES
+    (4, 'JUDGE_ENTRIES'),
+    (6, 'ASSIGN_ENTRY_GROUPS'),
+    (7, 'EDIT_USER_PROFILES'),
+    (7, 'CHANGE_USER_PASSWORDS'),
+    (7, 'ASSUME_USER_IDENTITIES'),
+    (7, 'JUDGE_ENTRIES');
+
+INSERT INTO contest (contest_id, contest_name, contest_url, contest_author, date_start, date_end, contest_state) VALUES
+    (1, 'Current Contest', 'https://www.khanacademy.org/computer-programming/current/1', 'Council', '2022-06-01', '2022-06-30', 'JUDGING'),
+    (2, 'Past Contest', 'https://www.khanacademy.org/computer-programming/past/2', 'Council', '2022-01-01', '2022-01-31', 'ARCHIVED');
+
+-- Contest 1 has two entries waiting for Group A, one for Group B and a flagged entry that is never judged.
+-- Contest 2's entries have not been assigned to groups yet, and one of them is disqualified.
+INSERT INTO entry (entry_id, contest_id, entry_url, entry_kaid, entry_title, entry_author, entry_author_kaid, assigned_group_id, flagged, disqualified) VALUES
+    (1, 1, 'https://www.khanacademy.org/computer-programming/a/1001', '1001', 'Entry A1', 'Author One', 'kaid_author1', 1, false, false),
+    (2, 1, 'https://www.khanacademy.org/computer-programming/a/1002', '1002', 'Entry A2', 'Author Two', 'kaid_author2', 1, false, false),
+    (3, 1, 'https://www.khanacademy.org/computer-programming/b/1003', '1003', 'Entry B1', 'Author Three', 'kaid_author3', 2, false, false),
+    (4, 1, 'https://www.khanacademy.org/computer-programming/a/1004', '1004', 'Entry A3', 'Author Four', 'kaid_author4', 1, true, false),
+    (5, 2, 'https://www.khanacademy.org/computer-programming/p/2001', '2001', 'Past Entry 1', 'Author One', 'kaid_author1', NULL, false, false),
+    (6, 2, 'https://www.khanacademy.org/computer-programming/p/2002', '2002', 'Past Entry 2', 'Author Two', 'kaid_author2', NULL, false, false),
+    (7, 2, 'https://www.khanacademy.org/computer-programming/p/2003', '2003', 'Past Entry 3', 'Author Three', 'kaid_author3', NULL, false, false),
+    (8, 2, 'https://www.khanacademy.org/computer-programming/p/2004', '2004', 'Past Entry 4', 'Author Four', 'kaid_author4', NULL, false, false),
+    (9, 2, 'https://www.khanacademy.org/computer-programming/p/2005', '2005', 'Past Entry 5', 'Author Five', 'kaid_author5', NULL, false, true);
+
+INSERT INTO kb_section (section_id, section_name, section_description, section_visibility) VALUES
+    (1, 'Judging', 'How to judge entries', 'Public');
+
+-- Article 1 has an unpublished draft, article 2 does not
+INSERT INTO kb_article (article_id, section_id, article_name, article_content, article_author, article_visibility, is_published) VALUES
+    (1, 1, 'Scoring Guide', 'Old content', 5, 'Public', true),
+    (2, 1, 'Skill Levels', 'Published content', 5, 'Public', true);
+
+INSERT INTO kb_article_draft (draft_id, article_id, draft_name, draft_content, draft_author, is_published) VALUES
+    (1, 1, 'Scoring Guide (Revised)', 'New content', 5, false),
+    (2, 2, 'Skill Levels', 'Published content', 5, true);
+
+-- Move the sequences past the fixed ids above
+SELECT setval('evaluator_group_group_id_seq', (SELECT MAX(group_id) FROM evaluator_group));
+SELECT setval('evaluator_evaluator_id_seq', (SELECT MAX(evaluator_id) FROM evaluator));
+SELECT setval('role_role_id_seq', (SELECT MAX(role_id) FROM role));
+SELECT setval('contest_contest_id_seq', (SELECT MAX(contest_id) FROM contest));
+SELECT setval('entry_entry_id_seq', (SELECT MAX(entry_id) FROM entry));
+SELECT setval('kb_section_section_id_seq', (SELECT MAX(section_id) FROM kb_section));
+SELECT setval('kb_article_article_id_seq', (SELECT MAX(article_id) FROM kb_article));
+SELECT setval('kb_article_draft_draft_id_seq', (SELECT MAX(draft_id) FROM kb_article_draft));